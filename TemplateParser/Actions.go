@@ -0,0 +1,66 @@
+package TemplateParser
+
+// SetAction
+// sets the Action of every entry registered under a mnemonic, such as entries loaded from
+// a grammar file, which cannot name Go functions. A nil action removes it. It reports
+// whether the mnemonic is registered.
+func (p *Parser) SetAction(name string, action func([]ObjectType) error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	variants, found := p.registry[p.mnemonicKey(name)]
+	for _, entry := range variants {
+		entry.Action = action
+	}
+	return found
+}
+
+// SetContextAction
+// sets the ContextAction of every entry registered under a mnemonic, as SetAction sets
+// their Action. It reports whether the mnemonic is registered.
+func (p *Parser) SetContextAction(name string, action func(*ParseContext, []ObjectType) error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	variants, found := p.registry[p.mnemonicKey(name)]
+	for _, entry := range variants {
+		entry.ContextAction = action
+	}
+	return found
+}
+
+// runAction
+// invokes the Action and then the ContextAction of the entry a line matched, or the Handler
+// of the directive it matched, if the line succeeded, marking the line as failed with the
+// first error. The ContextAction gets a copy
+// of ctx located at the line; a nil ctx stands for an empty context.
+func runAction(lr *LineResult, ctx *ParseContext) {
+	if lr.Ok && lr.directive != nil && lr.directive.Handler != nil {
+		if err := lr.directive.Handler(ctx.at(lr.File, lr.LineNumber), lr.Objects); err != nil {
+			lr.Ok, lr.Error = false, err.Error()
+		}
+		return
+	}
+	if !lr.Ok || lr.entry == nil {
+		return
+	}
+	if lr.entry.Action != nil {
+		if err := lr.entry.Action(lr.Objects); err != nil {
+			lr.Ok, lr.Error = false, err.Error()
+			return
+		}
+	}
+	if lr.entry.ContextAction != nil {
+		if err := lr.entry.ContextAction(ctx.at(lr.File, lr.LineNumber), lr.Objects); err != nil {
+			lr.Ok, lr.Error = false, err.Error()
+		}
+	}
+}
+
+// runActions
+// invokes the actions of successfully matched lines in order.
+func runActions(lines []LineResult, ctx *ParseContext) {
+	for idx := range lines {
+		runAction(&lines[idx], ctx)
+	}
+}
@@ -0,0 +1,128 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Region
+// is a contiguous range of addresses [Start, End) filled by consecutive source lines.
+type Region struct {
+	Start     uint64 `json:"start"`
+	End       uint64 `json:"end"`
+	FirstLine int    `json:"first_line"`
+	LastLine  int    `json:"last_line"`
+}
+
+// Size
+// returns the number of addresses covered by the region.
+func (r Region) Size() uint64 {
+	return r.End - r.Start
+}
+
+// Overlap
+// reports two regions sharing at least one address.
+type Overlap struct {
+	First  Region `json:"first"`
+	Second Region `json:"second"`
+}
+
+// AddressMap
+// describes the memory layout of a parsed source: where every symbol lives, which address
+// ranges the code occupies and which of those ranges overlap.
+type AddressMap struct {
+	Symbols  []Symbol  `json:"symbols"`
+	Regions  []Region  `json:"regions"`
+	Overlaps []Overlap `json:"overlaps"`
+}
+
+// lineSize
+// returns the number of addresses a line occupies, following the ParseSource location counter.
+func lineSize(lr LineResult) uint64 {
+	if lr.Directive != "" {
+		return lr.Size
+	}
+	if lr.Size > 0 {
+		return lr.Size
+	}
+	if len(lr.Objects) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// AddressMap
+// builds the address map of a parsed source. A new region starts whenever a line does not
+// begin where the previous one ended, such as after an origin change.
+func (sr *SourceResult) AddressMap() *AddressMap {
+	am := &AddressMap{Symbols: sr.Symbols.Symbols(), Regions: make([]Region, 0), Overlaps: make([]Overlap, 0)}
+	var current *Region
+	for _, lr := range sr.Lines {
+		size := lineSize(lr)
+		if size == 0 {
+			continue
+		}
+		if current == nil || lr.Address != current.End {
+			am.Regions = append(am.Regions, Region{lr.Address, lr.Address, lr.LineNumber, lr.LineNumber})
+			current = &am.Regions[len(am.Regions)-1]
+		}
+		current.End += size
+		current.LastLine = lr.LineNumber
+	}
+	sorted := append([]Region(nil), am.Regions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	for i := range sorted {
+		for j := i + 1; j < len(sorted) && sorted[j].Start < sorted[i].End; j++ {
+			am.Overlaps = append(am.Overlaps, Overlap{sorted[i], sorted[j]})
+		}
+	}
+	return am
+}
+
+// Used
+// returns the total number of addresses covered by the regions.
+func (am *AddressMap) Used() uint64 {
+	var used uint64
+	for _, region := range am.Regions {
+		used += region.Size()
+	}
+	return used
+}
+
+// WriteJSON
+// writes the address map as an indented JSON document.
+func (am *AddressMap) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(am)
+}
+
+// WriteTable
+// writes the address map as aligned, human-readable tables of symbols, regions and overlaps.
+func (am *AddressMap) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYMBOL\tADDRESS\tLINE")
+	for _, sym := range am.Symbols {
+		fmt.Fprintf(tw, "%s\t%#08x\t%d\n", sym.Name, sym.Address, sym.Line)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "START\tEND\tSIZE\tLINES")
+	for _, region := range am.Regions {
+		fmt.Fprintf(tw, "%#08x\t%#08x\t%d\t%d-%d\n",
+			region.Start, region.End, region.Size(), region.FirstLine, region.LastLine)
+	}
+	fmt.Fprintf(tw, "\t\t%d\tused\n", am.Used())
+	if len(am.Overlaps) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "OVERLAP\tLINES\tWITH\tLINES")
+		for _, ov := range am.Overlaps {
+			fmt.Fprintf(tw, "%#08x-%#08x\t%d-%d\t%#08x-%#08x\t%d-%d\n",
+				ov.First.Start, ov.First.End, ov.First.FirstLine, ov.First.LastLine,
+				ov.Second.Start, ov.Second.End, ov.Second.FirstLine, ov.Second.LastLine)
+		}
+	}
+	return tw.Flush()
+}
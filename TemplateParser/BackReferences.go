@@ -0,0 +1,57 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// ValidateBackReferences
+// checks that the SameAs and DifferentFrom constraints of a template list refer to earlier
+// slots.
+func ValidateBackReferences(templateList []TemplateObject) (bool, string) {
+	for idx, tmpl := range templateList {
+		for _, ref := range []int{tmpl.SameAs, tmpl.DifferentFrom} {
+			if ref < 0 || (ref != 0 && ref >= idx) {
+				return false, fmt.Sprintf("Slot %d refers to slot %d, which is not an earlier slot", idx, ref)
+			}
+		}
+	}
+	return true, ""
+}
+
+// checkBackReferences
+// verifies the SameAs and DifferentFrom constraints of slot idx against the objects
+// matched so far.
+func checkBackReferences(templateList []TemplateObject, objs []ObjectType, idx int) (bool, string) {
+	tmpl := templateList[idx]
+	if tmpl.SameAs > 0 && tmpl.SameAs < idx && !sameObjectValue(objs[idx], objs[tmpl.SameAs]) {
+		return false, backReferenceError(tmpl, fmt.Sprintf("Operand %d must be the same as operand %d", idx, tmpl.SameAs))
+	}
+	if tmpl.DifferentFrom > 0 && tmpl.DifferentFrom < idx && sameObjectValue(objs[idx], objs[tmpl.DifferentFrom]) {
+		return false, backReferenceError(tmpl, fmt.Sprintf("Operand %d must differ from operand %d", idx, tmpl.DifferentFrom))
+	}
+	return true, ""
+}
+
+// backReferenceError
+// appends the template's error text to a back-reference failure.
+func backReferenceError(tmpl TemplateObject, msg string) string {
+	if tmpl.TemplateError == "" {
+		return msg
+	}
+	return msg + ": " + tmpl.TemplateError
+}
+
+// sameObjectValue
+// reports whether two objects have the same type and value.
+func sameObjectValue(a, b ObjectType) bool {
+	if a.ObjectTypeId != b.ObjectTypeId {
+		return false
+	}
+	if ab, isBig := a.ObjectValue.(*big.Int); isBig {
+		bb, isBig := b.ObjectValue.(*big.Int)
+		return isBig && ab.Cmp(bb) == 0
+	}
+	return reflect.DeepEqual(a.ObjectValue, b.ObjectValue)
+}
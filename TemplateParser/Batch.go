@@ -0,0 +1,50 @@
+package TemplateParser
+
+// ParseLines
+// parses many lines against one template list, as ParseLine parses each of them, storing
+// the results in results, whose contents are overwritten, and returning them in the order
+// of the lines; LineNumber is the 1-based index of the line. One token buffer serves
+// every line and the results reuse the storage of the slice and of the objects of the
+// results it held, so parsing file after file into the same slice allocates little more
+// than the values of the objects. Results from an earlier call on the same slice are
+// overwritten and must not be kept.
+func ParseLines(lines []string, templateList []TemplateObject, results []LineResult) []LineResult {
+	return parseLines(defaultLexer, DefaultParserConfig(), lines, templateList, results)
+}
+
+// ParseLinesWithConfig
+// is ParseLines using the token conversions enabled by the configuration.
+func ParseLinesWithConfig(lines []string, templateList []TemplateObject, results []LineResult, config ParserConfig) []LineResult {
+	return parseLines(newLexer(config), config, lines, templateList, results)
+}
+
+// parseLines
+// implements ParseLinesWithConfig with an already built tokenizer.
+func parseLines(lx *lexer, config ParserConfig, lines []string, templateList []TemplateObject, results []LineResult) []LineResult {
+	if cap(results) < len(lines) {
+		results = append(results[:cap(results)], make([]LineResult, len(lines)-cap(results))...)
+	}
+	results = results[:len(lines)]
+	buf := getTokenBuffer()
+	tokens := *buf
+	for idx, line := range lines {
+		tokens = tokenizeLine(tokens, line, lx, config)
+		objs, errIdx, starts, ok, errmsg := matchTokensInto(results[idx].Objects, tokens, templateList, config)
+		result := newLineResult(config, idx+1, objs, ok, errmsg, templateList)
+		result.RawText = line
+		if !ok {
+			result.ErrorColumn = matchedColumn(tokens, tokens, starts, errIdx)
+			if errIdx >= 0 && errIdx < len(templateList) {
+				result.Expected = config.tokenName(templateList[errIdx].TemplateType)
+				result.TemplateError = templateList[errIdx].TemplateError
+			}
+		}
+		if config.Normalize.active() {
+			config.Normalize.Normalize(line).restoreColumns(&result)
+		}
+		result.redactSource(templateList)
+		results[idx] = result
+	}
+	putTokenBuffer(buf, tokens)
+	return results
+}
@@ -0,0 +1,120 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLinesReusesResults(t *testing.T) {
+	lines := []string{"mov r1, r2", "add r3, r4", "jmp 0800"}
+	results := ParseLines(lines, benchmarkTemplate, nil)
+	if len(results) != len(lines) {
+		t.Fatalf("%d results for %d lines", len(results), len(lines))
+	}
+	for idx, want := range []bool{true, true, false} {
+		if results[idx].Ok != want {
+			t.Errorf("%q ok = %v, want %v: %s", lines[idx], results[idx].Ok, want, results[idx].Error)
+		}
+	}
+	again := ParseLines(lines[:2], benchmarkTemplate, results)
+	if len(again) != 2 || &again[0] != &results[0] {
+		t.Errorf("ParseLines did not parse into the slice it was given")
+	}
+}
+
+func TestParseLinesMatchesParseLine(t *testing.T) {
+	results := ParseLines(benchmarkLines, benchmarkTemplate, nil)
+	for idx, line := range benchmarkLines {
+		objs, ok, errmsg := ParseLine(line, benchmarkTemplate)
+		lr := results[idx]
+		if lr.Ok != ok || lr.Error != errmsg || lr.LineNumber != idx+1 || lr.RawText != line {
+			t.Errorf("ParseLines(%q) = %v, %q, line %d, want %v, %q, line %d", line, lr.Ok, lr.Error, lr.LineNumber, ok, errmsg, idx+1)
+		}
+		if ok && !reflect.DeepEqual(lr.Objects, objs) {
+			t.Errorf("ParseLines(%q) objects = %v, want %v", line, lr.Objects, objs)
+		}
+	}
+	if lr := results[1]; lr.Ok || lr.ErrorColumn != 9 || lr.Expected != "Register" {
+		t.Errorf("ldi r3, ff = column %d, expected %q, want column 9 expecting a Register", lr.ErrorColumn, lr.Expected)
+	}
+}
+
+func TestParseLinesWithConfig(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Normalize.FoldCase = true
+	results := ParseLinesWithConfig([]string{"MOV R1, R2"}, benchmarkTemplate, nil, config)
+	if !results[0].Ok || results[0].RawText != "MOV R1, R2" {
+		t.Errorf("ParseLinesWithConfig = %+v, want a match keeping the raw text", results[0])
+	}
+}
+
+func TestParseLinesAllocations(t *testing.T) {
+	lines := []string{"mov r1, r2", "add r3, r4"}
+	results := ParseLines(lines, benchmarkTemplate, nil)
+	allocs := testing.AllocsPerRun(100, func() {
+		results = ParseLines(lines, benchmarkTemplate, results)
+	})
+	fresh := testing.AllocsPerRun(100, func() {
+		ParseLines(lines, benchmarkTemplate, nil)
+	})
+	if allocs >= fresh {
+		t.Errorf("reusing results allocates %v times per call, no fewer than %v for fresh results", allocs, fresh)
+	}
+}
+
+// BenchmarkParseLine measures tokenizing and matching a line with the package-level
+// ParseLine.
+func BenchmarkParseLine(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseLine("mov r1, r2 ; copy", benchmarkTemplate)
+	}
+}
+
+// BenchmarkParseLines parses batches into the same result slice on every iteration.
+func BenchmarkParseLines(b *testing.B) {
+	for _, n := range []int{10, 1000} {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = benchmarkLines[i%len(benchmarkLines)]
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			var results []LineResult
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				results = ParseLines(lines, benchmarkTemplate, results)
+			}
+		})
+	}
+}
+
+// BenchmarkParseSource measures ParseSource without and with a line cache; once the
+// first iteration has filled the cache the repeated lines of the source are found in it.
+func BenchmarkParseSource(b *testing.B) {
+	for _, bench := range []struct {
+		lines, cache int
+	}{{1000, 0}, {1000, 64}} {
+		parser := NewParser(DefaultParserConfig())
+		parser.SetLineCache(bench.cache)
+		for _, name := range []string{"mov", "add"} {
+			parser.RegisterTemplate(name, benchmarkTemplate)
+		}
+		var sb strings.Builder
+		for i := 0; i < bench.lines; i++ {
+			sb.WriteString(benchmarkLines[i%len(benchmarkLines)])
+			sb.WriteByte('\n')
+		}
+		source := sb.String()
+		b.Run(fmt.Sprintf("lines=%d/cache=%d", bench.lines, bench.cache), func(b *testing.B) {
+			b.SetBytes(int64(len(source)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseSource(strings.NewReader(source)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// TemplateWidth
+// returns the width in bits of the values accepted by a sized integer template type,
+// or 0 if the type is not a sized integer.
+func TemplateWidth(templateType int) int {
+	switch templateType {
+	case TokenUint8:
+		return 8
+	case TokenUint16:
+		return 16
+	case TokenUint32:
+		return 32
+	case TokenUint64:
+		return 64
+	case TokenUint128:
+		return 128
+	case TokenUint256:
+		return 256
+	}
+	return 0
+}
+
+// isWideTemplate
+// reports whether a template type is one of the arbitrary-precision slots.
+func isWideTemplate(templateType int) bool {
+	return templateType == TokenUint128 || templateType == TokenUint256
+}
+
+// isIntegerToken
+// reports whether a token type produces an integer literal.
+func isIntegerToken(tokenType int) bool {
+	switch tokenType {
+	case TokenUint8, TokenUint16, TokenUint32, TokenUint64, TokenBigInt:
+		return true
+	}
+	return false
+}
+
+// widenObject
+// converts an integer object into a *big.Int for a TokenUint128 or TokenUint256 slot,
+// checking that it fits the slot's width.
+func widenObject(obj *ObjectType, tmpl TemplateObject, config ParserConfig) (bool, string) {
+	tt := tmpl.TemplateType
+	if !isIntegerToken(obj.ObjectTypeId) {
+		return false, config.mismatchError(tmpl, obj.ObjectTypeId)
+	}
+	val, ok := ToBigInt(*obj)
+	if !ok {
+		return false, fmt.Sprintf("Invalid integer value: %s", tmpl.TemplateError)
+	}
+	if width := TemplateWidth(tt); val.BitLen() > width {
+		return false, fmt.Sprintf("Value does not fit in %d bits: %s", width, tmpl.TemplateError)
+	}
+	obj.ObjectTypeId = tt
+	obj.ObjectValue = val
+	return true, ""
+}
+
+// widthError
+// returns the error of an integer object of a wider type than its sized integer slot,
+// such as ff1, a Uint16, in a Uint8 slot: the value and the range the slot accepts, that
+// of its type narrowed by its MinValue and MaxValue. Values within the range written with
+// too many digits, as 00ff is, are reported as too wide. It reports false for objects the
+// generic type mismatch describes.
+func widthError(obj ObjectType, tmpl TemplateObject, config ParserConfig) (string, bool) {
+	tt := tmpl.TemplateType
+	limit, sized := maxDigits[tt]
+	own, isNumber := maxDigits[obj.ObjectTypeId]
+	if !sized || (!isNumber && obj.ObjectTypeId != TokenBigInt) || (isNumber && own <= limit) {
+		return "", false
+	}
+	val, ok := ToBigInt(obj)
+	if !ok {
+		return "", false
+	}
+	low, high := tmpl.MinValue, min(maxValue(tmpl), ^uint64(0)>>uint(64-TemplateWidth(tt)))
+	shown := fmt.Sprintf("%#x", val)
+	if tmpl.Sensitive {
+		shown = RedactedValue
+	}
+	if !val.IsUint64() || val.Uint64() > high || val.Uint64() < low {
+		return fmt.Sprintf("Value %s does not fit in %s, range %#x-%#x: %s",
+			shown, config.tokenName(tt), low, high, tmpl.TemplateError), true
+	}
+	return fmt.Sprintf("Value %s is written as a %s, wider than %s, range %#x-%#x: %s",
+		shown, config.tokenName(obj.ObjectTypeId), config.tokenName(tt), low, high, tmpl.TemplateError), true
+}
+
+// ToBigInt
+// returns the integer held by an object as a *big.Int, whether it was parsed as a
+// uint64 or as a big integer.
+func ToBigInt(obj ObjectType) (*big.Int, bool) {
+	switch val := obj.ObjectValue.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(val), true
+	case *big.Int:
+		if val == nil {
+			return nil, false
+		}
+		return new(big.Int).Set(val), true
+	}
+	return nil, false
+}
+
+// BigIntToBytes
+// encodes a non-negative integer as a big-endian byte array exactly width bits wide,
+// the form used when emitting wide operands. The width must be a multiple of 8.
+func BigIntToBytes(val *big.Int, width int) ([]byte, error) {
+	if width <= 0 || width%8 != 0 {
+		return nil, fmt.Errorf("width %d is not a positive multiple of 8", width)
+	}
+	if val.Sign() < 0 {
+		return nil, fmt.Errorf("value %s is negative", val)
+	}
+	if val.BitLen() > width {
+		return nil, fmt.Errorf("value %#x does not fit in %d bits", val, width)
+	}
+	return val.FillBytes(make([]byte, width/8)), nil
+}
+
+// BytesToBigInt
+// decodes a big-endian byte array produced by BigIntToBytes.
+func BytesToBigInt(data []byte) *big.Int {
+	return new(big.Int).SetBytes(data)
+}
@@ -0,0 +1,48 @@
+package TemplateParser
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestWideSlotChecks(t *testing.T) {
+	wide := []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenUint128},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenUint128, SameAs: 1, TemplateError: "key"},
+	}
+	different := append([]TemplateObject(nil), wide...)
+	different[3] = TemplateObject{TemplateType: TokenUint128, DifferentFrom: 1}
+	validated := append([]TemplateObject(nil), wide...)
+	validated[3] = TemplateObject{TemplateType: TokenUint128, Hooks: &SlotHooks{Validate: func(obj ObjectType) error {
+		if obj.ObjectValue.(*big.Int).Sign() == 0 {
+			return errors.New("must not be zero")
+		}
+		return nil
+	}}}
+	config := DefaultParserConfig()
+	config.MaxNumericDigits = 64
+	tests := []struct {
+		name         string
+		templateList []TemplateObject
+		line         string
+		ok           bool
+		err          string
+	}{
+		{"same", wide, "cmp 123456789abcdef0123, 123456789abcdef0123", true, ""},
+		{"not same", wide, "cmp 123456789abcdef0123, 123456789abcdef0124", false, "Operand 3 must be the same as operand 1: key"},
+		{"small same", wide, "cmp 10, 10", true, ""},
+		{"different", different, "cmp 10, 11", true, ""},
+		{"not different", different, "cmp 10, 10", false, "Operand 3 must differ from operand 1"},
+		{"validated", validated, "cmp 1, 0", false, "must not be zero"},
+		{"too wide", wide, "cmp 1, 1000000000000000000000000000000000", false, "Value does not fit in 128 bits: key"},
+	}
+	for _, tt := range tests {
+		_, ok, errmsg := ParseLineWithConfig(tt.line, tt.templateList, config)
+		if ok != tt.ok || errmsg != tt.err {
+			t.Errorf("%s: ParseLine(%q) = %v, %q, want %v, %q", tt.name, tt.line, ok, errmsg, tt.ok, tt.err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultBooleanKeywords are the words read as TokenBoolean tokens by configurations that
+// leave BooleanKeywords nil.
+var defaultBooleanKeywords = map[string]bool{"true": true, "false": false}
+
+// DefaultBooleanKeywords
+// returns the words read as TokenBoolean tokens by configurations that leave
+// BooleanKeywords nil. Changes to the map do not affect the package.
+func DefaultBooleanKeywords() map[string]bool {
+	keywords := make(map[string]bool, len(defaultBooleanKeywords))
+	for word, value := range defaultBooleanKeywords {
+		keywords[word] = value
+	}
+	return keywords
+}
+
+// booleanKeywords
+// returns the configuration's boolean keywords with lowercased words.
+func (config ParserConfig) booleanKeywords() map[string]bool {
+	keywords := config.BooleanKeywords
+	if keywords == nil {
+		keywords = defaultBooleanKeywords
+	}
+	lowered := make(map[string]bool, len(keywords))
+	for word, value := range keywords {
+		lowered[strings.ToLower(word)] = value
+	}
+	return lowered
+}
+
+// validBooleanKeyword
+// checks that a boolean keyword is a word the tokenizer reads as one: a letter followed
+// by letters, digits or underscores.
+func validBooleanKeyword(word string) error {
+	if word == "" {
+		return fmt.Errorf("boolean keyword is empty")
+	}
+	for idx := 0; idx < len(word); idx++ {
+		if c := word[idx]; (!isAlnum(c) && c != '_') || (idx == 0 && !isMacroNameByte(c, true)) {
+			return fmt.Errorf("boolean keyword %q is not a word", word)
+		}
+	}
+	return nil
+}
+
+// AddBooleanKeyword
+// makes a word such as on, off, yes or no a TokenBoolean token with the given value and
+// rebuilds the parser's tokenizer. Keywords ignore case. The first keyword added to a
+// configuration without BooleanKeywords keeps true and false.
+func (p *Parser) AddBooleanKeyword(word string, value bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return fmt.Errorf("cannot add boolean keyword %s: %s", word, errFrozen)
+	}
+	if err := validBooleanKeyword(word); err != nil {
+		return err
+	}
+	keywords := p.config.booleanKeywords()
+	keywords[strings.ToLower(word)] = value
+	p.config.BooleanKeywords = keywords
+	p.lexer = newLexer(p.config)
+	return nil
+}
+
+// BooleanKeywords
+// returns the words the parser reads as TokenBoolean tokens, lowercased, with their
+// values.
+func (p *Parser) BooleanKeywords() map[string]bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config.booleanKeywords()
+}
+
+// boolean
+// returns the length of the boolean keyword at the start of s, given the length of the
+// word there, or 0.
+func (lx *lexer) boolean(s string, word int) int {
+	if word == 0 || len(lx.booleans) == 0 {
+		return 0
+	}
+	if _, found := lx.booleans[s[:word]]; found {
+		return word
+	}
+	for keyword := range lx.booleans {
+		if len(keyword) == word && strings.EqualFold(keyword, s[:word]) {
+			return word
+		}
+	}
+	return 0
+}
+
+// booleanValue
+// returns the value of a boolean keyword token.
+func (config ParserConfig) booleanValue(word string) bool {
+	keywords := config.BooleanKeywords
+	if keywords == nil {
+		keywords = defaultBooleanKeywords
+	}
+	if value, found := keywords[word]; found {
+		return value
+	}
+	for keyword, value := range keywords {
+		if strings.EqualFold(keyword, word) {
+			return value
+		}
+	}
+	return false
+}
+
+// booleanToIdentifier
+// lets a boolean keyword fill an identifier slot as the word it was written as, so that
+// grammars using true or false as plain words keep matching.
+func booleanToIdentifier(obj *ObjectType, tmpl TemplateObject) {
+	if obj.ObjectTypeId == TokenBoolean && tmpl.TemplateType == TokenIdentifier {
+		obj.ObjectTypeId, obj.ObjectValue, obj.ObjectDescriptor = TokenIdentifier, obj.ObjectDescriptor, ""
+	}
+}
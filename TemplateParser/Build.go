@@ -0,0 +1,150 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// BuildSource
+// is one source of a build: its name, used as the File of its lines, and how to open it.
+type BuildSource struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// Relocation
+// is a label operand of a built line: where it is used, the symbol it names and the value
+// it was resolved to. Relative relocations hold the signed distance from the start of the
+// line to the label, stored as its two's complement.
+type Relocation struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line"`
+	Address  uint64 `json:"address"`
+	Operand  int    `json:"operand"`
+	Symbol   string `json:"symbol"`
+	Value    uint64 `json:"value"`
+	Relative bool   `json:"relative,omitempty"`
+}
+
+// BuildResult
+// is the outcome of a build: the lines of every source in order with their merged symbol
+// table and totals, as ParseSource would produce for the sources concatenated, together
+// with the label references resolved across them.
+type BuildResult struct {
+	*SourceResult
+	Files       []string
+	Relocations []Relocation
+}
+
+// BuildFiles
+// builds the named files with Build.
+func (p *Parser) BuildFiles(paths []string, workers int) (*BuildResult, error) {
+	sources := make([]BuildSource, len(paths))
+	for idx, path := range paths {
+		sources[idx] = BuildSource{filepath.Clean(path), func() (io.ReadCloser, error) { return os.Open(path) }}
+	}
+	return p.Build(sources, workers)
+}
+
+// Build
+// parses a project made of several sources. The sources are read by a pool of workers
+// goroutines, one per CPU if workers is below 1, and their lines are matched in parallel
+// as in ParseSourceParallel. The location counter then runs through the sources in the
+// order given, so each source starts where the previous one ended unless it sets an
+// origin, and labels are shared between sources: a label defined in one source can be
+// used in any other. The result does not depend on the number of workers. Include
+// directives in files opened by BuildFiles resolve relative to the including file when the
+// parser has no FileResolver.
+//
+// The returned error reports the first source, in the order given, that could not be
+// opened or read; the lines read from the other sources are still built. Without such a
+// failure it is a *LimitExceeded if the build exceeds the parser's Limits, and the result
+// holds the lines processed before the limit.
+func (p *Parser) Build(sources []BuildSource, workers int) (*BuildResult, error) {
+	defer p.span("Build", map[string]interface{}{"sources": len(sources), "workers": workers}).End()
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	ctx := p.newContext(nil)
+	read := p.span("read", nil)
+	lines := make([][]sourceLine, len(sources))
+	errs := make([]error, len(sources))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(sources)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				lines[idx], errs[idx] = p.readBuildSource(sources[idx], ctx.budget)
+			}
+		}()
+	}
+	for idx := range sources {
+		next <- idx
+	}
+	close(next)
+	wg.Wait()
+	read.End()
+
+	all := make([]sourceLine, 0)
+	for _, sourceLines := range lines {
+		all = append(all, sourceLines...)
+	}
+	pass := p.span("match", map[string]interface{}{"lines": len(all)})
+	parsed := p.matchParallel(all, workers, ctx)
+	pass.End()
+
+	result := &BuildResult{SourceResult: p.assembleSource(parsed, ctx), Files: make([]string, len(sources))}
+	for idx, source := range sources {
+		result.Files[idx] = source.Name
+	}
+	result.Relocations = relocations(result.Lines)
+	for idx, err := range errs {
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", sources[idx].Name, err)
+		}
+	}
+	return result, ctx.budget.err()
+}
+
+// readBuildSource
+// opens a source of a build and reads its lines, charging them to the build's budget.
+func (p *Parser) readBuildSource(source BuildSource, b *budget) ([]sourceLine, error) {
+	rc, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return p.readSource(rc, source.Name, nil, "", nil, b)
+}
+
+// relocations
+// lists the resolved label operands of successfully built lines, in line order.
+func relocations(lines []LineResult) []Relocation {
+	relocs := make([]Relocation, 0)
+	for _, lr := range lines {
+		if !lr.Ok {
+			continue
+		}
+		for idx, obj := range lr.Objects {
+			if obj.ObjectTypeId != TokenLabelRef && obj.ObjectTypeId != TokenLabelRel {
+				continue
+			}
+			reloc := Relocation{File: lr.File, Line: lr.LineNumber, Address: lr.Address, Operand: idx,
+				Symbol: obj.ObjectDescriptor, Relative: obj.ObjectTypeId == TokenLabelRel}
+			switch val := obj.ObjectValue.(type) {
+			case uint64:
+				reloc.Value = val
+			case int64:
+				reloc.Value = uint64(val)
+			}
+			relocs = append(relocs, reloc)
+		}
+	}
+	return relocs
+}
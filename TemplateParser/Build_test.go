@@ -0,0 +1,70 @@
+package TemplateParser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildSources returns sources reading the given texts, named after their keys.
+func buildSources(names []string, texts map[string]string) []BuildSource {
+	sources := make([]BuildSource, len(names))
+	for idx, name := range names {
+		text := texts[name]
+		sources[idx] = BuildSource{name, func() (io.ReadCloser, error) {
+			if text == "" {
+				return nil, errors.New("unreadable")
+			}
+			return io.NopCloser(strings.NewReader(text)), nil
+		}}
+	}
+	return sources
+}
+
+func TestBuildSharesLabelsAcrossSources(t *testing.T) {
+	texts := map[string]string{
+		"main.s": "start: jmp helper\nnop\n",
+		"lib.s":  "helper: nop\njmp start\n",
+	}
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	var first *BuildResult
+	for _, workers := range []int{1, 2, 0} {
+		result, err := p.Build(buildSources([]string{"main.s", "lib.s"}, texts), workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Ok() || !reflect.DeepEqual(result.Files, []string{"main.s", "lib.s"}) {
+			t.Fatalf("%d workers: build = %+v, want both files built", workers, result)
+		}
+		if first == nil {
+			first = result
+			continue
+		}
+		if !reflect.DeepEqual(result.Lines, first.Lines) || !reflect.DeepEqual(result.Relocations, first.Relocations) {
+			t.Errorf("%d workers: result differs from one worker", workers)
+		}
+	}
+	want := []Relocation{
+		{File: "main.s", Line: 1, Address: 0, Operand: 1, Symbol: "helper", Value: 3},
+		{File: "lib.s", Line: 2, Address: 4, Operand: 1, Symbol: "start", Value: 0},
+	}
+	if !reflect.DeepEqual(first.Relocations, want) {
+		t.Errorf("relocations = %+v, want %+v", first.Relocations, want)
+	}
+	if sym, _ := first.Symbols.Lookup("helper"); sym.Address != 3 {
+		t.Errorf("helper is at %d, want 3", sym.Address)
+	}
+}
+
+func TestBuildReportsUnreadableSource(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	result, err := p.Build(buildSources([]string{"ok.s", "bad.s"}, map[string]string{"ok.s": "nop\n"}), 2)
+	if err == nil || err.Error() != "bad.s: unreadable" {
+		t.Errorf("Build error = %v, want the unreadable source", err)
+	}
+	if result == nil || len(result.Lines) != 1 || !result.Lines[0].Ok {
+		t.Errorf("Build result = %+v, want the readable source built", result)
+	}
+}
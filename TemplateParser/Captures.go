@@ -0,0 +1,41 @@
+package TemplateParser
+
+import "fmt"
+
+// Captures
+// collects the objects of every named capture group in a matched line. A group is the
+// contiguous run of template slots sharing the same Group name, so a memory operand
+// written as base register and offset can be read back as a single "address" entry.
+// Slots without a Group are not captured. Returns nil if the template has no groups.
+func Captures(templateList []TemplateObject, objList []ObjectType) map[string][]ObjectType {
+	var groups map[string][]ObjectType
+	for idx, tmpl := range templateList {
+		if tmpl.Group == "" || idx >= len(objList) {
+			continue
+		}
+		if groups == nil {
+			groups = make(map[string][]ObjectType)
+		}
+		groups[tmpl.Group] = append(groups[tmpl.Group], objList[idx])
+	}
+	return groups
+}
+
+// ValidateGroups
+// checks that every capture group in a template list covers a contiguous run of slots.
+func ValidateGroups(templateList []TemplateObject) (bool, string) {
+	closed := make(map[string]bool)
+	current := ""
+	for idx, tmpl := range templateList {
+		if tmpl.Group != current {
+			if current != "" {
+				closed[current] = true
+			}
+			if closed[tmpl.Group] {
+				return false, fmt.Sprintf("Group %q is not contiguous at slot %d", tmpl.Group, idx)
+			}
+			current = tmpl.Group
+		}
+	}
+	return true, ""
+}
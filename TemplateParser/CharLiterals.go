@@ -0,0 +1,92 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxCharLiteral is the longest character literal the tokenizer looks for, '\UHHHHHHHH'.
+const maxCharLiteral = 12
+
+// charLength
+// returns the length of the character literal at the start of s, including both quotes,
+// or 0 if no closing quote follows within the length of the longest literal. A backslash
+// escapes the following character.
+func charLength(s string) int {
+	for idx := 1; idx < len(s) && idx < maxCharLiteral; idx++ {
+		switch s[idx] {
+		case '\'':
+			if idx == 1 {
+				return 0
+			}
+			return idx + 1
+		case '\\':
+			idx++
+		case '\n':
+			return 0
+		}
+	}
+	return 0
+}
+
+// UnescapeChar
+// returns the code point of a character literal token such as 'A', '\n', '\x7f' or 'é'.
+// The escape sequences are those of UnescapeString, plus \' for the quote itself; a \xHH
+// escape stands for the byte value HH. Returns the code point, a success flag and an
+// error message.
+func UnescapeChar(literal string) (uint64, bool, string) {
+	if len(literal) < 3 || literal[0] != '\'' || literal[len(literal)-1] != '\'' {
+		return 0, false, "Character is not quoted"
+	}
+	body := literal[1 : len(literal)-1]
+	switch body {
+	case `\'`:
+		return '\'', true, ""
+	case `"`:
+		return '"', true, ""
+	}
+	text, ok, errmsg := UnescapeString(`"` + body + `"`)
+	if !ok {
+		return 0, false, errmsg
+	}
+	if len(body) == 4 && body[:2] == `\x` {
+		return uint64(text[0]), true, ""
+	}
+	r, size := utf8.DecodeRuneInString(text)
+	if size == 0 || size != len(text) || (r == utf8.RuneError && size == 1) {
+		return 0, false, "Character literal must hold exactly one character"
+	}
+	return uint64(r), true, ""
+}
+
+// QuoteChar
+// is the inverse of UnescapeChar: it returns a code point as a character literal, escaping
+// the quote, backslashes and control characters.
+func QuoteChar(code uint64) string {
+	switch {
+	case code == '\'':
+		return `'\''`
+	case code == '"':
+		return `'"'`
+	case code > utf8.MaxRune:
+		return fmt.Sprintf(`'\U%08x'`, code)
+	}
+	quoted := QuoteString(string(rune(code)))
+	return "'" + quoted[1:len(quoted)-1] + "'"
+}
+
+// charToInteger
+// lets a character object fill an integer slot, as assemblers accept 'A' for an 8-bit
+// immediate, when its code point fits the width of the slot.
+func charToInteger(obj *ObjectType, tmpl TemplateObject) (bool, string) {
+	digits, isInteger := maxDigits[tmpl.TemplateType]
+	if obj.ObjectTypeId != TokenChar || !isInteger {
+		return true, ""
+	}
+	code, _ := obj.ObjectValue.(uint64)
+	if !fitsField(code, digits*4) {
+		return false, fmt.Sprintf("Character %s does not fit in %d bits: %s", QuoteChar(code), digits*4, tmpl.TemplateError)
+	}
+	obj.ObjectTypeId = tmpl.TemplateType
+	return true, ""
+}
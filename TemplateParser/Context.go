@@ -0,0 +1,173 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+)
+
+// ParseContext
+// carries state through the parsing of lines and sources, so tools running several passes
+// over a program can hand it from one pass to the next instead of keeping it in globals.
+// It reaches the ValidateContext callbacks of template slots as lines are matched and the
+// ContextAction of entries once they have matched.
+//
+// Symbols is the symbol table of the last source parsed with the context: actions see the
+// labels of their own source, validators, which run before labels are defined, the labels
+// of the previous call. Macros is the macro table lines are expanded with; nil means the
+// parser's. File and Line locate the line a callback is called for; each callback gets its
+// own copy of the context, so only changes to what Symbols, Macros and Data point to are
+// seen by later lines. Profile selects the profile lines are matched in, overriding the
+// parser's; .arch directives in a source override it in turn for the rest of their file.
+// Data holds whatever the caller wants to pass along. The constants defined by the equ
+// lines parsed in a context are kept in it too, so handing the context to the next call
+// keeps them in scope, while calls with a new or nil context start with only the
+// parser's constants.
+type ParseContext struct {
+	Symbols *SymbolTable
+	Macros  *MacroTable
+	File    string
+	Line    int
+	Profile string
+	Data    interface{}
+
+	budget    *budget
+	constants *constantTable // Constants of equ lines, set by the first call using the context
+}
+
+// ParseLineContext
+// parses a single line like ParseLine, as line ctx.Line of ctx.File, in a context. A nil
+// context stands for an empty one. Operand expressions are evaluated with the context's
+// constants and the labels of ctx.Symbols, the line failing if they name anything else.
+func (p *Parser) ParseLineContext(ctx *ParseContext, line string) (ParsedLine, bool, string) {
+	p.scopeContext(ctx)
+	lineCtx := ctx.unlimited(ctx.file(), ctx.line())
+	if lineCtx.constants == nil {
+		lineCtx.constants = newConstantScope(p.constants)
+	}
+	results := p.parseInContext(line, lineCtx)
+	if len(results) != 1 {
+		return ParsedLine{RawText: redactText(line, results, nil)}, false, "Line expands to several lines"
+	}
+	results[0].File = lineCtx.File
+	resolveLineExpressions(&results[0], lineCtx.Symbols)
+	runAction(&results[0], lineCtx)
+	return results[0].ParsedLine, results[0].Ok, results[0].Error
+}
+
+// ParseSourceContext
+// parses a source like ParseSource, in a context. ctx.File names the source for its lines
+// and include directives. Once the source is resolved, ctx.Symbols is set to its symbol
+// table, which is also returned in the result, and ctx keeps the constants of its equ
+// lines for the calls after it.
+func (p *Parser) ParseSourceContext(ctx *ParseContext, r io.Reader) (*SourceResult, error) {
+	return p.parseSource(ctx, r, ctx.file())
+}
+
+// CheckValidateContext
+// runs the ValidateContext hook of the template on a matched object, as CheckValidate runs
+// Validate.
+func (tmpl *TemplateObject) CheckValidateContext(ctx *ParseContext, obj ObjectType) (bool, string) {
+	validate := tmpl.Hooks.validateContext()
+	if validate == nil {
+		return true, ""
+	}
+	if err := validate(ctx, obj); err != nil {
+		if tmpl.TemplateError == "" {
+			return false, err.Error()
+		}
+		return false, fmt.Sprintf("%v: %s", err, tmpl.TemplateError)
+	}
+	return true, ""
+}
+
+// checkContextValidators
+// runs the ValidateContext callbacks of the slots of a successfully matched line, marking
+// the line as failed at the first object rejected. It returns the slot of that object, or
+// -1.
+func checkContextValidators(ctx *ParseContext, tmpl []TemplateObject, result *LineResult) int {
+	if !result.Ok {
+		return -1
+	}
+	for idx := range tmpl {
+		if idx >= len(result.Objects) {
+			break
+		}
+		if ok, errmsg := tmpl[idx].CheckValidateContext(ctx, result.Objects[idx]); !ok {
+			result.Ok, result.Error = false, errmsg
+			return idx
+		}
+	}
+	return -1
+}
+
+// newContext
+// returns the context of one parsing call: a copy of ctx, or an empty context if it is
+// nil, expanding the parser's macros unless ctx names a table, with the budget of the
+// parser's Limits and the constants of ctx, or a table of its own if ctx is nil.
+func (p *Parser) newContext(ctx *ParseContext) *ParseContext {
+	p.scopeContext(ctx)
+	call := ctx.unlimited(ctx.file(), ctx.line())
+	if call.Macros == nil {
+		call.Macros = p.Macros()
+	}
+	if call.constants == nil {
+		call.constants = newConstantScope(p.constants)
+	}
+	call.budget = p.config.newBudget()
+	return call
+}
+
+// scopeContext
+// gives a context the constant table of the calls using it, if it has none yet.
+func (p *Parser) scopeContext(ctx *ParseContext) {
+	if ctx != nil && ctx.constants == nil {
+		ctx.constants = newConstantScope(p.constants)
+	}
+}
+
+// macroTable
+// returns the macro table a line parsed in ctx is expanded with.
+func (p *Parser) macroTable(ctx *ParseContext) *MacroTable {
+	if ctx.Macros != nil {
+		return ctx.Macros
+	}
+	return p.Macros()
+}
+
+// at
+// returns a copy of the context located at a line, sharing its budget. A nil context
+// yields an empty one.
+func (ctx *ParseContext) at(file string, line int) *ParseContext {
+	var c ParseContext
+	if ctx != nil {
+		c = *ctx
+	}
+	c.File, c.Line = file, line
+	return &c
+}
+
+// unlimited
+// returns a copy of the context located at a line, without a budget.
+func (ctx *ParseContext) unlimited(file string, line int) *ParseContext {
+	c := ctx.at(file, line)
+	c.budget = nil
+	return c
+}
+
+// file
+// returns the file of a context, "" if it is nil.
+func (ctx *ParseContext) file() string {
+	if ctx == nil {
+		return ""
+	}
+	return ctx.File
+}
+
+// line
+// returns the line of a context, 0 if it is nil.
+func (ctx *ParseContext) line() int {
+	if ctx == nil {
+		return 0
+	}
+	return ctx.Line
+}
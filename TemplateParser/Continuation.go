@@ -0,0 +1,106 @@
+package TemplateParser
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// LineSegment
+// is one of the physical lines a statement was joined from with
+// ParserConfig.LineContinuation: its line number, the byte offset of its start in the
+// original input and the 1-based column of RawText its text starts at.
+type LineSegment struct {
+	Line   int   `json:"line"`
+	Column int   `json:"column"`
+	Offset int64 `json:"offset"`
+}
+
+// continued
+// returns the text of a line without the continuation marker it ends in, ignoring trailing
+// blanks, and whether it had one. A marker in a comment does not continue the line.
+func (config ParserConfig) continued(line string) (string, bool) {
+	marker := config.LineContinuation
+	if marker == "" || len(EatComments(line)) != len(line) {
+		return line, false
+	}
+	trimmed := strings.TrimRight(line, " \t")
+	if !strings.HasSuffix(trimmed, marker) {
+		return line, false
+	}
+	return trimmed[:len(trimmed)-len(marker)], true
+}
+
+// nextStatement
+// returns the next line joined with the lines after it for as long as it ends in the
+// configuration's continuation marker, the offset of its start, the number of the last
+// physical line it took, lineNo being the number of the line before it, and the segments
+// it was joined from, nil if it took a single line. A marker on the last line of the input
+// is dropped.
+func (lr *lineReader) nextStatement(config ParserConfig, lineNo int) (string, int64, int, []LineSegment, bool) {
+	text, offset, ok := lr.next()
+	if !ok {
+		return "", 0, lineNo, nil, false
+	}
+	lineNo++
+	joined, more := config.continued(text)
+	if !more {
+		return text, offset, lineNo, nil, true
+	}
+	segments := []LineSegment{{Line: lineNo, Column: 1, Offset: offset}}
+	for more {
+		next, nextOffset, found := lr.next()
+		if !found {
+			break
+		}
+		lineNo++
+		segments = append(segments, LineSegment{Line: lineNo, Column: len(joined) + 1, Offset: nextOffset})
+		var rest string
+		rest, more = config.continued(next)
+		joined += rest
+	}
+	if len(segments) == 1 {
+		segments = nil
+	}
+	return joined, offset, lineNo, segments, true
+}
+
+// segment
+// returns the segment of a joined line holding a 1-based column of RawText, or false if
+// the line was not joined.
+func (pl ParsedLine) segment(column int) (LineSegment, bool) {
+	if len(pl.Segments) == 0 {
+		return LineSegment{}, false
+	}
+	found := pl.Segments[0]
+	for _, seg := range pl.Segments[1:] {
+		if seg.Column > column {
+			break
+		}
+		found = seg
+	}
+	return found, true
+}
+
+// SourcePosition
+// returns the physical line and 1-based column a column of RawText comes from. For lines
+// joined with ParserConfig.LineContinuation they are those of the physical line holding
+// the column; other lines give their own number and the column unchanged. Column 0 gives
+// the line's number and 0.
+func (pl ParsedLine) SourcePosition(column int) (int, int) {
+	seg, joined := pl.segment(column)
+	if !joined || column <= 0 {
+		return pl.LineNumber, column
+	}
+	return seg.Line, column - seg.Column + 1
+}
+
+// segmentOffset
+// returns the offset in the original input of a 1-based column of a joined line's RawText.
+func (pl ParsedLine) segmentOffset(seg LineSegment, column int) int64 {
+	raw := pl.rawText()
+	prefix := raw[min(seg.Column-1, len(raw)):min(column-1, len(raw))]
+	if !pl.wide {
+		return seg.Offset + int64(len(prefix))
+	}
+	return seg.Offset + 2*int64(len(utf16.Encode([]rune(prefix))))
+}
@@ -0,0 +1,49 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineContinuation(t *testing.T) {
+	config := DefaultParserConfig()
+	config.LineContinuation = "\\"
+	p := newTestParser(t, config, exprGrammar)
+	result := parseTestSource(t, p, "li r1, \\  \n  10\nli r2, 20 ; not continued \\\nli r3, \\\n 1ff\nli r4, 4 \\")
+	if len(result.Lines) != 4 {
+		t.Fatalf("%d lines, want 4", len(result.Lines))
+	}
+	first := result.Lines[0]
+	if got := lineValue(t, result, 0); got != uint64(0x10) || first.RawText != "li r1,   10" || first.LineNumber != 1 {
+		t.Errorf("joined line = %q at line %d with value %v", first.RawText, first.LineNumber, got)
+	}
+	if want := []LineSegment{{Line: 1, Column: 1, Offset: 0}, {Line: 2, Column: 8, Offset: 11}}; !reflect.DeepEqual(first.Segments, want) {
+		t.Errorf("Segments = %+v, want %+v", first.Segments, want)
+	}
+	if second := result.Lines[1]; second.LineNumber != 3 || second.Segments != nil || lineValue(t, result, 1) != uint64(0x20) {
+		t.Errorf("a marker in a comment continued line %d: %+v", second.LineNumber, second.Segments)
+	}
+	third := result.Lines[2]
+	if third.Ok || third.LineNumber != 4 {
+		t.Fatalf("li r3, 1ff = %v at line %d, want a failure at line 4", third.Ok, third.LineNumber)
+	}
+	if line, column := third.SourcePosition(third.ErrorColumn); line != 5 || column != 2 {
+		t.Errorf("SourcePosition(%d) = %d:%d, want 5:2", third.ErrorColumn, line, column)
+	}
+	if line, column := third.SourcePosition(3); line != 4 || column != 3 {
+		t.Errorf("SourcePosition(3) = %d:%d, want 4:3", line, column)
+	}
+	if last := result.Lines[3]; last.LineNumber != 6 || lineValue(t, result, 3) != uint64(4) {
+		t.Errorf("the marker on the last line was not dropped: %q", last.RawText)
+	}
+}
+
+func TestLineContinuationOff(t *testing.T) {
+	result := parseTestSource(t, newTestParser(t, DefaultParserConfig(), exprGrammar), "li r1, \\\n10\n")
+	if len(result.Lines) != 2 || result.Lines[0].Ok {
+		t.Errorf("lines were joined without LineContinuation: %+v", result.Lines)
+	}
+	if line, column := result.Lines[0].SourcePosition(4); line != 1 || column != 4 {
+		t.Errorf("SourcePosition(4) = %d:%d, want 1:4", line, column)
+	}
+}
@@ -0,0 +1,133 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// TokenCustomBase is the first token type id handed out by Parser.AddTokenType.
+const TokenCustomBase = 256
+
+// CustomTokenType
+// describes a user-defined token: its id, name, the pattern that recognizes it and
+// the function converting its text into an object.
+type CustomTokenType struct {
+	Id      int
+	Name    string
+	Pattern string
+	Convert func(string) (ObjectType, error)
+	regex   *regexp.Regexp
+}
+
+// AddTokenType
+// defines a domain-specific token type such as a MAC address or IP literal. The pattern is
+// anchored at the start of the remaining input and is tried before the built-in patterns,
+// in the order custom types were added. The returned id is used as the TemplateType of
+// template slots expecting the new token and as the ObjectTypeId of the converted objects.
+// The name must not already name a built-in or custom type, so grammars can refer to it.
+func (p *Parser) AddTokenType(name string, pattern string, convert func(string) (ObjectType, error)) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return 0, fmt.Errorf("cannot add token type %s: %s", name, errFrozen)
+	}
+	if name == "" {
+		return 0, fmt.Errorf("token type name is empty")
+	}
+	if id, taken := p.TokenTypes().ByName(name); taken {
+		return 0, fmt.Errorf("token type name %s is already used by type %d", name, id)
+	}
+	if convert == nil {
+		return 0, fmt.Errorf("token type %s has no convert function", name)
+	}
+	// An alternation is anchored as a whole, whether or not it starts with ^
+	pattern = "^(?:" + pattern + ")"
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("token type %s: %w", name, err)
+	}
+	if regex.MatchString("") {
+		return 0, fmt.Errorf("token type %s: pattern matches the empty string", name)
+	}
+	id := TokenCustomBase + len(p.config.customTokens)
+	p.config.customTokens = append(p.config.customTokens,
+		CustomTokenType{Id: id, Name: name, Pattern: pattern, Convert: convert, regex: regex})
+	p.lexer = newLexer(p.config)
+	return id, nil
+}
+
+// TokenName
+// returns the name of a built-in or custom token type.
+func (p *Parser) TokenName(id int) string {
+	return p.config.tokenName(id)
+}
+
+// TokenSpec
+// describes a token type as the parser knows it: its id, its name and whether it is a
+// custom type. Known is false for ids the parser has no name for; such types are named
+// after their id.
+type TokenSpec struct {
+	Id     int
+	Name   string
+	Custom bool
+	Known  bool
+}
+
+// TokenSpec
+// returns the spec of a built-in or custom token type. It never fails: ids that are
+// neither built in nor registered get a generic name.
+func (p *Parser) TokenSpec(id int) TokenSpec {
+	return p.config.tokenSpec(id)
+}
+
+// TokenSpecs
+// returns the specs of every built-in token type followed by the custom types in the
+// order they were added.
+func (p *Parser) TokenSpecs() []TokenSpec {
+	return p.TokenTypes().Specs()
+}
+
+// customTokenType
+// looks up a custom token type by id.
+func (config ParserConfig) customTokenType(id int) (CustomTokenType, bool) {
+	idx := id - TokenCustomBase
+	if idx < 0 || idx >= len(config.customTokens) {
+		return CustomTokenType{}, false
+	}
+	return config.customTokens[idx], true
+}
+
+// customPatterns
+// returns the tokenizer patterns of the custom token types.
+func (config ParserConfig) customPatterns() []tokenPattern {
+	patterns := make([]tokenPattern, len(config.customTokens))
+	for idx, custom := range config.customTokens {
+		patterns[idx] = tokenPattern{custom.regex, custom.Id}
+	}
+	return patterns
+}
+
+// tokenSpec
+// resolves a token type id through the custom types and then the built-in types, falling
+// back to a generic name for ids neither covers, including custom ids from another parser.
+func (config ParserConfig) tokenSpec(id int) TokenSpec {
+	if custom, found := config.customTokenType(id); found && custom.Name != "" {
+		return TokenSpec{Id: id, Name: custom.Name, Custom: true, Known: true}
+	}
+	return builtinRegistry.Spec(id)
+}
+
+// tokenName
+// returns the name of a token type, falling back to a generic name for unknown ids.
+func (config ParserConfig) tokenName(id int) string {
+	return config.tokenSpec(id).Name
+}
+
+// mismatchError
+// formats the error reported when an object's type does not match its template slot.
+func (config ParserConfig) mismatchError(tmpl TemplateObject, ot int) string {
+	tt := tmpl.TemplateType
+	return fmt.Sprintf("Expected type (%d)%s but got type (%d)%s: %s",
+		tt, config.tokenName(tt), ot, config.tokenName(ot), tmpl.TemplateError)
+}
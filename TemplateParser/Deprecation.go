@@ -0,0 +1,191 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TokenDeprecation
+// marks a token class as deprecated from grammar version Since on, so lines using it still
+// match but carry a warning. An empty Since deprecates the class in every version. Message
+// is added to the warning, typically to name the replacement syntax.
+type TokenDeprecation struct {
+	Type    int
+	Since   string
+	Message string
+}
+
+// Warning
+// is a problem with a line that does not stop it from matching, such as the use of a
+// deprecated token class. Column is the 1-based byte column of the token concerned and
+// Length its length.
+type Warning struct {
+	Column  int    `json:"column"`
+	Length  int    `json:"length,omitempty"`
+	Message string `json:"message"`
+}
+
+// SetGrammarVersion
+// sets the grammar version lines are parsed for, such as "2.1". Token deprecations apply
+// once this version reaches their Since version; with no version set every deprecation
+// applies. The version is part of the grammar, so it cannot change once the parser is
+// frozen.
+func (p *Parser) SetGrammarVersion(version string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return fmt.Errorf("cannot set grammar version: %s", errFrozen)
+	}
+	p.grammarVersion = version
+	return nil
+}
+
+// GrammarVersion
+// returns the version set with SetGrammarVersion or by a grammar file.
+func (p *Parser) GrammarVersion() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.grammarVersion
+}
+
+// DeprecateTokenType
+// marks a built-in or custom token class as deprecated, replacing any earlier deprecation
+// of it.
+func (p *Parser) DeprecateTokenType(deprecation TokenDeprecation) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	name := p.config.tokenName(deprecation.Type)
+	if p.frozen != "" {
+		return fmt.Errorf("cannot deprecate %s: %s", name, errFrozen)
+	}
+	if !p.config.tokenSpec(deprecation.Type).Known || deprecation.Type == TokenUnknown {
+		return fmt.Errorf("cannot deprecate unknown token type %d", deprecation.Type)
+	}
+	if p.deprecations == nil {
+		p.deprecations = make(map[int]TokenDeprecation)
+	}
+	p.deprecations[deprecation.Type] = deprecation
+	return nil
+}
+
+// TokenDeprecations
+// returns every token deprecation, whether or not it applies to the current grammar
+// version, ordered by token type.
+func (p *Parser) TokenDeprecations() []TokenDeprecation {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tokenDeprecations()
+}
+
+// tokenDeprecations
+// implements TokenDeprecations.
+func (p *Parser) tokenDeprecations() []TokenDeprecation {
+	deprecations := make([]TokenDeprecation, 0, len(p.deprecations))
+	for _, deprecation := range p.deprecations {
+		deprecations = append(deprecations, deprecation)
+	}
+	sort.Slice(deprecations, func(a, b int) bool { return deprecations[a].Type < deprecations[b].Type })
+	return deprecations
+}
+
+// deprecationWarnings
+// returns a warning for every token of a line whose class is deprecated in the current
+// grammar version.
+func (p *Parser) deprecationWarnings(tokens []Token) []Warning {
+	if len(p.deprecations) == 0 {
+		return nil
+	}
+	var warnings []Warning
+	offset := 0
+	for _, token := range tokens {
+		if deprecation, found := p.deprecations[token.Type]; found && p.deprecationApplies(deprecation) {
+			warnings = append(warnings, Warning{Column: offset + 1, Length: len(token.ValueReceived),
+				Message: p.deprecationMessage(deprecation, token)})
+		}
+		offset += len(token.ValueReceived)
+	}
+	return warnings
+}
+
+// deprecationApplies
+// reports whether a deprecation applies to the current grammar version.
+func (p *Parser) deprecationApplies(deprecation TokenDeprecation) bool {
+	return deprecation.Since == "" || p.grammarVersion == "" ||
+		compareVersions(p.grammarVersion, deprecation.Since) >= 0
+}
+
+// deprecationMessage
+// describes the use of a deprecated token.
+func (p *Parser) deprecationMessage(deprecation TokenDeprecation, token Token) string {
+	msg := fmt.Sprintf("%s %q is deprecated", p.config.tokenName(token.Type), token.ValueReceived)
+	if deprecation.Since != "" {
+		msg += " since grammar version " + deprecation.Since
+	}
+	if deprecation.Message != "" {
+		msg += ": " + deprecation.Message
+	}
+	return msg
+}
+
+// compareVersions
+// compares dotted version strings part by part, numerically where both parts are numbers
+// and as text otherwise, so 1.10 follows 1.9. Missing parts count as zero.
+func compareVersions(a string, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for idx := 0; idx < max(len(aParts), len(bParts)); idx++ {
+		aPart, bPart := "0", "0"
+		if idx < len(aParts) {
+			aPart = aParts[idx]
+		}
+		if idx < len(bParts) {
+			bPart = bParts[idx]
+		}
+		aNum, aErr := strconv.ParseUint(aPart, 10, 64)
+		bNum, bErr := strconv.ParseUint(bPart, 10, 64)
+		switch {
+		case aErr == nil && bErr == nil && aNum != bNum:
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && aPart != bPart:
+			return strings.Compare(aPart, bPart)
+		}
+	}
+	return 0
+}
+
+// Warnings
+// returns a diagnostic for every warning of the parsed lines, in source order.
+func (sr *SourceResult) Warnings() []Diagnostic {
+	diagnostics := make([]Diagnostic, 0)
+	for _, line := range sr.Lines {
+		for _, warning := range line.Warnings {
+			diagnostics = append(diagnostics, newWarningDiagnostic(line, warning))
+		}
+	}
+	return diagnostics
+}
+
+// newWarningDiagnostic
+// builds the diagnostic of a warning on a line.
+func newWarningDiagnostic(lr LineResult, warning Warning) Diagnostic {
+	source := lr.Expanded
+	if source == "" {
+		source = lr.RawText
+	}
+	return Diagnostic{
+		ParseError: ParseError{File: lr.File, Line: lr.LineNumber, Column: warning.Column, Text: lr.RawText,
+			Message: warning.Message, Includes: lr.Includes},
+		Offset:        lr.originalOffset(warning.Column),
+		RuneColumn:    runeColumn(lr.columnText(), warning.Column),
+		DisplayColumn: displayColumn(lr.columnText(), warning.Column),
+		Source:        source,
+		Length:        warning.Length,
+		Warning:       true,
+	}
+}
@@ -0,0 +1,213 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Diagnostic
+// is a ParseError with what a compiler-style report needs: the text the column refers to,
+// the length of the offending token, and the type and error text of the template slot it
+// failed to match. Source is the line after macro expansion, which differs from Text for
+// lines produced by macros. Column counts bytes; RuneColumn counts characters instead and
+// DisplayColumn is where Column appears on screen, counting tab stops of DefaultTabWidth
+// and wide characters as two cells. Warning is set on diagnostics of warnings rather than
+// errors. Hint is the "did you mean" note of the line's suggestions.
+// Offset is the byte offset of the column in the original input, which differs from its
+// position in the decoded text for UTF-16 sources; for lines changed by macro expansion it
+// is the offset of the line.
+type Diagnostic struct {
+	ParseError
+	Offset        int64  `json:"offset,omitempty"`
+	RuneColumn    int    `json:"rune_column,omitempty"`
+	DisplayColumn int    `json:"display_column,omitempty"`
+	Source        string `json:"source,omitempty"`
+	Length        int    `json:"length,omitempty"`
+	Expected      string `json:"expected,omitempty"`
+	TemplateError string `json:"template_error,omitempty"`
+	Warning       bool   `json:"warning,omitempty"`
+	Hint          string `json:"hint,omitempty"`
+}
+
+// NewDiagnostic
+// builds the diagnostic of a failed line.
+func NewDiagnostic(lr LineResult) Diagnostic {
+	source := lr.Expanded
+	if source == "" {
+		source = lr.RawText
+	}
+	return Diagnostic{
+		ParseError:    lr.parseError(),
+		Offset:        lr.originalOffset(lr.ErrorColumn),
+		RuneColumn:    runeColumn(lr.columnText(), lr.ErrorColumn),
+		DisplayColumn: displayColumn(lr.columnText(), lr.ErrorColumn),
+		Source:        source,
+		Length:        lr.ErrorLength,
+		Expected:      lr.Expected,
+		TemplateError: lr.TemplateError,
+		Hint:          lr.Suggestions.DidYouMean(),
+	}
+}
+
+// displayColumn
+// returns the display column of a byte column with the default tab width, 0 when the
+// column is unknown.
+func displayColumn(text string, column int) int {
+	if column <= 0 {
+		return 0
+	}
+	return DisplayColumn(text, column, 0)
+}
+
+// runeColumn
+// returns the character column of a byte column, 0 when the column is unknown.
+func runeColumn(text string, column int) int {
+	if column <= 0 {
+		return 0
+	}
+	return RuneColumn(text, column)
+}
+
+// Diagnostics
+// returns a diagnostic for every failed line, in source order.
+func (sr *SourceResult) Diagnostics() []Diagnostic {
+	diagnostics := make([]Diagnostic, 0)
+	for _, line := range sr.Lines {
+		if !line.Ok {
+			diagnostics = append(diagnostics, NewDiagnostic(line))
+		}
+	}
+	return diagnostics
+}
+
+// String
+// renders the diagnostic the way compilers do:
+//
+//	file:3:9: error: Expected type (6)Register but got type (2)Uint64: source register
+//	    mov r1, 5
+//	            ^
+//	    expected Register: source register
+//
+// with any hint such as "did you mean mov64?" under the note, followed by the macro
+// expansions and include chain of the line. The source line and
+// caret are left out when the error concerns the whole line. Tabs are expanded to stops of
+// DefaultTabWidth.
+func (d Diagnostic) String() string {
+	return d.render(0)
+}
+
+// render
+// implements String with the given tab width.
+func (d Diagnostic) render(tabWidth int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s: %s\n", d.position(), d.severity(), d.Message)
+	if d.Column > 0 && d.Source != "" {
+		fmt.Fprintf(&sb, "    %s\n    %s\n", expandTabs(d.Source, tabWidth), caretLine(d.Source, d.Column, d.Length, tabWidth))
+		if d.Source != d.Text && d.Text != "" {
+			fmt.Fprintf(&sb, "    expanded from: %s\n", strings.TrimSpace(d.Text))
+		}
+	}
+	if d.Expected != "" {
+		note := "expected " + d.Expected
+		if d.TemplateError != "" {
+			note += ": " + d.TemplateError
+		}
+		fmt.Fprintf(&sb, "    %s\n", note)
+	}
+	if d.Hint != "" {
+		fmt.Fprintf(&sb, "    %s\n", d.Hint)
+	}
+	if chain := d.chain(); chain != "" {
+		sb.WriteString(strings.TrimPrefix(chain, "\n"))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// severity
+// returns "warning" or "error".
+func (d Diagnostic) severity() string {
+	if d.Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+// caretLine
+// returns the line placing a caret under the 1-based byte column of text, with tildes
+// under the rest of a token of the given length in bytes, measured in display cells.
+func caretLine(text string, column int, length int, tabWidth int) string {
+	start := min(max(column-1, 0), len(text))
+	end := min(start+max(length, 0), len(text))
+	width := displayWidth(text[:start], 0, tabWidth)
+	marker := "^"
+	if cells := displayWidth(text[start:end], width, tabWidth); cells > 1 {
+		marker += strings.Repeat("~", cells-1)
+	}
+	return strings.Repeat(" ", width) + marker
+}
+
+// DiagnosticFormatter
+// writes diagnostics in some output format, so tools built on the package can choose how
+// errors are reported.
+type DiagnosticFormatter interface {
+	FormatDiagnostic(w io.Writer, d Diagnostic) error
+}
+
+// TextFormatter
+// writes diagnostics with Diagnostic.String, with the source line and caret. TabWidth sets
+// the tab stops the source line is expanded to; zero means DefaultTabWidth.
+type TextFormatter struct {
+	TabWidth int
+}
+
+// FormatDiagnostic
+// writes a diagnostic as text.
+func (tf TextFormatter) FormatDiagnostic(w io.Writer, d Diagnostic) error {
+	_, err := io.WriteString(w, d.render(tf.TabWidth))
+	return err
+}
+
+// CompactFormatter
+// writes one file:line:column: message line per diagnostic, the form editors parse.
+// Warnings are marked with "warning: " before the message.
+type CompactFormatter struct{}
+
+// FormatDiagnostic
+// writes a diagnostic as a single line.
+func (CompactFormatter) FormatDiagnostic(w io.Writer, d Diagnostic) error {
+	prefix := ""
+	if d.Warning {
+		prefix = "warning: "
+	}
+	_, err := fmt.Fprintf(w, "%s: %s%s\n", d.position(), prefix, d.Message)
+	return err
+}
+
+// JSONFormatter
+// writes every diagnostic as a JSON object on its own line.
+type JSONFormatter struct{}
+
+// FormatDiagnostic
+// writes a diagnostic as a line of JSON.
+func (JSONFormatter) FormatDiagnostic(w io.Writer, d Diagnostic) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// WriteDiagnostics
+// writes diagnostics with a formatter, stopping at the first write error.
+func WriteDiagnostics(w io.Writer, formatter DiagnosticFormatter, diagnostics []Diagnostic) error {
+	for _, d := range diagnostics {
+		if err := formatter.FormatDiagnostic(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Directive
+// is a dot-directive such as .byte 1, 2, 3 or .string "hi", registered with
+// RegisterDirective apart from the instruction templates. Name is the directive's name
+// without the dot, matched as mnemonics are, so ignoring case unless the configuration
+// preserves it without folding mnemonics. Operands are the slots of the operands after the
+// name, matched as the slots of a template are; with Repeat the last slot may be repeated
+// any number of times, each time after a comma. Size, if set, returns the number of bytes
+// the directive emits for its objects, by which it advances the location counter. Handler,
+// if set, is called with the line's context and objects for every matched line, in source
+// order, as an entry's ContextAction is; an error fails the line.
+type Directive struct {
+	Name     string
+	Operands []TemplateObject
+	Repeat   bool
+	Size     func(objs []ObjectType) uint64
+	Handler  func(ctx *ParseContext, objs []ObjectType) error
+}
+
+// RegisterDirective
+// registers a dot-directive, replacing any directive of the same name. Lines starting
+// with a dot and its name, after their label, are matched against its operands instead of
+// the instruction templates, and carry its name in ParsedLine.Directive and its operands
+// in Objects. The name must be an identifier other than those of the built-in directives
+// org and arch, and the operand slots must pass the checks templates do.
+func (p *Parser) RegisterDirective(directive Directive) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.frozen != "" {
+		return false, errFrozen
+	}
+	tokens := contentTokens(p.lexer.scan(directive.Name))
+	if len(tokens) != 1 || tokens[0].Type != TokenIdentifier {
+		return false, fmt.Sprintf("Directive name %q is not an identifier", directive.Name)
+	}
+	name := p.mnemonicKey(directive.Name)
+	if name == OriginDirective || name == ArchDirective {
+		return false, fmt.Sprintf("Directive .%s is built in", name)
+	}
+	if directive.Repeat && len(directive.Operands) == 0 {
+		return false, fmt.Sprintf("Directive .%s repeats its last operand but has none", name)
+	}
+	if ok, errmsg := p.checkOperandSlots(directive.Operands); !ok {
+		return false, fmt.Sprintf("Directive .%s: %s", name, errmsg)
+	}
+	p.invalidateCache()
+	directive.Name = name
+	directive.Operands = append([]TemplateObject(nil), directive.Operands...)
+	if p.directives == nil {
+		p.directives = make(map[string]*Directive)
+	}
+	p.directives[name] = &directive
+	return true, ""
+}
+
+// Directives
+// returns the names of the registered directives, sorted.
+func (p *Parser) Directives() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.directives))
+	for name := range p.directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupDirective
+// returns the registered directive of a name, matched as in lines, ignoring a leading dot.
+func (p *Parser) LookupDirective(name string) (Directive, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	directive, found := p.directives[p.mnemonicKey(strings.TrimPrefix(name, "."))]
+	if !found {
+		return Directive{}, false
+	}
+	return *directive, true
+}
+
+// checkOperandSlots
+// runs the checks RegisterEntry makes on a template list on the operand slots of a
+// directive. The caller holds the parser's lock.
+func (p *Parser) checkOperandSlots(templateList []TemplateObject) (bool, string) {
+	checks := []func([]TemplateObject) (bool, string){ValidateGroups, ValidateNames, ValidateBackReferences,
+		ValidateChoices, p.checkChoiceTokens, ValidateFlags, p.checkFlagTokens}
+	for _, check := range checks {
+		if ok, errmsg := check(templateList); !ok {
+			return false, errmsg
+		}
+	}
+	return true, ""
+}
+
+// registeredDirective
+// returns the registered directive a tokenized line starts with, after its label, and the
+// tokens of its operands, or nil.
+func (p *Parser) registeredDirective(tokens []Token) (*Directive, []Token) {
+	if len(p.directives) == 0 {
+		return nil, nil
+	}
+	var buf [2]Token
+	content := leadingContent(tokens, buf[:])
+	if len(content) < 2 || content[0].Type != TokenUnknown || content[0].ValueReceived != "." ||
+		content[1].Type != TokenIdentifier {
+		return nil, nil
+	}
+	directive, found := p.directives[p.mnemonicKey(content[1].ValueReceived)]
+	if !found {
+		return nil, nil
+	}
+	seen := 0
+	for idx, token := range tokens {
+		if !isBlankToken(token) {
+			seen++
+		}
+		if seen == 2 {
+			return directive, tokens[idx+1:]
+		}
+	}
+	return nil, nil
+}
+
+// slots
+// returns the operand slots a directive matches tokens against: its operands, with the
+// last one repeated after a comma for every comma the tokens hold beyond those of the
+// operands.
+func (directive *Directive) slots(tokens []Token) []TemplateObject {
+	if !directive.Repeat {
+		return directive.Operands
+	}
+	commas := 0
+	for _, token := range tokens {
+		if token.Type == TokenComma {
+			commas++
+		}
+	}
+	for _, tmpl := range directive.Operands {
+		if tmpl.TemplateType == TokenComma {
+			commas--
+		}
+	}
+	last := directive.Operands[len(directive.Operands)-1]
+	slots := append([]TemplateObject(nil), directive.Operands...)
+	for ; commas > 0; commas-- {
+		slots = append(slots, TemplateObject{TemplateType: TokenComma, TemplateError: last.TemplateError}, last)
+	}
+	return slots
+}
+
+// matchDirective
+// matches the operand tokens of a directive line as matchEntry matches those of an
+// instruction, constants and operand expressions included.
+func (p *Parser) matchDirective(lineNo int, label string, allTokens []Token, operands []Token, directive *Directive, ctx *ParseContext) LineResult {
+	result := LineResult{ParsedLine: ParsedLine{LineNumber: lineNo, Label: label, Directive: directive.Name}}
+	result.directive = directive
+	slots := directive.slots(operands)
+	if !hasContent(operands) {
+		if len(slots) > 0 {
+			result.Error = fmt.Sprintf("Expected operands after .%s", directive.Name)
+			result.ErrorColumn = objectColumn(allTokens, operands, len(operands))
+			return result
+		}
+		result.Ok = true
+		result.Size = directive.size(nil)
+		return result
+	}
+	if len(slots) == 0 {
+		result.Error = fmt.Sprintf("Directive .%s takes no operands", directive.Name)
+		result.ErrorColumn = objectColumn(allTokens, operands, 0)
+		return result
+	}
+	leading := allTokens[:len(allTokens)-len(operands)]
+	constants := p.constantScope(ctx)
+	operands = p.substituteConstants(collapseExpressions(operands, slots), slots, constants)
+	allTokens = append(append([]Token(nil), leading...), operands...)
+	objs, errIdx, starts, ok, errmsg := matchTokens(operands, slots, p.config)
+	result.Objects, result.Ok, result.Error = objs, ok, errmsg
+	if !ok {
+		result.ErrorColumn = matchedColumn(allTokens, operands, starts, errIdx)
+		if errIdx >= 0 && errIdx < len(slots) {
+			result.Expected = p.config.tokenName(slots[errIdx].TemplateType)
+			result.TemplateError = slots[errIdx].TemplateError
+		}
+		return result
+	}
+	result.templates = slots
+	result.describe(p.config)
+	p.evaluateExpressions(&result, slots, constants)
+	if result.Ok {
+		result.Size = directive.size(result.Objects)
+	}
+	return result
+}
+
+// size
+// returns the number of bytes a directive emits for its objects.
+func (directive *Directive) size(objs []ObjectType) uint64 {
+	if directive.Size == nil {
+		return 0
+	}
+	return directive.Size(objs)
+}
@@ -0,0 +1,99 @@
+package TemplateParser
+
+import (
+	"errors"
+	"testing"
+)
+
+// registerByte
+// registers a .byte directive taking any number of Uint8 operands, one byte each, whose
+// handler fails on zero, failing the test if registering fails.
+func registerByte(t *testing.T, p *Parser) {
+	t.Helper()
+	directive := Directive{
+		Name:     "Byte",
+		Operands: []TemplateObject{{TemplateType: TokenUint8}},
+		Repeat:   true,
+		Size:     func(objs []ObjectType) uint64 { return uint64(len(objs)+1) / 2 },
+		Handler: func(ctx *ParseContext, objs []ObjectType) error {
+			for _, obj := range objs {
+				if obj.ObjectValue == uint64(0) {
+					return errors.New("zero byte")
+				}
+			}
+			return nil
+		},
+	}
+	if ok, errmsg := p.RegisterDirective(directive); !ok {
+		t.Fatalf("RegisterDirective: %s", errmsg)
+	}
+}
+
+func TestRegisteredDirective(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	registerByte(t, p)
+	result := parseTestSource(t, p, "start: .byte 1, 2, 3\nli r1, 10\n.byte\n.byte 1, 0\n")
+	first := result.Lines[0]
+	if !first.Ok || first.Directive != "byte" || first.Label != "start" || len(first.Operands) != 3 {
+		t.Fatalf("line 1 = ok %v, directive %q, label %q, %d operands (%s)", first.Ok, first.Directive,
+			first.Label, len(first.Operands), first.Error)
+	}
+	if got := result.Lines[1].Address; got != 3 {
+		t.Errorf("address after .byte 1, 2, 3 = %d, want 3", got)
+	}
+	if result.Lines[2].Ok || result.Lines[2].Error != "Expected operands after .byte" {
+		t.Errorf("line 3 = ok %v, error %q", result.Lines[2].Ok, result.Lines[2].Error)
+	}
+	if result.Lines[3].Ok {
+		t.Errorf("line 4 passed its handler")
+	}
+	if names := p.Directives(); len(names) != 1 || names[0] != "byte" {
+		t.Errorf("Directives() = %v, want [byte]", names)
+	}
+	if _, found := p.LookupDirective(".BYTE"); !found {
+		t.Errorf("LookupDirective(.BYTE) found nothing")
+	}
+}
+
+func TestRegisterDirectiveRejects(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	for _, directive := range []Directive{
+		{Name: "two words"},
+		{Name: "ORG"},
+		{Name: "arch"},
+		{Name: "each", Repeat: true},
+	} {
+		if ok, _ := p.RegisterDirective(directive); ok {
+			t.Errorf("RegisterDirective(%q) succeeded", directive.Name)
+		}
+	}
+}
+
+func TestDirectiveCase(t *testing.T) {
+	preserve := DefaultParserConfig()
+	preserve.PreserveCase = true
+	folding := preserve
+	folding.FoldMnemonics = true
+	tests := []struct {
+		name    string
+		config  ParserConfig
+		line    string
+		matched bool
+	}{
+		{"default", DefaultParserConfig(), ".BYTE 1", true},
+		{"preserve case", preserve, ".Byte 1", true},
+		{"preserve case", preserve, ".byte 1", false},
+		{"fold mnemonics", folding, ".BYTE 1", true},
+	}
+	for _, tt := range tests {
+		p := newTestParser(t, tt.config, exprGrammar)
+		registerByte(t, p)
+		lr := parseTestSource(t, p, tt.line+"\n").Lines[0]
+		if matched := lr.Ok && lr.Directive != ""; matched != tt.matched {
+			t.Errorf("%s: %q matched a directive = %v, want %v (%s)", tt.name, tt.line, matched, tt.matched, lr.Error)
+		}
+		if _, found := p.LookupDirective(tt.line[:5]); found != tt.matched {
+			t.Errorf("%s: LookupDirective(%q) found = %v, want %v", tt.name, tt.line[:5], found, tt.matched)
+		}
+	}
+}
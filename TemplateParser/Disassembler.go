@@ -0,0 +1,113 @@
+package TemplateParser
+
+import "fmt"
+
+// Decode
+// is the inverse of Encode. It finds the first registered entry available with the
+// current target options and profile whose encoding's constant fields match an instruction word and
+// rebuilds the line the word encodes: the mnemonic, the operands read from their fields
+// and the punctuation of the template. address is where the instruction sits; relative
+// label fields hold the signed distance from it, as in the results of ParseSource. Every
+// operand slot of the entry must have a field. Encoding the decoded line gives the word
+// back, apart from bits no field covers.
+func (p *Parser) Decode(word uint64, address uint64) (ParsedLine, bool, string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, name := range p.mnemonics {
+		for _, entry := range p.registry[name] {
+			if entry.Encoding == nil || !matchesOpcode(entry.Encoding, word) {
+				continue
+			}
+			if ok, _ := p.available(entry, p.profile); !ok {
+				continue
+			}
+			return p.decodeEntry(entry, word, address)
+		}
+	}
+	return ParsedLine{}, false, fmt.Sprintf("No template encodes %#x", word)
+}
+
+// matchesOpcode
+// reports whether the constant fields of an encoding match an instruction word.
+func matchesOpcode(enc *Encoding, word uint64) bool {
+	for _, field := range enc.Fields {
+		if field.Slot == 0 && word>>uint(field.Offset)&fieldMask(field.Width) != field.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeEntry
+// rebuilds the line an entry encodes into an instruction word.
+func (p *Parser) decodeEntry(entry *TemplateEntry, word uint64, address uint64) (ParsedLine, bool, string) {
+	fields := make(map[int]BitField, len(entry.Encoding.Fields))
+	for _, field := range entry.Encoding.Fields {
+		if field.Slot > 0 {
+			fields[field.Slot] = field
+		}
+	}
+	objs := make([]ObjectType, len(entry.Objects))
+	objs[0] = newObject(TokenIdentifier, entry.Name, "")
+	for idx := 1; idx < len(entry.Objects); idx++ {
+		tmpl := entry.Objects[idx]
+		field, encoded := fields[idx]
+		switch {
+		case tmpl.TemplateType == TokenOperator:
+			op, _ := tmpl.TemplateValue.ObjectValue.(string)
+			if op == "" {
+				return ParsedLine{}, false, fmt.Sprintf("Slot %d of %s does not name its operator", idx, entry.Name)
+			}
+			objs[idx] = newObject(TokenOperator, op, "")
+		case isPunctuation(tmpl.TemplateType):
+			objs[idx] = newObject(tmpl.TemplateType, punctuationText[tmpl.TemplateType], "")
+		case !encoded:
+			return ParsedLine{}, false, fmt.Sprintf("Slot %d of %s is not encoded", idx, entry.Name)
+		default:
+			bits := word >> uint(field.Offset) & fieldMask(field.Width)
+			var value interface{} = bits
+			if field.Signed && field.Width < 64 && bits>>uint(field.Width-1) != 0 {
+				value = int64(bits | ^fieldMask(field.Width))
+			} else if field.Signed || tmpl.TemplateType == TokenLabelRel {
+				value = int64(bits)
+			}
+			objs[idx] = newObject(tmpl.TemplateType, value, "")
+		}
+		objs[idx].ObjectSensitive = tmpl.Sensitive
+	}
+	pl := ParsedLine{Address: address, Size: entry.Size, Cycles: entry.Cycles, Objects: objs, entry: entry}
+	pl.templates = entry.Objects
+	pl.describe(p.config)
+	return pl, true, ""
+}
+
+// Disassemble
+// decodes an instruction word with Decode and renders the line with FormatLine. Label
+// operands are written as the address they refer to, since the word does not record
+// their names.
+func (p *Parser) Disassemble(word uint64, address uint64) (string, bool, string) {
+	pl, ok, errmsg := p.Decode(word, address)
+	if !ok {
+		return "", false, errmsg
+	}
+	objs := append([]ObjectType(nil), pl.Objects...)
+	for idx, obj := range objs {
+		switch val := obj.ObjectValue.(type) {
+		case int64:
+			if obj.ObjectTypeId == TokenLabelRel {
+				objs[idx] = newObject(TokenExpression, address+uint64(val), "")
+			} else if val < 0 {
+				text := fmt.Sprintf("-%x", uint64(-val))
+				objs[idx] = newObject(TokenExpression, text, text)
+			} else {
+				objs[idx].ObjectValue = uint64(val)
+			}
+		case uint64:
+			if obj.ObjectTypeId == TokenLabelRef {
+				objs[idx] = newObject(TokenExpression, val, "")
+			}
+		}
+	}
+	pl.Objects = objs
+	return p.FormatLine(pl)
+}
@@ -0,0 +1,113 @@
+package TemplateParser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultTabWidth is the distance between tab stops used for display columns when no
+// other width is given.
+const DefaultTabWidth = 4
+
+// DisplayColumn
+// converts a 1-based byte column of text into the 1-based column a terminal or editor
+// shows it at: tabs advance to the next multiple of tabWidth, East Asian wide and
+// fullwidth characters take two cells and combining marks none. A tabWidth below 1 means
+// DefaultTabWidth.
+func DisplayColumn(text string, column int, tabWidth int) int {
+	return displayWidth(text[:min(max(column-1, 0), len(text))], 0, tabWidth) + 1
+}
+
+// RuneColumn
+// converts a 1-based byte column of text into the 1-based number of the character it falls
+// on, as editors counting characters rather than bytes report positions.
+func RuneColumn(text string, column int) int {
+	return utf8.RuneCountInString(text[:min(max(column-1, 0), len(text))]) + 1
+}
+
+// displayWidth
+// returns the number of cells text takes when it starts at cell start.
+func displayWidth(text string, start int, tabWidth int) int {
+	if tabWidth < 1 {
+		tabWidth = DefaultTabWidth
+	}
+	cells := start
+	for _, r := range text {
+		if r == '\t' {
+			cells += tabWidth - cells%tabWidth
+			continue
+		}
+		cells += runeWidth(r)
+	}
+	return cells - start
+}
+
+// expandTabs
+// replaces tabs with spaces up to the next tab stop so the caret line lines up in any
+// terminal.
+func expandTabs(text string, tabWidth int) string {
+	if !strings.ContainsRune(text, '\t') {
+		return text
+	}
+	if tabWidth < 1 {
+		tabWidth = DefaultTabWidth
+	}
+	var sb strings.Builder
+	cells := 0
+	for _, r := range text {
+		if r == '\t' {
+			pad := tabWidth - cells%tabWidth
+			sb.WriteString(strings.Repeat(" ", pad))
+			cells += pad
+			continue
+		}
+		sb.WriteRune(r)
+		cells += runeWidth(r)
+	}
+	return sb.String()
+}
+
+// runeWidth
+// returns the number of terminal cells a character takes.
+func runeWidth(r rune) int {
+	switch {
+	case r == utf8.RuneError || unicode.IsControl(r):
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWideRune(r):
+		return 2
+	}
+	return 1
+}
+
+// wideRanges lists the East Asian wide and fullwidth blocks and the emoji blocks that
+// terminals draw two cells wide.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo initials
+	{0x2e80, 0x303e},   // CJK radicals, Kangxi, CJK symbols and punctuation
+	{0x3041, 0x33ff},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe30, 0xfe4f},   // CJK compatibility forms
+	{0xff00, 0xff60},   // Fullwidth forms
+	{0xffe0, 0xffe6},   // Fullwidth signs
+	{0x1f300, 0x1f64f}, // Miscellaneous symbols and pictographs, emoticons
+	{0x1f900, 0x1f9ff}, // Supplemental symbols and pictographs
+	{0x20000, 0x3fffd}, // CJK extensions B and later
+}
+
+// isWideRune
+// reports whether a character is drawn two cells wide.
+func isWideRune(r rune) bool {
+	for _, span := range wideRanges {
+		if r >= span[0] && r <= span[1] {
+			return true
+		}
+	}
+	return false
+}
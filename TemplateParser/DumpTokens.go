@@ -0,0 +1,38 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DumpTokens
+// writes the tokens Tokenize splits input into as an aligned table. See Parser.DumpTokens.
+func DumpTokens(input string, w io.Writer) {
+	NewParser(DefaultParserConfig()).DumpTokens(input, w)
+}
+
+// DumpTokens
+// writes the tokens the parser's tokenizer splits input into as an aligned table, one row
+// per token with its index, the name of its type, its text quoted as a Go string, so
+// blanks and control characters show, and its position as line:column, both counted from
+// 1 with columns in bytes. Blank and unknown tokens are listed too, so the table accounts
+// for every byte of the input. The same input and configuration always give the same
+// table, which makes it suited to golden files and bug reports.
+func (p *Parser) DumpTokens(input string, w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tTYPE\tTEXT\tPOS")
+	line, column := 1, 1
+	for idx, token := range p.Tokenize(input) {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d:%d\n", idx, p.TokenName(token.Type), strconv.Quote(token.ValueReceived), line, column)
+		if breaks := strings.Count(token.ValueReceived, "\n"); breaks > 0 {
+			line += breaks
+			column = len(token.ValueReceived) - strings.LastIndexByte(token.ValueReceived, '\n')
+		} else {
+			column += len(token.ValueReceived)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,187 @@
+package TemplateParser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Emitter
+// receives the lines of a source from ParseSourceTo, in source order, so results can be
+// streamed to files or databases as they are produced instead of being kept in a
+// SourceResult. OnLine is called for every line that parsed and OnError for every line
+// that failed; an error from OnLine stops the source. Finish is called once after the
+// last line, also when OnLine failed, to flush and release what the emitter holds.
+type Emitter interface {
+	OnLine(pl ParsedLine) error
+	OnError(pe ParseError)
+	Finish() error
+}
+
+// ParseSourceTo
+// parses a source like ParseSource and hands its lines to an emitter rather than
+// returning them. Lines are emitted once the second pass has resolved their labels, as
+// they would appear in the SourceResult, and the result is dropped after the last line.
+// The returned error is that of OnLine if it failed, then that of Finish, then the error
+// ParseSource returns.
+func (p *Parser) ParseSourceTo(r io.Reader, emit Emitter) error {
+	result, err := p.ParseSource(r)
+	if emitErr := result.Emit(emit); emitErr != nil {
+		return emitErr
+	}
+	return err
+}
+
+// Emit
+// hands the lines of the result to an emitter as ParseSourceTo does, calling its Finish
+// once they are handed over, and returns the first error of OnLine or Finish.
+func (sr *SourceResult) Emit(emit Emitter) error {
+	var err error
+	for _, lr := range sr.Lines {
+		if !lr.Ok {
+			emit.OnError(lr.parseError())
+			continue
+		}
+		if err = emit.OnLine(lr.ParsedLine); err != nil {
+			break
+		}
+	}
+	if finishErr := emit.Finish(); err == nil {
+		err = finishErr
+	}
+	return err
+}
+
+// SliceEmitter
+// is an emitter keeping the lines and errors it receives in memory, in the order they were
+// emitted. The zero value is ready to use.
+type SliceEmitter struct {
+	Lines  []ParsedLine
+	Errors []ParseError
+}
+
+// OnLine
+// appends a line to Lines.
+func (se *SliceEmitter) OnLine(pl ParsedLine) error {
+	se.Lines = append(se.Lines, pl)
+	return nil
+}
+
+// OnError
+// appends an error to Errors.
+func (se *SliceEmitter) OnError(pe ParseError) {
+	se.Errors = append(se.Errors, pe)
+}
+
+// Finish
+// does nothing, the lines being kept.
+func (se *SliceEmitter) Finish() error {
+	return nil
+}
+
+// JSONLinesEmitter
+// is an emitter writing one JSON object per line to a writer: a line as ParsedLine
+// marshals, and an error as an object whose "error" field holds the ParseError. Output
+// is buffered until Finish.
+type JSONLinesEmitter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error // First write error, returned by the calls after it
+}
+
+// NewJSONLinesEmitter
+// creates an emitter writing JSON lines to w.
+func NewJSONLinesEmitter(w io.Writer) *JSONLinesEmitter {
+	bw := bufio.NewWriter(w)
+	return &JSONLinesEmitter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+// OnLine
+// writes a line.
+func (je *JSONLinesEmitter) OnLine(pl ParsedLine) error {
+	if je.err == nil {
+		je.err = je.enc.Encode(pl)
+	}
+	return je.err
+}
+
+// OnError
+// writes an error. A failure to write it is returned by Finish.
+func (je *JSONLinesEmitter) OnError(pe ParseError) {
+	if je.err == nil {
+		je.err = je.enc.Encode(struct {
+			Error ParseError `json:"error"`
+		}{pe})
+	}
+}
+
+// Finish
+// flushes the output, returning the first error writing it.
+func (je *JSONLinesEmitter) Finish() error {
+	if je.err == nil {
+		je.err = je.w.Flush()
+	}
+	return je.err
+}
+
+// ListingEmitter
+// is an emitter writing an assembler listing to a writer: the line number, address and
+// source text of every line, each failed line followed by its error, and the name of the
+// file before the first line of every file the source includes. Output is buffered until
+// Finish.
+type ListingEmitter struct {
+	w    *bufio.Writer
+	file string // File of the last line written
+	err  error  // First write error, returned by the calls after it
+}
+
+// NewListingEmitter
+// creates an emitter writing a listing to w.
+func NewListingEmitter(w io.Writer) *ListingEmitter {
+	return &ListingEmitter{w: bufio.NewWriter(w)}
+}
+
+// OnLine
+// writes a line as its number, its address in hex and its source text.
+func (le *ListingEmitter) OnLine(pl ParsedLine) error {
+	le.header(pl.File)
+	le.printf("%5d  %04x  %s\n", pl.LineNumber, pl.Address, pl.RawText)
+	return le.err
+}
+
+// OnError
+// writes a failed line with **** in place of its address, followed by its error. A
+// failure to write it is returned by Finish.
+func (le *ListingEmitter) OnError(pe ParseError) {
+	le.header(pe.File)
+	le.printf("%5d  ****  %s\n", pe.Line, pe.Text)
+	le.printf("%13s %s\n", "error:", pe.Message)
+}
+
+// Finish
+// flushes the output, returning the first error writing it.
+func (le *ListingEmitter) Finish() error {
+	if le.err == nil {
+		le.err = le.w.Flush()
+	}
+	return le.err
+}
+
+// header
+// writes the name of a file before its first line, when it differs from that of the last
+// line written.
+func (le *ListingEmitter) header(file string) {
+	if file != le.file {
+		le.file = file
+		le.printf("%s:\n", file)
+	}
+}
+
+// printf
+// writes formatted text unless an earlier write failed.
+func (le *ListingEmitter) printf(format string, args ...interface{}) {
+	if le.err == nil {
+		_, le.err = fmt.Fprintf(le.w, format, args...)
+	}
+}
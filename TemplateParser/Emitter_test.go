@@ -0,0 +1,167 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// emitterSource has two lines that parse around one that does not.
+const emitterSource = "li r1, 5\nli r1, zz\nle r1, 2 + 3\n"
+
+func TestSliceEmitter(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	var se SliceEmitter
+	if err := p.ParseSourceTo(strings.NewReader(emitterSource), &se); err != nil {
+		t.Fatalf("ParseSourceTo: %v", err)
+	}
+	if len(se.Lines) != 2 || se.Lines[0].LineNumber != 1 || se.Lines[1].LineNumber != 3 {
+		t.Fatalf("Lines = %+v, want lines 1 and 3", se.Lines)
+	}
+	if len(se.Errors) != 1 || se.Errors[0].Line != 2 || se.Errors[0].Text != "li r1, zz" {
+		t.Fatalf("Errors = %+v, want line 2", se.Errors)
+	}
+}
+
+func TestJSONLinesEmitter(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	var sb strings.Builder
+	if err := p.ParseSourceTo(strings.NewReader(emitterSource), NewJSONLinesEmitter(&sb)); err != nil {
+		t.Fatalf("ParseSourceTo: %v", err)
+	}
+	rows := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(rows) != 3 {
+		t.Fatalf("got %d JSON lines, want 3:\n%s", len(rows), sb.String())
+	}
+	var first ParsedLine
+	if err := json.Unmarshal([]byte(rows[0]), &first); err != nil {
+		t.Fatalf("line 1: %v", err)
+	}
+	if first.LineNumber != 1 || first.Mnemonic != "li" {
+		t.Errorf("line 1 = %+v", first)
+	}
+	var failed struct {
+		Error ParseError `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(rows[1]), &failed); err != nil {
+		t.Fatalf("line 2: %v", err)
+	}
+	if failed.Error.Line != 2 || failed.Error.Message == "" {
+		t.Errorf("line 2 error = %+v", failed.Error)
+	}
+}
+
+func TestListingEmitter(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	var sb strings.Builder
+	if err := p.ParseSourceTo(strings.NewReader(emitterSource), NewListingEmitter(&sb)); err != nil {
+		t.Fatalf("ParseSourceTo: %v", err)
+	}
+	want := "    1  0000  li r1, 5\n" +
+		"    2  ****  li r1, zz\n" +
+		"       error: Expected type (5)Uint8 but got type (0)Identifier: \n" +
+		"    3  0002  le r1, 2 + 3\n"
+	if sb.String() != want {
+		t.Errorf("listing =\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+func TestListingEmitterFileHeaders(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	p.SetFileResolver(FSResolver{includeFS})
+	var sb strings.Builder
+	if err := p.ParseSourceTo(strings.NewReader("start: nop\ninclude \"lib.s\"\nnop\n"), NewListingEmitter(&sb)); err != nil {
+		t.Fatalf("ParseSourceTo: %v", err)
+	}
+	want := "    1  0000  start: nop\n" +
+		"lib.s:\n" +
+		"    1  0001  nop\n" +
+		"sub/inner.s:\n" +
+		"    1  0002  jmp start\n" +
+		"    2  ****  bogus\n" +
+		"       error: Unknown mnemonic bogus\n" +
+		":\n" +
+		"    3  0004  nop\n"
+	if sb.String() != want {
+		t.Errorf("listing =\n%s\nwant\n%s", sb.String(), want)
+	}
+}
+
+// failingWriter
+// fails every write.
+type failingWriter struct{}
+
+// Write
+// returns errWrite.
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+var errWrite = errors.New("disk full")
+
+func TestEmittersReturnWriteErrors(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	emitters := map[string]Emitter{
+		"json":    NewJSONLinesEmitter(failingWriter{}),
+		"listing": NewListingEmitter(failingWriter{}),
+	}
+	for name, emit := range emitters {
+		if err := p.ParseSourceTo(strings.NewReader(emitterSource), emit); !errors.Is(err, errWrite) {
+			t.Errorf("%s: ParseSourceTo = %v, want %v", name, err, errWrite)
+		}
+	}
+}
+
+// stopEmitter
+// fails OnLine after a number of lines and counts the calls it receives.
+type stopEmitter struct {
+	SliceEmitter
+	limit    int
+	finished int
+}
+
+// OnLine
+// keeps a line, failing once limit lines are kept.
+func (se *stopEmitter) OnLine(pl ParsedLine) error {
+	if len(se.Lines) == se.limit {
+		return errWrite
+	}
+	return se.SliceEmitter.OnLine(pl)
+}
+
+// Finish
+// counts the call.
+func (se *stopEmitter) Finish() error {
+	se.finished++
+	return nil
+}
+
+func TestEmitStopsAtOnLineError(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	result := parseTestSource(t, p, "li r1, 1\nli r1, 2\nli r1, 3\n")
+	emit := &stopEmitter{limit: 1}
+	if err := result.Emit(emit); !errors.Is(err, errWrite) {
+		t.Fatalf("Emit = %v, want %v", err, errWrite)
+	}
+	if len(emit.Lines) != 1 || emit.finished != 1 {
+		t.Errorf("Emit kept %d lines and called Finish %d times, want 1 and 1", len(emit.Lines), emit.finished)
+	}
+}
+
+func TestEmitFlushesOnlyInFinish(t *testing.T) {
+	var sb strings.Builder
+	je := NewJSONLinesEmitter(&sb)
+	if err := je.OnLine(ParsedLine{LineNumber: 1}); err != nil {
+		t.Fatalf("OnLine: %v", err)
+	}
+	if sb.Len() != 0 {
+		t.Errorf("output before Finish = %q, want it buffered", sb.String())
+	}
+	if err := je.Finish(); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if !strings.HasPrefix(sb.String(), `{"line":1`) {
+		t.Errorf("output after Finish = %q", sb.String())
+	}
+}
@@ -0,0 +1,188 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BitField
+// is a field of an encoded instruction word: Width bits starting at bit Offset, counting
+// from the least significant bit. The field holds the value of template slot Slot, or
+// Value when Slot is 0, the mnemonic slot, as for opcode bits. Signed fields take negative
+// values, such as relative branch distances, in two's complement.
+type BitField struct {
+	Slot   int
+	Offset int
+	Width  int
+	Value  uint64
+	Signed bool
+}
+
+// Encoding
+// is the bit layout of the instruction word of a template entry. Width is the size of the
+// word in bits, at most 64; when 0 it is the entry's Size in bytes. EncodeBytes writes the
+// word least significant byte first unless BigEndian is set.
+type Encoding struct {
+	Width     int
+	Fields    []BitField
+	BigEndian bool
+}
+
+// maxEncodingWidth is the widest instruction word an Encoding can describe.
+const maxEncodingWidth = 64
+
+// encodingWidth
+// returns the width in bits of an entry's instruction word.
+func (entry *TemplateEntry) encodingWidth() int {
+	if entry.Encoding.Width != 0 {
+		return entry.Encoding.Width
+	}
+	return int(entry.Size) * 8
+}
+
+// validateEncoding
+// checks that the fields of an entry's encoding refer to its slots, fit in the instruction
+// word and do not overlap.
+func validateEncoding(entry *TemplateEntry) (bool, string) {
+	if entry.Encoding == nil {
+		return true, ""
+	}
+	width := entry.encodingWidth()
+	if width <= 0 || width > maxEncodingWidth {
+		return false, fmt.Sprintf("Template %s encoding width %d is not between 1 and %d bits",
+			entry.Name, width, maxEncodingWidth)
+	}
+	fields := append([]BitField(nil), entry.Encoding.Fields...)
+	sort.SliceStable(fields, func(a, b int) bool { return fields[a].Offset < fields[b].Offset })
+	end := 0
+	for _, field := range fields {
+		switch {
+		case field.Slot < 0 || field.Slot >= len(entry.Objects):
+			return false, fmt.Sprintf("Template %s encoding field at bit %d refers to slot %d, which does not exist",
+				entry.Name, field.Offset, field.Slot)
+		case field.Width <= 0 || field.Offset < 0 || field.Offset+field.Width > width:
+			return false, fmt.Sprintf("Template %s encoding field at bit %d, %d bits wide, does not fit in %d bits",
+				entry.Name, field.Offset, field.Width, width)
+		case field.Offset < end:
+			return false, fmt.Sprintf("Template %s encoding field at bit %d overlaps the field before it",
+				entry.Name, field.Offset)
+		case field.Slot == 0 && !fitsField(field.Value, field.Width):
+			return false, fmt.Sprintf("Template %s encoding value %#x does not fit in %d bits",
+				entry.Name, field.Value, field.Width)
+		}
+		end = field.Offset + field.Width
+	}
+	return true, ""
+}
+
+// fitsField
+// reports whether an unsigned value fits in a field of the given width.
+func fitsField(val uint64, width int) bool {
+	return width >= 64 || val>>uint(width) == 0
+}
+
+// fieldValue
+// returns the bits an object contributes to a field, checking that its value fits.
+func fieldValue(obj ObjectType, field BitField) (uint64, bool, string) {
+	magnitude := field.Width
+	if field.Signed {
+		magnitude--
+	}
+	var bits uint64
+	switch val := obj.ObjectValue.(type) {
+	case uint64:
+		bits = val
+	case int64:
+		if val < 0 && !field.Signed {
+			return 0, false, fmt.Sprintf("Operand %d value %d is negative", field.Slot, val)
+		}
+		if val < 0 {
+			if !fitsField(uint64(-(val + 1)), magnitude) {
+				return 0, false, fmt.Sprintf("Operand %d value %d does not fit in %d bits", field.Slot, val, field.Width)
+			}
+			return uint64(val) & fieldMask(field.Width), true, ""
+		}
+		bits = uint64(val)
+	default:
+		return 0, false, fmt.Sprintf("Operand %d has no numeric value", field.Slot)
+	}
+	if !fitsField(bits, magnitude) {
+		return 0, false, fmt.Sprintf("Operand %d value %#x does not fit in %d bits", field.Slot, bits, field.Width)
+	}
+	return bits, true, ""
+}
+
+// fieldMask
+// returns the mask of the low width bits.
+func fieldMask(width int) uint64 {
+	if width >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(width) - 1
+}
+
+// encodingEntry
+// returns the entry a parsed line matched, or the entry registered under its mnemonic for
+// lines built by hand.
+func (p *Parser) encodingEntry(pl ParsedLine) (*TemplateEntry, bool, string) {
+	entry := pl.entry
+	if entry == nil {
+		var found bool
+		if entry, found = p.Lookup(pl.Mnemonic); !found {
+			return nil, false, fmt.Sprintf("Unknown mnemonic %s", pl.Mnemonic)
+		}
+	}
+	if entry.Encoding == nil {
+		return nil, false, fmt.Sprintf("Template %s has no encoding", entry.Name)
+	}
+	return entry, true, ""
+}
+
+// Encode
+// builds the instruction word of a successfully parsed line from the Encoding of the
+// template entry it matched. Label operands must have been resolved, as ParseSource does,
+// and every operand value must fit in its field.
+func (p *Parser) Encode(pl ParsedLine) (uint64, bool, string) {
+	entry, ok, errmsg := p.encodingEntry(pl)
+	if !ok {
+		return 0, false, errmsg
+	}
+	var word uint64
+	for _, field := range entry.Encoding.Fields {
+		bits := field.Value
+		if field.Slot > 0 {
+			if field.Slot >= len(pl.Objects) {
+				return 0, false, fmt.Sprintf("Operand %d is missing", field.Slot)
+			}
+			if bits, ok, errmsg = fieldValue(pl.Objects[field.Slot], field); !ok {
+				return 0, false, errmsg
+			}
+		}
+		word |= bits << uint(field.Offset)
+	}
+	return word, true, ""
+}
+
+// EncodeBytes
+// encodes a parsed line with Encode and returns the instruction word as bytes, in the byte
+// order of the entry's encoding. The word must be a whole number of bytes wide.
+func (p *Parser) EncodeBytes(pl ParsedLine) ([]byte, bool, string) {
+	word, ok, errmsg := p.Encode(pl)
+	if !ok {
+		return nil, false, errmsg
+	}
+	entry, _, _ := p.encodingEntry(pl)
+	width := entry.encodingWidth()
+	if width%8 != 0 {
+		return nil, false, fmt.Sprintf("Template %s encoding width %d is not a whole number of bytes", entry.Name, width)
+	}
+	data := make([]byte, width/8)
+	for idx := range data {
+		shift := uint(8 * idx)
+		if entry.Encoding.BigEndian {
+			shift = uint(8 * (len(data) - 1 - idx))
+		}
+		data[idx] = byte(word >> shift)
+	}
+	return data, true, ""
+}
@@ -0,0 +1,106 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// encodingGrammar has a big-endian load, a little-endian branch with a signed offset and
+// an instruction without an encoding.
+const encodingGrammar = `templates:
+  - mnemonic: ldi
+    size: 2
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+    encoding:
+      big_endian: true
+      fields:
+        - {offset: 12, width: 4, value: 1}
+        - {slot: 1, offset: 8, width: 4}
+        - {slot: 3, offset: 0, width: 8}
+  - mnemonic: br
+    size: 2
+    operands:
+      - {type: Identifier}
+      - {type: LabelRel}
+    encoding:
+      fields:
+        - {offset: 8, width: 8, value: 0x20}
+        - {slot: 1, offset: 0, width: 8, signed: true}
+  - mnemonic: nop
+    size: 1
+    operands:
+      - {type: Identifier}
+`
+
+func TestEncode(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	source := "back: ldi r3, 7f\nbr back\nbr next\nldi r10, 1\nnext: nop\n"
+	result := parseTestSource(t, p, source)
+	tests := []struct {
+		word  uint64
+		bytes []byte
+		err   string
+	}{
+		{0x137f, []byte{0x13, 0x7f}, ""},
+		{0x20fe, []byte{0xfe, 0x20}, ""},
+		{0x2004, []byte{0x04, 0x20}, ""},
+		{0, nil, "Operand 1 value 0x10 does not fit in 4 bits"},
+		{0, nil, "Template nop has no encoding"},
+	}
+	for idx, tt := range tests {
+		lr := result.Lines[idx]
+		if !lr.Ok {
+			t.Fatalf("%q failed: %s", lr.RawText, lr.Error)
+		}
+		word, ok, errmsg := p.Encode(lr.ParsedLine)
+		if word != tt.word || ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("Encode(%q) = %#x, %v, %q, want %#x, %q", lr.RawText, word, ok, errmsg, tt.word, tt.err)
+		}
+		if data, _, _ := p.EncodeBytes(lr.ParsedLine); !bytes.Equal(data, tt.bytes) {
+			t.Errorf("EncodeBytes(%q) = % x, want % x", lr.RawText, data, tt.bytes)
+		}
+	}
+}
+
+func TestEncodeSignedFieldRange(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	for _, tt := range []struct {
+		distance int64
+		err      string
+	}{
+		{-128, ""},
+		{127, ""},
+		{-129, "Operand 1 value -129 does not fit in 8 bits"},
+		{128, "Operand 1 value 0x80 does not fit in 8 bits"},
+	} {
+		pl := ParsedLine{Mnemonic: "br", Objects: []ObjectType{StringObject("br", ""),
+			{ObjectTypeId: TokenLabelRel, ObjectValue: tt.distance}}}
+		if _, ok, errmsg := p.Encode(pl); ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("Encode of a branch by %d = %v, %q, want %q", tt.distance, ok, errmsg, tt.err)
+		}
+	}
+}
+
+func TestEncodingIsValidated(t *testing.T) {
+	for _, tt := range []struct {
+		fields string
+		err    string
+	}{
+		{"{slot: 2, offset: 0, width: 4}", "refers to slot 2, which does not exist"},
+		{"{slot: 1, offset: 6, width: 4}", "does not fit in 8 bits"},
+		{"{offset: 0, width: 4, value: 1}, {slot: 1, offset: 2, width: 4}", "overlaps the field before it"},
+		{"{offset: 0, width: 2, value: 4}", "value 0x4 does not fit in 2 bits"},
+	} {
+		grammar := "templates:\n  - mnemonic: inc\n    size: 1\n    operands:\n      - {type: Identifier}\n" +
+			"      - {type: Register}\n    encoding:\n      fields: [" + tt.fields + "]\n"
+		err := NewParser(DefaultParserConfig()).LoadTemplatesFromYAML(strings.NewReader(grammar))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("fields %s: error %v, want %q", tt.fields, err, tt.err)
+		}
+	}
+}
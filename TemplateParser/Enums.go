@@ -0,0 +1,131 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateChoices
+// checks the Choices of a template list: every Enum slot lists at least one keyword, none
+// of them empty or repeated ignoring case, and no other slot lists any.
+func ValidateChoices(templateList []TemplateObject) (bool, string) {
+	for idx, tmpl := range templateList {
+		if tmpl.TemplateType != TokenEnum {
+			if len(tmpl.Choices) > 0 {
+				return false, fmt.Sprintf("Slot %d lists choices but is not an Enum slot", idx)
+			}
+			continue
+		}
+		if len(tmpl.Choices) == 0 {
+			return false, fmt.Sprintf("Enum slot %d has no choices", idx)
+		}
+		for cIdx, choice := range tmpl.Choices {
+			if choice == "" {
+				return false, fmt.Sprintf("Enum slot %d has an empty choice", idx)
+			}
+			if earlier := choiceIndex(tmpl.Choices[:cIdx], choice); earlier >= 0 {
+				return false, fmt.Sprintf("Enum slot %d lists %s twice", idx, choice)
+			}
+		}
+	}
+	return true, ""
+}
+
+// checkChoiceTokens
+// checks that the parser reads every choice of the Enum slots of a template list as a
+// single identifier or boolean keyword, so that lines can hold it: one letter is not an
+// identifier and words such as 12 are hex literals. The caller holds the parser's lock.
+func (p *Parser) checkChoiceTokens(templateList []TemplateObject) (bool, string) {
+	for idx, tmpl := range templateList {
+		for _, choice := range tmpl.Choices {
+			var tokens []Token
+			for _, token := range p.lexer.scan(choice) {
+				if !isBlankToken(token) {
+					tokens = append(tokens, token)
+				}
+			}
+			if len(tokens) != 1 || (tokens[0].Type != TokenIdentifier && tokens[0].Type != TokenBoolean) {
+				return false, fmt.Sprintf("Choice %s of enum slot %d is not an identifier", choice, idx)
+			}
+		}
+	}
+	return true, ""
+}
+
+// choiceIndex
+// returns the index of a word among the choices of an Enum slot, ignoring case, or -1.
+func choiceIndex(choices []string, word string) int {
+	for idx, choice := range choices {
+		if strings.EqualFold(choice, word) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// identifierToChoice
+// turns the identifier filling an Enum slot into the index of the choice it names, keeping
+// the choice as the object's descriptor. Anything else, including identifiers that are not
+// among the choices, fails with an error listing them.
+func identifierToChoice(obj *ObjectType, tmpl TemplateObject, config ParserConfig) (bool, string) {
+	if tmpl.TemplateType != TokenEnum {
+		return true, ""
+	}
+	word, isWord := obj.ObjectValue.(string)
+	switch obj.ObjectTypeId {
+	case TokenIdentifier:
+	case TokenBoolean:
+		word, isWord = obj.ObjectDescriptor, true
+	default:
+		isWord = false
+	}
+	if isWord {
+		if idx := choiceIndex(tmpl.Choices, word); idx >= 0 {
+			obj.ObjectTypeId, obj.ObjectValue, obj.ObjectDescriptor = TokenEnum, uint64(idx), tmpl.Choices[idx]
+			return true, ""
+		}
+	}
+	got := word
+	switch {
+	case obj.ObjectSensitive:
+		got = RedactedValue
+	case !isWord:
+		got = fmt.Sprintf("type (%d)%s", obj.ObjectTypeId, config.tokenName(obj.ObjectTypeId))
+	}
+	return false, fmt.Sprintf("Expected one of %s but got %s: %s", strings.Join(tmpl.Choices, ", "), got, tmpl.TemplateError)
+}
+
+// sameChoices
+// reports whether two slots list the same choices, ignoring case and order.
+func sameChoices(a TemplateObject, b TemplateObject) bool {
+	return choicesCover(a, b) && choicesCover(b, a)
+}
+
+// choicesCover
+// reports whether every choice of slot b is a choice of slot a.
+func choicesCover(a TemplateObject, b TemplateObject) bool {
+	for _, choice := range b.Choices {
+		if choiceIndex(a.Choices, choice) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// choicesOverlap
+// reports whether an Enum slot can take the same word as another slot: any identifier
+// for a slot taking names, one of its choices for a keyword or another Enum slot.
+func choicesOverlap(enum TemplateObject, other TemplateObject) bool {
+	if other.TemplateType == TokenEnum {
+		for _, choice := range other.Choices {
+			if choiceIndex(enum.Choices, choice) >= 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if word := slotWord(other); word != "" {
+		return choiceIndex(enum.Choices, word) >= 0
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+// enumTemplate is a conditional branch whose condition is one of four keywords.
+var enumTemplate = []TemplateObject{
+	{TemplateType: TokenIdentifier},
+	{TemplateType: TokenEnum, Choices: []string{"eq", "ne", "lt", "gt"}, TemplateError: "bad condition"},
+	{TemplateType: TokenComma},
+	{TemplateType: TokenUint16},
+}
+
+func TestEnumSlot(t *testing.T) {
+	tests := []struct {
+		line  string
+		index uint64
+		word  string
+		err   string
+	}{
+		{"bra ne, 0800", 1, "ne", ""},
+		{"bra GT, 0800", 3, "gt", ""},
+		{"bra le, 0800", 0, "", "Expected one of eq, ne, lt, gt but got le: bad condition"},
+		{"bra 10, 0800", 0, "", "Expected one of eq, ne, lt, gt but got type (5)Uint8: bad condition"},
+	}
+	for _, tt := range tests {
+		objs, ok, errmsg := ParseLine(tt.line, enumTemplate)
+		if tt.err != "" {
+			if ok || errmsg != tt.err {
+				t.Errorf("ParseLine(%q) = %v, %q, want %q", tt.line, ok, errmsg, tt.err)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("ParseLine(%q) failed: %s", tt.line, errmsg)
+			continue
+		}
+		if obj := objs[1]; obj.ObjectTypeId != TokenEnum || obj.ObjectValue != tt.index || obj.ObjectDescriptor != tt.word {
+			t.Errorf("ParseLine(%q) enum = %+v, want index %d of %s", tt.line, obj, tt.index, tt.word)
+		}
+	}
+}
+
+func TestEnumBooleanChoice(t *testing.T) {
+	tmpl := []TemplateObject{{TemplateType: TokenIdentifier}, {TemplateType: TokenEnum, Choices: []string{"false", "true", "auto"}}}
+	for line, want := range map[string]uint64{"set true": 1, "set auto": 2} {
+		objs, ok, errmsg := ParseLine(line, tmpl)
+		if !ok || objs[1].ObjectValue != want {
+			t.Errorf("ParseLine(%q) = %v, %v, %q, want choice %d", line, objs, ok, errmsg, want)
+		}
+	}
+}
+
+func TestValidateChoices(t *testing.T) {
+	tests := []struct {
+		slot TemplateObject
+		err  string
+	}{
+		{TemplateObject{TemplateType: TokenEnum, Choices: []string{"eq", "ne"}}, ""},
+		{TemplateObject{TemplateType: TokenEnum}, "Enum slot 1 has no choices"},
+		{TemplateObject{TemplateType: TokenEnum, Choices: []string{"eq", ""}}, "Enum slot 1 has an empty choice"},
+		{TemplateObject{TemplateType: TokenEnum, Choices: []string{"eq", "EQ"}}, "Enum slot 1 lists EQ twice"},
+		{TemplateObject{TemplateType: TokenRegister, Choices: []string{"eq"}}, "Slot 1 lists choices but is not an Enum slot"},
+	}
+	for _, tt := range tests {
+		ok, errmsg := ValidateChoices([]TemplateObject{{TemplateType: TokenIdentifier}, tt.slot})
+		if ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("ValidateChoices(%v) = %v, %q, want %q", tt.slot.Choices, ok, errmsg, tt.err)
+		}
+	}
+}
+
+func TestEnumChoicesMustBeIdentifiers(t *testing.T) {
+	p := NewParser(DefaultParserConfig())
+	for _, choice := range []string{"b", "r1", "12"} {
+		tmpl := []TemplateObject{{TemplateType: TokenIdentifier}, {TemplateType: TokenEnum, Choices: []string{"eq", choice}}}
+		if ok, errmsg := p.RegisterTemplate("bra", tmpl); ok || !strings.Contains(errmsg, "Choice "+choice+" of enum slot 1 is not an identifier") {
+			t.Errorf("RegisterTemplate with choice %s = %v, %q", choice, ok, errmsg)
+		}
+	}
+}
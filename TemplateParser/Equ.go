@@ -0,0 +1,120 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EquDirective is the name of the directive that defines a constant: NAME equ <expression>
+const EquDirective = "equ"
+
+// isEquDirective
+// reports whether a tokenized line is a NAME equ <expression> definition, matching equ as
+// mnemonics are matched.
+func (p *Parser) isEquDirective(tokens []Token) bool {
+	var buf [3]Token
+	content := leadingContent(tokens, buf[:])
+	return len(content) >= 3 && content[0].Type == TokenIdentifier &&
+		content[1].Type == TokenIdentifier && p.mnemonicKey(content[1].ValueReceived) == EquDirective
+}
+
+// definesConstant
+// reports whether a source line, or a line of its macro expansion, is an equ definition.
+func (p *Parser) definesConstant(line string) bool {
+	lines, _, ok, _ := expandLine(p.Macros(), line)
+	for _, expanded := range lines {
+		if _, tokens := SplitLabel(p.filterTokens(p.TokenizeLine(expanded.text))); ok && p.isEquDirective(tokens) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEqu
+// evaluates the expression of an equ line with the constants defined so far and defines
+// the constant in the table of the parsing call. Redefining a constant with a different
+// value is an error. The result holds the name and the value as its objects.
+func (p *Parser) parseEqu(lineNo int, tokens []Token, constants *constantTable) LineResult {
+	result := LineResult{ParsedLine: ParsedLine{LineNumber: lineNo, Directive: EquDirective}}
+	content := contentTokens(tokens)
+	name := content[0].ValueReceived
+	var sb, source strings.Builder
+	seen := 0
+	for _, token := range tokens {
+		if seen >= 2 {
+			sb.WriteString(token.ValueReceived)
+			source.WriteString(token.text())
+		} else if !isBlankToken(token) {
+			seen++
+		}
+	}
+	text := strings.TrimSpace(sb.String())
+	expr, err := p.compileOperandExpr(text)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid expression %s: %v", text, err)
+		return result
+	}
+	val, err := expr.root.eval(constantLookup(constants, nil))
+	if err != nil {
+		result.Error = fmt.Sprintf("Cannot evaluate %s: %v", text, err)
+		return result
+	}
+	num, isInt := val.(uint64)
+	if !isInt {
+		result.Error = fmt.Sprintf("Expression %s is not an integer", text)
+		return result
+	}
+	key := p.identifierKey(name)
+	if prev, found := constants.lookup(key); found && prev != num {
+		result.Error = fmt.Sprintf("Constant %s already defined as %#x", name, prev)
+		return result
+	}
+	constants.define(key, num)
+	result.Objects = []ObjectType{newObject(TokenIdentifier, name, ""), newObject(TokenUint64, num, text)}
+	result.Objects[0].ObjectText = content[0].text()
+	result.Objects[1].ObjectText = strings.TrimSpace(source.String())
+	result.Ok = true
+	return result
+}
+
+// maxDigits gives the most hex digits each sized integer token holds.
+var maxDigits = map[int]int{TokenUint8: 2, TokenUint16: 4, TokenUint32: 8, TokenUint64: 16}
+
+// substituteConstants
+// replaces identifiers naming a constant with integer tokens where the matching template
+// slot expects an integer. The token takes the slot's type if the value fits it, and the
+// type of its own size otherwise, so that oversized values are still reported.
+func (p *Parser) substituteConstants(tokens []Token, templateList []TemplateObject, constants *constantTable) []Token {
+	if constants.empty() {
+		return tokens
+	}
+	var substituted []Token
+	slot := 0
+	for idx, token := range tokens {
+		if token.Type == TokenUnknown {
+			continue
+		}
+		if slot < len(templateList) && token.Type == TokenIdentifier {
+			tt := templateList[slot].TemplateType
+			if val, found := constants.lookup(p.identifierKey(token.ValueReceived)); found && (isIntegerToken(tt) || isWideTemplate(tt)) {
+				if substituted == nil {
+					substituted = append([]Token(nil), tokens...)
+				}
+				digits := p.config.numberText(val, TokenUint8)
+				natural, _ := p.lexer.numberToken(len(digits))
+				if limit, sized := maxDigits[tt]; isWideTemplate(tt) || (sized && len(digits) <= limit) {
+					natural = tt
+					if isWideTemplate(tt) {
+						natural = TokenUint64
+					}
+				}
+				substituted[idx] = Token{Type: natural, ValueReceived: digits, raw: token.text()}
+			}
+		}
+		slot++
+	}
+	if substituted == nil {
+		return tokens
+	}
+	return substituted
+}
@@ -0,0 +1,115 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEquConstantsBelongToTheirSource(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	first := parseTestSource(t, p, "FOO equ 10\nli r1, FOO\n")
+	if got := lineValue(t, first, 1); got != uint64(0x10) {
+		t.Errorf("first source: FOO = %v, want 0x10", got)
+	}
+	second := parseTestSource(t, p, "FOO equ 20\nli r1, FOO\n")
+	if !second.Lines[0].Ok {
+		t.Fatalf("redefining FOO in another source failed: %s", second.Lines[0].Error)
+	}
+	if got := lineValue(t, second, 1); got != uint64(0x20) {
+		t.Errorf("second source: FOO = %v, want 0x20", got)
+	}
+	third := parseTestSource(t, p, "li r1, FOO\n")
+	if third.Lines[0].Ok {
+		t.Errorf("FOO resolved in a source not defining it: %v", third.Lines[0].Objects)
+	}
+	if _, found := p.Constant("FOO"); found {
+		t.Errorf("equ constant FOO was defined on the parser")
+	}
+	if got := second.Constants["foo"]; got != 0x20 {
+		t.Errorf("second.Constants = %v, want foo: 0x20", second.Constants)
+	}
+}
+
+func TestEquRedefinitionWithinSource(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	result := parseTestSource(t, p, "FOO equ 10\nFOO equ 10\nFOO equ 20\n")
+	if !result.Lines[1].Ok {
+		t.Errorf("redefining FOO with the same value failed: %s", result.Lines[1].Error)
+	}
+	if result.Lines[2].Ok || !strings.Contains(result.Lines[2].Error, "already defined") {
+		t.Errorf("redefining FOO with another value: ok=%v %q", result.Lines[2].Ok, result.Lines[2].Error)
+	}
+}
+
+func TestEquDefineConstantIsShared(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.DefineConstant("BASE", 0x30)
+	for _, source := range []string{"li r1, BASE\n", "le r1, BASE+1\n"} {
+		result := parseTestSource(t, p, source)
+		if !result.Lines[0].Ok {
+			t.Errorf("%q: %s", source, result.Lines[0].Error)
+		}
+	}
+	result := parseTestSource(t, p, "BASE equ 31\n")
+	if result.Lines[0].Ok {
+		t.Errorf("equ redefined the parser's constant BASE with another value")
+	}
+}
+
+func TestEquConstantsFollowTheContext(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	ctx := &ParseContext{}
+	if _, err := p.ParseSourceContext(ctx, strings.NewReader("FOO equ 10\n")); err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.ParseSourceContext(ctx, strings.NewReader("li r1, FOO\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lineValue(t, result, 0); got != uint64(0x10) {
+		t.Errorf("FOO = %v in the context's next source, want 0x10", got)
+	}
+	if _, ok, _ := p.ParseLineContext(ctx, "li r1, FOO"); !ok {
+		t.Errorf("FOO not resolved by ParseLineContext with the context")
+	}
+	if _, ok, _ := p.ParseLineContext(nil, "li r1, FOO"); ok {
+		t.Errorf("FOO resolved by ParseLineContext without a context")
+	}
+}
+
+func TestEquLineCacheKeepsSourcesApart(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.SetLineCache(64)
+	for _, tt := range []struct {
+		source string
+		want   uint64
+	}{
+		{"FOO equ 10\nli r1, FOO\n", 0x10},
+		{"FOO equ 20\nli r1, FOO\n", 0x20},
+	} {
+		if got := lineValue(t, parseTestSource(t, p, tt.source), 1); got != tt.want {
+			t.Errorf("%q: FOO = %v, want %#x", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestEquDirectiveCase(t *testing.T) {
+	preserve := DefaultParserConfig()
+	preserve.PreserveCase = true
+	for _, tt := range []struct {
+		name   string
+		config ParserConfig
+		line   string
+		equ    bool
+	}{
+		{"default", DefaultParserConfig(), "FOO EQU 10", true},
+		{"preserve case", preserve, "FOO equ 10", true},
+		{"preserve case", preserve, "FOO EQU 10", false},
+	} {
+		p := newTestParser(t, tt.config, exprGrammar)
+		result := parseTestSource(t, p, tt.line+"\n")
+		if _, equ := result.Constants[p.identifierKey("FOO")]; equ != tt.equ {
+			t.Errorf("%s: %q defines FOO = %v, want %v (%s)", tt.name, tt.line, equ, tt.equ, result.Lines[0].Error)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+)
+
+// ParseError
+// is a failure on one line of a source, with its position. Column is 1-based and counts
+// bytes of the line after macro expansion; it is 0 if the error concerns the whole line.
+// File and Includes locate lines read from included files, and Origin the macro
+// expansions that produced the token in error.
+type ParseError struct {
+	File     string        `json:"file,omitempty"`
+	Line     int           `json:"line"`
+	Column   int           `json:"column,omitempty"`
+	Text     string        `json:"text,omitempty"`
+	Message  string        `json:"message"`
+	Includes []IncludeSite `json:"includes,omitempty"`
+	Origin   []TokenOrigin `json:"origin,omitempty"`
+}
+
+// Error
+// formats the error as file:line:column: message, followed by the macro expansions and
+// the include chain, innermost first. The file and column are left out when unknown.
+func (pe ParseError) Error() string {
+	return pe.position() + ": " + pe.Message + pe.chain()
+}
+
+// position
+// formats the location of the error as file:line:column.
+func (pe ParseError) position() string {
+	pos := fmt.Sprintf("%d", pe.Line)
+	if pe.Column > 0 {
+		pos = fmt.Sprintf("%d:%d", pe.Line, pe.Column)
+	}
+	if pe.File != "" {
+		pos = pe.File + ":" + pos
+	}
+	return pos
+}
+
+// chain
+// formats the macro expansions and include directives leading to the error, one per
+// line, innermost first.
+func (pe ParseError) chain() string {
+	msg := ""
+	for idx := len(pe.Origin) - 1; idx >= 0; idx-- {
+		origin := pe.Origin[idx]
+		msg += fmt.Sprintf("\n\tin expansion of @%s at line %d:%d", origin.Macro, origin.Line, origin.Column)
+		if idx > 0 {
+			msg += " of @" + pe.Origin[idx-1].Macro
+		}
+		if origin.DefinedFile != "" {
+			msg += fmt.Sprintf(", defined at %s:%d", origin.DefinedFile, origin.DefinedLine)
+		}
+	}
+	for idx := len(pe.Includes) - 1; idx >= 0; idx-- {
+		site := pe.Includes[idx]
+		if site.File == "" {
+			msg += fmt.Sprintf("\n\tincluded from line %d", site.Line)
+		} else {
+			msg += fmt.Sprintf("\n\tincluded from %s:%d", site.File, site.Line)
+		}
+	}
+	return msg
+}
+
+// Errors
+// returns an error for every failed line, in source order.
+func (sr *SourceResult) Errors() []ParseError {
+	errors := make([]ParseError, 0)
+	for _, line := range sr.Lines {
+		if !line.Ok {
+			errors = append(errors, line.parseError())
+		}
+	}
+	return errors
+}
+
+// parseError
+// returns the error of a failed line.
+func (lr LineResult) parseError() ParseError {
+	return ParseError{lr.File, lr.LineNumber, lr.ErrorColumn, lr.RawText, lr.Error, lr.Includes, lr.Origin}
+}
+
+// Check
+// parses a whole source with ParseSource and returns every error found in it, rather than
+// only the first, so editors and build tools can report all problems at once. The returned
+// error only reports failures reading r.
+func (p *Parser) Check(r io.Reader) ([]ParseError, error) {
+	result, err := p.ParseSource(r)
+	if result == nil {
+		return nil, err
+	}
+	return result.Errors(), err
+}
+
+// tokenOffset
+// returns the byte offset of token idx within a token list.
+func tokenOffset(tokens []Token, idx int) int {
+	offset := 0
+	for _, token := range tokens[:idx] {
+		offset += len(token.ValueReceived)
+	}
+	return offset
+}
+
+// objectColumn
+// returns the 1-based column of the token that produced object objIdx of a line. tokens
+// is the tail of allTokens that was matched; for indices past the last object the column
+// just after the last token is returned, and for negative indices 0.
+func objectColumn(allTokens []Token, tokens []Token, objIdx int) int {
+	if objIdx < 0 {
+		return 0
+	}
+	offset := 0
+	for _, token := range allTokens[:len(allTokens)-len(tokens)] {
+		offset += len(token.ValueReceived)
+	}
+	count, end := 0, offset
+	for _, token := range tokens {
+		if token.Type != TokenUnknown {
+			if count == objIdx {
+				return offset + 1
+			}
+			count++
+		}
+		offset += len(token.ValueReceived)
+		if !isBlankToken(token) {
+			end = offset
+		}
+	}
+	return end + 1
+}
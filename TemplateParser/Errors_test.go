@@ -0,0 +1,44 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCollectsEveryError(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	errs, err := p.Check(strings.NewReader("li r1, 5\nli r1, r2\nli r1, 7\nbogus\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Check found %d errors, want 2: %v", len(errs), errs)
+	}
+	for idx, want := range []struct {
+		line   int
+		column int
+		text   string
+	}{{2, 8, "li r1, r2"}, {4, 1, "bogus"}} {
+		if pe := errs[idx]; pe.Line != want.line || pe.Column != want.column || pe.Text != want.text || pe.Message == "" {
+			t.Errorf("error %d = %+v, want line %d, column %d, text %q", idx, pe, want.line, want.column, want.text)
+		}
+	}
+}
+
+func TestParseErrorFormat(t *testing.T) {
+	pe := ParseError{File: "lib.asm", Line: 3, Column: 5, Message: "bad operand",
+		Includes: []IncludeSite{{File: "main.asm", Line: 1}, {File: "mid.asm", Line: 2}},
+		Origin: []TokenOrigin{{Macro: "outer", Line: 3, Column: 1},
+			{Macro: "inner", Line: 1, Column: 4, DefinedFile: "macros.asm", DefinedLine: 9}}}
+	want := "lib.asm:3:5: bad operand" +
+		"\n\tin expansion of @inner at line 1:4 of @outer, defined at macros.asm:9" +
+		"\n\tin expansion of @outer at line 3:1" +
+		"\n\tincluded from mid.asm:2" +
+		"\n\tincluded from main.asm:1"
+	if got := pe.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got := (ParseError{Line: 7, Message: "no template"}).Error(); got != "7: no template" {
+		t.Errorf("Error() without file and column = %q", got)
+	}
+}
@@ -0,0 +1,589 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expr
+// is a compiled guard expression. The language is deliberately small: integer, string and
+// boolean literals, slot references by name or as $N (the object at position N), the
+// operators || && ! == != < <= > >= + - * / % & | ^ << >> and parentheses, plus the
+// functions len(x), int(x) and str(x). Integers are unsigned 64-bit values and may be
+// written in decimal or with a 0x prefix.
+type Expr struct {
+	source string
+	root   exprNode
+}
+
+// exprNode
+// is a node of a compiled expression tree.
+type exprNode interface {
+	eval(env exprLookup) (interface{}, error)
+}
+
+// exprLookup
+// resolves a name used in an expression.
+type exprLookup func(name string) (interface{}, bool)
+
+type exprLiteral struct{ value interface{} }
+type exprRef struct{ name string }
+type exprUnary struct {
+	op      string
+	operand exprNode
+}
+type exprBinary struct {
+	op          string
+	left, right exprNode
+}
+type exprCall struct {
+	name string
+	args []exprNode
+}
+
+// CompileExpr
+// parses an expression so it can be evaluated repeatedly.
+func CompileExpr(source string) (*Expr, error) {
+	lex, err := lexExpr(source)
+	if err != nil {
+		return nil, err
+	}
+	return compileExprTokens(source, lex)
+}
+
+// compileExprTokens
+// parses already lexed expression tokens.
+func compileExprTokens(source string, lex []exprToken) (*Expr, error) {
+	ep := &exprParser{tokens: lex}
+	root, err := ep.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if ep.pos < len(ep.tokens) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", ep.tokens[ep.pos].text, ep.tokens[ep.pos].offset)
+	}
+	return &Expr{source, root}, nil
+}
+
+// String
+// returns the source text of the expression.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval
+// evaluates the expression. Names are looked up in env; values may be uint64, int, string,
+// bool or ObjectType (whose value is used). Errors show the values of sensitive objects,
+// and of everything computed from them, as RedactedValue.
+func (e *Expr) Eval(env map[string]interface{}) (interface{}, error) {
+	val, err := e.root.eval(func(name string) (interface{}, bool) {
+		val, found := env[name]
+		return val, found
+	})
+	val, _ = unwrapValue(val)
+	return val, err
+}
+
+// Names
+// returns the names the expression refers to, slot references such as $2 included, in
+// the order they first appear.
+func (e *Expr) Names() []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	var walk func(node exprNode)
+	walk = func(node exprNode) {
+		switch n := node.(type) {
+		case exprRef:
+			if !seen[n.name] {
+				seen[n.name] = true
+				names = append(names, n.name)
+			}
+		case exprUnary:
+			walk(n.operand)
+		case exprBinary:
+			walk(n.left)
+			walk(n.right)
+		case exprCall:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	return names
+}
+
+// EvalBool
+// evaluates the expression and requires a boolean result.
+func (e *Expr) EvalBool(env map[string]interface{}) (bool, error) {
+	val, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q is not boolean", e.source)
+	}
+	return b, nil
+}
+
+// normalizeValue
+// converts environment values into the types the evaluator works with.
+func normalizeValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case ObjectType:
+		return normalizeValue(v.ObjectValue)
+	case *ObjectType:
+		return normalizeValue(v.ObjectValue)
+	case int:
+		return uint64(v)
+	case int64:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	}
+	return val
+}
+
+// sensitiveValue
+// marks a value read from a sensitive object, or computed from one, so errors display it
+// as RedactedValue.
+type sensitiveValue struct{ value interface{} }
+
+// unwrapValue
+// returns a value without its sensitive mark, and whether it had one.
+func unwrapValue(val interface{}) (interface{}, bool) {
+	if marked, isMarked := val.(sensitiveValue); isMarked {
+		return marked.value, true
+	}
+	return val, false
+}
+
+// markValue
+// marks a value as sensitive if sensitive is true.
+func markValue(val interface{}, sensitive bool) interface{} {
+	if sensitive {
+		return sensitiveValue{val}
+	}
+	return val
+}
+
+// displayValue
+// formats a value for an error message, as DisplayValue formats objects.
+func displayValue(val interface{}, sensitive bool) string {
+	obj := ObjectType{ObjectValue: val, ObjectSensitive: sensitive}
+	return obj.DisplayValue()
+}
+
+// isSensitive
+// reports whether an environment value is a sensitive object.
+func isSensitive(val interface{}) bool {
+	switch v := val.(type) {
+	case ObjectType:
+		return v.ObjectSensitive
+	case *ObjectType:
+		return v != nil && v.ObjectSensitive
+	}
+	return false
+}
+
+func (n exprLiteral) eval(env exprLookup) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n exprRef) eval(env exprLookup) (interface{}, error) {
+	val, found := env(n.name)
+	if !found {
+		return nil, unknownNameError{n.name}
+	}
+	return markValue(normalizeValue(val), isSensitive(val)), nil
+}
+
+func (n exprUnary) eval(env exprLookup) (interface{}, error) {
+	marked, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	val, sensitive := unwrapValue(marked)
+	switch n.op {
+	case "!":
+		if b, ok := val.(bool); ok {
+			return markValue(!b, sensitive), nil
+		}
+	case "-":
+		if i, ok := val.(uint64); ok {
+			return markValue(-i, sensitive), nil
+		}
+	}
+	return nil, fmt.Errorf("operator %s does not apply to %s", n.op, displayValue(val, sensitive))
+}
+
+func (n exprBinary) eval(env exprLookup) (interface{}, error) {
+	marked, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	left, lsensitive := unwrapValue(marked)
+	// Short-circuit the logical operators
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator %s needs booleans", n.op)
+		}
+		if (n.op == "&&" && !lb) || (n.op == "||" && lb) {
+			return markValue(lb, lsensitive), nil
+		}
+		marked, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		right, rsensitive := unwrapValue(marked)
+		rb, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("operator %s needs booleans", n.op)
+		}
+		return markValue(rb, lsensitive || rsensitive), nil
+	}
+	if marked, err = n.right.eval(env); err != nil {
+		return nil, err
+	}
+	right, rsensitive := unwrapValue(marked)
+	val, err := binaryValue(n.op, left, right, rsensitive)
+	if err != nil {
+		return nil, err
+	}
+	return markValue(val, lsensitive || rsensitive), nil
+}
+
+// binaryValue
+// applies a binary operator other than && and || to unmarked operands; rsensitive tells
+// whether the right one is sensitive, for the errors that display it.
+func binaryValue(op string, left, right interface{}, rsensitive bool) (interface{}, error) {
+	switch op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+	if ls, ok := left.(string); ok {
+		rs, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string with %s", displayValue(right, rsensitive))
+		}
+		switch op {
+		case "+":
+			return ls + rs, nil
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		}
+		return nil, fmt.Errorf("operator %s does not apply to strings", op)
+	}
+	li, lok := left.(uint64)
+	ri, rok := right.(uint64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s needs integers", op)
+	}
+	switch op {
+	case "<":
+		return li < ri, nil
+	case "<=":
+		return li <= ri, nil
+	case ">":
+		return li > ri, nil
+	case ">=":
+		return li >= ri, nil
+	case "+":
+		return li + ri, nil
+	case "-":
+		return li - ri, nil
+	case "*":
+		return li * ri, nil
+	case "/", "%":
+		if ri == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if op == "/" {
+			return li / ri, nil
+		}
+		return li % ri, nil
+	case "&":
+		return li & ri, nil
+	case "|":
+		return li | ri, nil
+	case "^":
+		return li ^ ri, nil
+	case "<<":
+		return li << ri, nil
+	case ">>":
+		return li >> ri, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func (n exprCall) eval(env exprLookup) (interface{}, error) {
+	if len(n.args) != 1 {
+		return nil, fmt.Errorf("%s expects 1 argument", n.name)
+	}
+	marked, err := n.args[0].eval(env)
+	if err != nil {
+		return nil, err
+	}
+	val, sensitive := unwrapValue(marked)
+	result, err := callValue(n.name, val, sensitive)
+	if err != nil {
+		return nil, err
+	}
+	return markValue(result, sensitive), nil
+}
+
+// callValue
+// applies a function to an unmarked argument, displaying it redacted in errors if
+// sensitive is true.
+func callValue(name string, val interface{}, sensitive bool) (interface{}, error) {
+	switch name {
+	case "len":
+		if s, ok := val.(string); ok {
+			return uint64(len(s)), nil
+		}
+		return nil, fmt.Errorf("len needs a string")
+	case "int":
+		switch v := val.(type) {
+		case uint64:
+			return v, nil
+		case bool:
+			if v {
+				return uint64(1), nil
+			}
+			return uint64(0), nil
+		case string:
+			i, err := strconv.ParseUint(v, 0, 64)
+			if err != nil {
+				if numErr, isNum := err.(*strconv.NumError); isNum {
+					numErr.Num = displayValue(v, sensitive)
+				}
+				return nil, fmt.Errorf("int: %w", err)
+			}
+			return i, nil
+		}
+	case "str":
+		return fmt.Sprint(val), nil
+	}
+	return nil, fmt.Errorf("unknown function %s", name)
+}
+
+// exprToken
+// is a lexical token of the expression language.
+type exprToken struct {
+	kind   byte // 'n' number, 's' string, 'i' identifier, 'o' operator
+	text   string
+	offset int
+}
+
+// exprOperators lists the operators, longest first.
+var exprOperators = []string{"||", "&&", "==", "!=", "<=", ">=", "<<", ">>",
+	"!", "<", ">", "+", "-", "*", "/", "%", "&", "|", "^", "(", ")", ","}
+
+// lexExpr
+// splits an expression into tokens.
+func lexExpr(src string) ([]exprToken, error) {
+	tokens := make([]exprToken, 0)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (isAlnum(src[j])) {
+				j++
+			}
+			tokens = append(tokens, exprToken{'n', src[i:j], i})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, exprToken{'s', src[i : j+1], i})
+			i = j + 1
+		case isAlnum(c) || c == '_' || c == '$':
+			j := i + 1
+			for j < len(src) && (isAlnum(src[j]) || src[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, exprToken{'i', src[i:j], i})
+			i = j
+		default:
+			found := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, exprToken{'o', op, i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// isAlnum
+// reports whether c is an ASCII letter or digit.
+func isAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// exprParser
+// is a recursive descent parser over expression tokens.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// exprLevels lists the binary operators by increasing precedence.
+var exprLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (ep *exprParser) peekOp(ops ...string) (string, bool) {
+	if ep.pos >= len(ep.tokens) || ep.tokens[ep.pos].kind != 'o' {
+		return "", false
+	}
+	for _, op := range ops {
+		if ep.tokens[ep.pos].text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (ep *exprParser) parseOr() (exprNode, error) {
+	return ep.parseLevel(0)
+}
+
+func (ep *exprParser) parseLevel(level int) (exprNode, error) {
+	if level >= len(exprLevels) {
+		return ep.parseUnary()
+	}
+	left, err := ep.parseLevel(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, found := ep.peekOp(exprLevels[level]...)
+		if !found {
+			return left, nil
+		}
+		ep.pos++
+		right, err := ep.parseLevel(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{op, left, right}
+	}
+}
+
+func (ep *exprParser) parseUnary() (exprNode, error) {
+	if op, found := ep.peekOp("!", "-"); found {
+		ep.pos++
+		operand, err := ep.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return exprUnary{op, operand}, nil
+	}
+	return ep.parsePrimary()
+}
+
+func (ep *exprParser) parsePrimary() (exprNode, error) {
+	if ep.pos >= len(ep.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := ep.tokens[ep.pos]
+	ep.pos++
+	switch tok.kind {
+	case 'n':
+		val, err := strconv.ParseUint(tok.text, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at offset %d", tok.text, tok.offset)
+		}
+		return exprLiteral{val}, nil
+	case 's':
+		val, ok, errmsg := UnescapeString(tok.text)
+		if !ok {
+			return nil, fmt.Errorf("%s at offset %d", errmsg, tok.offset)
+		}
+		return exprLiteral{val}, nil
+	case 'i':
+		switch tok.text {
+		case "true":
+			return exprLiteral{true}, nil
+		case "false":
+			return exprLiteral{false}, nil
+		}
+		if _, found := ep.peekOp("("); found {
+			ep.pos++
+			args := make([]exprNode, 0)
+			for {
+				if _, found := ep.peekOp(")"); found {
+					ep.pos++
+					return exprCall{tok.text, args}, nil
+				}
+				if len(args) > 0 {
+					if _, found := ep.peekOp(","); !found {
+						return nil, fmt.Errorf("expected , or ) in call to %s", tok.text)
+					}
+					ep.pos++
+				}
+				arg, err := ep.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+			}
+		}
+		return exprRef{tok.text}, nil
+	case 'o':
+		if tok.text == "(" {
+			inner, err := ep.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, found := ep.peekOp(")"); !found {
+				return nil, fmt.Errorf("missing ) for ( at offset %d", tok.offset)
+			}
+			ep.pos++
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.offset)
+}
@@ -0,0 +1,71 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprEval(t *testing.T) {
+	env := map[string]interface{}{
+		"a":    uint64(2),
+		"name": "r1",
+		"obj":  ObjectType{ObjectTypeId: TokenUint8, ObjectValue: uint64(7)},
+	}
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{"a + 3 * 2", uint64(8)},
+		{"obj == 7 && a < obj", true},
+		{"len(name) == 2 || missing", true},
+		{"int(\"0x10\") >> 2", uint64(4)},
+		{"str(a) + name", "2r1"},
+	}
+	for _, tt := range tests {
+		expr, err := CompileExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("CompileExpr(%q): %v", tt.expr, err)
+		}
+		if got, err := expr.Eval(env); err != nil || got != tt.want {
+			t.Errorf("%s = %v, %v, want %v", tt.expr, got, err, tt.want)
+		}
+	}
+}
+
+func TestExprErrorsRedactSensitiveValues(t *testing.T) {
+	env := map[string]interface{}{
+		"key":  ObjectType{ObjectTypeId: TokenQuotedString, ObjectValue: "hunter2", ObjectSensitive: true},
+		"pin":  ObjectType{ObjectTypeId: TokenUint16, ObjectValue: uint64(0x1234), ObjectSensitive: true},
+		"name": "plain",
+	}
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"-key", "operator - does not apply to " + RedactedValue},
+		{"!(pin + 1)", "operator ! does not apply to " + RedactedValue},
+		{"name < pin", "cannot compare string with " + RedactedValue},
+		{"name < str(pin)", ""},
+		{"int(key)", `int: strconv.ParseUint: parsing "` + RedactedValue + `": invalid syntax`},
+		{"-name", "operator - does not apply to plain"},
+	}
+	for _, tt := range tests {
+		expr, err := CompileExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("CompileExpr(%q): %v", tt.expr, err)
+		}
+		_, err = expr.Eval(env)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.expr, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: error %v, want %q", tt.expr, err, tt.err)
+		}
+		if err != nil && (strings.Contains(err.Error(), "hunter2") || strings.Contains(err.Error(), "1234")) {
+			t.Errorf("%s: error %q shows a sensitive value", tt.expr, err)
+		}
+	}
+}
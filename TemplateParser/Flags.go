@@ -0,0 +1,184 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlagSeparator is the text between the flags of a Flags operand, as in read|write|exec.
+const FlagSeparator = "|"
+
+// Flag
+// is one flag a Flags slot accepts: its name and the bits it sets.
+type Flag struct {
+	Name  string `json:"name" yaml:"name"`
+	Value uint64 `json:"value" yaml:"value"`
+}
+
+// ValidateFlags
+// checks the Flags of a template list: every Flags slot lists at least one flag, each with
+// a name made of letters, digits and underscores that no other flag of the slot has,
+// ignoring case, and no other slot lists any.
+func ValidateFlags(templateList []TemplateObject) (bool, string) {
+	for idx, tmpl := range templateList {
+		if tmpl.TemplateType != TokenFlags {
+			if len(tmpl.Flags) > 0 {
+				return false, fmt.Sprintf("Slot %d lists flags but is not a Flags slot", idx)
+			}
+			continue
+		}
+		if len(tmpl.Flags) == 0 {
+			return false, fmt.Sprintf("Flags slot %d has no flags", idx)
+		}
+		for fIdx, flag := range tmpl.Flags {
+			if flag.Name == "" || strings.IndexFunc(flag.Name, func(ch rune) bool { return !isSpecWord(ch) }) >= 0 {
+				return false, fmt.Sprintf("Flags slot %d has an invalid flag name %q", idx, flag.Name)
+			}
+			if flagIndex(tmpl.Flags[:fIdx], flag.Name) >= 0 {
+				return false, fmt.Sprintf("Flags slot %d lists %s twice", idx, flag.Name)
+			}
+		}
+	}
+	return true, ""
+}
+
+// checkFlagTokens
+// checks that the parser reads every flag name of the Flags slots of a template list as a
+// single token, so that lines can hold it. The caller holds the parser's lock.
+func (p *Parser) checkFlagTokens(templateList []TemplateObject) (bool, string) {
+	for idx, tmpl := range templateList {
+		for _, flag := range tmpl.Flags {
+			count := 0
+			for _, token := range p.lexer.scan(flag.Name) {
+				if !isBlankToken(token) {
+					count++
+				}
+			}
+			if count != 1 {
+				return false, fmt.Sprintf("Flag %s of flags slot %d is not a single token", flag.Name, idx)
+			}
+		}
+	}
+	return true, ""
+}
+
+// flagIndex
+// returns the index of the flag with a name, ignoring case, or -1.
+func flagIndex(flags []Flag, name string) int {
+	for idx, flag := range flags {
+		if strings.EqualFold(flag.Name, name) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// flagNames
+// returns the names of a slot's flags separated by commas, for error messages.
+func flagNames(flags []Flag) string {
+	names := make([]string, len(flags))
+	for idx, flag := range flags {
+		names[idx] = flag.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// flagValues
+// returns the flags of a slot with their values, as in read=0x1, write=0x2, joined by sep.
+func flagValues(flags []Flag, sep string) string {
+	items := make([]string, len(flags))
+	for idx, flag := range flags {
+		items[idx] = fmt.Sprintf("%s=%#x", flag.Name, flag.Value)
+	}
+	return strings.Join(items, sep)
+}
+
+// matchFlags
+// matches a Flags slot: one or more of its flags separated by FlagSeparator, with or
+// without blanks around it and whether or not the configuration makes it an operator. The
+// object holds the flags' values ORed together, and the flags as written in the template,
+// joined by FlagSeparator, as its descriptor. Naming a flag twice is an error.
+func matchFlags(tmpl TemplateObject, cursor *TokenCursor) (ObjectType, bool, string) {
+	obj := newObject(TokenFlags, uint64(0), "")
+	var names []string
+	for {
+		token, ok := cursor.Peek()
+		idx := flagIndex(tmpl.Flags, token.ValueReceived)
+		if !ok || idx < 0 {
+			got := "the end of the line"
+			switch {
+			case ok && tmpl.Sensitive:
+				got = RedactedValue
+			case ok:
+				got = token.ValueReceived
+			}
+			return obj, false, fmt.Sprintf("Expected flags among %s but got %s: %s", flagNames(tmpl.Flags), got, tmpl.TemplateError)
+		}
+		flag := tmpl.Flags[idx]
+		for _, name := range names {
+			if name == flag.Name {
+				return obj, false, fmt.Sprintf("Flag %s is given twice: %s", flag.Name, tmpl.TemplateError)
+			}
+		}
+		obj.ObjectValue = obj.ObjectValue.(uint64) | flag.Value
+		names = append(names, flag.Name)
+		obj.ObjectDescriptor = strings.Join(names, FlagSeparator)
+		if !cursor.nextSeparator(FlagSeparator) {
+			return obj, true, ""
+		}
+	}
+}
+
+// nextSeparator
+// consumes the next token, and the separator after it if there is one, reporting whether
+// there was. The separator may be a TokenUnknown token, which the cursor steps over.
+func (tc *TokenCursor) nextSeparator(separator string) bool {
+	from := tc.pos
+	tc.Next()
+	for _, token := range tc.tokens[from+1 : tc.pos] {
+		if strings.TrimSpace(token.ValueReceived) == separator {
+			return true
+		}
+	}
+	if token, ok := tc.Peek(); ok && token.ValueReceived == separator {
+		tc.Next()
+		return true
+	}
+	return false
+}
+
+// sameFlags
+// reports whether two slots list the same flags with the same values, ignoring case and
+// order.
+func sameFlags(a TemplateObject, b TemplateObject) bool {
+	return flagsCover(a, b) && flagsCover(b, a)
+}
+
+// flagsCover
+// reports whether every flag of slot b is a flag of slot a with the same value.
+func flagsCover(a TemplateObject, b TemplateObject) bool {
+	for _, flag := range b.Flags {
+		if idx := flagIndex(a.Flags, flag.Name); idx < 0 || a.Flags[idx].Value != flag.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// flagsOverlap
+// reports whether a Flags slot can start with the same word as another slot: one of its
+// flags for a keyword or another Flags slot, and any word otherwise.
+func flagsOverlap(flags TemplateObject, other TemplateObject) bool {
+	if other.TemplateType == TokenFlags {
+		for _, flag := range other.Flags {
+			if flagIndex(flags.Flags, flag.Name) >= 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if word := slotWord(other); word != "" {
+		return flagIndex(flags.Flags, word) >= 0
+	}
+	return true
+}
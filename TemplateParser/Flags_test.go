@@ -0,0 +1,77 @@
+package TemplateParser
+
+import "testing"
+
+// flagsTemplate maps memory with a list of access flags.
+var flagsTemplate = []TemplateObject{
+	{TemplateType: TokenIdentifier},
+	{TemplateType: TokenUint16},
+	{TemplateType: TokenComma},
+	{TemplateType: TokenFlags, Flags: []Flag{{"read", 1}, {"write", 2}, {"exec", 4}}, TemplateError: "bad access"},
+}
+
+func TestFlagsSlot(t *testing.T) {
+	tests := []struct {
+		line  string
+		value uint64
+		names string
+		err   string
+	}{
+		{"map 1000, read", 1, "read", ""},
+		{"map 1000, read|write", 3, "read|write", ""},
+		{"map 1000, EXEC | read", 5, "exec|read", ""},
+		{"map 1000, read|write|exec", 7, "read|write|exec", ""},
+		{"map 1000, read|read", 0, "", "Flag read is given twice: bad access"},
+		{"map 1000, read|copy", 0, "", "Expected flags among read, write, exec but got copy: bad access"},
+		{"map 1000, read|", 0, "", "Expected flags among read, write, exec but got the end of the line: bad access"},
+	}
+	for _, tt := range tests {
+		objs, ok, errmsg := ParseLine(tt.line, flagsTemplate)
+		if tt.err != "" {
+			if ok || errmsg != tt.err {
+				t.Errorf("ParseLine(%q) = %v, %q, want %q", tt.line, ok, errmsg, tt.err)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("ParseLine(%q) failed: %s", tt.line, errmsg)
+			continue
+		}
+		if obj := objs[3]; obj.ObjectTypeId != TokenFlags || obj.ObjectValue != tt.value || obj.ObjectDescriptor != tt.names {
+			t.Errorf("ParseLine(%q) flags = %+v, want %#x %s", tt.line, obj, tt.value, tt.names)
+		}
+	}
+}
+
+func TestFlagsSlotWithOperatorSeparator(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Operators = append(config.Operators, "|")
+	objs, ok, errmsg := ParseLineWithConfig("map 1000, write|exec", flagsTemplate, config)
+	if !ok || objs[3].ObjectValue != uint64(6) {
+		t.Errorf("ParseLineWithConfig = %v, %v, %q, want write|exec with | as an operator", objs, ok, errmsg)
+	}
+}
+
+func TestValidateFlags(t *testing.T) {
+	tests := []struct {
+		slot TemplateObject
+		err  string
+	}{
+		{TemplateObject{TemplateType: TokenFlags, Flags: []Flag{{"read", 1}}}, ""},
+		{TemplateObject{TemplateType: TokenFlags}, "Flags slot 1 has no flags"},
+		{TemplateObject{TemplateType: TokenFlags, Flags: []Flag{{"read-only", 1}}}, `Flags slot 1 has an invalid flag name "read-only"`},
+		{TemplateObject{TemplateType: TokenFlags, Flags: []Flag{{"read", 1}, {"READ", 2}}}, "Flags slot 1 lists READ twice"},
+		{TemplateObject{TemplateType: TokenUint8, Flags: []Flag{{"read", 1}}}, "Slot 1 lists flags but is not a Flags slot"},
+	}
+	for _, tt := range tests {
+		ok, errmsg := ValidateFlags([]TemplateObject{{TemplateType: TokenIdentifier}, tt.slot})
+		if ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("ValidateFlags(%v) = %v, %q, want %q", tt.slot.Flags, ok, errmsg, tt.err)
+		}
+	}
+	p := NewParser(DefaultParserConfig())
+	tmpl := []TemplateObject{{TemplateType: TokenIdentifier}, {TemplateType: TokenFlags, Flags: []Flag{{"r1x2", 1}, {"ok", 2}}}}
+	if ok, errmsg := p.RegisterTemplate("map", tmpl); !ok {
+		t.Errorf("RegisterTemplate with single-token flags failed: %s", errmsg)
+	}
+}
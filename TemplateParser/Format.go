@@ -0,0 +1,332 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// minDigits gives the number of hex digits a literal needs to be read back as each
+// sized integer token.
+var minDigits = map[int]int{TokenUint8: 1, TokenUint16: 3, TokenUint32: 5, TokenUint64: 9, TokenBigInt: 17}
+
+// FormatLine
+// renders a parsed line back into source text in a canonical layout: the label, the
+// mnemonic and its operands separated by single spaces, no space before a comma or a
+// closing bracket or after an opening one, and the comment after " ; ". Integers are
+// written in hex with just enough digits to be read back as the same token type. Values
+// are written as they are, so sensitive operands appear in clear. It fails if an object
+// has no source form that the parser reads back as the same object.
+func (p *Parser) FormatLine(pl ParsedLine) (string, bool, string) {
+	parts := make([]string, 0, len(pl.Objects)+2)
+	if pl.Label != "" {
+		parts = append(parts, pl.Label+":")
+	}
+	if pl.Directive == OriginDirective && len(pl.Objects) == 1 {
+		text, ok, errmsg := p.formatNumber(pl.Objects[0], TokenUint8, true)
+		if !ok {
+			return "", false, errmsg
+		}
+		parts = append(parts, "."+OriginDirective, text)
+	} else if pl.Directive == EquDirective && len(pl.Objects) == 2 {
+		text, ok, errmsg := pl.Objects[1].ObjectDescriptor, true, ""
+		if text == "" {
+			text, ok, errmsg = p.formatNumber(pl.Objects[1], TokenUint8, true)
+		}
+		if !ok {
+			return "", false, errmsg
+		}
+		parts = append(parts, fmt.Sprint(pl.Objects[0].ObjectValue), EquDirective, text)
+	} else if pl.Directive == ArchDirective && len(pl.Objects) == 1 {
+		parts = append(parts, "."+ArchDirective, fmt.Sprint(pl.Objects[0].ObjectValue))
+	} else {
+		if pl.directive != nil {
+			parts = append(parts, "."+pl.directive.Name)
+		}
+		var sb strings.Builder
+		objs := pl.writtenObjects()
+		for idx, obj := range objs {
+			text, ok, errmsg := p.formatObject(obj)
+			if !ok {
+				return "", false, fmt.Sprintf("Operand %d: %s", idx, errmsg)
+			}
+			if idx > 0 && needsSpace(objs[idx-1], obj) {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(text)
+		}
+		if sb.Len() > 0 {
+			parts = append(parts, sb.String())
+		}
+	}
+	text := strings.Join(parts, " ")
+	if pl.Comment != "" {
+		text += " ; " + pl.Comment
+	}
+	return text, true, ""
+}
+
+// punctuationText gives the source text of each punctuation token type.
+var punctuationText = map[int]string{TokenComma: ",", TokenColon: ":", TokenLBracket: "[", TokenRBracket: "]",
+	TokenLParen: "(", TokenRParen: ")", TokenPlus: "+", TokenMinus: "-"}
+
+// Format
+// renders the objects of a line matched against a template list back into source text,
+// laid out as FormatLine does. Each object must have the type of its slot, as the objects
+// returned by Parse do; punctuation objects without a value are written with their usual
+// text, or the operator of their slot.
+func (p *Parser) Format(templateList []TemplateObject, objs []ObjectType) (string, bool, string) {
+	if len(objs) != len(templateList) {
+		return "", false, fmt.Sprintf("Template has %d slots but got %d objects", len(templateList), len(objs))
+	}
+	filled := make([]ObjectType, len(objs))
+	for idx, obj := range objs {
+		tmpl := templateList[idx]
+		if obj.ObjectTypeId != tmpl.TemplateType {
+			return "", false, fmt.Sprintf("Operand %d: %s", idx, p.config.mismatchError(tmpl, obj.ObjectTypeId))
+		}
+		if _, isText := obj.ObjectValue.(string); !isText && isPunctuation(obj.ObjectTypeId) {
+			obj.ObjectValue = punctuationText[obj.ObjectTypeId]
+			if obj.ObjectTypeId == TokenOperator {
+				obj.ObjectValue, _ = tmpl.TemplateValue.ObjectValue.(string)
+			}
+		}
+		if ok, errmsg := tmpl.checkOperator(obj); !ok {
+			return "", false, fmt.Sprintf("Operand %d: %s", idx, errmsg)
+		}
+		filled[idx] = obj
+	}
+	return p.FormatLine(ParsedLine{Objects: filled})
+}
+
+// Format
+// renders the objects of a line matched against a template list back into source text.
+// See Parser.Format.
+func Format(templateList []TemplateObject, objs []ObjectType) (string, bool, string) {
+	return FormatWithConfig(templateList, objs, DefaultParserConfig())
+}
+
+// FormatWithConfig
+// is Format for a configuration, such as one with register aliases or custom token types.
+func FormatWithConfig(templateList []TemplateObject, objs []ObjectType, config ParserConfig) (string, bool, string) {
+	return NewParser(config).Format(templateList, objs)
+}
+
+// VerifyLine
+// parses a line, formats the result with FormatLine and parses the formatted text again,
+// failing unless both parses produce the same result. The formatted text is returned.
+func (p *Parser) VerifyLine(line string) (string, bool, string) {
+	results := p.parseExpanded(0, line)
+	if len(results) != 1 {
+		return "", false, "Line expands to several lines"
+	}
+	if !results[0].Ok {
+		return "", false, results[0].Error
+	}
+	return p.verifyResult(results[0], nil)
+}
+
+// verifyResult
+// formats a successfully parsed line and checks that the text parses back to the same
+// result, returning the text. The text is parsed in a copy of ctx, if set, without limits.
+func (p *Parser) verifyResult(lr LineResult, ctx *ParseContext) (string, bool, string) {
+	text, ok, errmsg := p.FormatLine(lr.ParsedLine)
+	if !ok {
+		return "", false, "Cannot format line: " + errmsg
+	}
+	again, _ := p.parseLine(lr.LineNumber, text, nil, ctx.unlimited(lr.File, lr.LineNumber))
+	again.setSource(text)
+	if !again.Ok {
+		return text, false, fmt.Sprintf("Formatted line %q does not parse: %s", text, again.Error)
+	}
+	if ok, errmsg := sameParse(lr, again); !ok {
+		return text, false, fmt.Sprintf("Formatted line %q parses differently: %s", text, errmsg)
+	}
+	return text, true, ""
+}
+
+// sameParse
+// compares two parses of a line, ignoring their source text.
+func sameParse(a, b LineResult) (bool, string) {
+	switch {
+	case a.Label != b.Label:
+		return false, fmt.Sprintf("label %q became %q", a.Label, b.Label)
+	case a.Directive != b.Directive:
+		return false, fmt.Sprintf("directive %q became %q", a.Directive, b.Directive)
+	case a.Comment != b.Comment:
+		return false, fmt.Sprintf("comment %q became %q", a.Comment, b.Comment)
+	case a.Size != b.Size || a.Cycles != b.Cycles:
+		return false, "a different template entry matched"
+	case len(a.Objects) != len(b.Objects):
+		return false, fmt.Sprintf("%d objects became %d", len(a.Objects), len(b.Objects))
+	}
+	for idx := range a.Objects {
+		oa, ob := a.Objects[idx], b.Objects[idx]
+		if !sameObjectValue(oa, ob) || oa.ObjectWidth != ob.ObjectWidth ||
+			oa.ObjectDescriptor != ob.ObjectDescriptor || oa.ObjectSensitive != ob.ObjectSensitive {
+			return false, fmt.Sprintf("object %d %+v became %+v", idx, oa, ob)
+		}
+	}
+	if !reflect.DeepEqual(withoutText(a.Groups), withoutText(b.Groups)) {
+		return false, "capture groups differ"
+	}
+	return true, ""
+}
+
+// withoutText
+// returns a copy of capture groups with the source text of their objects cleared, since a
+// formatted line may write the same values differently.
+func withoutText(groups map[string][]ObjectType) map[string][]ObjectType {
+	if groups == nil {
+		return nil
+	}
+	stripped := make(map[string][]ObjectType, len(groups))
+	for name, objs := range groups {
+		copied := make([]ObjectType, len(objs))
+		for idx, obj := range objs {
+			obj.ObjectText = ""
+			copied[idx] = obj
+		}
+		stripped[name] = copied
+	}
+	return stripped
+}
+
+// needsSpace
+// reports whether formatted objects are separated by a space.
+func needsSpace(prev, next ObjectType) bool {
+	switch prev.ObjectTypeId {
+	case TokenLParen, TokenLBracket:
+		return false
+	}
+	switch next.ObjectTypeId {
+	case TokenComma, TokenColon, TokenRParen, TokenRBracket:
+		return false
+	}
+	return true
+}
+
+// formatObject
+// renders one object as source text.
+func (p *Parser) formatObject(obj ObjectType) (string, bool, string) {
+	switch obj.ObjectTypeId {
+	case TokenQuotedString:
+		if s, isString := obj.ObjectValue.(string); isString {
+			return QuoteString(s), true, ""
+		}
+		if _, isIndex := obj.ObjectValue.(uint64); isIndex {
+			return QuoteString(obj.ObjectDescriptor), true, ""
+		}
+	case TokenChar:
+		if code, isInt := obj.ObjectValue.(uint64); isInt {
+			return QuoteChar(code), true, ""
+		}
+	case TokenEnum, TokenFlags:
+		if obj.ObjectDescriptor != "" {
+			return obj.ObjectDescriptor, true, ""
+		}
+	case TokenBoolean:
+		if obj.ObjectDescriptor != "" {
+			return obj.ObjectDescriptor, true, ""
+		}
+		if val, isBool := obj.ObjectValue.(bool); isBool {
+			return strconv.FormatBool(val), true, ""
+		}
+	case TokenUint8, TokenUint16, TokenUint32, TokenUint64, TokenBigInt:
+		if val, err := p.config.parseNumber(obj.ObjectDescriptor); obj.ObjectDescriptor != "" && err == nil && val == obj.ObjectValue {
+			return obj.ObjectDescriptor, true, ""
+		}
+		return p.formatNumber(obj, obj.ObjectTypeId, false)
+	case TokenUint128, TokenUint256:
+		return p.formatNumber(obj, TokenUint8, true)
+	case TokenRegister:
+		return p.formatRegister(obj)
+	case TokenMemory:
+		return p.formatMemory(obj)
+	case TokenLabelDef:
+		return fmt.Sprint(obj.ObjectValue) + ":", true, ""
+	case TokenExpression:
+		if obj.ObjectDescriptor != "" {
+			return obj.ObjectDescriptor, true, ""
+		}
+		return p.formatNumber(obj, TokenUint8, true)
+	case TokenLabelRef, TokenLabelRel:
+		if s, isString := obj.ObjectValue.(string); isString {
+			return s, true, ""
+		}
+		if obj.ObjectDescriptor != "" {
+			return obj.ObjectDescriptor, true, ""
+		}
+		return "", false, "resolved label has no name"
+	default:
+		if s, isString := obj.ObjectValue.(string); isString {
+			return s, true, ""
+		}
+		return fmt.Sprint(obj.ObjectValue), true, ""
+	}
+	return "", false, fmt.Sprintf("unexpected value %v for %s", obj.ObjectValue, p.TokenName(obj.ObjectTypeId))
+}
+
+// formatNumber
+// writes an integer in hex with enough digits to be read back as tokenType, or as any
+// integer token if anySize is set.
+func (p *Parser) formatNumber(obj ObjectType, tokenType int, anySize bool) (string, bool, string) {
+	val, isInt := ToBigInt(obj)
+	if !isInt {
+		return "", false, fmt.Sprintf("%v is not an integer", obj.ObjectValue)
+	}
+	text := val.Text(16)
+	if anySize && len(text) > 16 {
+		tokenType = TokenBigInt
+	}
+	if p.config.Numbers.base() != 16 && val.IsUint64() {
+		text = p.config.numberText(val.Uint64(), tokenType)
+	} else {
+		if width := minDigits[tokenType]; len(text) < width {
+			text = strings.Repeat("0", width-len(text)) + text
+		}
+		if len(text) >= 2 && isLetter(text[0]) && isLetter(text[1]) {
+			text = "0" + text
+		}
+	}
+	got, length := p.lexer.next(text)
+	if length != len(text) || (got != tokenType && !(anySize && isIntegerToken(got))) {
+		return "", false, fmt.Sprintf("%#x cannot be written as a %s", val, p.TokenName(tokenType))
+	}
+	return text, true, ""
+}
+
+// formatRegister
+// writes a register number with the prefix or suffix that gives it its width, checking
+// that the text parses back to the same register and width.
+func (p *Parser) formatRegister(obj ObjectType) (string, bool, string) {
+	num, isInt := obj.ObjectValue.(uint64)
+	if !isInt {
+		return "", false, fmt.Sprintf("register %v is not an integer", obj.ObjectValue)
+	}
+	digits := fmt.Sprintf("%x", num)
+	candidates := make([]string, 0, 4)
+	if obj.ObjectWidth != 0 {
+		for _, prefix := range sortedKeys(p.config.RegisterPrefixes) {
+			if p.config.RegisterPrefixes[prefix] == obj.ObjectWidth {
+				candidates = append(candidates, prefix+digits, prefix+"0"+digits)
+			}
+		}
+		for _, suffix := range sortedKeys(p.config.RegisterSuffixes) {
+			if p.config.RegisterSuffixes[suffix] == obj.ObjectWidth {
+				candidates = append(candidates, "r"+digits+suffix, "r0"+digits+suffix)
+			}
+		}
+	}
+	candidates = append(candidates, "r"+digits, "r0"+digits)
+	for _, text := range candidates {
+		if got, length := p.lexer.next(text); got != TokenRegister || length != len(text) {
+			continue
+		}
+		if val, width, err := p.config.parseRegister(text); err == nil && val == num && width == obj.ObjectWidth {
+			return text, true, ""
+		}
+	}
+	return "", false, fmt.Sprintf("register %#x of width %d has no source form", num, obj.ObjectWidth)
+}
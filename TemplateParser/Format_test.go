@@ -0,0 +1,76 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyLine(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"li r1, 10", "li r1, 10", true},
+		{"LI   R1 ,10", "li r1, 10", true},
+		{"li r1, 10 ; load", "li r1, 10 ; load", true},
+		{"li r1, 100", "", false},
+	}
+	for _, tt := range tests {
+		got, ok, errmsg := p.VerifyLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("VerifyLine(%q) = %q, %v, %q, want %q, %v", tt.line, got, ok, errmsg, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	objs, ok, _ := ParseLine("MOV  R1 ,R2", benchmarkTemplate)
+	if !ok {
+		t.Fatal("ParseLine failed")
+	}
+	if text, ok, errmsg := Format(benchmarkTemplate, objs); !ok || text != "mov r1, r2" {
+		t.Errorf("Format = %q, %v, %q, want mov r1, r2", text, ok, errmsg)
+	}
+	objs[2] = ObjectType{ObjectTypeId: TokenComma}
+	if text, ok, _ := Format(benchmarkTemplate, objs); !ok || text != "mov r1, r2" {
+		t.Errorf("Format of a comma without a value = %q, %v", text, ok)
+	}
+	objs[3] = newObject(TokenUint8, uint64(2), "")
+	if _, ok, errmsg := Format(benchmarkTemplate, objs); ok || !strings.HasPrefix(errmsg, "Operand 3: Expected type (6)Register") {
+		t.Errorf("Format of a mismatched object = %v, %q", ok, errmsg)
+	}
+	if _, ok, errmsg := Format(benchmarkTemplate, objs[:2]); ok || errmsg != "Template has 4 slots but got 2 objects" {
+		t.Errorf("Format of too few objects = %v, %q", ok, errmsg)
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	tests := []struct {
+		word    uint64
+		address uint64
+		want    string
+	}{
+		{0x137f, 0, "ldi r3, 7f"},
+		{0x20fe, 0x10, "br e"},
+		{0x2004, 0x10, "br 14"},
+	}
+	for _, tt := range tests {
+		text, ok, errmsg := p.Disassemble(tt.word, tt.address)
+		if !ok || text != tt.want {
+			t.Errorf("Disassemble(%#x, %#x) = %q, %v, %q, want %q", tt.word, tt.address, text, ok, errmsg, tt.want)
+		}
+		pl, ok, errmsg := p.Decode(tt.word, tt.address)
+		if !ok {
+			t.Fatalf("Decode(%#x) failed: %s", tt.word, errmsg)
+		}
+		if word, ok, errmsg := p.Encode(pl); !ok || word != tt.word {
+			t.Errorf("Encode(Decode(%#x)) = %#x, %v, %q", tt.word, word, ok, errmsg)
+		}
+	}
+	if _, ok, errmsg := p.Disassemble(0xf000, 0); ok || errmsg != "No template encodes 0xf000" {
+		t.Errorf("Disassemble of an unknown opcode = %v, %q", ok, errmsg)
+	}
+}
@@ -0,0 +1,158 @@
+package TemplateParser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// errFrozen is the message reported when a frozen parser's template set would change.
+const errFrozen = "Parser is frozen"
+
+// grammarState
+// is everything that decides how a parser matches lines, in the form GrammarHash hashes.
+type grammarState struct {
+	Grammar          GrammarFile           `json:"grammar"`
+	Templates        []GrammarOperand      `json:"templates,omitempty"`
+	TokenTypes       []customTokenHash     `json:"token_types,omitempty"`
+	PreserveCase     bool                  `json:"preserve_case"`
+	FoldMnemonics    bool                  `json:"fold_mnemonics"`
+	MaxNumericDigits int                   `json:"max_numeric_digits"`
+	RegisterSuffixes map[string]int        `json:"register_suffixes,omitempty"`
+	RegisterPrefixes map[string]int        `json:"register_prefixes,omitempty"`
+	RegisterAliases  map[string]uint64     `json:"register_aliases,omitempty"`
+	Directives       []directiveHash       `json:"directives,omitempty"`
+	Numbers          NumberOptions         `json:"numbers"`
+	Separators       Separators            `json:"separators"`
+	TokenPrecedence  []int                 `json:"token_precedence,omitempty"`
+	FirstMatch       bool                  `json:"first_match"`
+	Letters          []*unicode.RangeTable `json:"identifier_letters,omitempty"`
+	UnknownTokens    UnknownTokenMode      `json:"unknown_tokens"`
+	LineContinuation string                `json:"line_continuation,omitempty"`
+	Normalize        Normalizer            `json:"normalize"`
+	Overloads        bool                  `json:"overloads"`
+	StringIndexes    bool                  `json:"string_indexes"`
+	IncludeKeyword   string                `json:"include_keyword,omitempty"`
+}
+
+// directiveHash
+// is the hashed form of a registered directive. Size and Handler functions cannot be
+// hashed, so a directive is identified by its name, operands and Repeat flag.
+type directiveHash struct {
+	Name     string           `json:"name"`
+	Operands []GrammarOperand `json:"operands,omitempty"`
+	Repeat   bool             `json:"repeat,omitempty"`
+}
+
+// customTokenHash
+// is the hashed form of a custom token type. Convert functions cannot be hashed, so a
+// custom type is identified by its name and pattern.
+type customTokenHash struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// GrammarHash
+// returns a SHA-256 hash of the parser's template set: the registered entries in
+// registration order, the list set with SetTemplates, the grammar version and token
+// deprecations, the custom token types, the registered directives and the configuration
+// affecting tokenizing and matching. Two parsers with the same hash match lines the same
+// way, as long as their macros, constants, target options, Validate callbacks, actions,
+// the convert functions of their custom token types and the Size and Handler functions
+// of their directives agree; those are not covered by the hash.
+func (p *Parser) GrammarHash() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.grammarHash()
+}
+
+// grammarHash
+// implements GrammarHash.
+func (p *Parser) grammarHash() string {
+	state := grammarState{
+		Grammar:          p.grammarFile(),
+		PreserveCase:     p.config.PreserveCase,
+		FoldMnemonics:    p.config.FoldMnemonics,
+		MaxNumericDigits: p.config.MaxNumericDigits,
+		RegisterSuffixes: p.config.RegisterSuffixes,
+		RegisterPrefixes: p.config.RegisterPrefixes,
+		RegisterAliases:  p.config.RegisterAliases,
+		Numbers:          p.config.Numbers,
+		Separators:       p.config.Separators,
+		TokenPrecedence:  p.config.TokenPrecedence,
+		FirstMatch:       p.config.FirstMatch,
+		Letters:          p.config.IdentifierLetters,
+		UnknownTokens:    p.config.UnknownTokens,
+		LineContinuation: p.config.LineContinuation,
+		Normalize:        p.config.Normalize,
+		Overloads:        p.config.Overloads,
+		StringIndexes:    p.config.StringIndexes,
+		IncludeKeyword:   p.config.IncludeKeyword,
+	}
+	if p.templates != nil {
+		state.Templates = p.config.operandSpecs(p.templates)
+	}
+	names := make([]string, 0, len(p.directives))
+	for name := range p.directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		directive := p.directives[name]
+		state.Directives = append(state.Directives,
+			directiveHash{name, p.config.operandSpecs(directive.Operands), directive.Repeat})
+	}
+	for _, custom := range p.config.customTokens {
+		state.TokenTypes = append(state.TokenTypes, customTokenHash{custom.Name, custom.Pattern})
+	}
+	// The state holds only strings, numbers and booleans, in structs, slices and maps keyed
+	// by strings, which always encode
+	data, _ := json.Marshal(state)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// operandSpecs
+// returns the grammar file form of a list of template slots.
+func (config ParserConfig) operandSpecs(templateList []TemplateObject) []GrammarOperand {
+	return config.grammarFile([]TemplateEntry{{Objects: templateList}}).Templates[0].Operands
+}
+
+// Freeze
+// stops the parser's template set from changing and returns its GrammarHash. Afterwards
+// registering entries, setting templates, adding token types, register aliases and
+// operators, deprecating token types and setting the grammar version fail, and every
+// SourceResult the parser produces is stamped with the hash so cached results and
+// distributed workers can check they used the same grammar. Freezing an already frozen
+// parser returns the same hash.
+func (p *Parser) Freeze() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.frozen == "" {
+		p.frozen = p.grammarHash()
+	}
+	return p.frozen
+}
+
+// Frozen
+// returns the hash of a frozen parser's template set, and whether the parser is frozen.
+func (p *Parser) Frozen() (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.frozen, p.frozen != ""
+}
+
+// VerifyGrammar
+// checks that a result was produced by a frozen parser with the same template set as p.
+func (sr *SourceResult) VerifyGrammar(p *Parser) error {
+	if sr.GrammarHash == "" {
+		return fmt.Errorf("result was not produced by a frozen parser")
+	}
+	if hash := p.GrammarHash(); hash != sr.GrammarHash {
+		return fmt.Errorf("result was produced with grammar %s, not %s", sr.GrammarHash, hash)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package TemplateParser
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestFreeze(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	hash := p.GrammarHash()
+	if same := newTestParser(t, DefaultParserConfig(), exprGrammar).GrammarHash(); same != hash {
+		t.Errorf("parsers of the same grammar hash to %s and %s", hash, same)
+	}
+	if frozen := p.Freeze(); frozen != hash {
+		t.Fatalf("Freeze() = %s, want %s", frozen, hash)
+	}
+	if ok, _ := p.RegisterSpec("nop"); ok {
+		t.Errorf("RegisterSpec succeeded on a frozen parser")
+	}
+	result := parseTestSource(t, p, "li r1, 10\n")
+	if err := result.VerifyGrammar(p); err != nil {
+		t.Errorf("VerifyGrammar: %v", err)
+	}
+	other := newTestParser(t, DefaultParserConfig(), exprGrammar+"  - mnemonic: nop\n    operands:\n      - {type: Identifier}\n")
+	if other.GrammarHash() == hash {
+		t.Errorf("adding an entry did not change the hash")
+	}
+	if err := result.VerifyGrammar(other); err == nil {
+		t.Errorf("VerifyGrammar succeeded with another grammar")
+	}
+}
+
+func TestGrammarHashCoversConfig(t *testing.T) {
+	base := newTestParser(t, DefaultParserConfig(), exprGrammar).GrammarHash()
+	tests := []struct {
+		name   string
+		change func(config *ParserConfig)
+	}{
+		{"Numbers.Base", func(config *ParserConfig) { config.Numbers.Base = 10 }},
+		{"Numbers.Bases", func(config *ParserConfig) { config.Numbers.Bases = []int{16} }},
+		{"Numbers.Overflow", func(config *ParserConfig) { config.Numbers.Overflow = OverflowWrap }},
+		{"Numbers.WidthSuffixes", func(config *ParserConfig) { config.Numbers.WidthSuffixes = map[string]int{"u8": 8} }},
+		{"Separators", func(config *ParserConfig) { config.Separators.OptionalCommas = true }},
+		{"TokenPrecedence", func(config *ParserConfig) { config.TokenPrecedence = []int{TokenRegister} }},
+		{"FirstMatch", func(config *ParserConfig) { config.FirstMatch = true }},
+		{"IdentifierLetters", func(config *ParserConfig) { config.IdentifierLetters = []*unicode.RangeTable{unicode.Greek} }},
+		{"UnknownTokens", func(config *ParserConfig) { config.UnknownTokens = UnknownStrict }},
+		{"LineContinuation", func(config *ParserConfig) { config.LineContinuation = "\\" }},
+		{"Normalize", func(config *ParserConfig) { config.Normalize.FoldCase = true }},
+		{"Overloads", func(config *ParserConfig) { config.Overloads = true }},
+		{"StringIndexes", func(config *ParserConfig) { config.StringIndexes = true }},
+		{"IncludeKeyword", func(config *ParserConfig) { config.IncludeKeyword = "use" }},
+	}
+	for _, tt := range tests {
+		config := DefaultParserConfig()
+		tt.change(&config)
+		if newTestParser(t, config, exprGrammar).GrammarHash() == base {
+			t.Errorf("changing %s did not change the hash", tt.name)
+		}
+	}
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	if ok, errmsg := p.RegisterDirective(Directive{Name: "byte", Operands: []TemplateObject{{TemplateType: TokenUint8}}}); !ok {
+		t.Fatalf("RegisterDirective: %s", errmsg)
+	}
+	if p.GrammarHash() == base {
+		t.Errorf("registering a directive did not change the hash")
+	}
+}
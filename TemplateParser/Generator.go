@@ -0,0 +1,210 @@
+package TemplateParser
+
+import (
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// generateAttempts is how many times GenerateLine draws a line before giving up.
+const generateAttempts = 16
+
+// generateLetters are the letters random identifiers are made of.
+const generateLetters = "abcdefghijklmnopqrstuvwxyz"
+
+// GenerateLine
+// produces a random line matching a template list, for property-based tests that parse
+// the lines generated and compare the objects with those the line was made from.
+// See Parser.GenerateLine.
+func GenerateLine(tmpl []TemplateObject, rng *rand.Rand) string {
+	return NewParser(DefaultParserConfig()).GenerateLine(tmpl, rng)
+}
+
+// GenerateLine
+// produces a random line matching a template list, written as Format writes it: the word
+// of the mnemonic and keyword slots, random registers, integers and characters within the
+// range of their slot, sometimes at its bounds, random identifiers, strings, choices,
+// flags and memory operands, and the usual text of punctuation. SameAs and DifferentFrom slots are honoured
+// and the Validate callbacks of the slots must accept the objects drawn. The same rng state
+// gives the same line. It returns "" for templates it cannot fill: slots with a Matcher,
+// label definitions, macros and custom token types, or slots whose Validate callbacks
+// reject every object drawn.
+func (p *Parser) GenerateLine(tmpl []TemplateObject, rng *rand.Rand) string {
+	for attempt := 0; attempt < generateAttempts; attempt++ {
+		objs, ok := p.generateObjects(tmpl, rng)
+		if !ok {
+			continue
+		}
+		if text, ok, _ := p.Format(tmpl, objs); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// generateObjects
+// draws an object for every slot of a template list, reporting false if a slot cannot be
+// filled or rejects the object drawn.
+func (p *Parser) generateObjects(tmpl []TemplateObject, rng *rand.Rand) ([]ObjectType, bool) {
+	objs := make([]ObjectType, len(tmpl))
+	for idx, slot := range tmpl {
+		if slot.SameAs > 0 && slot.SameAs < idx {
+			objs[idx] = objs[slot.SameAs]
+			continue
+		}
+		obj, ok := p.generateObject(idx, slot, rng)
+		if !ok {
+			return nil, false
+		}
+		if slot.DifferentFrom > 0 && slot.DifferentFrom < idx && sameObjectValue(obj, objs[slot.DifferentFrom]) {
+			return nil, false
+		}
+		if ok, _ := slot.CheckValidate(obj); !ok {
+			return nil, false
+		}
+		objs[idx] = obj
+	}
+	return objs, true
+}
+
+// generateObject
+// draws an object for one slot.
+func (p *Parser) generateObject(idx int, slot TemplateObject, rng *rand.Rand) (ObjectType, bool) {
+	if slot.Hooks.matcher() != nil {
+		return ObjectType{}, false
+	}
+	obj := ObjectType{ObjectTypeId: slot.TemplateType}
+	switch slot.TemplateType {
+	case TokenIdentifier:
+		if word, _ := slot.TemplateValue.ObjectValue.(string); word != "" && (idx == 0 || slot.Hooks.validate() != nil) {
+			obj.ObjectValue = word
+			return obj, true
+		}
+		obj.ObjectValue = p.generateWord(rng)
+	case TokenLabelRef, TokenLabelRel:
+		obj.ObjectValue = p.generateWord(rng)
+	case TokenQuotedString:
+		text := make([]byte, rng.Intn(13))
+		for pos := range text {
+			text[pos] = byte(' ' + rng.Intn('~'-' '+1))
+		}
+		obj.ObjectValue = string(text)
+	case TokenBoolean:
+		keywords := p.config.booleanKeywords()
+		words := make([]string, 0, len(keywords))
+		for word := range keywords {
+			words = append(words, word)
+		}
+		if len(words) == 0 {
+			return obj, false
+		}
+		sort.Strings(words)
+		word := words[rng.Intn(len(words))]
+		obj.ObjectValue, obj.ObjectDescriptor = keywords[word], word
+	case TokenEnum:
+		if len(slot.Choices) == 0 {
+			return obj, false
+		}
+		choice := rng.Intn(len(slot.Choices))
+		obj.ObjectValue, obj.ObjectDescriptor = uint64(choice), slot.Choices[choice]
+	case TokenFlags:
+		if len(slot.Flags) == 0 {
+			return obj, false
+		}
+		var value uint64
+		names := make([]string, 0, len(slot.Flags))
+		for _, pick := range rng.Perm(len(slot.Flags))[:1+rng.Intn(len(slot.Flags))] {
+			value |= slot.Flags[pick].Value
+			names = append(names, slot.Flags[pick].Name)
+		}
+		obj.ObjectValue, obj.ObjectDescriptor = value, strings.Join(names, FlagSeparator)
+	case TokenMemory:
+		lo, hi, ok := p.generateRange(TemplateObject{TemplateType: TokenRegister})
+		if !ok {
+			return obj, false
+		}
+		register := func() uint64 {
+			span := new(big.Int).Sub(hi, lo)
+			return new(big.Int).Add(lo, new(big.Int).Rand(rng, span.Add(span, big.NewInt(1)))).Uint64()
+		}
+		var mem MemoryOperand
+		if rng.Intn(4) > 0 {
+			mem.HasBase, mem.Base = true, register()
+		}
+		if rng.Intn(2) == 0 {
+			mem.HasIndex, mem.Index, mem.Scale = true, register(), uint64(1)<<rng.Intn(4)
+		}
+		if rng.Intn(4) > 0 {
+			mem.Displacement = rng.Int63n(1<<16) - 1<<15
+		}
+		obj.ObjectValue = mem
+	case TokenUint8, TokenUint16, TokenUint32, TokenUint64, TokenBigInt, TokenUint128, TokenUint256,
+		TokenRegister, TokenChar, TokenExpression:
+		lo, hi, ok := p.generateRange(slot)
+		if !ok {
+			return obj, false
+		}
+		val := new(big.Int).Set(lo)
+		switch rng.Intn(4) {
+		case 0:
+		case 1:
+			val.Set(hi)
+		default:
+			span := new(big.Int).Sub(hi, lo)
+			val.Add(val, new(big.Int).Rand(rng, span.Add(span, big.NewInt(1))))
+		}
+		if isWideTemplate(slot.TemplateType) || slot.TemplateType == TokenBigInt {
+			obj.ObjectValue = val
+		} else {
+			obj.ObjectValue = val.Uint64()
+		}
+	default:
+		if !isPunctuation(slot.TemplateType) {
+			return obj, false
+		}
+	}
+	return obj, true
+}
+
+// generateRange
+// returns the values generateObject may give an integer, register or character slot: those
+// its token type holds, within the range of the slot when it has one, and no wider than the
+// longest literal the configuration reads. TokenBigInt slots take values wider than 64 bits,
+// as long as the configuration reads such literals.
+func (p *Parser) generateRange(slot TemplateObject) (*big.Int, *big.Int, bool) {
+	digits := max(p.config.MaxNumericDigits, 16)
+	longest := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(4*digits)), big.NewInt(1))
+	obj := ObjectType{ObjectTypeId: slot.TemplateType}
+	switch slot.TemplateType {
+	case TokenExpression:
+		obj.ObjectTypeId = TokenUint64
+	case TokenBigInt:
+		if digits <= 16 {
+			return nil, nil, false
+		}
+		return new(big.Int).Lsh(big.NewInt(1), 64), longest, true
+	}
+	lo, hi := perturbRange(obj, slot)
+	if hi.Cmp(longest) > 0 {
+		hi = longest
+	}
+	return lo, hi, lo.Cmp(hi) <= 0
+}
+
+// generateWord
+// returns a random word the tokenizer reads as a single identifier, rather than a number,
+// register, boolean or alias.
+func (p *Parser) generateWord(rng *rand.Rand) string {
+	for {
+		word := make([]byte, 2+rng.Intn(7))
+		for pos := range word {
+			word[pos] = generateLetters[rng.Intn(len(generateLetters))]
+		}
+		if got, length := p.lexer.next(string(word)); got == TokenIdentifier && length == len(word) {
+			if _, alias := p.config.RegisterAliases[string(word)]; !alias {
+				return string(word)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package TemplateParser
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// mnemonicSlot is the mnemonic slot of the templates GenerateLine is tested with.
+var mnemonicSlot = TemplateObject{TemplateType: TokenIdentifier, TemplateValue: StringObject("op", "")}
+
+func TestGenerateLineParses(t *testing.T) {
+	templates := [][]TemplateObject{
+		{mnemonicSlot, {TemplateType: TokenRegister}, {TemplateType: TokenComma}, {TemplateType: TokenUint8, MinValue: 3, MaxValue: 9}},
+		{mnemonicSlot, {TemplateType: TokenUint32}, {TemplateType: TokenComma}, {TemplateType: TokenQuotedString}},
+		{mnemonicSlot, {TemplateType: TokenLabelRef}, {TemplateType: TokenComma}, {TemplateType: TokenChar}},
+		{mnemonicSlot, {TemplateType: TokenEnum, Choices: []string{"eq", "ne"}}, {TemplateType: TokenComma}, {TemplateType: TokenBoolean}},
+		{mnemonicSlot, {TemplateType: TokenRegister}, {TemplateType: TokenComma}, {TemplateType: TokenRegister, SameAs: 1}},
+		{mnemonicSlot, {TemplateType: TokenRegister}, {TemplateType: TokenComma}, {TemplateType: TokenRegister, DifferentFrom: 1}},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tmpl := range templates {
+		for range 50 {
+			line := GenerateLine(tmpl, rng)
+			objs, ok, errmsg := ParseLine(line, tmpl)
+			if !ok {
+				t.Errorf("generated line %q does not parse: %s", line, errmsg)
+				continue
+			}
+			if objs[0].ObjectValue != "op" {
+				t.Errorf("generated line %q has mnemonic %v", line, objs[0].ObjectValue)
+			}
+			if slot := tmpl[3]; slot.HasRange() {
+				if val := objs[3].ObjectValue.(uint64); val < slot.MinValue || val > slot.MaxValue {
+					t.Errorf("generated line %q is outside the range of its slot", line)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateLineIsDeterministic(t *testing.T) {
+	tmpl := []TemplateObject{mnemonicSlot, {TemplateType: TokenRegister}, {TemplateType: TokenComma}, {TemplateType: TokenUint16}}
+	first, second := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+	for range 10 {
+		if a, b := GenerateLine(tmpl, first), GenerateLine(tmpl, second); a != b {
+			t.Fatalf("the same seed generated %q and %q", a, b)
+		}
+	}
+}
+
+func TestGenerateLineUnfillable(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, tmpl := range [][]TemplateObject{
+		{{TemplateType: TokenLabelDef}, mnemonicSlot},
+		{mnemonicSlot, {TemplateType: TokenMacro}},
+		{mnemonicSlot, {TemplateType: TokenUint8, MinValue: 9, MaxValue: 3}},
+	} {
+		if line := GenerateLine(tmpl, rng); line != "" {
+			t.Errorf("GenerateLine(%v) = %q, want \"\"", tmpl, line)
+		}
+	}
+}
@@ -0,0 +1,540 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GrammarOperand
+// is the data file form of a TemplateObject. Type is a token type name such as "Register".
+// Operator names the operator an Operator slot must hold; without it any operator matches.
+// Choices lists the keywords an Enum slot accepts and Flags the flags of a Flags slot.
+type GrammarOperand struct {
+	Type          string   `json:"type" yaml:"type"`
+	Descriptor    string   `json:"descriptor,omitempty" yaml:"descriptor,omitempty"`
+	Error         string   `json:"error,omitempty" yaml:"error,omitempty"`
+	Min           uint64   `json:"min,omitempty" yaml:"min,omitempty"`
+	Max           uint64   `json:"max,omitempty" yaml:"max,omitempty"`
+	Sensitive     bool     `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	Group         string   `json:"group,omitempty" yaml:"group,omitempty"`
+	Name          string   `json:"name,omitempty" yaml:"name,omitempty"`
+	SameAs        int      `json:"same_as,omitempty" yaml:"same_as,omitempty"`
+	DifferentFrom int      `json:"different_from,omitempty" yaml:"different_from,omitempty"`
+	Operator      string   `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Choices       []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+	Flags         []Flag   `json:"flags,omitempty" yaml:"flags,omitempty"`
+}
+
+// GrammarGuard
+// is the data file form of a Guard.
+type GrammarGuard struct {
+	Expr  string `json:"expr" yaml:"expr"`
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// GrammarField
+// is the data file form of a BitField.
+type GrammarField struct {
+	Slot   int    `json:"slot,omitempty" yaml:"slot,omitempty"`
+	Offset int    `json:"offset" yaml:"offset"`
+	Width  int    `json:"width" yaml:"width"`
+	Value  uint64 `json:"value,omitempty" yaml:"value,omitempty"`
+	Signed bool   `json:"signed,omitempty" yaml:"signed,omitempty"`
+}
+
+// GrammarEncoding
+// is the data file form of an Encoding.
+type GrammarEncoding struct {
+	Width     int            `json:"width,omitempty" yaml:"width,omitempty"`
+	Fields    []GrammarField `json:"fields" yaml:"fields"`
+	BigEndian bool           `json:"big_endian,omitempty" yaml:"big_endian,omitempty"`
+}
+
+// GrammarTemplate
+// is the data file form of a TemplateEntry.
+type GrammarTemplate struct {
+	Mnemonic    string            `json:"mnemonic" yaml:"mnemonic"`
+	Operands    []GrammarOperand  `json:"operands" yaml:"operands"`
+	Profile     string            `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Requires    map[string]string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	Unavailable string            `json:"unavailable,omitempty" yaml:"unavailable,omitempty"`
+	Size        uint64            `json:"size,omitempty" yaml:"size,omitempty"`
+	Cycles      int               `json:"cycles,omitempty" yaml:"cycles,omitempty"`
+	Guards      []GrammarGuard    `json:"guards,omitempty" yaml:"guards,omitempty"`
+	Encoding    *GrammarEncoding  `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Order       []int             `json:"order,omitempty" yaml:"order,omitempty"`
+}
+
+// GrammarDeprecation
+// is the data file form of a TokenDeprecation. Type is a token type name.
+type GrammarDeprecation struct {
+	Type    string `json:"type" yaml:"type"`
+	Since   string `json:"since,omitempty" yaml:"since,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// GrammarFile
+// is the top level of a JSON or YAML grammar definition. Version, Operators, Booleans,
+// NumberSuffixes, DeprecatedTokens and Profiles configure the Parser loading the file; the
+// package-level loaders, which return entries without a parser, ignore them.
+type GrammarFile struct {
+	Version          string               `json:"version,omitempty" yaml:"version,omitempty"`
+	Operators        []string             `json:"operators,omitempty" yaml:"operators,omitempty"`
+	Booleans         map[string]bool      `json:"booleans,omitempty" yaml:"booleans,omitempty"`
+	NumberSuffixes   map[string]uint64    `json:"number_suffixes,omitempty" yaml:"number_suffixes,omitempty"`
+	DeprecatedTokens []GrammarDeprecation `json:"deprecated_tokens,omitempty" yaml:"deprecated_tokens,omitempty"`
+	Profiles         []Profile            `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	Templates        []GrammarTemplate    `json:"templates" yaml:"templates"`
+}
+
+// LoadTemplatesFromJSON
+// reads a JSON grammar definition and returns its template entries. Token type names
+// are resolved against the built-in types; use Parser.LoadTemplatesFromJSON for grammars
+// that use custom token types.
+func LoadTemplatesFromJSON(r io.Reader) ([]TemplateEntry, error) {
+	return DefaultParserConfig().loadTemplates(r, decodeJSON)
+}
+
+// LoadTemplatesFromYAML
+// reads a YAML grammar definition and returns its template entries.
+func LoadTemplatesFromYAML(r io.Reader) ([]TemplateEntry, error) {
+	return DefaultParserConfig().loadTemplates(r, decodeYAML)
+}
+
+// ExportTemplatesJSON
+// writes template entries as a JSON grammar definition that LoadTemplatesFromJSON reads back.
+func ExportTemplatesJSON(w io.Writer, entries []TemplateEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(DefaultParserConfig().grammarFile(entries))
+}
+
+// ExportTemplatesYAML
+// writes template entries as a YAML grammar definition that LoadTemplatesFromYAML reads back.
+func ExportTemplatesYAML(w io.Writer, entries []TemplateEntry) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(DefaultParserConfig().grammarFile(entries)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// LoadTemplatesFromJSON
+// reads a JSON grammar definition, resolving token type names against the parser's
+// built-in and custom types and substituting its grammar parameters (see
+// InterpolateGrammar), and registers every template it defines.
+func (p *Parser) LoadTemplatesFromJSON(r io.Reader) error {
+	return p.loadAndRegister(r, decodeJSON)
+}
+
+// LoadTemplatesFromYAML
+// reads a YAML grammar definition and registers every template it defines.
+func (p *Parser) LoadTemplatesFromYAML(r io.Reader) error {
+	return p.loadAndRegister(r, decodeYAML)
+}
+
+// Entries
+// returns every registered template entry, grouped by mnemonic in registration order.
+func (p *Parser) Entries() []TemplateEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.entries()
+}
+
+// entries
+// implements Entries.
+func (p *Parser) entries() []TemplateEntry {
+	entries := make([]TemplateEntry, 0, len(p.mnemonics))
+	for _, name := range p.mnemonics {
+		for _, entry := range p.registry[name] {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// ExportTemplatesJSON
+// writes the parser's registered templates as a JSON grammar definition.
+func (p *Parser) ExportTemplatesJSON(w io.Writer) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p.grammarFile())
+}
+
+// grammarFile
+// returns the data file form of the parser's registered templates, grammar version,
+// profiles and token deprecations.
+func (p *Parser) grammarFile() GrammarFile {
+	gf := p.config.grammarFile(p.entries())
+	gf.Version = p.grammarVersion
+	if len(p.profileOrder) > 0 {
+		gf.Profiles = p.definedProfiles()
+	}
+	for _, deprecation := range p.tokenDeprecations() {
+		gf.DeprecatedTokens = append(gf.DeprecatedTokens, GrammarDeprecation{
+			p.config.tokenName(deprecation.Type), deprecation.Since, deprecation.Message})
+	}
+	return gf
+}
+
+// loadAndRegister
+// loads a grammar with the parser's token types and grammar parameters and registers its
+// entries.
+func (p *Parser) loadAndRegister(r io.Reader, decode grammarDecoder) error {
+	p.mu.RLock()
+	params := make(map[string]string, len(p.grammarParams))
+	for name, value := range p.grammarParams {
+		params[name] = value
+	}
+	p.mu.RUnlock()
+	r, err := InterpolateGrammar(r, params)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var gf GrammarFile
+	if err := decode(data, &gf); err != nil {
+		return err
+	}
+	return locateGrammarError(data, p.registerGrammar(gf))
+}
+
+// registerGrammar
+// applies the operators, boolean keywords, number suffixes, deprecations and profiles of a
+// decoded grammar file and registers its templates, reporting failures as a GrammarError
+// on the value responsible. Profiles the parser already defines identically, as when
+// several files of one grammar repeat them, are kept. The whole file is applied to a
+// staged copy of the parser's grammar first, and the parser takes on the result only if
+// every part of it is valid, so a failing file changes nothing and lines being matched
+// never see part of a file.
+func (p *Parser) registerGrammar(gf GrammarFile) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	staged := p.stage()
+	if err := staged.applyGrammar(gf); err != nil {
+		return err
+	}
+	p.adopt(staged)
+	return nil
+}
+
+// stage
+// returns a parser holding a copy of the grammar of p, its configuration, templates,
+// profiles and deprecations, for a grammar file to be applied to before p takes it on.
+// The caller holds the parser's lock.
+func (p *Parser) stage() *Parser {
+	staged := &Parser{
+		config:         p.config,
+		lexer:          p.lexer,
+		registry:       make(map[string][]*TemplateEntry, len(p.registry)),
+		mnemonics:      append([]string(nil), p.mnemonics...),
+		profileOrder:   append([]string(nil), p.profileOrder...),
+		frozen:         p.frozen,
+		grammarVersion: p.grammarVersion,
+		duplicates:     append([]TemplateIssue(nil), p.duplicates...),
+		constants:      p.constants,
+	}
+	for key, variants := range p.registry {
+		staged.registry[key] = append([]*TemplateEntry(nil), variants...)
+	}
+	if p.profiles != nil {
+		staged.profiles = make(map[string]Profile, len(p.profiles))
+		for name, profile := range p.profiles {
+			staged.profiles[name] = profile
+		}
+	}
+	if p.deprecations != nil {
+		staged.deprecations = make(map[int]TokenDeprecation, len(p.deprecations))
+		for tt, deprecation := range p.deprecations {
+			staged.deprecations[tt] = deprecation
+		}
+	}
+	return staged
+}
+
+// adopt
+// replaces the grammar of p with that of a staged parser at once. The caller holds the
+// parser's lock.
+func (p *Parser) adopt(staged *Parser) {
+	p.invalidateCache()
+	p.config, p.lexer = staged.config, staged.lexer
+	p.registry, p.mnemonics = staged.registry, staged.mnemonics
+	p.profiles, p.profileOrder = staged.profiles, staged.profileOrder
+	p.grammarVersion, p.deprecations, p.duplicates = staged.grammarVersion, staged.deprecations, staged.duplicates
+}
+
+// applyGrammar
+// implements registerGrammar on a staged parser.
+func (p *Parser) applyGrammar(gf GrammarFile) error {
+	for idx, op := range gf.Operators {
+		if err := p.AddOperator(op); err != nil {
+			return grammarErrorf(fmt.Sprintf("operators[%d]", idx), "%v", err)
+		}
+	}
+	words := make([]string, 0, len(gf.Booleans))
+	for word := range gf.Booleans {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	for _, word := range words {
+		if err := p.AddBooleanKeyword(word, gf.Booleans[word]); err != nil {
+			return grammarErrorf("booleans."+word, "%v", err)
+		}
+	}
+	for _, suffix := range sortedSuffixes(gf.NumberSuffixes) {
+		if err := p.AddNumberSuffix(suffix, gf.NumberSuffixes[suffix]); err != nil {
+			return grammarErrorf("number_suffixes."+suffix, "%v", err)
+		}
+	}
+	for idx, gd := range gf.DeprecatedTokens {
+		tt, found := p.config.tokenTypeByName(gd.Type)
+		if !found {
+			return grammarErrorf(fmt.Sprintf("deprecated_tokens[%d].type", idx), "deprecated token: unknown type %q", gd.Type)
+		}
+		if err := p.DeprecateTokenType(TokenDeprecation{tt, gd.Since, gd.Message}); err != nil {
+			return grammarErrorf(fmt.Sprintf("deprecated_tokens[%d]", idx), "%v", err)
+		}
+	}
+	defined := make(map[string]Profile)
+	for _, profile := range p.Profiles() {
+		defined[profile.Name] = profile
+	}
+	for idx, profile := range gf.Profiles {
+		if existing, found := defined[profile.Name]; found && existing == profile {
+			continue
+		}
+		if ok, errmsg := p.DefineProfile(profile.Name, profile.Extends); !ok {
+			return grammarErrorf(fmt.Sprintf("profiles[%d]", idx), "%s", errmsg)
+		}
+	}
+	if gf.Version != "" {
+		p.mu.Lock()
+		p.grammarVersion = gf.Version
+		p.mu.Unlock()
+	}
+	entries, err := p.config.GrammarEntries(gf)
+	if err != nil {
+		return err
+	}
+	for idx, entry := range entries {
+		if ok, errmsg := p.RegisterEntry(entry); !ok {
+			return grammarErrorf(fmt.Sprintf("templates[%d]", idx), "%s", errmsg)
+		}
+	}
+	return nil
+}
+
+// grammarDecoder
+// validates the text of a grammar file and decodes it.
+type grammarDecoder func(data []byte, gf *GrammarFile) error
+
+// decodeJSON
+// validates a JSON grammar file against GrammarSchema and decodes it.
+func decodeJSON(data []byte, gf *GrammarFile) error {
+	if err := validateGrammarJSON(data); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(gf)
+}
+
+// decodeYAML
+// validates a YAML grammar file against GrammarSchema and decodes it.
+func decodeYAML(data []byte, gf *GrammarFile) error {
+	if err := validateGrammarYAML(data); err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(gf)
+}
+
+// loadTemplates
+// decodes a grammar file and converts it into template entries, locating compilation
+// errors in the file.
+func (config ParserConfig) loadTemplates(r io.Reader, decode grammarDecoder) ([]TemplateEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var gf GrammarFile
+	if err := decode(data, &gf); err != nil {
+		return nil, err
+	}
+	entries, err := config.GrammarEntries(gf)
+	return entries, locateGrammarError(data, err)
+}
+
+// GrammarEntries
+// converts a decoded grammar file into template entries, resolving token type names
+// with this configuration. Errors are a *GrammarError naming the offending value; the
+// loaders reading grammar files fill in its position in the file.
+func (config ParserConfig) GrammarEntries(gf GrammarFile) ([]TemplateEntry, error) {
+	entries := make([]TemplateEntry, 0, len(gf.Templates))
+	for tIdx, gt := range gf.Templates {
+		if gt.Mnemonic == "" {
+			return nil, grammarErrorf(fmt.Sprintf("templates[%d].mnemonic", tIdx), "template %d: missing mnemonic", tIdx)
+		}
+		objects := make([]TemplateObject, len(gt.Operands))
+		for oIdx, op := range gt.Operands {
+			tt, found := config.tokenTypeByName(op.Type)
+			if !found {
+				return nil, grammarErrorf(fmt.Sprintf("templates[%d].operands[%d].type", tIdx, oIdx),
+					"template %s operand %d: unknown type %q", gt.Mnemonic, oIdx, op.Type)
+			}
+			var value interface{}
+			if op.Operator != "" {
+				if tt != TokenOperator {
+					return nil, grammarErrorf(fmt.Sprintf("templates[%d].operands[%d].operator", tIdx, oIdx),
+						"template %s operand %d: operator set on a %s slot", gt.Mnemonic, oIdx, op.Type)
+				}
+				value = op.Operator
+			}
+			if len(op.Choices) > 0 && tt != TokenEnum {
+				return nil, grammarErrorf(fmt.Sprintf("templates[%d].operands[%d].choices", tIdx, oIdx),
+					"template %s operand %d: choices set on a %s slot", gt.Mnemonic, oIdx, op.Type)
+			}
+			if len(op.Flags) > 0 && tt != TokenFlags {
+				return nil, grammarErrorf(fmt.Sprintf("templates[%d].operands[%d].flags", tIdx, oIdx),
+					"template %s operand %d: flags set on a %s slot", gt.Mnemonic, oIdx, op.Type)
+			}
+			objects[oIdx] = TemplateObject{
+				TemplateType:  tt,
+				TemplateValue: ObjectType{ObjectDescriptor: op.Descriptor, ObjectValue: value},
+				TemplateError: op.Error,
+				MinValue:      op.Min,
+				MaxValue:      op.Max,
+				Sensitive:     op.Sensitive,
+				Group:         op.Group,
+				Name:          op.Name,
+				SameAs:        op.SameAs,
+				DifferentFrom: op.DifferentFrom,
+				Choices:       op.Choices,
+				Flags:         op.Flags,
+			}
+		}
+		var guards []Guard
+		for gIdx, gg := range gt.Guards {
+			if _, err := CompileExpr(gg.Expr); err != nil {
+				return nil, grammarErrorf(fmt.Sprintf("templates[%d].guards[%d].expr", tIdx, gIdx),
+					"template %s guard %q: %v", gt.Mnemonic, gg.Expr, err)
+			}
+			guards = append(guards, Guard{Expr: gg.Expr, Error: gg.Error})
+		}
+		entries = append(entries, TemplateEntry{
+			Name:             gt.Mnemonic,
+			Objects:          objects,
+			Profile:          gt.Profile,
+			RequiredOptions:  gt.Requires,
+			UnavailableError: gt.Unavailable,
+			Size:             gt.Size,
+			Cycles:           gt.Cycles,
+			Guards:           guards,
+			Encoding:         gt.Encoding.encoding(),
+			Order:            gt.Order,
+		})
+	}
+	return entries, nil
+}
+
+// grammarFile
+// converts template entries into their data file form.
+func (config ParserConfig) grammarFile(entries []TemplateEntry) GrammarFile {
+	gf := GrammarFile{Operators: config.Operators, Templates: make([]GrammarTemplate, len(entries))}
+	if config.BooleanKeywords != nil {
+		gf.Booleans = config.booleanKeywords()
+	}
+	gf.NumberSuffixes = config.numberSuffixes()
+	for tIdx, entry := range entries {
+		operands := make([]GrammarOperand, len(entry.Objects))
+		for oIdx, tmpl := range entry.Objects {
+			operands[oIdx] = GrammarOperand{
+				Type:          config.tokenName(tmpl.TemplateType),
+				Descriptor:    tmpl.TemplateValue.ObjectDescriptor,
+				Error:         tmpl.TemplateError,
+				Min:           tmpl.MinValue,
+				Max:           tmpl.MaxValue,
+				Sensitive:     tmpl.Sensitive,
+				Group:         tmpl.Group,
+				Name:          tmpl.Name,
+				SameAs:        tmpl.SameAs,
+				DifferentFrom: tmpl.DifferentFrom,
+				Choices:       tmpl.Choices,
+				Flags:         tmpl.Flags,
+			}
+			if tmpl.TemplateType == TokenOperator {
+				operands[oIdx].Operator, _ = tmpl.TemplateValue.ObjectValue.(string)
+			}
+		}
+		var guards []GrammarGuard
+		for _, guard := range entry.Guards {
+			guards = append(guards, GrammarGuard{guard.Expr, guard.Error})
+		}
+		gf.Templates[tIdx] = GrammarTemplate{
+			Mnemonic:    entry.Name,
+			Operands:    operands,
+			Profile:     entry.Profile,
+			Requires:    entry.RequiredOptions,
+			Unavailable: entry.UnavailableError,
+			Size:        entry.Size,
+			Cycles:      entry.Cycles,
+			Guards:      guards,
+			Encoding:    grammarEncoding(entry.Encoding),
+			Order:       entry.Order,
+		}
+	}
+	return gf
+}
+
+// encoding
+// converts the data file form of an encoding, nil if the template has none.
+func (ge *GrammarEncoding) encoding() *Encoding {
+	if ge == nil {
+		return nil
+	}
+	enc := &Encoding{Width: ge.Width, BigEndian: ge.BigEndian, Fields: make([]BitField, len(ge.Fields))}
+	for idx, gf := range ge.Fields {
+		enc.Fields[idx] = BitField{gf.Slot, gf.Offset, gf.Width, gf.Value, gf.Signed}
+	}
+	return enc
+}
+
+// grammarEncoding
+// converts an encoding into its data file form.
+func grammarEncoding(enc *Encoding) *GrammarEncoding {
+	if enc == nil {
+		return nil
+	}
+	ge := &GrammarEncoding{Width: enc.Width, BigEndian: enc.BigEndian, Fields: make([]GrammarField, len(enc.Fields))}
+	for idx, field := range enc.Fields {
+		ge.Fields[idx] = GrammarField{field.Slot, field.Offset, field.Width, field.Value, field.Signed}
+	}
+	return ge
+}
+
+// tokenTypeByName
+// resolves a built-in or custom token type name to its id. TokenUnknown is left out, as
+// no template slot can take it.
+func (config ParserConfig) tokenTypeByName(name string) (int, bool) {
+	for _, custom := range config.customTokens {
+		if custom.Name == name {
+			return custom.Id, true
+		}
+	}
+	if id, found := builtinRegistry.ByName(name); found && id != TokenUnknown {
+		return id, true
+	}
+	return 0, false
+}
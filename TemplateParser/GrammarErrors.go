@@ -0,0 +1,166 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GrammarError
+// is a grammar definition that could not be compiled into templates, located in the file
+// it was read from. Path names the value the error concerns in the form SchemaError uses,
+// e.g. templates[2].operands[0].type, and Key is its last property name. Line and Column
+// are the 1-based position of the value in the grammar text after parameter substitution,
+// or 0 when it was not read from a file.
+type GrammarError struct {
+	Path    string
+	Key     string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error
+// formats the error as line:column: message (path), leaving out what is not known.
+func (ge *GrammarError) Error() string {
+	var sb strings.Builder
+	if ge.Line > 0 {
+		fmt.Fprintf(&sb, "%d:%d: ", ge.Line, ge.Column)
+	}
+	sb.WriteString(ge.Message)
+	if ge.Path != "" {
+		fmt.Fprintf(&sb, " (%s)", ge.Path)
+	}
+	return sb.String()
+}
+
+// grammarErrorf
+// builds the error of a grammar value at path, not yet located.
+func grammarErrorf(path string, format string, args ...interface{}) *GrammarError {
+	return &GrammarError{Path: path, Key: pathKey(path), Message: fmt.Sprintf(format, args...)}
+}
+
+// pathKey
+// returns the last property name of a value path, "" if it ends in an index.
+func pathKey(path string) string {
+	if cut := strings.LastIndexAny(path, ".]"); cut >= 0 {
+		if path[cut] == ']' {
+			return ""
+		}
+		return path[cut+1:]
+	}
+	return path
+}
+
+// grammarNode
+// parses grammar text, JSON or YAML, into a YAML node tree to look positions up in; nil if
+// the text does not parse.
+func grammarNode(data []byte) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// locatePath
+// finds the node of the value at a path, or of the deepest value on the path that exists.
+// If key is set and names a property of that value, its key node is returned instead.
+func locatePath(root *yaml.Node, path string, key string) *yaml.Node {
+	node := root
+	for _, step := range splitPath(path) {
+		next := childNode(node, step)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	if key != "" && node.Kind == yaml.MappingNode {
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			if node.Content[idx].Value == key {
+				return node.Content[idx]
+			}
+		}
+	}
+	return node
+}
+
+// splitPath
+// splits a value path into property names and [index] steps.
+func splitPath(path string) []string {
+	var steps []string
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			cut := strings.IndexByte(part[1:], '[') + 1
+			if cut == 0 {
+				cut = len(part)
+			}
+			steps = append(steps, part[:cut])
+			part = part[cut:]
+		}
+	}
+	return steps
+}
+
+// childNode
+// returns the value of a property or the item at an [index] of a node, nil if it has none.
+func childNode(node *yaml.Node, step string) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if strings.HasPrefix(step, "[") {
+		idx, err := strconv.Atoi(strings.TrimSuffix(step[1:], "]"))
+		if node.Kind != yaml.SequenceNode || err != nil || idx < 0 || idx >= len(node.Content) {
+			return nil
+		}
+		return node.Content[idx]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == step {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+// locateGrammarError
+// fills in the line and column of a GrammarError or of every SchemaError in err from the
+// grammar text it concerns. Other errors are returned unchanged.
+func locateGrammarError(data []byte, err error) error {
+	var ge *GrammarError
+	var schemaErrs SchemaErrors
+	switch {
+	case errors.As(err, &ge):
+		if ge.Line == 0 {
+			if root := grammarNode(data); root != nil {
+				node := locatePath(root, ge.Path, "")
+				ge.Line, ge.Column = node.Line, node.Column
+			}
+		}
+	case errors.As(err, &schemaErrs):
+		if root := grammarNode(data); root != nil {
+			for idx := range schemaErrs {
+				node := locatePath(root, schemaErrs[idx].Path, schemaErrs[idx].key)
+				schemaErrs[idx].Line, schemaErrs[idx].Column = node.Line, node.Column
+			}
+		}
+	}
+	return err
+}
+
+// offsetPosition
+// converts a byte offset in text into a 1-based line and character column.
+func offsetPosition(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	start := bytes.LastIndexByte(before, '\n') + 1
+	return line, len(bytes.Runes(before[start:])) + 1
+}
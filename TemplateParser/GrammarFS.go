@@ -0,0 +1,58 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// LoadTemplatesFS
+// reads a grammar definition from a file system, such as an embed.FS, so applications can
+// ship their grammar inside the binary. The format is chosen by the file extension:
+// .json, or .yaml and .yml.
+func LoadTemplatesFS(fsys fs.FS, name string) ([]TemplateEntry, error) {
+	return DefaultParserConfig().loadTemplatesFS(fsys, name)
+}
+
+// LoadTemplatesFS
+// reads a grammar definition from a file system and registers every template it defines.
+func (p *Parser) LoadTemplatesFS(fsys fs.FS, name string) error {
+	decode, err := grammarFormat(name)
+	if err != nil {
+		return err
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return p.loadAndRegister(f, decode)
+}
+
+// loadTemplatesFS
+// opens a grammar file in a file system and converts it into template entries.
+func (config ParserConfig) loadTemplatesFS(fsys fs.FS, name string) ([]TemplateEntry, error) {
+	decode, err := grammarFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return config.loadTemplates(f, decode)
+}
+
+// grammarFormat
+// picks the decoder for a grammar file from its extension.
+func grammarFormat(name string) (grammarDecoder, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return decodeJSON, nil
+	case ".yaml", ".yml":
+		return decodeYAML, nil
+	}
+	return nil, fmt.Errorf("%s: unknown grammar file extension", name)
+}
@@ -0,0 +1,184 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// InterpolateGrammar
+// substitutes load-time parameters into the text of a grammar definition, so one file can
+// describe a family of related targets. ${name} is replaced by the value of parameter name,
+// ${name:-text} falls back to text when the parameter is not set, and $${ produces a literal
+// ${. Referring to a parameter that is neither set nor defaulted is an error. The result
+// can be passed to any of the grammar loaders.
+//
+// Values are written in the form their place in the text needs, as YAML and JSON read it,
+// so a value cannot change the structure of the grammar: escaped inside double- and
+// single-quoted strings, as they are elsewhere if they are made only of letters, digits and
+// _ . + - /, such as a register count or word size, and as a double-quoted string
+// otherwise. References in comments are left as they are.
+func InterpolateGrammar(r io.Reader, params map[string]string) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text, err := interpolate(string(data), params)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(text), nil
+}
+
+// EnvParams
+// returns grammar parameters holding the values of the listed environment variables that
+// are set. Only the listed variables are read.
+func EnvParams(names ...string) map[string]string {
+	params := make(map[string]string, len(names))
+	for _, name := range names {
+		if val, found := os.LookupEnv(name); found {
+			params[name] = val
+		}
+	}
+	return params
+}
+
+// SetGrammarParam
+// sets a parameter substituted into grammar files loaded by the parser's loaders.
+func (p *Parser) SetGrammarParam(name, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.grammarParams == nil {
+		p.grammarParams = make(map[string]string)
+	}
+	p.grammarParams[name] = value
+}
+
+// GrammarParam
+// returns a grammar parameter and whether it is set.
+func (p *Parser) GrammarParam(name string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	val, found := p.grammarParams[name]
+	return val, found
+}
+
+// interpolate
+// implements InterpolateGrammar on a string, keeping track of the quoted string, if any,
+// each reference is in.
+func interpolate(text string, params map[string]string) (string, error) {
+	var sb strings.Builder
+	line, quote := 1, byte(0)
+	for idx := 0; idx < len(text); idx++ {
+		c := text[idx]
+		if c == '\n' {
+			line++
+		}
+		switch {
+		case quote == '"' && c == '\\' && idx+1 < len(text):
+			if text[idx+1] == '\n' {
+				line++
+			}
+			sb.WriteString(text[idx : idx+2])
+			idx++
+			continue
+		case quote == '\'' && c == '\'' && strings.HasPrefix(text[idx:], "''"):
+			sb.WriteString("''")
+			idx++
+			continue
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\'') && opensScalar(text, idx):
+			quote = c
+		case quote == 0 && c == '#' && (idx == 0 || strings.IndexByte(" \t\n", text[idx-1]) >= 0):
+			end := strings.IndexByte(text[idx:], '\n')
+			if end < 0 {
+				end = len(text) - idx
+			}
+			sb.WriteString(text[idx : idx+end])
+			idx += end - 1
+			continue
+		}
+		if c != '$' {
+			sb.WriteByte(c)
+			continue
+		}
+		if strings.HasPrefix(text[idx:], "$${") {
+			sb.WriteString("${")
+			idx += 2
+			continue
+		}
+		if !strings.HasPrefix(text[idx:], "${") {
+			sb.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(text[idx:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("line %d: unterminated ${", line)
+		}
+		ref := text[idx+2 : idx+end]
+		name, fallback, hasDefault := strings.Cut(ref, ":-")
+		if name == "" || strings.ContainsAny(name, " \t\n") {
+			return "", fmt.Errorf("line %d: invalid parameter reference ${%s}", line, ref)
+		}
+		val, found := params[name]
+		if !found {
+			if !hasDefault {
+				return "", fmt.Errorf("line %d: grammar parameter %s is not set", line, name)
+			}
+			val = fallback
+		}
+		quoted, err := quoteParam(val, quote)
+		if err != nil {
+			return "", fmt.Errorf("line %d: grammar parameter %s %v", line, name, err)
+		}
+		sb.WriteString(quoted)
+		idx += end
+	}
+	return sb.String(), nil
+}
+
+// opensScalar
+// reports whether the quote at idx starts a quoted string: whether it is the first
+// character of a line, a key or a value rather than a character within a plain one, such
+// as the apostrophe of don't.
+func opensScalar(text string, idx int) bool {
+	prev := strings.TrimRight(text[:idx], " \t")
+	return prev == "" || strings.IndexByte("\n:-[{,?", prev[len(prev)-1]) >= 0
+}
+
+// quoteParam
+// returns a parameter value in the form it takes in a string quoted with quote, or outside
+// strings if quote is 0.
+func quoteParam(val string, quote byte) (string, error) {
+	switch quote {
+	case '"':
+		return strings.TrimSuffix(strings.TrimPrefix(doubleQuoted(val), `"`), `"`), nil
+	case '\'':
+		if strings.ContainsAny(val, "\r\n") {
+			return "", errors.New("holds a line break, which a single-quoted string cannot")
+		}
+		return strings.ReplaceAll(val, "'", "''"), nil
+	}
+	if val == "" || strings.ContainsFunc(val, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_.+-/", r)
+	}) {
+		return doubleQuoted(val), nil
+	}
+	return val, nil
+}
+
+// doubleQuoted
+// returns a string as a double-quoted JSON string, which YAML reads the same way.
+func doubleQuoted(val string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(val)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,67 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	params := map[string]string{"n": "15", "msg": `bad: "x" \ y`, "quote": "it's", "any": "{a: 1}"}
+	tests := []struct {
+		text string
+		want string
+		err  string
+	}{
+		{"max: ${n}", "max: 15", ""},
+		{"max: ${unset:-7}", "max: 7", ""},
+		{"{max: ${n}, error: ${msg}}", `{max: 15, error: "bad: \"x\" \\ y"}`, ""},
+		{`error: "${msg}"`, `error: "bad: \"x\" \\ y"`, ""},
+		{"error: '${quote}'", "error: 'it''s'", ""},
+		{"error: don't ${any}", `error: don't "{a: 1}"`, ""},
+		{"error: '' ${n}", "error: '' 15", ""},
+		{`"max": ${n}, "error": "${msg}"`, `"max": 15, "error": "bad: \"x\" \\ y"`, ""},
+		{"max: 1 # was ${n} or ${unset}", "max: 1 # was ${n} or ${unset}", ""},
+		{"text: $${n}", "text: ${n}", ""},
+		{"max: ${unset}", "", "line 1: grammar parameter unset is not set"},
+		{"a: 1\nmax: ${n", "", "line 2: unterminated ${"},
+	}
+	for _, tt := range tests {
+		got, err := interpolate(tt.text, params)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("interpolate(%q) error = %v, want %q", tt.text, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("interpolate(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestGrammarParamsCannotChangeStructure(t *testing.T) {
+	grammar := `templates:
+  - mnemonic: li
+    operands:
+      - {type: Identifier}
+      - {type: Register, max: ${regs}, error: ${msg}}
+      - {type: Comma}
+      - {type: Uint8}
+`
+	p := NewParser(DefaultParserConfig())
+	p.SetGrammarParam("regs", "7")
+	p.SetGrammarParam("msg", "x}, {type: Comma")
+	if err := p.LoadTemplatesFromYAML(strings.NewReader(grammar)); err != nil {
+		t.Fatal(err)
+	}
+	entries := p.Entries()
+	if len(entries) != 1 || len(entries[0].Objects) != 4 {
+		t.Fatalf("Entries() = %+v, want li with 4 slots", entries)
+	}
+	if slot := entries[0].Objects[1]; slot.MaxValue != 7 || slot.TemplateError != "x}, {type: Comma" {
+		t.Errorf("register slot = max %d, error %q", slot.MaxValue, slot.TemplateError)
+	}
+	if _, ok, _ := p.ParseLine("li r8, 1"); ok {
+		t.Errorf("li r8 matched above the parameterized maximum")
+	}
+}
@@ -0,0 +1,429 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GrammarSchemaID is the identifier of GrammarSchema.
+const GrammarSchemaID = "https://github.com/jantypas/TemplateParser/grammar.schema.json"
+
+// GrammarSchema
+// is the JSON Schema for JSON and YAML grammar definition files. Editors can use it to
+// offer completion and validation; a copy is kept in grammar.schema.json at the root of
+// the repository. The loaders check every file against it before converting it, so schema
+// errors carry the path of the offending value.
+const GrammarSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/jantypas/TemplateParser/grammar.schema.json",
+  "title": "TemplateParser grammar",
+  "type": "object",
+  "required": ["templates"],
+  "additionalProperties": false,
+  "properties": {
+    "version": {"type": "string", "minLength": 1, "description": "Grammar version, compared with the since version of token deprecations"},
+    "deprecated_tokens": {
+      "type": "array",
+      "description": "Token classes that produce warnings when used",
+      "items": {
+        "type": "object",
+        "required": ["type"],
+        "additionalProperties": false,
+        "properties": {
+          "type": {"type": "string", "minLength": 1, "description": "Token type name"},
+          "since": {"type": "string", "description": "Grammar version the deprecation starts at"},
+          "message": {"type": "string", "description": "Text added to the warning, such as the replacement syntax"}
+        }
+      }
+    },
+    "operators": {
+      "type": "array",
+      "description": "Operators added to the tokenizer, such as -> or ::",
+      "items": {"type": "string", "minLength": 1}
+    },
+    "booleans": {
+      "type": "object",
+      "description": "Words read as Boolean tokens besides true and false, such as on and off, with their values",
+      "additionalProperties": {"type": "boolean"}
+    },
+    "number_suffixes": {
+      "type": "object",
+      "description": "Suffixes of scaled hex literals, such as k in 10k, with the value they multiply by",
+      "additionalProperties": {"type": "integer", "minimum": 1}
+    },
+    "profiles": {
+      "type": "array",
+      "description": "Named template sets, such as revisions of an instruction set, selected with .arch",
+      "items": {
+        "type": "object",
+        "required": ["name"],
+        "additionalProperties": false,
+        "properties": {
+          "name": {"type": "string", "minLength": 1, "description": "Profile name"},
+          "extends": {"type": "string", "minLength": 1, "description": "Earlier profile whose templates this one inherits"}
+        }
+      }
+    },
+    "templates": {
+      "type": "array",
+      "description": "Template entries, one per mnemonic variant",
+      "items": {
+        "type": "object",
+        "required": ["mnemonic", "operands"],
+        "additionalProperties": false,
+        "properties": {
+          "mnemonic": {"type": "string", "minLength": 1, "description": "Mnemonic the template is registered under"},
+          "profile": {"type": "string", "minLength": 1, "description": "Profile the template belongs to; templates without one are shared by every profile"},
+          "operands": {
+            "type": "array",
+            "description": "Slots of the line, starting with the Identifier slot for the mnemonic",
+            "items": {
+              "type": "object",
+              "required": ["type"],
+              "additionalProperties": false,
+              "properties": {
+                "type": {"type": "string", "minLength": 1, "description": "Token type name, such as Register or Uint8"},
+                "descriptor": {"type": "string", "description": "Name of the slot"},
+                "error": {"type": "string", "description": "Message reported when the slot does not match"},
+                "min": {"type": "integer", "minimum": 0, "description": "Smallest allowed value"},
+                "max": {"type": "integer", "minimum": 0, "description": "Largest allowed value"},
+                "sensitive": {"type": "boolean", "description": "Redact the value in output"},
+                "group": {"type": "string", "description": "Capture group of the slot"},
+                "name": {"type": "string", "description": "Key of the slot in the named objects of a parsed line"},
+                "same_as": {"type": "integer", "minimum": 0, "description": "Earlier slot whose value this operand must repeat"},
+                "different_from": {"type": "integer", "minimum": 0, "description": "Earlier slot whose value this operand must not repeat"},
+                "operator": {"type": "string", "minLength": 1, "description": "Operator an Operator slot must hold"},
+                "choices": {
+                  "type": "array",
+                  "description": "Keywords an Enum slot accepts",
+                  "items": {"type": "string", "minLength": 1}
+                },
+                "flags": {
+                  "type": "array",
+                  "description": "Flags a Flags slot accepts, joined by | in operands",
+                  "items": {
+                    "type": "object",
+                    "required": ["name", "value"],
+                    "additionalProperties": false,
+                    "properties": {
+                      "name": {"type": "string", "minLength": 1},
+                      "value": {"type": "integer", "minimum": 0}
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "requires": {
+            "type": "object",
+            "description": "Target options that must have the given values",
+            "additionalProperties": {"type": "string"}
+          },
+          "unavailable": {"type": "string", "description": "Message reported when the entry is gated off"},
+          "size": {"type": "integer", "minimum": 0, "description": "Encoded size in bytes"},
+          "cycles": {"type": "integer", "minimum": 0, "description": "Cycle count"},
+          "order": {
+            "type": "array",
+            "description": "Operand slots in the order lines write them, starting with 0",
+            "items": {"type": "integer", "minimum": 0}
+          },
+          "guards": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "required": ["expr"],
+              "additionalProperties": false,
+              "properties": {
+                "expr": {"type": "string", "minLength": 1, "description": "Guard expression"},
+                "error": {"type": "string", "description": "Message reported when the guard is false"}
+              }
+            }
+          },
+          "encoding": {
+            "type": "object",
+            "required": ["fields"],
+            "additionalProperties": false,
+            "description": "Bit layout of the instruction word",
+            "properties": {
+              "width": {"type": "integer", "minimum": 1, "maximum": 64, "description": "Word size in bits, size times eight by default"},
+              "big_endian": {"type": "boolean", "description": "Write the most significant byte first"},
+              "fields": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "required": ["offset", "width"],
+                  "additionalProperties": false,
+                  "properties": {
+                    "slot": {"type": "integer", "minimum": 0, "description": "Slot whose value fills the field, 0 for a constant"},
+                    "offset": {"type": "integer", "minimum": 0, "description": "Bit position of the least significant bit"},
+                    "width": {"type": "integer", "minimum": 1, "maximum": 64, "description": "Field width in bits"},
+                    "value": {"type": "integer", "minimum": 0, "description": "Constant value of a slot 0 field"},
+                    "signed": {"type": "boolean", "description": "Accept negative values in two's complement"}
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// SchemaError
+// is a grammar file value that does not conform to GrammarSchema. Path locates the value,
+// e.g. templates[2].operands[0].type. Line and Column are its 1-based position in the
+// grammar text, or that of the unknown property the error names, when the error came from
+// ValidateGrammarJSON or ValidateGrammarYAML.
+type SchemaError struct {
+	Path    string
+	Message string
+	Line    int
+	Column  int
+
+	key string // Unknown property the error is about
+}
+
+// Error
+// formats the error as line:column: path: message.
+func (se SchemaError) Error() string {
+	msg := se.Message
+	if se.Path != "" {
+		msg = se.Path + ": " + msg
+	}
+	if se.Line > 0 {
+		msg = fmt.Sprintf("%d:%d: %s", se.Line, se.Column, msg)
+	}
+	return msg
+}
+
+// SchemaErrors
+// is every schema violation found in a grammar file.
+type SchemaErrors []SchemaError
+
+// Error
+// joins the violations, one per line.
+func (se SchemaErrors) Error() string {
+	msgs := make([]string, len(se))
+	for idx, err := range se {
+		msgs[idx] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var (
+	grammarSchemaOnce sync.Once
+	grammarSchema     map[string]interface{}
+)
+
+// compiledGrammarSchema
+// returns GrammarSchema decoded once.
+func compiledGrammarSchema() map[string]interface{} {
+	grammarSchemaOnce.Do(func() {
+		if err := json.Unmarshal([]byte(GrammarSchema), &grammarSchema); err != nil {
+			panic("TemplateParser: invalid grammar schema: " + err.Error())
+		}
+	})
+	return grammarSchema
+}
+
+// ValidateGrammarJSON
+// checks a JSON grammar definition against GrammarSchema, returning SchemaErrors located
+// in the text if it does not conform. Syntax errors are returned as a GrammarError.
+func ValidateGrammarJSON(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return validateGrammarJSON(data)
+}
+
+// validateGrammarJSON
+// implements ValidateGrammarJSON on the text of a grammar.
+func validateGrammarJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line, column := offsetPosition(data, syntax.Offset)
+			return &GrammarError{Line: line, Column: column, Message: err.Error()}
+		}
+		return err
+	}
+	return locateGrammarError(data, ValidateGrammar(doc))
+}
+
+// ValidateGrammarYAML
+// checks a YAML grammar definition against GrammarSchema.
+func ValidateGrammarYAML(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return validateGrammarYAML(data)
+}
+
+// validateGrammarYAML
+// implements ValidateGrammarYAML on the text of a grammar.
+func validateGrammarYAML(data []byte) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	return locateGrammarError(data, ValidateGrammar(doc))
+}
+
+// ValidateGrammar
+// checks a decoded grammar document (maps, slices and scalars as produced by encoding/json
+// or yaml) against GrammarSchema.
+func ValidateGrammar(doc interface{}) error {
+	var errs SchemaErrors
+	validateSchema(compiledGrammarSchema(), doc, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateSchema
+// checks a value against the subset of JSON Schema that GrammarSchema uses.
+func validateSchema(schema map[string]interface{}, val interface{}, path string, errs *SchemaErrors) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	want, _ := schema["type"].(string)
+	switch want {
+	case "object":
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			fail("expected an object, got %s", schemaTypeName(val))
+			return
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, found := obj[name.(string)]; !found {
+				fail("missing required property %q", name)
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinSchemaPath(path, key)
+			if sub, found := props[key].(map[string]interface{}); found {
+				validateSchema(sub, obj[key], child, errs)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					fail("unknown property %q", key)
+					(*errs)[len(*errs)-1].key = key
+				}
+			case map[string]interface{}:
+				validateSchema(extra, obj[key], child, errs)
+			}
+		}
+	case "array":
+		arr, ok := val.([]interface{})
+		if !ok {
+			fail("expected an array, got %s", schemaTypeName(val))
+			return
+		}
+		if items, found := schema["items"].(map[string]interface{}); found {
+			for idx, item := range arr {
+				validateSchema(items, item, fmt.Sprintf("%s[%d]", path, idx), errs)
+			}
+		}
+	case "string":
+		str, ok := val.(string)
+		if !ok {
+			fail("expected a string, got %s", schemaTypeName(val))
+			return
+		}
+		if minLen, found := schema["minLength"].(float64); found && float64(len(str)) < minLen {
+			fail("must not be empty")
+		}
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			fail("expected a boolean, got %s", schemaTypeName(val))
+		}
+	case "integer":
+		num, ok := schemaInteger(val)
+		if !ok {
+			fail("expected an integer, got %s", schemaTypeName(val))
+			return
+		}
+		if minimum, found := schema["minimum"].(float64); found && num < minimum {
+			fail("must be at least %v", minimum)
+		}
+		if maximum, found := schema["maximum"].(float64); found && num > maximum {
+			fail("must be at most %v", maximum)
+		}
+	}
+}
+
+// schemaInteger
+// returns the value of an integral number decoded by encoding/json or yaml.
+func schemaInteger(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return 0, false
+		}
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, v == float64(int64(v))
+	}
+	return 0, false
+}
+
+// schemaTypeName
+// names the JSON type of a decoded value for error messages.
+func schemaTypeName(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, isInt := schemaInteger(val); isInt {
+		return "integer"
+	}
+	return "number"
+}
+
+// joinSchemaPath
+// appends a property name to a value path.
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
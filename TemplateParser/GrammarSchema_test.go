@@ -0,0 +1,64 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateSchema makes TestGrammarSchemaFile rewrite grammar.schema.json from GrammarSchema:
+//
+//	go test ./TemplateParser -run TestGrammarSchemaFile -args -update-schema
+var updateSchema = flag.Bool("update-schema", false, "rewrite grammar.schema.json from GrammarSchema")
+
+func TestGrammarSchemaFile(t *testing.T) {
+	path := filepath.Join("..", "grammar.schema.json")
+	want := GrammarSchema + "\n"
+	if *updateSchema {
+		if err := os.WriteFile(path, []byte(want), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s differs from GrammarSchema; run the test with -args -update-schema to rewrite it", path)
+	}
+}
+
+func TestGrammarSchemaIsJSON(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(GrammarSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["$id"] != GrammarSchemaID {
+		t.Errorf("$id = %v, want %s", schema["$id"], GrammarSchemaID)
+	}
+}
+
+func TestValidateGrammarYAML(t *testing.T) {
+	tests := []struct {
+		grammar string
+		err     string
+	}{
+		{exprGrammar, ""},
+		{"templates:\n  - mnemonic: li\n    order: [1, 0]\n    operands:\n      - {type: Identifier}\n      - {type: Register}\n", ""},
+		{"templates:\n  - mnemonic: li\n    speed: 3\n", "speed"},
+		{"templates:\n  - mnemonic: li\n    order: [-1]\n", "order"},
+		{"version: 1\n", "templates"},
+	}
+	for _, tt := range tests {
+		err := ValidateGrammarYAML(strings.NewReader(tt.grammar))
+		if tt.err == "" && err != nil {
+			t.Errorf("ValidateGrammarYAML(%q) = %v", tt.grammar, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("ValidateGrammarYAML(%q) = %v, want an error about %s", tt.grammar, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// grammarPrefix sets every part of the parser a grammar file can configure.
+const grammarPrefix = `version: "2"
+operators: ["->"]
+booleans: {yes: true}
+number_suffixes: {k: 1024}
+deprecated_tokens:
+  - {type: Char, since: "2"}
+profiles:
+  - {name: base}
+templates:
+  - mnemonic: ld
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+`
+
+func TestLoadGrammarAppliesNothingOnError(t *testing.T) {
+	tests := []struct {
+		name  string
+		tail  string
+		error string
+	}{
+		{"unknown profile", "  - mnemonic: st\n    profile: none\n    operands:\n      - {type: Identifier}\n", "unknown profile none"},
+		{"no identifier", "  - mnemonic: st\n    operands:\n      - {type: Register}\n", "must start with an identifier"},
+		{"unknown type", "  - mnemonic: st\n    operands:\n      - {type: Identifier}\n      - {type: Nothing}\n", "Nothing"},
+	}
+	for _, tt := range tests {
+		p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+		before := p.Config()
+		mnemonics := p.Mnemonics()
+		err := p.LoadTemplatesFromYAML(strings.NewReader(grammarPrefix + tt.tail))
+		if err == nil || !strings.Contains(err.Error(), tt.error) {
+			t.Errorf("%s: error = %v, want one about %s", tt.name, err, tt.error)
+			continue
+		}
+		if got := p.Operators(); len(got) != len(before.Operators) {
+			t.Errorf("%s: operators = %v after a failed load", tt.name, got)
+		}
+		if _, found := p.BooleanKeywords()["yes"]; found {
+			t.Errorf("%s: boolean keyword yes added by a failed load", tt.name)
+		}
+		if got := p.NumberSuffixes(); len(got) != 0 {
+			t.Errorf("%s: number suffixes = %v after a failed load", tt.name, got)
+		}
+		if got := p.TokenDeprecations(); len(got) != 0 {
+			t.Errorf("%s: deprecations = %v after a failed load", tt.name, got)
+		}
+		if got := p.Profiles(); len(got) != 0 {
+			t.Errorf("%s: profiles = %v after a failed load", tt.name, got)
+		}
+		if got := p.GrammarVersion(); got != "" {
+			t.Errorf("%s: grammar version = %q after a failed load", tt.name, got)
+		}
+		if got := p.Mnemonics(); !reflect.DeepEqual(got, mnemonics) {
+			t.Errorf("%s: mnemonics = %v after a failed load, want %v", tt.name, got, mnemonics)
+		}
+	}
+}
+
+func TestLoadGrammarAppliesEverything(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	if err := p.LoadTemplatesFromYAML(strings.NewReader(grammarPrefix)); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Operators(); !reflect.DeepEqual(got, []string{"->"}) {
+		t.Errorf("operators = %v", got)
+	}
+	if got := p.NumberSuffixes()["k"]; got != 1024 {
+		t.Errorf("suffix k = %d", got)
+	}
+	if got := p.GrammarVersion(); got != "2" {
+		t.Errorf("grammar version = %q", got)
+	}
+	if got := p.Profiles(); len(got) != 1 || got[0].Name != "base" {
+		t.Errorf("profiles = %v", got)
+	}
+	if _, found := p.Lookup("ld"); !found {
+		t.Errorf("ld not registered")
+	}
+	if _, found := p.Lookup("li"); !found {
+		t.Errorf("li of the earlier grammar lost")
+	}
+	if err := p.LoadTemplatesFromYAML(strings.NewReader(grammarPrefix)); err != nil {
+		t.Errorf("loading the same grammar again: %v", err)
+	}
+}
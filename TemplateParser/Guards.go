@@ -0,0 +1,81 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Guard
+// is a condition a matched line must satisfy, written in the expression language of
+// CompileExpr. The expression sees every slot of the template as $N (counting from 0, the
+// mnemonic) and every slot with a descriptor under that name. Error, if set, is reported
+// when the guard is false; otherwise the message names the expression.
+type Guard struct {
+	Expr     string
+	Error    string
+	compiled *Expr
+}
+
+// compileGuards
+// compiles the guards of a template entry in place.
+func compileGuards(entry *TemplateEntry) (bool, string) {
+	for idx := range entry.Guards {
+		guard := &entry.Guards[idx]
+		compiled, err := CompileExpr(guard.Expr)
+		if err != nil {
+			return false, fmt.Sprintf("Template %s guard %q: %s", entry.Name, guard.Expr, err)
+		}
+		guard.compiled = compiled
+	}
+	return true, ""
+}
+
+// guardEnv
+// builds the names a guard expression can refer to from a matched line.
+func guardEnv(tmpl []TemplateObject, objs []ObjectType) map[string]interface{} {
+	env := make(map[string]interface{}, 2*len(objs))
+	for idx, obj := range objs {
+		env["$"+strconv.Itoa(idx)] = obj
+		if idx < len(tmpl) && tmpl[idx].TemplateValue.ObjectDescriptor != "" {
+			env[tmpl[idx].TemplateValue.ObjectDescriptor] = obj
+		}
+		if idx < len(tmpl) && tmpl[idx].Name != "" {
+			env[tmpl[idx].Name] = obj
+		}
+	}
+	return env
+}
+
+// checkGuards
+// evaluates the guards of an entry against a successfully matched line, marking the line
+// as failed at the first guard that is false or cannot be evaluated.
+func checkGuards(entry *TemplateEntry, result *LineResult) {
+	if !result.Ok || len(entry.Guards) == 0 {
+		return
+	}
+	env := guardEnv(entry.Objects, result.Objects)
+	for _, guard := range entry.Guards {
+		compiled := guard.compiled
+		if compiled == nil {
+			var err error
+			if compiled, err = CompileExpr(guard.Expr); err != nil {
+				result.Ok, result.Error = false, fmt.Sprintf("Guard %q: %s", guard.Expr, err)
+				return
+			}
+		}
+		passed, err := compiled.EvalBool(env)
+		if err != nil {
+			result.Ok, result.Error = false, fmt.Sprintf("Guard %q: %s", guard.Expr, err)
+			return
+		}
+		if !passed {
+			result.Ok = false
+			if guard.Error != "" {
+				result.Error = guard.Error
+			} else {
+				result.Error = fmt.Sprintf("Guard failed: %s", guard.Expr)
+			}
+			return
+		}
+	}
+}
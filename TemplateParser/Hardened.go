@@ -0,0 +1,30 @@
+package TemplateParser
+
+import "fmt"
+
+// recoverLine
+// turns a panic raised while parsing a line in hardened mode into the failed result of
+// the line, so arbitrary input and misbehaving custom token converters, filters and guards
+// cannot bring down the caller. It must be deferred.
+func (p *Parser) recoverLine(lineNo int, line string, results *[]LineResult) {
+	if !p.config.Hardened {
+		return
+	}
+	if r := recover(); r != nil {
+		result := LineResult{ParsedLine: ParsedLine{LineNumber: lineNo}, Error: fmt.Sprintf("Internal error while parsing the line: %v", r)}
+		result.setSource(line)
+		*results = []LineResult{result}
+	}
+}
+
+// recoverTokens
+// turns a panic raised while tokenizing in hardened mode into a single TokenUnknown token
+// holding the whole input. It must be deferred.
+func (p *Parser) recoverTokens(input string, tokens *[]Token) {
+	if !p.config.Hardened {
+		return
+	}
+	if r := recover(); r != nil {
+		*tokens = []Token{{Type: TokenUnknown, ValueReceived: input}}
+	}
+}
@@ -0,0 +1,87 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+// newTestParser
+// creates a parser with a configuration and the templates of a YAML grammar, failing the
+// test if the grammar does not load.
+func newTestParser(t testing.TB, config ParserConfig, grammar string) *Parser {
+	t.Helper()
+	p := NewParser(config)
+	if err := p.LoadTemplatesFromYAML(strings.NewReader(grammar)); err != nil {
+		t.Fatalf("LoadTemplatesFromYAML: %v", err)
+	}
+	return p
+}
+
+// parseTestSource
+// parses a source, failing the test if reading it fails.
+func parseTestSource(t testing.TB, p *Parser, source string) *SourceResult {
+	t.Helper()
+	result, err := p.ParseSource(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("ParseSource: %v", err)
+	}
+	return result
+}
+
+// lineValue
+// returns the value of the last object of a line of a result, failing the test unless
+// the line parsed.
+func lineValue(t testing.TB, result *SourceResult, idx int) interface{} {
+	t.Helper()
+	if idx >= len(result.Lines) {
+		t.Fatalf("result has %d lines, want line %d", len(result.Lines), idx+1)
+	}
+	lr := result.Lines[idx]
+	if !lr.Ok {
+		t.Fatalf("line %d %q failed: %s", lr.LineNumber, lr.RawText, lr.Error)
+	}
+	return lr.Objects[len(lr.Objects)-1].ObjectValue
+}
+
+// exprGrammar has an instruction with a Uint8 operand and one with an expression.
+const exprGrammar = `templates:
+  - mnemonic: li
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+  - mnemonic: le
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Expression}
+`
+
+// benchmarkLines is a small instruction mix used by the benchmarks.
+var benchmarkLines = []string{
+	"mov r1, r2 ; copy",
+	"ldi r3, ff",
+	"add r1, r3",
+	"str \"hello, world\", 1234abcd",
+	"jmp 0800",
+}
+
+// benchmarkTemplate matches the two-register lines of benchmarkLines.
+var benchmarkTemplate = []TemplateObject{
+	{TemplateType: TokenIdentifier},
+	{TemplateType: TokenRegister},
+	{TemplateType: TokenComma},
+	{TemplateType: TokenRegister},
+}
+
+// sensitiveGrammar has an instruction whose Uint16 operand is sensitive.
+const sensitiveGrammar = `templates:
+  - mnemonic: key
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint16, sensitive: true}
+`
@@ -0,0 +1,222 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IncludeDirective is the default keyword of the directive including another file:
+// include "file". ParserConfig.IncludeKeyword changes it.
+const IncludeDirective = "include"
+
+// defaultIncludeDepth is the include nesting allowed when ParserConfig.MaxIncludeDepth is 0.
+const defaultIncludeDepth = 16
+
+// IncludeSite
+// is an include directive that led to a line: the file it appears in and its line number.
+type IncludeSite struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// FileResolver
+// opens the files named by include directives. from is the resolved name of the including
+// file, or "" when the directive is in a source given to ParseSource. Open returns the
+// resolved name of the file, which identifies it in include chains and cycle checks.
+type FileResolver interface {
+	Open(name string, from string) (io.ReadCloser, string, error)
+}
+
+// DirResolver
+// resolves include names on the operating system's filesystem. Relative names are taken
+// relative to the directory of the including file, or to Dir for top-level sources.
+type DirResolver struct {
+	Dir string
+}
+
+// Open
+// opens an include file relative to the including file.
+func (dr DirResolver) Open(name string, from string) (io.ReadCloser, string, error) {
+	resolved := name
+	if !filepath.IsAbs(name) {
+		dir := dr.Dir
+		if from != "" {
+			dir = filepath.Dir(from)
+		}
+		resolved = filepath.Join(dir, name)
+	}
+	f, err := os.Open(resolved)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, resolved, nil
+}
+
+// FSResolver
+// resolves include names in an fs.FS, such as an embedded file system. Names are slash
+// separated and relative to the directory of the including file; top-level sources
+// resolve them relative to the root of FS.
+type FSResolver struct {
+	FS fs.FS
+}
+
+// Open
+// opens an include file relative to the including file.
+func (fr FSResolver) Open(name string, from string) (io.ReadCloser, string, error) {
+	resolved := path.Clean(name)
+	if from != "" && !path.IsAbs(name) {
+		resolved = path.Join(path.Dir(from), name)
+	}
+	f, err := fr.FS.Open(strings.TrimPrefix(resolved, "/"))
+	if err != nil {
+		return nil, "", err
+	}
+	return f, resolved, nil
+}
+
+// SetFileResolver
+// sets the resolver ParseSource and ParseFile use for include directives. Without one,
+// include directives in sources given to ParseSource fail, while files parsed with
+// ParseFile resolve them on the filesystem relative to the including file.
+func (p *Parser) SetFileResolver(resolver FileResolver) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resolver = resolver
+}
+
+// FileResolver
+// returns the resolver set with SetFileResolver, or nil.
+func (p *Parser) FileResolver() FileResolver {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.resolver
+}
+
+// includeKeyword
+// returns the configured include keyword.
+func (config ParserConfig) includeKeyword() string {
+	if config.IncludeKeyword == "" {
+		return IncludeDirective
+	}
+	return config.IncludeKeyword
+}
+
+// includeDepth
+// returns the configured include nesting limit.
+func (config ParserConfig) includeDepth() int {
+	if config.MaxIncludeDepth <= 0 {
+		return defaultIncludeDepth
+	}
+	return config.MaxIncludeDepth
+}
+
+// includeName
+// reports whether a source line is an include directive and returns the file it names.
+// The keyword is matched ignoring case unless the configuration preserves case without
+// folding mnemonics. A line starting with the keyword whose operand is not a single
+// quoted string is still an include directive; the returned message then describes the
+// problem.
+func (p *Parser) includeName(line string) (string, bool, string) {
+	code := strings.TrimSpace(EatComments(line))
+	keyword := p.config.includeKeyword()
+	if len(code) <= len(keyword) || !isBlankByte(code[len(keyword)]) {
+		return "", false, ""
+	}
+	if word := code[:len(keyword)]; word != keyword && (!p.config.foldsMnemonics() || !strings.EqualFold(word, keyword)) {
+		return "", false, ""
+	}
+	operand := contentTokens(p.lexer.scan(strings.TrimSpace(code[len(keyword):])))
+	if len(operand) != 1 || operand[0].Type != TokenQuotedString {
+		return "", true, fmt.Sprintf("Expected a quoted file name after %s", keyword)
+	}
+	name, ok, errmsg := UnescapeString(operand[0].ValueReceived)
+	if !ok {
+		return "", true, errmsg
+	}
+	if name == "" {
+		return "", true, fmt.Sprintf("Empty file name after %s", keyword)
+	}
+	return name, true, ""
+}
+
+// isBlankByte
+// reports whether a byte is a space or tab.
+func isBlankByte(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// includeFile
+// reads the lines of the file named by an include directive on line, appending them to
+// lines. Failures to resolve, open or read the file, include cycles and nesting deeper
+// than the configured limit are recorded as an error on the directive's line. The lines
+// read are charged to a budget.
+func (p *Parser) includeFile(line sourceLine, name string, lines []sourceLine, b *budget) []sourceLine {
+	chain := append(append([]IncludeSite(nil), line.includes...), IncludeSite{line.file, line.number})
+	fail := func(format string, args ...interface{}) []sourceLine {
+		line.err = fmt.Sprintf(format, args...)
+		return append(lines, line)
+	}
+	resolver := p.resolver
+	if resolver == nil && line.file != "" {
+		resolver = DirResolver{}
+	}
+	if resolver == nil {
+		return fail("Cannot include %s: no file resolver is set", name)
+	}
+	if len(chain) > p.config.includeDepth() {
+		return fail("Cannot include %s: include depth exceeds %d", name, p.config.includeDepth())
+	}
+	rc, resolved, err := resolver.Open(name, line.file)
+	if err != nil {
+		return fail("Cannot include %s: %v", name, err)
+	}
+	defer rc.Close()
+	for _, site := range chain {
+		if site.File != "" && site.File == resolved {
+			return fail("Cannot include %s: include cycle %s", name, includeCycle(chain, resolved))
+		}
+	}
+	lines, err = p.readSource(rc, resolved, chain, line.profile, lines, b)
+	if err != nil {
+		return fail("Cannot include %s: %v", name, err)
+	}
+	return lines
+}
+
+// includeCycle
+// describes an include cycle as the chain of files from the repeated file back to itself.
+func includeCycle(chain []IncludeSite, resolved string) string {
+	names := make([]string, 0, len(chain)+1)
+	for _, site := range chain {
+		if site.File == resolved || len(names) > 0 {
+			names = append(names, site.File)
+		}
+	}
+	return strings.Join(append(names, resolved), " -> ")
+}
+
+// withSource
+// records the file, include chain, offset and segments of a source line on its results.
+func (line sourceLine) withSource(results []LineResult) []LineResult {
+	for idx := range results {
+		results[idx].File, results[idx].Includes = line.file, line.includes
+		results[idx].Offset, results[idx].wide = line.offset, line.wide
+		results[idx].Segments = line.segments
+	}
+	return results
+}
+
+// includeError
+// returns the failed result of an include directive that could not be processed.
+func (line sourceLine) includeError(config ParserConfig) LineResult {
+	return LineResult{
+		ParsedLine: ParsedLine{LineNumber: line.number, RawText: line.text, Directive: config.includeKeyword(),
+			File: line.file, Includes: line.includes, Offset: line.offset, Segments: line.segments, wide: line.wide},
+		Error: line.err,
+	}
+}
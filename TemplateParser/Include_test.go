@@ -0,0 +1,77 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// includeFS holds a chain of included files, the innermost with an error, and a cycle.
+var includeFS = fstest.MapFS{
+	"lib.s":       {Data: []byte("nop\ninclude \"sub/inner.s\"\n")},
+	"sub/inner.s": {Data: []byte("jmp start\nbogus\n")},
+	"a.s":         {Data: []byte("include \"b.s\"\n")},
+	"b.s":         {Data: []byte("include \"a.s\"\n")},
+}
+
+func TestIncludeChain(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	p.SetFileResolver(FSResolver{includeFS})
+	result := parseTestSource(t, p, "start: nop\ninclude \"lib.s\" ; library\nnop\n")
+	var files []string
+	for _, lr := range result.Lines {
+		files = append(files, lr.File)
+	}
+	if want := []string{"", "lib.s", "sub/inner.s", "sub/inner.s", ""}; !reflect.DeepEqual(files, want) {
+		t.Fatalf("lines come from %q, want %q", files, want)
+	}
+	if jmp := result.Lines[2]; !jmp.Ok || jmp.Objects[1].ObjectValue != uint64(0) || jmp.Address != 2 {
+		t.Errorf("included jmp = %+v, want a jump to start at address 2", jmp)
+	}
+	errs := result.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("errors = %v, want one", errs)
+	}
+	want := []IncludeSite{{"", 2}, {"lib.s", 2}}
+	if !reflect.DeepEqual(errs[0].Includes, want) || errs[0].File != "sub/inner.s" || errs[0].Line != 2 {
+		t.Errorf("error = %+v, want line 2 of sub/inner.s included through %v", errs[0], want)
+	}
+	if msg := errs[0].Error(); !strings.HasSuffix(msg, "\n\tincluded from lib.s:2\n\tincluded from line 2") {
+		t.Errorf("error message %q does not show the include chain", msg)
+	}
+}
+
+func TestIncludeFailures(t *testing.T) {
+	shallow := DefaultParserConfig()
+	shallow.MaxIncludeDepth = 1
+	keyword := DefaultParserConfig()
+	keyword.IncludeKeyword = "use"
+	tests := []struct {
+		name     string
+		config   ParserConfig
+		resolver FileResolver
+		source   string
+		err      string
+	}{
+		{"no resolver", DefaultParserConfig(), nil, `include "lib.s"`,
+			"Cannot include lib.s: no file resolver is set"},
+		{"missing file", DefaultParserConfig(), FSResolver{includeFS}, `include "gone.s"`,
+			"Cannot include gone.s: open gone.s: file does not exist"},
+		{"cycle", DefaultParserConfig(), FSResolver{includeFS}, `include "a.s"`,
+			"Cannot include a.s: include cycle a.s -> b.s -> a.s"},
+		{"depth", shallow, FSResolver{includeFS}, `include "lib.s"`,
+			"Cannot include sub/inner.s: include depth exceeds 1"},
+		{"operand", DefaultParserConfig(), FSResolver{includeFS}, `include lib`,
+			"Expected a quoted file name after include"},
+		{"keyword", keyword, FSResolver{includeFS}, `use ""`, "Empty file name after use"},
+	}
+	for _, tt := range tests {
+		p := newTestParser(t, tt.config, labelGrammar)
+		p.SetFileResolver(tt.resolver)
+		errs := parseTestSource(t, p, tt.source+"\n").Errors()
+		if len(errs) != 1 || errs[0].Message != tt.err {
+			t.Errorf("%s: errors = %v, want %q", tt.name, errs, tt.err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package TemplateParser
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	maxInterned      = 4096 // Most words an intern table holds
+	maxInternedBytes = 32   // Longest word interned; longer ones are lowercased as they are
+)
+
+// internTable
+// holds one copy of every lowercased word a tokenizer has produced. Mnemonics and
+// registers repeat on most lines, so sharing their lowercase forms saves allocating a
+// string per token. It is safe for concurrent use.
+type internTable struct {
+	mu    sync.Mutex
+	words map[string]string
+}
+
+// newInternTable
+// returns an empty intern table.
+func newInternTable() *internTable {
+	return &internTable{words: make(map[string]string)}
+}
+
+// lower
+// lowercases a token as lowerInPlace does, returning the interned copy of short ASCII
+// words. Text without uppercase letters is returned as it is.
+func (lx *lexer) lower(text string) string {
+	if !isASCII(text) {
+		return lowerInPlace(text)
+	}
+	upper := strings.IndexFunc(text, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+	if upper < 0 {
+		return text
+	}
+	if len(text) > maxInternedBytes || lx.interned == nil {
+		return strings.ToLower(text)
+	}
+	var buf [maxInternedBytes]byte
+	word := buf[:len(text)]
+	for idx := 0; idx < len(text); idx++ {
+		c := text[idx]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		word[idx] = c
+	}
+	t := lx.interned
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if interned, found := t.words[string(word)]; found {
+		return interned
+	}
+	interned := string(word)
+	if len(t.words) < maxInterned {
+		t.words[interned] = interned
+	}
+	return interned
+}
+
+// tokenBuffers pools the token slices lines are tokenized into while they are parsed.
+var tokenBuffers = sync.Pool{New: func() interface{} { return new([]Token) }}
+
+// getTokenBuffer
+// returns a token slice from the pool, with no tokens.
+func getTokenBuffer() *[]Token {
+	return tokenBuffers.Get().(*[]Token)
+}
+
+// putTokenBuffer
+// returns the storage of tokens to the pool, clearing it so the pool does not keep the
+// lines it held alive.
+func putTokenBuffer(buf *[]Token, tokens []Token) {
+	clear(tokens[:cap(tokens)])
+	*buf = tokens[:0]
+	tokenBuffers.Put(buf)
+}
@@ -0,0 +1,167 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Objects produced by ParseLine carry token type ids, so the JSON form of both tokens and
+// objects names their type with the token type name ("Register", "Uint8"...) next to the
+// numeric id. Decoding prefers the name and falls back to the id for types without one,
+// such as custom token types.
+
+// tokenJSON
+// is the JSON form of a Token.
+type tokenJSON struct {
+	Type   string `json:"type"`
+	TypeId int    `json:"type_id"`
+	Value  string `json:"value"`
+}
+
+// objectJSON
+// is the JSON form of an ObjectType.
+type objectJSON struct {
+	Type       string          `json:"type"`
+	TypeId     int             `json:"type_id"`
+	Value      json.RawMessage `json:"value"`
+	Descriptor string          `json:"descriptor,omitempty"`
+	Sensitive  bool            `json:"sensitive,omitempty"`
+	Width      int             `json:"width,omitempty"`
+	Text       string          `json:"text,omitempty"`
+}
+
+// MarshalJSON
+// encodes a token with the name of its type.
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tokenJSON{DefaultParserConfig().tokenName(t.Type), t.Type, t.ValueReceived})
+}
+
+// UnmarshalJSON
+// decodes a token written by MarshalJSON.
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var tj tokenJSON
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return err
+	}
+	t.Type = typeIdFromJSON(tj.Type, tj.TypeId)
+	t.ValueReceived = tj.Value
+	return nil
+}
+
+// MarshalJSON
+// encodes an object with the name of its type. Sensitive values are written as
+// RedactedValue, wide integers as hex strings and everything else as plain JSON values.
+func (obj ObjectType) MarshalJSON() ([]byte, error) {
+	var value interface{} = obj.ObjectValue
+	switch val := obj.ObjectValue.(type) {
+	case *big.Int:
+		if val != nil {
+			value = fmt.Sprintf("%#x", val)
+		}
+	}
+	text := obj.ObjectText
+	if obj.ObjectSensitive {
+		value = RedactedValue
+		if text != "" {
+			text = RedactedValue
+		}
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(objectJSON{
+		Type:       DefaultParserConfig().tokenName(obj.ObjectTypeId),
+		TypeId:     obj.ObjectTypeId,
+		Value:      raw,
+		Descriptor: obj.ObjectDescriptor,
+		Sensitive:  obj.ObjectSensitive,
+		Width:      obj.ObjectWidth,
+		Text:       text,
+	})
+}
+
+// UnmarshalJSON
+// decodes an object written by MarshalJSON. Numbers become uint64 (int64 if negative),
+// hex strings of wide integer types become *big.Int, the values of Memory objects become
+// MemoryOperand and other values keep their JSON type.
+func (obj *ObjectType) UnmarshalJSON(data []byte) error {
+	var oj objectJSON
+	if err := json.Unmarshal(data, &oj); err != nil {
+		return err
+	}
+	*obj = ObjectType{
+		ObjectTypeId:     typeIdFromJSON(oj.Type, oj.TypeId),
+		ObjectDescriptor: oj.Descriptor,
+		ObjectSensitive:  oj.Sensitive,
+		ObjectWidth:      oj.Width,
+		ObjectText:       oj.Text,
+	}
+	value, err := decodeObjectValue(obj.ObjectTypeId, oj.Value)
+	if err != nil {
+		return fmt.Errorf("object %s: %w", oj.Type, err)
+	}
+	obj.ObjectValue = value
+	return nil
+}
+
+// decodeObjectValue
+// converts the raw JSON value of an object back into the Go type the parser uses.
+func decodeObjectValue(typeId int, raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	if typeId == TokenMemory && bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		var mem MemoryOperand
+		if err := json.Unmarshal(raw, &mem); err != nil {
+			return nil, err
+		}
+		return mem, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	switch val := value.(type) {
+	case json.Number:
+		if u, ok := new(big.Int).SetString(val.String(), 10); ok && u.IsUint64() {
+			return u.Uint64(), nil
+		}
+		if i, err := val.Int64(); err == nil {
+			return i, nil
+		}
+		return nil, fmt.Errorf("invalid integer %s", val)
+	case string:
+		if typeId == TokenBigInt || isWideTemplate(typeId) {
+			if n, ok := new(big.Int).SetString(val, 0); ok {
+				return n, nil
+			}
+		}
+		return val, nil
+	}
+	return value, nil
+}
+
+// typeIdFromJSON
+// resolves the type of a decoded token or object from its name, falling back to its id.
+func typeIdFromJSON(name string, id int) int {
+	if tt, found := builtinRegistry.ByName(name); found {
+		return tt
+	}
+	return id
+}
+
+// ResultJSON
+// turns the results of ParseLine into a JSON document of the form
+// {"ok": true, "error": "", "objects": [...]}.
+func ResultJSON(objList []ObjectType, ok bool, errmsg string) ([]byte, error) {
+	return json.Marshal(struct {
+		Ok      bool         `json:"ok"`
+		Error   string       `json:"error"`
+		Objects []ObjectType `json:"objects"`
+	}{ok, errmsg, objList})
+}
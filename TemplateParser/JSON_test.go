@@ -0,0 +1,85 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestTokenJSON(t *testing.T) {
+	data, err := json.Marshal(Token{Type: TokenRegister, ValueReceived: "r1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"Register","type_id":6,"value":"r1"}`; string(data) != want {
+		t.Errorf("token JSON = %s, want %s", data, want)
+	}
+	for _, doc := range []string{
+		`{"type":"Register","type_id":99,"value":"r1"}`,
+		`{"type":"","type_id":6,"value":"r1"}`,
+	} {
+		var token Token
+		if err := json.Unmarshal([]byte(doc), &token); err != nil || token.Type != TokenRegister {
+			t.Errorf("decoding %s = %+v, %v, want a Register", doc, token, err)
+		}
+	}
+}
+
+func TestObjectJSONRoundTrip(t *testing.T) {
+	wide, _ := new(big.Int).SetString("123456789abcdef0123", 16)
+	for _, obj := range []ObjectType{
+		{ObjectTypeId: TokenUint8, ObjectValue: uint64(0x10), ObjectText: "10"},
+		{ObjectTypeId: TokenExpression, ObjectValue: int64(-3), ObjectText: "0-3"},
+		{ObjectTypeId: TokenQuotedString, ObjectValue: "a \"b\"", ObjectDescriptor: "text"},
+		{ObjectTypeId: TokenBoolean, ObjectValue: true},
+		{ObjectTypeId: TokenBigInt, ObjectValue: wide, ObjectWidth: 80},
+		{ObjectTypeId: TokenMemory, ObjectValue: MemoryOperand{HasBase: true, Base: 1, Displacement: -8}},
+		{ObjectTypeId: TokenCustomBase, ObjectValue: "eq"},
+	} {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got ObjectType
+		if err := json.Unmarshal(data, &got); err != nil || !reflect.DeepEqual(got, obj) {
+			t.Errorf("round trip of %+v through %s = %+v, %v", obj, data, got, err)
+		}
+	}
+}
+
+func TestSensitiveObjectJSON(t *testing.T) {
+	data, err := json.Marshal(ObjectType{ObjectTypeId: TokenUint16, ObjectValue: uint64(0x5eed),
+		ObjectSensitive: true, ObjectText: "5eed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ObjectType
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("5eed")) || got.ObjectValue != RedactedValue || got.ObjectText != RedactedValue ||
+		!got.ObjectSensitive {
+		t.Errorf("sensitive object JSON = %s, want the value and text redacted", data)
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	objs, ok, errmsg := ParseLine("mov r1, r2", benchmarkTemplate)
+	data, err := ResultJSON(objs, ok, errmsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Ok      bool         `json:"ok"`
+		Error   string       `json:"error"`
+		Objects []ObjectType `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Ok || doc.Error != "" || !reflect.DeepEqual(doc.Objects, objs) {
+		t.Errorf("ResultJSON = %s, want the objects of ParseLine %+v", data, objs)
+	}
+}
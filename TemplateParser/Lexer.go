@@ -0,0 +1,449 @@
+package TemplateParser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// lexer
+// is the tokenizer for one configuration. Built-in token classes are recognised by a
+// single pass over the input that looks at the next byte to decide which class can start
+// there, instead of trying a regular expression per class at every position; custom token
+// types still use their regular expressions. At each position the longest token any class
+// matches wins, and ties go to the class ranked first by the configuration's
+// TokenPrecedence. Identifiers (two letters or more), hex literals and registers only
+// match whole words, so r1x is a single unknown token rather than r1 followed by x.
+// Letters are ASCII unless the configuration names Unicode letter classes.
+//
+// With FirstMatch set the classes are instead tried in a fixed order and the first one
+// matching wins: custom types, the operator table, registers with a configured prefix and
+// register aliases, then quoted strings, character literals, punctuation, macros, boolean
+// keywords, identifiers, hex literals and rN registers.
+type lexer struct {
+	custom    []tokenPattern
+	prefixes  []string          // Register prefixes, longest first
+	suffixes  []string          // Register suffixes, longest first
+	aliases   map[string]bool   // Lowercased register aliases
+	booleans  map[string]bool   // Lowercased boolean keywords
+	units     []string          // Number suffixes, longest first
+	scales    map[string]uint64 // Scale of each lowercased number suffix
+	numbers   *NumberOptions    // Number options reading more than hex literals, nil otherwise
+	maxDigits int               // Longest hex literal accepted as TokenBigInt, 0 if big literals are disabled
+	operators []string          // Operator table, longest first
+	letters   []*unicode.RangeTable
+	firstOnly bool         // Use the fixed first-match order
+	rank      map[int]int  // Tie-break rank of each token class, lower wins
+	interned  *internTable // Lowercased words, shared by the lines tokenized
+}
+
+// defaultLexer is the tokenizer of the default configuration, used by the package-level
+// functions so they do not build one per call.
+var defaultLexer = newLexer(DefaultParserConfig())
+
+// newLexer
+// builds the tokenizer for a configuration.
+func newLexer(config ParserConfig) *lexer {
+	lx := &lexer{
+		custom:    config.customPatterns(),
+		prefixes:  sortedKeys(config.RegisterPrefixes),
+		suffixes:  sortedKeys(config.RegisterSuffixes),
+		operators: sortedOperators(config.Operators),
+		letters:   config.IdentifierLetters,
+		firstOnly: config.FirstMatch,
+		rank:      config.tokenRanks(),
+		booleans:  config.booleanKeywords(),
+		scales:    config.numberSuffixes(),
+		interned:  newInternTable(),
+	}
+	if len(config.RegisterAliases) > 0 {
+		lx.aliases = make(map[string]bool, len(config.RegisterAliases))
+		for alias := range config.RegisterAliases {
+			lx.aliases[strings.ToLower(alias)] = true
+		}
+	}
+	if config.MaxNumericDigits > 16 {
+		lx.maxDigits = config.MaxNumericDigits
+	}
+	lx.units = sortedSuffixes(lx.scales)
+	if opts := config.numberOptions(); opts.extended() {
+		lx.numbers = &opts
+	}
+	return lx
+}
+
+// scan
+// splits input into tokens. Characters that start no token become TokenUnknown tokens of
+// one character, or of one byte where the input is not valid UTF-8, so the token texts
+// always concatenate back to the input and never split a character.
+func (lx *lexer) scan(input string) []Token {
+	return lx.scanInto(nil, input)
+}
+
+// scanInto
+// implements scan, storing the tokens in dst, which is allocated if it has no capacity.
+func (lx *lexer) scanInto(dst []Token, input string) []Token {
+	tokens := dst[:0]
+	if cap(tokens) == 0 {
+		tokens = make([]Token, 0, len(input)/2+1)
+	}
+	for offset := 0; offset < len(input); {
+		remaining := input[offset:]
+		tokenType, length := lx.next(remaining)
+		if length == 0 {
+			_, length = utf8.DecodeRuneInString(remaining)
+			tokenType = TokenUnknown
+		}
+		tokens = append(tokens, Token{Type: tokenType, ValueReceived: remaining[:length]})
+		offset += length
+	}
+	return tokens
+}
+
+// next
+// returns the type and length of the token at the start of s, or a length of 0 if no
+// token starts there: the longest token any class matches, ranked by precedence on ties.
+// A word no class matches as a whole is returned as a single TokenUnknown token.
+func (lx *lexer) next(s string) (int, int) {
+	if lx.firstOnly {
+		return lx.firstMatch(s)
+	}
+	bestType, bestLength := TokenUnknown, 0
+	consider := func(tokenType int, length int) {
+		if length == 0 || length < bestLength {
+			return
+		}
+		if length > bestLength || lx.tokenRank(tokenType) < lx.tokenRank(bestType) {
+			bestType, bestLength = tokenType, length
+		}
+	}
+	for _, pattern := range lx.custom {
+		if loc := pattern.regex.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			consider(pattern.tokenType, loc[1])
+		}
+	}
+	consider(TokenOperator, lx.operator(s))
+	consider(TokenRegister, lx.prefixedRegister(s))
+	consider(TokenRegister, lx.registerAlias(s))
+	consider(lx.symbol(s))
+	word := lx.wordLength(s, 0)
+	if first := lx.letter(s); first > 0 && lx.letter(s[first:]) > 0 {
+		consider(TokenIdentifier, word)
+	}
+	consider(TokenBoolean, lx.boolean(s, word))
+	if lx.numbers != nil {
+		consider(lx.optionNumber(s, word))
+	} else {
+		consider(lx.suffixedNumber(s, word))
+		if digits := hexLength(s, 0); digits > 0 && digits == word {
+			consider(lx.numberToken(digits))
+		}
+	}
+	if length := lx.register(s); length > 1 && length == word {
+		consider(TokenRegister, length)
+	}
+	if bestLength == 0 && word > 0 {
+		return TokenUnknown, word
+	}
+	return bestType, bestLength
+}
+
+// tokenRank
+// returns the tie-break rank of a token class; hex literals of every width share one.
+func (lx *lexer) tokenRank(tokenType int) int {
+	if isIntegerToken(tokenType) {
+		tokenType = TokenUint8
+	}
+	if rank, found := lx.rank[tokenType]; found {
+		return rank
+	}
+	return len(lx.rank)
+}
+
+// symbol
+// returns the type and length of the quoted string, character literal, punctuation or
+// macro at the start of s, which its first byte decides.
+func (lx *lexer) symbol(s string) (int, int) {
+	switch s[0] {
+	case '"':
+		return TokenQuotedString, quotedLength(s)
+	case '\'':
+		return TokenChar, charLength(s)
+	case ',':
+		return TokenComma, 1
+	case ':':
+		return TokenColon, 1
+	case '[':
+		return TokenLBracket, 1
+	case ']':
+		return TokenRBracket, 1
+	case '(':
+		return TokenLParen, 1
+	case ')':
+		return TokenRParen, 1
+	case '+':
+		return TokenPlus, 1
+	case '-':
+		return TokenMinus, 1
+	case '@':
+		if first := lx.letter(s[1:]); first > 0 {
+			return TokenMacro, lx.wordLength(s, 1+first)
+		}
+	}
+	return TokenUnknown, 0
+}
+
+// register
+// returns the length of the rN register at the start of s, with its configured width
+// suffix, or 1 when s starts with r but no register follows it.
+func (lx *lexer) register(s string) int {
+	if s[0] != 'r' && s[0] != 'R' {
+		return 0
+	}
+	length := hexLength(s, 1)
+	for _, suffix := range lx.suffixes {
+		if hasPrefixFold(s[length:], suffix) {
+			length += len(suffix)
+			break
+		}
+	}
+	return length
+}
+
+// firstMatch
+// implements next with the fixed first-match order of FirstMatch.
+func (lx *lexer) firstMatch(s string) (int, int) {
+	for _, pattern := range lx.custom {
+		if loc := pattern.regex.FindStringIndex(s); loc != nil && loc[0] == 0 && loc[1] > 0 {
+			return pattern.tokenType, loc[1]
+		}
+	}
+	if length := lx.operator(s); length > 0 {
+		return TokenOperator, length
+	}
+	if length := lx.prefixedRegister(s); length > 0 {
+		return TokenRegister, length
+	}
+	if length := lx.registerAlias(s); length > 0 {
+		return TokenRegister, length
+	}
+	c := s[0]
+	switch c {
+	case '"':
+		return TokenQuotedString, quotedLength(s)
+	case '\'':
+		return TokenChar, charLength(s)
+	case ',':
+		return TokenComma, 1
+	case ':':
+		return TokenColon, 1
+	case '[':
+		return TokenLBracket, 1
+	case ']':
+		return TokenRBracket, 1
+	case '(':
+		return TokenLParen, 1
+	case ')':
+		return TokenRParen, 1
+	case '+':
+		return TokenPlus, 1
+	case '-':
+		return TokenMinus, 1
+	case '@':
+		if first := lx.letter(s[1:]); first > 0 {
+			return TokenMacro, lx.wordLength(s, 1+first)
+		}
+		return TokenUnknown, 0
+	}
+	if length := lx.boolean(s, lx.wordLength(s, 0)); length > 0 {
+		return TokenBoolean, length
+	}
+	if first := lx.letter(s); first > 0 {
+		if second := lx.letter(s[first:]); second > 0 {
+			return TokenIdentifier, lx.wordLength(s, first+second)
+		}
+	}
+	if lx.numbers != nil {
+		if tokenType, length := lx.optionNumber(s, lx.wordLength(s, 0)); length > 0 {
+			return tokenType, length
+		}
+	} else if digits := hexLength(s, 0); digits > 0 {
+		if tokenType, length := lx.suffixedNumber(s, lx.wordLength(s, 0)); length > 0 {
+			return tokenType, length
+		}
+		return lx.numberToken(digits)
+	}
+	if c == 'r' || c == 'R' {
+		length := hexLength(s, 1)
+		for _, suffix := range lx.suffixes {
+			if hasPrefixFold(s[length:], suffix) {
+				length += len(suffix)
+				break
+			}
+		}
+		return TokenRegister, length
+	}
+	return TokenUnknown, 0
+}
+
+// numberToken
+// classifies a run of hex digits by length. Runs longer than the widest accepted literal
+// are split, the first token taking as many digits as it can.
+func (lx *lexer) numberToken(digits int) (int, int) {
+	switch {
+	case lx.maxDigits > 0 && digits > 16:
+		return TokenBigInt, min(digits, lx.maxDigits)
+	case digits > 8:
+		return TokenUint64, min(digits, 16)
+	case digits > 4:
+		return TokenUint32, digits
+	case digits > 2:
+		return TokenUint16, digits
+	}
+	return TokenUint8, digits
+}
+
+// prefixedRegister
+// returns the length of a register written with a configured prefix at the start of s:
+// the prefix, a decimal digit, further hex digits and then the end of the word. It returns
+// 0 if there is none.
+func (lx *lexer) prefixedRegister(s string) int {
+	for _, prefix := range lx.prefixes {
+		if len(s) <= len(prefix) || !hasPrefixFold(s, prefix) || !isDecimal(s[len(prefix)]) {
+			continue
+		}
+		length := hexLength(s, len(prefix)+1)
+		if length == len(s) || !lx.isWordStart(s[length:]) {
+			return length
+		}
+	}
+	return 0
+}
+
+// registerAlias
+// returns the length of the register alias that forms the word at the start of s, or 0.
+func (lx *lexer) registerAlias(s string) int {
+	if lx.aliases == nil || !isLetter(s[0]) {
+		return 0
+	}
+	length := lx.wordLength(s, 1)
+	if !lx.aliases[strings.ToLower(s[:length])] {
+		return 0
+	}
+	return length
+}
+
+// quotedLength
+// returns the length of the quoted string at the start of s, including both quotes, or 0
+// if the string is not terminated. A backslash escapes the following character, which may
+// not be a newline.
+func quotedLength(s string) int {
+	for idx := 1; idx < len(s); idx++ {
+		switch s[idx] {
+		case '"':
+			return idx + 1
+		case '\\':
+			if idx+1 >= len(s) || s[idx+1] == '\n' {
+				return 0
+			}
+			idx++
+		}
+	}
+	return 0
+}
+
+// wordLength
+// returns the end of the run of letters, digits and underscores starting at from.
+func wordLength(s string, from int) int {
+	for from < len(s) && (isAlnum(s[from]) || s[from] == '_') {
+		from++
+	}
+	return from
+}
+
+// letter
+// returns the length in bytes of the letter at the start of s, or 0 if s does not start
+// with one.
+func (lx *lexer) letter(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+	if isLetter(s[0]) {
+		return 1
+	}
+	if s[0] < utf8.RuneSelf || lx.letters == nil {
+		return 0
+	}
+	if r, size := utf8.DecodeRuneInString(s); r != utf8.RuneError && unicode.IsOneOf(lx.letters, r) {
+		return size
+	}
+	return 0
+}
+
+// wordLength
+// is the package-level wordLength also accepting the letters of the configured Unicode
+// classes and the combining marks following them.
+func (lx *lexer) wordLength(s string, from int) int {
+	for from < len(s) {
+		if isWordByte(s[from]) {
+			from++
+			continue
+		}
+		size := lx.letter(s[from:])
+		if size == 0 && lx.letters != nil {
+			if r, n := utf8.DecodeRuneInString(s[from:]); unicode.Is(unicode.Mn, r) {
+				size = n
+			}
+		}
+		if size == 0 {
+			return from
+		}
+		from += size
+	}
+	return from
+}
+
+// isWordStart
+// reports whether s starts with a character that continues a word.
+func (lx *lexer) isWordStart(s string) bool {
+	return lx.wordLength(s, 0) > 0
+}
+
+// hexLength
+// returns the end of the run of hex digits starting at from.
+func hexLength(s string, from int) int {
+	for from < len(s) && isHexDigit(s[from]) {
+		from++
+	}
+	return from
+}
+
+// hasPrefixFold
+// reports whether s starts with prefix, ignoring case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// isLetter
+// reports whether c is an ASCII letter.
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isDecimal
+// reports whether c is a decimal digit.
+func isDecimal(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isHexDigit
+// reports whether c is a hex digit.
+func isHexDigit(c byte) bool {
+	return isDecimal(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isWordByte
+// reports whether c is a word character in the sense of the regexp \b assertion.
+func isWordByte(c byte) bool {
+	return isAlnum(c) || c == '_'
+}
@@ -0,0 +1,211 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeTypes(t *testing.T) {
+	const blank = TokenUnknown
+	tests := []struct {
+		line string
+		want []int
+	}{
+		{"mov r1, r2", []int{TokenIdentifier, blank, TokenRegister, TokenComma, blank, TokenRegister}},
+		{"ldi r3, ff", []int{TokenIdentifier, blank, TokenRegister, TokenComma, blank, TokenIdentifier}},
+		{"jmp 0800", []int{TokenIdentifier, blank, TokenUint16}},
+		{"ld 10", []int{TokenIdentifier, blank, TokenUint8}},
+		{"ld 1234abcd", []int{TokenIdentifier, blank, TokenUint32}},
+		{"ld 123456789", []int{TokenIdentifier, blank, TokenUint64}},
+		{`str "hello, world"`, []int{TokenIdentifier, blank, TokenQuotedString}},
+		{"ld [r1+4]", []int{TokenIdentifier, blank, TokenLBracket, TokenRegister, TokenPlus, TokenUint8, TokenRBracket}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, token := range Tokenize(tt.line) {
+			got = append(got, token.Type)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) types = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeKeepsText(t *testing.T) {
+	for _, line := range benchmarkLines {
+		var sb strings.Builder
+		for _, token := range Tokenize(line) {
+			sb.WriteString(token.ValueReceived)
+		}
+		if sb.String() != line {
+			t.Errorf("tokens of %q join to %q", line, sb.String())
+		}
+	}
+}
+
+// BenchmarkTokenize compares the regular expression tokenizer the lexer replaced with the
+// lexer on the same lines.
+func BenchmarkTokenize(b *testing.B) {
+	regex, lx := newRegexTokenizer(DefaultParserConfig()), newLexer(DefaultParserConfig())
+	b.Run("regex", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, line := range benchmarkLines {
+				regex.scan(line)
+			}
+		}
+	})
+	b.Run("lexer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, line := range benchmarkLines {
+				lx.scan(line)
+			}
+		}
+	})
+}
+
+// lexerCorpus are lines the lexer must split as the regular expression tokenizer did.
+var lexerCorpus = append([]string{
+	"", "nop", "lbl: nop", "loop: dec r1 ; count down",
+	"ld [r1+4]", "ld [r2-10], r3", "ld -4(r2)", "add r1,r2,r3", "add\tr1,\tr2",
+	"ld 1", "ld 12", "ld 123", "ld 1234", "ld 12345", "ld 12345678", "ld 123456789",
+	"ld 1234567890abcdef", "ld 0800, 0FFF", "ld r10, r0001", "ld R7, r1F",
+	`str ""`, `str "a, b ; c"`, `str "say \"hi\""`, `str "open`,
+	"@mac x, y", "@m1 r1", "xr ddd abc1 ab_c", "a1 b2", "x y z", "%$#!",
+}, benchmarkLines...)
+
+func TestLexerMatchesRegexTokenizer(t *testing.T) {
+	prefixed := DefaultParserConfig()
+	prefixed.RegisterPrefixes = map[string]int{"x": 64, "w": 32}
+	prefixed.RegisterSuffixes = map[string]int{"b": 8, "w": 16}
+	big := DefaultParserConfig()
+	big.MaxNumericDigits = 32
+	custom := NewParser(DefaultParserConfig())
+	if _, err := custom.AddTokenType("Cond", `eq|ne`, func(text string) (ObjectType, error) {
+		return StringObject(text, ""), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		config ParserConfig
+		lines  []string
+	}{
+		{"default", DefaultParserConfig(), lexerCorpus},
+		{"register affixes", prefixed, []string{"mov x1, w2", "mov r1b, r2w", "xadd x10, r3", "ld w0, [x1+8]"}},
+		{"big literals", big, []string{"ld 123456789abcdef01", "ld 0123456789abcdef0123456789abcdef"}},
+		{"custom types", custom.config, []string{"b eq 10", "b ne, r1", "cmp r1, r2"}},
+	} {
+		regex, lx := newRegexTokenizer(tt.config), newLexer(tt.config)
+		for _, line := range tt.lines {
+			if want, got := regex.scan(line), lx.scan(line); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %q tokenized as\n%v, want\n%v", tt.name, line, got, want)
+			}
+		}
+	}
+}
+
+// TestLexerDiffersFromRegexTokenizer lists where the lexer deliberately splits a line
+// differently: words match only as a whole, a register ties with an identifier as the
+// longest match and wins it, and true and false are boolean keywords.
+func TestLexerDiffersFromRegexTokenizer(t *testing.T) {
+	regex := newRegexTokenizer(DefaultParserConfig())
+	for _, tt := range []struct {
+		line       string
+		regex, got []int
+	}{
+		{"r1x", []int{TokenRegister, TokenUnknown}, []int{TokenUnknown}},
+		{"a_b", []int{TokenUint8, TokenUnknown, TokenUint8}, []int{TokenUnknown}},
+		{"ra", []int{TokenIdentifier}, []int{TokenRegister}},
+		{"true", []int{TokenIdentifier}, []int{TokenBoolean}},
+	} {
+		var want, got []int
+		for _, token := range regex.scan(tt.line) {
+			want = append(want, token.Type)
+		}
+		for _, token := range Tokenize(tt.line) {
+			got = append(got, token.Type)
+		}
+		if !reflect.DeepEqual(want, tt.regex) || !reflect.DeepEqual(got, tt.got) {
+			t.Errorf("%q tokenized as %v by the regexps and %v by the lexer, want %v and %v",
+				tt.line, want, got, tt.regex, tt.got)
+		}
+	}
+}
+
+// BenchmarkParserTokenize measures a parser's tokenizer, whose pattern table is built
+// once at construction.
+func BenchmarkParserTokenize(b *testing.B) {
+	parser := NewParser(DefaultParserConfig())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range benchmarkLines {
+			parser.Tokenize(line)
+		}
+	}
+}
+
+// BenchmarkParserTokenizeInto reuses one token slice, which allocates nothing per line.
+func BenchmarkParserTokenizeInto(b *testing.B) {
+	parser := NewParser(DefaultParserConfig())
+	var tokens []Token
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range benchmarkLines {
+			tokens = parser.TokenizeInto(tokens, line)
+		}
+	}
+}
+
+// BenchmarkParserTokenizeLine tokenizes uppercase lines the way the parser does before
+// matching them, so lowercasing draws on the parser's interned words.
+func BenchmarkParserTokenizeLine(b *testing.B) {
+	parser := NewParser(DefaultParserConfig())
+	lines := make([]string, len(benchmarkLines))
+	for idx, line := range benchmarkLines {
+		lines[idx] = strings.ToUpper(line)
+	}
+	var tokens []Token
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, line := range lines {
+			tokens = parser.TokenizeLineInto(tokens, line)
+		}
+	}
+}
+
+func TestCustomTokenAlternationIsAnchored(t *testing.T) {
+	for _, firstMatch := range []bool{false, true} {
+		config := DefaultParserConfig()
+		config.FirstMatch = firstMatch
+		p := NewParser(config)
+		tag, err := p.AddTokenType("Tag", `^a|b`, func(text string) (ObjectType, error) {
+			return StringObject(text, ""), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tokens := p.TokenizeLine("cab"); len(tokens) != 1 || tokens[0].Type != TokenIdentifier {
+			t.Errorf("FirstMatch %v: cab tokenized as %v, want one Identifier", firstMatch, tokens)
+		}
+		if tokens := p.TokenizeLine("b"); len(tokens) != 1 || tokens[0].Type != tag {
+			t.Errorf("FirstMatch %v: b tokenized as %v, want one Tag", firstMatch, tokens)
+		}
+	}
+}
+
+func TestDefaultBooleanKeywordsIsACopy(t *testing.T) {
+	DefaultBooleanKeywords()["on"] = true
+	delete(DefaultBooleanKeywords(), "true")
+	if tokens := Tokenize("on true"); tokens[0].Type != TokenIdentifier || tokens[2].Type != TokenBoolean {
+		t.Errorf("changing the default keywords changed the tokenizer: %v", tokens)
+	}
+	if want := map[string]bool{"true": true, "false": false}; !reflect.DeepEqual(DefaultBooleanKeywords(), want) {
+		t.Errorf("DefaultBooleanKeywords() = %v, want %v", DefaultBooleanKeywords(), want)
+	}
+}
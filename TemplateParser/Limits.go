@@ -0,0 +1,158 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Limits
+// caps the work a single ParseSource, ParseFile, ParseAll, ParseSourceParallel or Build
+// call may do, so services can bound what untrusted input costs them. Lines counts every
+// line read, including blank lines and the lines of included files; Bytes the bytes of
+// those lines; Tokens the tokens of the lines matched; and Expansions the macro
+// invocations expanded. Zero leaves a quantity unlimited. A call that reaches a limit
+// stops, returns the lines it processed before the limit together with a *LimitExceeded
+// error.
+type Limits struct {
+	Lines      int64
+	Bytes      int64
+	Tokens     int64
+	Expansions int64
+}
+
+// LimitStats
+// counts the work done by a call, as Limits measures it.
+type LimitStats struct {
+	Lines      int64 `json:"lines"`
+	Bytes      int64 `json:"bytes"`
+	Tokens     int64 `json:"tokens"`
+	Expansions int64 `json:"expansions"`
+}
+
+// LimitExceeded
+// is the error of a call stopped by one of its Limits. Limit names the limit, "lines",
+// "bytes", "tokens" or "expansions", and Max its value; File and Line locate the source
+// line that would have exceeded it. Stats counts the work done before it, which the
+// partial result returned with the error covers. Calls spreading lines over several
+// workers may have processed some lines after the one named.
+type LimitExceeded struct {
+	Limit string     `json:"limit"`
+	Max   int64      `json:"max"`
+	File  string     `json:"file,omitempty"`
+	Line  int        `json:"line"`
+	Stats LimitStats `json:"stats"`
+}
+
+// Error
+// describes the limit that was exceeded and where.
+func (le *LimitExceeded) Error() string {
+	return fmt.Sprintf("%s: %s limit of %d exceeded after %d lines",
+		ParseError{File: le.File, Line: le.Line}.position(), le.Limit, le.Max, le.Stats.Lines)
+}
+
+// budget
+// tracks the work of one call against the configured Limits. A nil budget is unlimited.
+// Reading stops at the first line over the Lines or Bytes limit, while the lines read
+// before it are still matched until the Tokens or Expansions limit is reached. It is safe
+// for concurrent use.
+type budget struct {
+	limits                           Limits
+	lines, bytes, tokens, expansions atomic.Int64
+	readStopped, matchStopped        atomic.Bool
+
+	mu       sync.Mutex
+	exceeded *LimitExceeded
+}
+
+// newBudget
+// returns the budget of a call, nil if the configuration sets no limits.
+func (config ParserConfig) newBudget() *budget {
+	if config.Limits == (Limits{}) {
+		return nil
+	}
+	return &budget{limits: config.Limits}
+}
+
+// charge
+// adds n to a counter, reporting false and recording the limit if the counter would go
+// over max. Charges over the limit are not counted, nor any once the phase, reading or
+// matching, has stopped.
+func (b *budget) charge(counter *atomic.Int64, n int64, limit string, max int64, stop *atomic.Bool, file string, line int) bool {
+	if stop.Load() {
+		return false
+	}
+	if total := counter.Add(n); max > 0 && total > max {
+		counter.Add(-n)
+		stop.Store(true)
+		b.mu.Lock()
+		if b.exceeded == nil {
+			b.exceeded = &LimitExceeded{Limit: limit, Max: max, File: file, Line: line}
+		}
+		b.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// readLine
+// charges a line read from a source and its bytes.
+func (b *budget) readLine(text string, file string, line int) bool {
+	if b == nil {
+		return true
+	}
+	if !b.charge(&b.lines, 1, "lines", b.limits.Lines, &b.readStopped, file, line) {
+		return false
+	}
+	return b.charge(&b.bytes, int64(len(text)), "bytes", b.limits.Bytes, &b.readStopped, file, line)
+}
+
+// matchLine
+// charges the tokens of a line about to be matched.
+func (b *budget) matchLine(tokens int, file string, line int) bool {
+	if b == nil {
+		return true
+	}
+	return b.charge(&b.tokens, int64(tokens), "tokens", b.limits.Tokens, &b.matchStopped, file, line)
+}
+
+// expand
+// charges the macro invocations expanded in a line.
+func (b *budget) expand(expansions int, file string, line int) bool {
+	if b == nil || expansions == 0 {
+		return true
+	}
+	return b.charge(&b.expansions, int64(expansions), "expansions", b.limits.Expansions, &b.matchStopped, file, line)
+}
+
+// stopped
+// reports whether matching has stopped at the Tokens or Expansions limit.
+func (b *budget) stopped() bool {
+	return b != nil && b.matchStopped.Load()
+}
+
+// err
+// returns the LimitExceeded error of an exceeded budget with the work done so far, or
+// nil.
+func (b *budget) err() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.exceeded == nil {
+		return nil
+	}
+	le := *b.exceeded
+	le.Stats = LimitStats{b.lines.Load(), b.bytes.Load(), b.tokens.Load(), b.expansions.Load()}
+	return &le
+}
+
+// firstError
+// returns a read error if there is one, else the budget's limit error.
+func (b *budget) firstError(err error) error {
+	if err != nil {
+		return err
+	}
+	return b.err()
+}
@@ -0,0 +1,60 @@
+package TemplateParser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimitsStopParsing(t *testing.T) {
+	mt := NewMacroTable()
+	if ok, errmsg := mt.Define("one", "1"); !ok {
+		t.Fatal(errmsg)
+	}
+	source := "li r1, @one\nli r2, @one\nli r3, 3\n"
+	tests := []struct {
+		name   string
+		limits Limits
+		lines  int
+		want   LimitExceeded
+	}{
+		{"lines", Limits{Lines: 2}, 2, LimitExceeded{Limit: "lines", Max: 2, Line: 3, Stats: LimitStats{2, 22, 8, 2}}},
+		{"bytes", Limits{Bytes: 15}, 1, LimitExceeded{Limit: "bytes", Max: 15, Line: 2, Stats: LimitStats{2, 11, 4, 1}}},
+		{"tokens", Limits{Tokens: 6}, 1, LimitExceeded{Limit: "tokens", Max: 6, Line: 2, Stats: LimitStats{3, 30, 4, 2}}},
+		{"expansions", Limits{Expansions: 1}, 1, LimitExceeded{Limit: "expansions", Max: 1, Line: 2, Stats: LimitStats{3, 30, 4, 1}}},
+	}
+	for _, tt := range tests {
+		config := DefaultParserConfig()
+		config.Limits = tt.limits
+		p := newTestParser(t, config, exprGrammar)
+		p.SetMacros(mt)
+		result, err := p.ParseSource(strings.NewReader(source))
+		var le *LimitExceeded
+		if !errors.As(err, &le) {
+			t.Fatalf("%s: error = %v, want a *LimitExceeded", tt.name, err)
+		}
+		if *le != tt.want {
+			t.Errorf("%s: error = %+v, want %+v", tt.name, *le, tt.want)
+		}
+		if len(result.Lines) != tt.lines {
+			t.Errorf("%s: %d lines returned, want %d", tt.name, len(result.Lines), tt.lines)
+		}
+	}
+}
+
+func TestLimitsNotReached(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Limits = Limits{Lines: 2, Tokens: 8}
+	p := newTestParser(t, config, exprGrammar)
+	result, err := p.ParseSource(strings.NewReader("li r1, 1\nli r2, 2\n"))
+	if err != nil || len(result.Lines) != 2 {
+		t.Errorf("ParseSource = %d lines, %v, want both lines without an error", len(result.Lines), err)
+	}
+}
+
+func TestLimitExceededError(t *testing.T) {
+	le := &LimitExceeded{Limit: "lines", Max: 2, File: "main.asm", Line: 3, Stats: LimitStats{Lines: 2}}
+	if got, want := le.Error(), "main.asm:3: lines limit of 2 exceeded after 2 lines"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+package TemplateParser
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LineCacheStats
+// describes the line cache of a Parser: the most lines it holds, the lines it holds now,
+// and the lookups that found a line and that did not since the cache was enabled.
+type LineCacheStats struct {
+	Size    int    `json:"size"`
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+// lineCacheKey
+// identifies a cached line: its text after macro expansion and the profile it was
+// matched in.
+type lineCacheKey struct {
+	text    string
+	profile string
+}
+
+// lineCacheEntry
+// is a cached line: its result, the number of non-blank tokens it was charged for and the
+// version of the constant table it was matched with.
+type lineCacheEntry struct {
+	key       lineCacheKey
+	result    LineResult
+	tokens    int
+	constants uint64
+}
+
+// lineCache
+// is a least recently used cache of line results. It is safe for concurrent use.
+type lineCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[lineCacheKey]*list.Element
+	order   *list.List // Most recently used first
+	hits    uint64
+	misses  uint64
+}
+
+// SetLineCache
+// enables a cache of the results of the last size distinct lines matched, for workloads
+// such as REPLs and test harnesses that parse the same lines again and again. Lines are
+// looked up by their text after macro expansion and the profile they are matched in, so
+// a hit skips tokenizing and matching; the result is copied, with the line number of the
+// line being parsed. The cache is emptied whenever the template set or configuration
+// changes, and a line matched before a constant changed value is matched again.
+//
+// Lines whose results could differ with the same text are never cached: equ lines, which
+// define a constant as they are parsed, lines produced by macro expansion, whose tokens
+// record their origin, lines whose mnemonic has slots with a ValidateContext callback,
+// and every line while token filters are in use. A size of 0 or less disables the cache.
+func (p *Parser) SetLineCache(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if size <= 0 {
+		p.cache = nil
+		return
+	}
+	p.cache = &lineCache{size: size, entries: make(map[lineCacheKey]*list.Element), order: list.New()}
+}
+
+// LineCacheStats
+// returns the statistics of the line cache, all zero if it is disabled.
+func (p *Parser) LineCacheStats() LineCacheStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.cache == nil {
+		return LineCacheStats{}
+	}
+	c := p.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return LineCacheStats{Size: c.size, Entries: c.order.Len(), Hits: c.hits, Misses: c.misses}
+}
+
+// invalidateCache
+// empties the line cache after a change to the template set or configuration. The
+// caller holds the parser's write lock.
+func (p *Parser) invalidateCache() {
+	if p.cache == nil {
+		return
+	}
+	c := p.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.order.Init()
+}
+
+// get
+// returns a copy of the cached result of a line matched with the given constants
+// version, and the number of tokens it was charged for.
+func (c *lineCache) get(key lineCacheKey, constants uint64) (LineResult, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, found := c.entries[key]
+	if !found || elem.Value.(*lineCacheEntry).constants != constants {
+		c.misses++
+		return LineResult{}, 0, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	entry := elem.Value.(*lineCacheEntry)
+	return entry.result.clone(), entry.tokens, true
+}
+
+// put
+// stores a copy of the result of a line, dropping the least recently used line if the
+// cache is full.
+func (c *lineCache) put(key lineCacheKey, result LineResult, tokens int, constants uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lineCacheEntry{key, result.clone(), tokens, constants}
+	if elem, found := c.entries[key]; found {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lineCacheEntry).key)
+	}
+}
+
+// clone
+// returns a copy of a result that shares nothing the parser or its caller modifies in
+// place, such as the objects label resolution rewrites.
+func (lr LineResult) clone() LineResult {
+	lr.Objects = append([]ObjectType(nil), lr.Objects...)
+	lr.Operands = append([]Operand(nil), lr.Operands...)
+	lr.Warnings = append([]Warning(nil), lr.Warnings...)
+	if lr.pending != nil {
+		pending := make(map[int]pendingExpr, len(lr.pending))
+		for slot, expr := range lr.pending {
+			pending[slot] = expr
+		}
+		lr.pending = pending
+	}
+	if lr.Groups != nil || lr.Named != nil {
+		lr.refresh()
+	}
+	return lr
+}
+
+// cacheable
+// reports whether the result of a line can be cached: it is not an equ line, and no
+// template its mnemonic could select validates its slots against the parse context.
+func (p *Parser) cacheable(result LineResult, tokens []Token) bool {
+	if result.Directive == EquDirective {
+		return false
+	}
+	_, body := SplitLabel(tokens)
+	if variants, found := p.registry[p.mnemonicKey(FirstIdentifier(body))]; found {
+		for _, entry := range variants {
+			if usesContext(entry.Objects) {
+				return false
+			}
+		}
+		return true
+	}
+	return !usesContext(p.templates)
+}
+
+// usesContext
+// reports whether any slot of a template list has a ValidateContext callback.
+func usesContext(templateList []TemplateObject) bool {
+	for _, tmpl := range templateList {
+		if tmpl.Hooks.validateContext() != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package TemplateParser
+
+import "testing"
+
+func TestLineCacheHits(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.SetLineCache(8)
+	result := parseTestSource(t, p, "li r1, 10\nle r2, 1\nli r1, 10\n")
+	if got := lineValue(t, result, 2); got != uint64(0x10) || result.Lines[2].LineNumber != 3 {
+		t.Errorf("cached line = %v at line %d, want 0x10 at line 3", got, result.Lines[2].LineNumber)
+	}
+	want := LineCacheStats{Size: 8, Entries: 2, Hits: 1, Misses: 2}
+	if stats := p.LineCacheStats(); stats != want {
+		t.Errorf("LineCacheStats() = %+v, want %+v", stats, want)
+	}
+	result.Lines[2].Objects[3].ObjectValue = uint64(0x99)
+	if got := lineValue(t, parseTestSource(t, p, "li r1, 10\n"), 0); got != uint64(0x10) {
+		t.Errorf("a change to a returned result reached the cache: %v", got)
+	}
+}
+
+func TestLineCacheEvicts(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.SetLineCache(1)
+	parseTestSource(t, p, "li r1, 1\nli r1, 2\nli r1, 1\n")
+	if stats := p.LineCacheStats(); stats.Entries != 1 || stats.Hits != 0 || stats.Misses != 3 {
+		t.Errorf("LineCacheStats() = %+v, want the least recently used line evicted", stats)
+	}
+}
+
+func TestLineCacheInvalidation(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.SetLineCache(8)
+	parseTestSource(t, p, "mov r1, r2\n")
+	if ok, errmsg := p.RegisterTemplate("mov", benchmarkTemplate); !ok {
+		t.Fatal(errmsg)
+	}
+	if stats := p.LineCacheStats(); stats.Entries != 0 {
+		t.Errorf("LineCacheStats() = %+v after registering a template, want an empty cache", stats)
+	}
+	if result := parseTestSource(t, p, "mov r1, r2\n"); !result.Lines[0].Ok {
+		t.Errorf("the line failed after the template was registered: %s", result.Lines[0].Error)
+	}
+}
+
+func TestLineCacheResolvesLabels(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	p.SetLineCache(8)
+	result := parseTestSource(t, p, "jmp loop\nnop\nloop: nop\njmp loop\n")
+	if first, second := lineValue(t, result, 0), lineValue(t, result, 3); first != uint64(3) || second != uint64(3) {
+		t.Errorf("jmp loop resolved to %v and %v, want 3", first, second)
+	}
+	other := parseTestSource(t, p, "loop: nop\njmp loop\n")
+	if got := lineValue(t, other, 1); got != uint64(0) {
+		t.Errorf("cached jmp loop in another source resolved to %v, want 0", got)
+	}
+}
+
+func TestLineCacheDisabled(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.SetLineCache(4)
+	p.SetLineCache(0)
+	parseTestSource(t, p, "li r1, 1\nli r1, 1\n")
+	if stats := p.LineCacheStats(); stats != (LineCacheStats{}) {
+		t.Errorf("LineCacheStats() = %+v with the cache disabled", stats)
+	}
+}
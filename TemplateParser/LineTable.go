@@ -0,0 +1,164 @@
+package TemplateParser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lineTableHeader starts the text form of a LineTable, followed by its format version.
+const lineTableHeader = "tpline 1"
+
+// LineRow
+// is a row of a LineTable: the Length bytes at Address were assembled from line Line of
+// File.
+type LineRow struct {
+	Address uint64 `json:"address"`
+	Length  uint64 `json:"length"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+}
+
+// LineTable
+// maps address ranges of assembled output to the source lines they came from, so a
+// debugger can show the source of an instruction. Rows are ordered by address.
+//
+// WriteText writes the table in a line-oriented text form that ReadLineTable reads back:
+//
+//	tpline 1
+//	file 0 main.asm
+//	row 100 2 0 7
+//
+// The header gives the format version, file lines number the source files and every row
+// holds a hex address and length, a file number and a decimal line number.
+type LineTable struct {
+	Rows []LineRow `json:"rows"`
+}
+
+// BuildLineTable
+// builds the line table of a parsed source from its successfully matched lines that
+// occupy bytes, such as the lines of a SourceResult or BuildResult.
+func BuildLineTable(lines []LineResult) *LineTable {
+	lt := &LineTable{Rows: make([]LineRow, 0)}
+	for _, lr := range lines {
+		if lr.Ok && lr.Size > 0 {
+			lt.Rows = append(lt.Rows, LineRow{lr.Address, lr.Size, lr.File, lr.LineNumber})
+		}
+	}
+	sort.SliceStable(lt.Rows, func(a, b int) bool { return lt.Rows[a].Address < lt.Rows[b].Address })
+	return lt
+}
+
+// LineTable
+// builds the line table of the lines a debug map covers, one row per line spanning the
+// bytes its fields produced. Bytes of an instruction no field covers are left out, so
+// BuildLineTable gives the full ranges when the parsed lines are at hand.
+func (dm *DebugMap) LineTable() *LineTable {
+	lt := &LineTable{Rows: make([]LineRow, 0)}
+	index := make(map[IncludeSite]int)
+	for _, record := range dm.Entries {
+		key := IncludeSite{record.File, record.Line}
+		end := record.Address + uint64(record.Length)
+		idx, found := index[key]
+		if !found {
+			index[key] = len(lt.Rows)
+			lt.Rows = append(lt.Rows, LineRow{record.Address, uint64(record.Length), record.File, record.Line})
+			continue
+		}
+		row := &lt.Rows[idx]
+		start := min(row.Address, record.Address)
+		row.Length = max(row.Address+row.Length, end) - start
+		row.Address = start
+	}
+	sort.SliceStable(lt.Rows, func(a, b int) bool { return lt.Rows[a].Address < lt.Rows[b].Address })
+	return lt
+}
+
+// Lookup
+// returns the row whose range holds an address.
+func (lt *LineTable) Lookup(address uint64) (LineRow, bool) {
+	idx := sort.Search(len(lt.Rows), func(i int) bool { return lt.Rows[i].Address > address })
+	for idx > 0 {
+		idx--
+		row := lt.Rows[idx]
+		if address < row.Address+row.Length {
+			return row, true
+		}
+		if row.Address < address {
+			break
+		}
+	}
+	return LineRow{}, false
+}
+
+// WriteText
+// writes the table in its text form.
+func (lt *LineTable) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, lineTableHeader)
+	files := make(map[string]int)
+	for _, row := range lt.Rows {
+		if _, found := files[row.File]; !found {
+			files[row.File] = len(files)
+			fmt.Fprintf(bw, "file %d %s\n", files[row.File], row.File)
+		}
+	}
+	for _, row := range lt.Rows {
+		fmt.Fprintf(bw, "row %x %x %d %d\n", row.Address, row.Length, files[row.File], row.Line)
+	}
+	return bw.Flush()
+}
+
+// ReadLineTable
+// reads a line table in the text form WriteText writes.
+func ReadLineTable(r io.Reader) (*LineTable, error) {
+	scanner := bufio.NewScanner(r)
+	lt := &LineTable{Rows: make([]LineRow, 0)}
+	files := make(map[int]string)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := scanner.Text()
+		if lineNo == 1 {
+			if text != lineTableHeader {
+				return nil, fmt.Errorf("line 1: expected %q", lineTableHeader)
+			}
+			continue
+		}
+		kind, rest, _ := strings.Cut(text, " ")
+		switch kind {
+		case "file":
+			num, name, _ := strings.Cut(rest, " ")
+			id, err := strconv.Atoi(num)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid file number %q", lineNo, num)
+			}
+			files[id] = name
+		case "row":
+			var row LineRow
+			var file int
+			if _, err := fmt.Sscanf(rest, "%x %x %d %d", &row.Address, &row.Length, &file, &row.Line); err != nil {
+				return nil, fmt.Errorf("line %d: invalid row: %v", lineNo, err)
+			}
+			name, found := files[file]
+			if !found {
+				return nil, fmt.Errorf("line %d: undeclared file %d", lineNo, file)
+			}
+			row.File = name
+			lt.Rows = append(lt.Rows, row)
+		case "":
+		default:
+			return nil, fmt.Errorf("line %d: unknown record %q", lineNo, kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lineNo == 0 {
+		return nil, fmt.Errorf("line 1: expected %q", lineTableHeader)
+	}
+	return lt, nil
+}
@@ -0,0 +1,74 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildLineTable(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	result := parseTestSource(t, p, "ldi r3, 7f\nnop\n; comment\nend: br end\n")
+	lt := BuildLineTable(result.Lines)
+	want := []LineRow{{0, 2, "", 1}, {2, 1, "", 2}, {3, 2, "", 4}}
+	if !reflect.DeepEqual(lt.Rows, want) {
+		t.Fatalf("rows = %+v, want %+v", lt.Rows, want)
+	}
+	for _, tt := range []struct {
+		address uint64
+		line    int
+		found   bool
+	}{
+		{0, 1, true},
+		{1, 1, true},
+		{2, 2, true},
+		{4, 4, true},
+		{5, 0, false},
+	} {
+		if row, found := lt.Lookup(tt.address); found != tt.found || row.Line != tt.line {
+			t.Errorf("Lookup(%d) = %+v, %v, want line %d", tt.address, row, found, tt.line)
+		}
+	}
+	dm, ok, errmsg := p.BuildDebugMap(result.Lines)
+	if !ok {
+		t.Fatal(errmsg)
+	}
+	if rows := dm.LineTable().Rows; !reflect.DeepEqual(rows, []LineRow{{0, 2, "", 1}, {3, 2, "", 4}}) {
+		t.Errorf("debug map rows = %+v, want the encoded lines", rows)
+	}
+}
+
+func TestLineTableText(t *testing.T) {
+	lt := &LineTable{Rows: []LineRow{{0x100, 2, "main.asm", 7}, {0x102, 1, "lib.asm", 3}, {0x103, 4, "main.asm", 8}}}
+	var buf bytes.Buffer
+	if err := lt.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "tpline 1\nfile 0 main.asm\nfile 1 lib.asm\nrow 100 2 0 7\nrow 102 1 1 3\nrow 103 4 0 8\n"
+	if buf.String() != want {
+		t.Errorf("WriteText = %q, want %q", buf.String(), want)
+	}
+	read, err := ReadLineTable(&buf)
+	if err != nil || !reflect.DeepEqual(read, lt) {
+		t.Errorf("ReadLineTable = %+v, %v, want %+v", read, err, lt)
+	}
+}
+
+func TestReadLineTableErrors(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		err  string
+	}{
+		{"", `line 1: expected "tpline 1"`},
+		{"tpline 2\n", `line 1: expected "tpline 1"`},
+		{"tpline 1\nfile x a.asm\n", `line 2: invalid file number "x"`},
+		{"tpline 1\nrow 0 1 0 1\n", "line 2: undeclared file 0"},
+		{"tpline 1\nfile 0 a.asm\nrow 0 1\n", "line 3: invalid row: EOF"},
+		{"tpline 1\nsym main 0\n", `line 2: unknown record "sym"`},
+	} {
+		if _, err := ReadLineTable(strings.NewReader(tt.text)); err == nil || err.Error() != tt.err {
+			t.Errorf("ReadLineTable(%q) error = %v, want %q", tt.text, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package TemplateParser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SetLogger
+// sets the logger the parser reports its matching decisions to at debug level, for finding
+// out why a line does not match: the tokens of every line, with its sensitive operands
+// masked, each template tried with the reason it was rejected, and the outcome of the
+// line. Lines found in the line cache are reported as such. nil, the default, disables
+// logging. The logger's handler runs while the parser matches lines and must not call the
+// parser.
+func (p *Parser) SetLogger(logger *slog.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = logger
+}
+
+// Logger
+// returns the logger set with SetLogger.
+func (p *Parser) Logger() *slog.Logger {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.logger
+}
+
+// debugging
+// reports whether the parser has a logger taking debug records.
+func (p *Parser) debugging() bool {
+	return p.logger != nil && p.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// tokenSummary
+// describes the tokens of a line as Type(text), without the blanks. Tokens overlapping one
+// of spans of the line are shown as Type(<redacted>).
+func (config ParserConfig) tokenSummary(tokens []Token, spans []sourceSpan) string {
+	parts := make([]string, 0, len(tokens))
+	offset := 0
+	for _, token := range tokens {
+		start := offset
+		offset += len(token.text())
+		if isBlankToken(token) {
+			continue
+		}
+		text := token.ValueReceived
+		for _, span := range spans {
+			if span.start < offset && start < span.end {
+				text = RedactedValue
+				break
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s(%s)", config.tokenName(token.Type), text))
+	}
+	return strings.Join(parts, " ")
+}
+
+// logTokens
+// reports the tokens a line was split into, once it is matched, masking its sensitive
+// operands as its RawText is. The text and tokens of a line none of whose source may be
+// shown are left out.
+func (p *Parser) logTokens(lineNo int, line string, tokens []Token, result LineResult) {
+	if !p.debugging() {
+		return
+	}
+	attrs := []slog.Attr{slog.Int("line", lineNo)}
+	if texts, hidden := result.sensitiveTexts(nil); !hidden {
+		spans := sensitiveSpans(line, texts)
+		attrs = append(attrs, slog.String("text", maskSpans(line, spans)),
+			slog.String("tokens", p.config.tokenSummary(tokens, spans)))
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, "tokenized line", attrs...)
+}
+
+// logSkipped
+// reports the entries of a line's mnemonic that were not tried in a profile, with the
+// reason each is unavailable, such as a target option it requires.
+func (p *Parser) logSkipped(lineNo int, tokens []Token, profile string) {
+	if !p.debugging() {
+		return
+	}
+	for _, entry := range p.registry[p.mnemonicKey(FirstIdentifier(tokens))] {
+		ok, why := p.available(entry, profile)
+		if ok {
+			continue
+		}
+		p.logger.LogAttrs(context.Background(), slog.LevelDebug, "template skipped",
+			slog.Int("line", lineNo), slog.String("mnemonic", entry.Name),
+			slog.String("form", p.config.templateSpec(entry.form())), slog.String("reason", why))
+	}
+}
+
+// logAttempt
+// reports the outcome of matching a line against one template entry.
+func (p *Parser) logAttempt(entry *TemplateEntry, result LineResult) {
+	if !p.debugging() {
+		return
+	}
+	attrs := []slog.Attr{slog.Int("line", result.LineNumber), slog.String("mnemonic", entry.Name),
+		slog.String("form", p.config.templateSpec(entry.form()))}
+	if entry.Profile != "" {
+		attrs = append(attrs, slog.String("profile", entry.Profile))
+	}
+	if result.Ok {
+		p.logger.LogAttrs(context.Background(), slog.LevelDebug, "template matched", attrs...)
+		return
+	}
+	attrs = append(attrs, slog.String("error", result.Error), slog.Int("column", result.ErrorColumn))
+	if result.Expected != "" {
+		attrs = append(attrs, slog.String("expected", result.Expected))
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, "template rejected", attrs...)
+}
+
+// logResult
+// reports the outcome of a line: the directive or mnemonic it matched, or why it failed.
+func (p *Parser) logResult(result LineResult, cached bool) {
+	if !p.debugging() {
+		return
+	}
+	attrs := []slog.Attr{slog.Int("line", result.LineNumber)}
+	if cached {
+		attrs = append(attrs, slog.Bool("cached", true))
+	}
+	switch {
+	case result.Directive != "":
+		attrs = append(attrs, slog.String("directive", result.Directive))
+	case result.Mnemonic != "":
+		attrs = append(attrs, slog.String("mnemonic", result.Mnemonic))
+	}
+	if result.Ok {
+		p.logger.LogAttrs(context.Background(), slog.LevelDebug, "line matched", attrs...)
+		return
+	}
+	attrs = append(attrs, slog.String("error", result.Error))
+	if result.ErrorColumn > 0 {
+		attrs = append(attrs, slog.Int("column", result.ErrorColumn))
+	}
+	if result.Suggestions != nil && len(result.Suggestions.Mnemonics) > 0 {
+		attrs = append(attrs, slog.String("did_you_mean", strings.Join(result.Suggestions.Mnemonics, ", ")))
+	}
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, "line failed", attrs...)
+}
@@ -0,0 +1,27 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogTokensRedactsSensitiveOperands(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), sensitiveGrammar)
+	var buf bytes.Buffer
+	p.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if _, ok, errmsg := p.ParseLine("key r1, 5eed"); !ok {
+		t.Fatalf("ParseLine: %s", errmsg)
+	}
+	if _, ok, _ := p.ParseLine("key r1, 5eed, 1"); ok {
+		t.Fatal("ParseLine of a line with an extra operand succeeded")
+	}
+	logged := buf.String()
+	if strings.Contains(logged, "5eed") {
+		t.Errorf("log shows a sensitive operand:\n%s", logged)
+	}
+	if !strings.Contains(logged, "key r1, "+RedactedValue) {
+		t.Errorf("log does not show the masked line:\n%s", logged)
+	}
+}
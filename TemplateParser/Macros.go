@@ -0,0 +1,355 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMacroDepth is the expansion depth used when MacroTable.MaxDepth is zero.
+const DefaultMacroDepth = 16
+
+// Macro
+// is a text macro: invoking @Name or @Name(arg1, arg2) is replaced by Body, with $1, $2...
+// in the body replaced by the arguments. A body may contain newlines to expand into
+// several lines. The substituted body is expanded again, so macros passed as arguments
+// or used in the body are expanded after substitution.
+//
+// A macro with a Func is a preprocessor function instead. Its arguments are expanded
+// first, left to right, and quoted string arguments are unquoted before the call. The text
+// the function returns is inserted as is and is not expanded again, so a function cannot
+// smuggle macro invocations into the source.
+//
+// File and Line locate the definition of macros registered with DefineAt.
+type Macro struct {
+	Name string
+	Body string
+	Func PreprocessorFunc
+	File string
+	Line int
+}
+
+// PreprocessorFunc
+// computes the replacement text of a preprocessor function invocation such as @env("HOME").
+type PreprocessorFunc func(args []string) (string, error)
+
+// MacroTable
+// holds the macros a Parser expands before template matching. MaxDepth limits how deeply
+// macros may expand into other macros. Macros may be defined while other goroutines
+// expand lines with the table; MaxDepth must be set before the table is shared.
+type MacroTable struct {
+	mu       sync.RWMutex
+	macros   map[string]Macro
+	MaxDepth int
+}
+
+// NewMacroTable
+// creates an empty macro table.
+func NewMacroTable() *MacroTable {
+	return &MacroTable{macros: make(map[string]Macro)}
+}
+
+// Define
+// registers or replaces a macro. The name is given without the leading @.
+func (mt *MacroTable) Define(name string, body string) (bool, string) {
+	return mt.DefineAt(name, body, "", 0)
+}
+
+// DefineAt
+// registers or replaces a macro like Define, recording the file and line defining it so
+// that tokens produced by its expansion can point back to the definition.
+func (mt *MacroTable) DefineAt(name string, body string, file string, line int) (bool, string) {
+	name = strings.TrimPrefix(name, "@")
+	if !isMacroName(name) {
+		return false, fmt.Sprintf("Invalid macro name %q", name)
+	}
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.macros[name] = Macro{Name: name, Body: body, File: file, Line: line}
+	return true, ""
+}
+
+// DefineFunc
+// registers or replaces a preprocessor function. Only functions registered on the table
+// can be invoked from source text; nothing is available by default.
+func (mt *MacroTable) DefineFunc(name string, fn PreprocessorFunc) (bool, string) {
+	name = strings.TrimPrefix(name, "@")
+	if !isMacroName(name) {
+		return false, fmt.Sprintf("Invalid macro name %q", name)
+	}
+	if fn == nil {
+		return false, fmt.Sprintf("Preprocessor function @%s is nil", name)
+	}
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.macros[name] = Macro{Name: name, Func: fn}
+	return true, ""
+}
+
+// Lookup
+// returns the macro with the given name, without the leading @.
+func (mt *MacroTable) Lookup(name string) (Macro, bool) {
+	mt.mu.RLock()
+	defer mt.mu.RUnlock()
+	macro, found := mt.macros[strings.TrimPrefix(name, "@")]
+	return macro, found
+}
+
+// Expand
+// replaces every macro invocation in a line of text. Invocations inside quoted strings and
+// comments are left alone, as are names that are not in the table. Expansion fails if a
+// macro invokes itself, directly or indirectly, or nests deeper than MaxDepth.
+func (mt *MacroTable) Expand(line string) (string, bool, string) {
+	expanded, _, ok, errmsg := mt.expand(line, nil)
+	return expanded, ok, errmsg
+}
+
+// macroSpan
+// is the part [start, end) of an expansion's text produced by one macro invocation.
+// depth is 0 for invocations in the expanded line itself and grows by one for each
+// macro body the invocation is nested in.
+type macroSpan struct {
+	start, end int
+	depth      int
+	origin     TokenOrigin
+}
+
+// expand
+// performs Expand with the chain of macros currently being expanded. It also returns the
+// spans of the expansion produced by each invocation, each followed by the spans nested
+// in it.
+func (mt *MacroTable) expand(line string, active []string) (string, []macroSpan, bool, string) {
+	maxDepth := mt.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMacroDepth
+	}
+	var sb strings.Builder
+	var spans []macroSpan
+	inString := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString && c == '\\' && i+1 < len(line):
+			sb.WriteString(line[i : i+2])
+			i++
+			continue
+		case c == '"':
+			inString = !inString
+		case c == ';' && !inString:
+			sb.WriteString(line[i:])
+			return sb.String(), spans, true, ""
+		case c == '@' && !inString:
+			end := i + 1
+			for end < len(line) && isMacroNameByte(line[end], end == i+1) {
+				end++
+			}
+			macro, found := mt.Lookup(line[i+1 : end])
+			if !found {
+				break
+			}
+			args, next, ok := macroArgs(line, end)
+			if !ok {
+				return "", nil, false, fmt.Sprintf("Unterminated argument list for macro @%s", macro.Name)
+			}
+			for _, name := range active {
+				if name == macro.Name {
+					return "", nil, false, fmt.Sprintf("Macro cycle: @%s -> @%s",
+						strings.Join(active, " -> @"), macro.Name)
+				}
+			}
+			if len(active) >= maxDepth {
+				return "", nil, false, fmt.Sprintf("Macro expansion of @%s exceeds depth %d", macro.Name, maxDepth)
+			}
+			var body, errmsg string
+			var nested []macroSpan
+			if macro.Func != nil {
+				body, ok, errmsg = mt.call(macro, args, append(active, macro.Name))
+			} else {
+				body, nested, ok, errmsg = mt.expand(substituteArgs(macro.Body, args), append(active, macro.Name))
+			}
+			if !ok {
+				return "", nil, false, errmsg
+			}
+			start := sb.Len()
+			sb.WriteString(body)
+			spans = append(spans, macroSpan{start, sb.Len(), 0, invocationOrigin(macro, line, i)})
+			for _, span := range nested {
+				span.start, span.end, span.depth = span.start+start, span.end+start, span.depth+1
+				spans = append(spans, span)
+			}
+			i = next - 1
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), spans, true, ""
+}
+
+// invocationOrigin
+// describes the invocation of a macro at byte pos of text, which is a source line or the
+// body of the macro containing the invocation.
+func invocationOrigin(macro Macro, text string, pos int) TokenOrigin {
+	lineStart := strings.LastIndexByte(text[:pos], '\n') + 1
+	return TokenOrigin{Macro: macro.Name, Line: strings.Count(text[:pos], "\n") + 1, Column: pos - lineStart + 1,
+		DefinedFile: macro.File, DefinedLine: macro.Line}
+}
+
+// call
+// expands the arguments of a preprocessor function and invokes it.
+func (mt *MacroTable) call(macro Macro, args []string, active []string) (string, bool, string) {
+	values := make([]string, len(args))
+	for idx, arg := range args {
+		expanded, _, ok, errmsg := mt.expand(arg, active)
+		if !ok {
+			return "", false, errmsg
+		}
+		if strings.HasPrefix(expanded, "\"") {
+			unquoted, ok, errmsg := UnescapeString(expanded)
+			if !ok {
+				return "", false, fmt.Sprintf("@%s argument %d: %s", macro.Name, idx+1, errmsg)
+			}
+			expanded = unquoted
+		}
+		values[idx] = expanded
+	}
+	result, err := macro.Func(values)
+	if err != nil {
+		return "", false, fmt.Sprintf("@%s: %v", macro.Name, err)
+	}
+	return result, true, ""
+}
+
+// macroArgs
+// reads an optional parenthesized, comma separated argument list starting at pos.
+// Returns the arguments, the position after the list and whether it was terminated.
+func macroArgs(line string, pos int) ([]string, int, bool) {
+	if pos >= len(line) || line[pos] != '(' {
+		return nil, pos, true
+	}
+	args := make([]string, 0)
+	depth := 0
+	start := pos + 1
+	inString := false
+	for i := pos + 1; i < len(line); i++ {
+		switch c := line[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ')':
+			args = append(args, strings.TrimSpace(line[start:i]))
+			if len(args) == 1 && args[0] == "" {
+				args = args[:0]
+			}
+			return args, i + 1, true
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	return nil, len(line), false
+}
+
+// substituteArgs
+// replaces $1, $2... in a macro body with the corresponding arguments. Missing
+// arguments expand to nothing.
+func substituteArgs(body string, args []string) string {
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] == '$' && i+1 < len(body) && body[i+1] >= '1' && body[i+1] <= '9' {
+			end := i + 1
+			for end < len(body) && body[end] >= '0' && body[end] <= '9' {
+				end++
+			}
+			n, _ := strconv.Atoi(body[i+1 : end])
+			if n <= len(args) {
+				sb.WriteString(args[n-1])
+			}
+			i = end - 1
+			continue
+		}
+		sb.WriteByte(body[i])
+	}
+	return sb.String()
+}
+
+// isMacroName
+// reports whether a name follows the @macro token syntax.
+func isMacroName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isMacroNameByte(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// isMacroNameByte
+// reports whether c may appear in a macro name, at the first position or later.
+func isMacroNameByte(c byte, first bool) bool {
+	if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && ((c >= '0' && c <= '9') || c == '_')
+}
+
+// SetMacros
+// sets the macro table expanded by Parse, ParseAll and ParseSource before matching.
+func (p *Parser) SetMacros(mt *MacroTable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.macros = mt
+}
+
+// Macros
+// returns the parser's macro table, nil if none has been set.
+func (p *Parser) Macros() *MacroTable {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.macros
+}
+
+// expandedLine
+// is one line of the expansion of a source line, with the spans of its text produced by
+// macro invocations.
+type expandedLine struct {
+	text  string
+	spans []macroSpan
+}
+
+// expandLine
+// expands the macros of a table in a line and splits the result into the lines to be
+// parsed. A nil table expands nothing.
+func expandLine(mt *MacroTable, line string) ([]expandedLine, int, bool, string) {
+	if mt == nil {
+		return []expandedLine{{text: line}}, 0, true, ""
+	}
+	expanded, spans, ok, errmsg := mt.expand(line, nil)
+	if !ok {
+		return nil, len(spans), false, errmsg
+	}
+	parts := strings.Split(expanded, "\n")
+	lines := make([]expandedLine, len(parts))
+	start := 0
+	for idx, part := range parts {
+		end := start + len(part)
+		lines[idx].text = part
+		for _, span := range spans {
+			if span.start < end && span.end > start {
+				span.start, span.end = max(span.start, start)-start, min(span.end, end)-start
+				lines[idx].spans = append(lines[idx].spans, span)
+			}
+		}
+		start = end + 1
+	}
+	return lines, len(spans), true, ""
+}
@@ -0,0 +1,45 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMacroExpand(t *testing.T) {
+	mt := NewMacroTable()
+	for name, body := range map[string]string{
+		"two":   "2",
+		"add":   "add $1, $2",
+		"twice": "@add($1, $1)",
+		"loop":  "@loop",
+		"pair":  "nop\nnop",
+	} {
+		if ok, errmsg := mt.Define(name, body); !ok {
+			t.Fatalf("Define(%s): %s", name, errmsg)
+		}
+	}
+	tests := []struct {
+		line string
+		want string
+		err  string
+	}{
+		{"ldi r1, @two", "ldi r1, 2", ""},
+		{"@add(r1, r2)", "add r1, r2", ""},
+		{"@twice(r3)", "add r3, r3", ""},
+		{"@pair", "nop\nnop", ""},
+		{"ldi r1, 2", "ldi r1, 2", ""},
+		{"@loop", "", "Macro cycle"},
+	}
+	for _, tt := range tests {
+		got, ok, errmsg := mt.Expand(tt.line)
+		if tt.err != "" {
+			if ok || !strings.Contains(errmsg, tt.err) {
+				t.Errorf("Expand(%q) = %q, %v, %q, want an error about %s", tt.line, got, ok, errmsg, tt.err)
+			}
+			continue
+		}
+		if !ok || got != tt.want {
+			t.Errorf("Expand(%q) = %q, %v, %q, want %q", tt.line, got, ok, errmsg, tt.want)
+		}
+	}
+}
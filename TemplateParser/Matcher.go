@@ -0,0 +1,37 @@
+package TemplateParser
+
+import "fmt"
+
+// MatcherFunc
+// matches a template slot with custom logic, for operand forms the built-in token types do
+// not cover, such as vendor-specific addressing syntax like (r1)+ or #-4[r2]. It consumes
+// the tokens of the operand from the cursor, as many as it needs, and returns the object
+// standing for them; its type is set to the slot's TemplateType. A matcher is called even
+// at the end of the line, so it may match an operand from no tokens. An error fails the
+// line at the column where the matcher started.
+type MatcherFunc func(cursor *TokenCursor) (ObjectType, error)
+
+// matchSlot
+// runs the matcher of a template slot.
+func matchSlot(tmpl TemplateObject, cursor *TokenCursor) (ObjectType, bool, string) {
+	obj, err := tmpl.Hooks.Matcher(cursor)
+	obj.ObjectTypeId = tmpl.TemplateType
+	if err != nil {
+		if tmpl.TemplateError != "" {
+			return obj, false, fmt.Sprintf("%v: %s", err, tmpl.TemplateError)
+		}
+		return obj, false, err.Error()
+	}
+	return obj, true, ""
+}
+
+// matchedColumn
+// returns the 1-based column of the token object objIdx of a matched line started at.
+// starts holds the index in tokens of the first token of every object, which differs from
+// the count objectColumn makes once a matcher has consumed several tokens.
+func matchedColumn(allTokens []Token, tokens []Token, starts []int, objIdx int) int {
+	if objIdx < 0 || objIdx >= len(starts) {
+		return objectColumn(allTokens, tokens, max(objIdx, len(tokens)))
+	}
+	return tokenOffset(allTokens, len(allTokens)-len(tokens)+starts[objIdx]) + 1
+}
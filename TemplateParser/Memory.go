@@ -0,0 +1,224 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MemoryScale is the text between the index register of a Memory operand and its scale,
+// as in [r2 + r3*4].
+const MemoryScale = "*"
+
+// MemoryOperand
+// is the value of the object a Memory slot matches: an address formed of an optional
+// base register, an optional index register multiplied by Scale and a signed
+// displacement. Scale is 1 for an index written without one and 0 when there is no
+// index. The widths are those the register names carry, as for Register objects.
+type MemoryOperand struct {
+	HasBase      bool   `json:"hasBase,omitempty"`
+	Base         uint64 `json:"base,omitempty"`
+	BaseWidth    int    `json:"baseWidth,omitempty"`
+	HasIndex     bool   `json:"hasIndex,omitempty"`
+	Index        uint64 `json:"index,omitempty"`
+	IndexWidth   int    `json:"indexWidth,omitempty"`
+	Scale        uint64 `json:"scale,omitempty"`
+	Displacement int64  `json:"displacement,omitempty"`
+}
+
+// String
+// writes the operand as [r2 + r3*4 + 0x10], registers by number and the displacement in
+// hex, for display. Format writes it back as source text.
+func (mem MemoryOperand) String() string {
+	var terms []string
+	if mem.HasBase {
+		terms = append(terms, fmt.Sprintf("r%x", mem.Base))
+	}
+	if mem.HasIndex && mem.Scale != 1 {
+		terms = append(terms, fmt.Sprintf("r%x%s%d", mem.Index, MemoryScale, mem.Scale))
+	} else if mem.HasIndex {
+		terms = append(terms, fmt.Sprintf("r%x", mem.Index))
+	}
+	return memoryText(terms, mem.Displacement, fmt.Sprintf("%#x", absDisplacement(mem.Displacement)))
+}
+
+// memoryText
+// joins the register terms of a memory operand and its displacement, written as disp
+// without its sign, into [term + term - disp]. A zero displacement is left out unless it
+// is the only term.
+func memoryText(terms []string, displacement int64, disp string) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	sb.WriteString(strings.Join(terms, " + "))
+	switch {
+	case len(terms) == 0 && displacement < 0:
+		sb.WriteString("-" + disp)
+	case len(terms) == 0:
+		sb.WriteString(disp)
+	case displacement < 0:
+		sb.WriteString(" - " + disp)
+	case displacement > 0:
+		sb.WriteString(" + " + disp)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// absDisplacement
+// returns the magnitude of a displacement, which holds for math.MinInt64 too.
+func absDisplacement(displacement int64) uint64 {
+	if displacement < 0 {
+		return uint64(-(displacement + 1)) + 1
+	}
+	return uint64(displacement)
+}
+
+// uses
+// reports whether the operand addresses through register reg.
+func (mem MemoryOperand) uses(reg uint64) bool {
+	return (mem.HasBase && mem.Base == reg) || (mem.HasIndex && mem.Index == reg)
+}
+
+// matchMemory
+// matches a Memory slot: a [ followed by terms joined by + or - and a ], the first term
+// possibly preceded by a -. A term is a register, a register and its scale joined by
+// MemoryScale, or an integer. The first register without a scale is the base and any
+// other register the index, so at most two registers may be given and only one with a
+// scale; the integers add up to the displacement. Registers cannot be subtracted.
+func matchMemory(tmpl TemplateObject, cursor *TokenCursor, config ParserConfig) (ObjectType, bool, string) {
+	obj := newObject(TokenMemory, MemoryOperand{}, "")
+	fail := func(format string, args ...interface{}) (ObjectType, bool, string) {
+		return obj, false, fmt.Sprintf(format, args...) + ": " + tmpl.TemplateError
+	}
+	got := func() string {
+		token, ok := cursor.Peek()
+		switch {
+		case !ok:
+			return "the end of the line"
+		case tmpl.Sensitive:
+			return RedactedValue
+		}
+		return token.ValueReceived
+	}
+	if token, ok := cursor.Peek(); !ok || token.Type != TokenLBracket {
+		return fail("Expected a memory operand but got %s", got())
+	}
+	cursor.Next()
+	var mem MemoryOperand
+	negative := false
+	if token, ok := cursor.Peek(); ok && token.Type == TokenMinus {
+		negative = true
+		cursor.Next()
+	}
+	for {
+		token, ok := cursor.Peek()
+		switch {
+		case ok && token.Type == TokenRegister:
+			if negative {
+				return fail("A register cannot be subtracted in a memory operand")
+			}
+			reg, width, err := config.parseRegister(token.ValueReceived)
+			if err != nil {
+				return fail("Invalid register %s in a memory operand", got())
+			}
+			scale := uint64(0)
+			if cursor.nextSeparator(MemoryScale) {
+				factor, ok := cursor.Peek()
+				if !ok || !isIntegerToken(factor.Type) {
+					return fail("Expected the scale of register %s but got %s", token.ValueReceived, got())
+				}
+				if scale, err = config.parseNumber(factor.ValueReceived); err != nil || scale == 0 {
+					return fail("Invalid scale %s in a memory operand", got())
+				}
+				cursor.Next()
+			}
+			switch {
+			case scale == 0 && !mem.HasBase:
+				mem.HasBase, mem.Base, mem.BaseWidth = true, reg, width
+			case !mem.HasIndex:
+				mem.HasIndex, mem.Index, mem.IndexWidth, mem.Scale = true, reg, width, max(scale, 1)
+			default:
+				return fail("A memory operand takes at most a base and an index register")
+			}
+		case ok && isIntegerToken(token.Type):
+			val, err := config.parseNumber(token.ValueReceived)
+			if err != nil {
+				return fail("Invalid displacement %s in a memory operand", got())
+			}
+			if !addDisplacement(&mem.Displacement, val, negative) {
+				return fail("The displacement of the memory operand does not fit in 64 bits")
+			}
+			cursor.Next()
+		default:
+			return fail("Expected a register or displacement in a memory operand but got %s", got())
+		}
+		token, ok = cursor.Peek()
+		switch {
+		case ok && (token.Type == TokenPlus || token.Type == TokenMinus):
+			negative = token.Type == TokenMinus
+			cursor.Next()
+		case ok && token.Type == TokenRBracket:
+			cursor.Next()
+			obj.ObjectValue = mem
+			return obj, true, ""
+		default:
+			return fail("Expected +, - or ] in a memory operand but got %s", got())
+		}
+	}
+}
+
+// addDisplacement
+// adds an integer term, subtracting it if negative, to a displacement, reporting false if
+// the sum overflows.
+func addDisplacement(displacement *int64, val uint64, negative bool) bool {
+	sum := *displacement
+	switch {
+	case !negative && val <= math.MaxInt64 && sum <= math.MaxInt64-int64(val):
+		sum += int64(val)
+	case negative && val <= math.MaxInt64 && sum >= math.MinInt64+int64(val):
+		sum -= int64(val)
+	case negative && val == 1<<63 && sum >= 0:
+		sum = sum + math.MinInt64
+	default:
+		return false
+	}
+	*displacement = sum
+	return true
+}
+
+// formatMemory
+// writes a memory operand as source text, the registers as formatRegister writes them
+// and the displacement and scale as numbers the tokenizer reads back.
+func (p *Parser) formatMemory(obj ObjectType) (string, bool, string) {
+	mem, isMemory := obj.ObjectValue.(MemoryOperand)
+	if !isMemory {
+		return "", false, fmt.Sprintf("memory operand %v is not a MemoryOperand", obj.ObjectValue)
+	}
+	var terms []string
+	if mem.HasBase {
+		text, ok, errmsg := p.formatRegister(ObjectType{ObjectTypeId: TokenRegister, ObjectValue: mem.Base, ObjectWidth: mem.BaseWidth})
+		if !ok {
+			return "", false, errmsg
+		}
+		terms = append(terms, text)
+	}
+	if mem.HasIndex {
+		text, ok, errmsg := p.formatRegister(ObjectType{ObjectTypeId: TokenRegister, ObjectValue: mem.Index, ObjectWidth: mem.IndexWidth})
+		if !ok {
+			return "", false, errmsg
+		}
+		if mem.Scale != 1 {
+			scale, ok, errmsg := p.formatNumber(ObjectType{ObjectTypeId: TokenUint64, ObjectValue: mem.Scale}, TokenUint8, true)
+			if !ok {
+				return "", false, errmsg
+			}
+			text += MemoryScale + scale
+		}
+		terms = append(terms, text)
+	}
+	disp, ok, errmsg := p.formatNumber(ObjectType{ObjectTypeId: TokenUint64, ObjectValue: absDisplacement(mem.Displacement)}, TokenUint8, true)
+	if !ok {
+		return "", false, errmsg
+	}
+	return memoryText(terms, mem.Displacement, disp), true, ""
+}
@@ -0,0 +1,102 @@
+package TemplateParser
+
+import (
+	"math"
+	"testing"
+)
+
+// memoryTemplate is a load from a memory operand.
+var memoryTemplate = []TemplateObject{
+	{TemplateType: TokenIdentifier},
+	{TemplateType: TokenRegister},
+	{TemplateType: TokenComma},
+	{TemplateType: TokenMemory, TemplateError: "bad address"},
+}
+
+func TestMemorySlot(t *testing.T) {
+	tests := []struct {
+		line string
+		want MemoryOperand
+	}{
+		{"ld r1, [r2]", MemoryOperand{HasBase: true, Base: 2}},
+		{"ld r1, [r2 + 10]", MemoryOperand{HasBase: true, Base: 2, Displacement: 0x10}},
+		{"ld r1, [r2 - 10]", MemoryOperand{HasBase: true, Base: 2, Displacement: -0x10}},
+		{"ld r1, [r2 + r3*4 + 8]", MemoryOperand{HasBase: true, Base: 2, HasIndex: true, Index: 3, Scale: 4, Displacement: 8}},
+		{"ld r1, [r3*2]", MemoryOperand{HasIndex: true, Index: 3, Scale: 2}},
+		{"ld r1, [r2 + r3]", MemoryOperand{HasBase: true, Base: 2, HasIndex: true, Index: 3, Scale: 1}},
+		{"ld r1, [-20 + 4]", MemoryOperand{Displacement: -0x1c}},
+		{"ld r1, [1000]", MemoryOperand{Displacement: 0x1000}},
+	}
+	for _, tt := range tests {
+		objs, ok, errmsg := ParseLine(tt.line, memoryTemplate)
+		if !ok {
+			t.Errorf("ParseLine(%q) failed: %s", tt.line, errmsg)
+			continue
+		}
+		if obj := objs[3]; obj.ObjectTypeId != TokenMemory || obj.ObjectValue != tt.want {
+			t.Errorf("ParseLine(%q) memory = %+v, want %+v", tt.line, obj.ObjectValue, tt.want)
+		}
+	}
+}
+
+func TestMemorySlotErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		err  string
+	}{
+		{"ld r1, r2", "Expected a memory operand but got r2: bad address"},
+		{"ld r1, [r2 + r3 + r4]", "A memory operand takes at most a base and an index register: bad address"},
+		{"ld r1, [10 - r2]", "A register cannot be subtracted in a memory operand: bad address"},
+		{"ld r1, [r2*0]", "Invalid scale 0 in a memory operand: bad address"},
+		{"ld r1, [r2 r3]", "Expected +, - or ] in a memory operand but got r3: bad address"},
+		{"ld r1, [r2 +", "Expected a register or displacement in a memory operand but got the end of the line: bad address"},
+	}
+	for _, tt := range tests {
+		if _, ok, errmsg := ParseLine(tt.line, memoryTemplate); ok || errmsg != tt.err {
+			t.Errorf("ParseLine(%q) = %v, %q, want %q", tt.line, ok, errmsg, tt.err)
+		}
+	}
+}
+
+func TestAddDisplacement(t *testing.T) {
+	for _, tt := range []struct {
+		start    int64
+		val      uint64
+		negative bool
+		want     int64
+		ok       bool
+	}{
+		{0, 5, false, 5, true},
+		{0, 5, true, -5, true},
+		{math.MaxInt64, 1, false, math.MaxInt64, false},
+		{0, 1 << 63, true, math.MinInt64, true},
+		{-1, 1 << 63, true, -1, false},
+	} {
+		got := tt.start
+		if ok := addDisplacement(&got, tt.val, tt.negative); ok != tt.ok || got != tt.want {
+			t.Errorf("addDisplacement(%d, %#x, %v) = %d, %v, want %d, %v", tt.start, tt.val, tt.negative, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMemoryOperandText(t *testing.T) {
+	mem := MemoryOperand{HasBase: true, Base: 2, HasIndex: true, Index: 3, Scale: 4, Displacement: -0x10}
+	if got := mem.String(); got != "[r2 + r3*4 - 0x10]" {
+		t.Errorf("String() = %q", got)
+	}
+	p := NewParser(DefaultParserConfig())
+	for _, line := range []string{"ld r1, [r2 + r3*4 - 10]", "ld r1, [-8]", "ld r1, [r2]"} {
+		objs, ok, errmsg := ParseLine(line, memoryTemplate)
+		if !ok {
+			t.Fatalf("ParseLine(%q) failed: %s", line, errmsg)
+		}
+		text, ok, errmsg := p.Format(memoryTemplate, objs)
+		if !ok {
+			t.Fatalf("Format(%q) failed: %s", line, errmsg)
+		}
+		back, ok, _ := ParseLine(text, memoryTemplate)
+		if !ok || back[3].ObjectValue != objs[3].ObjectValue {
+			t.Errorf("%q formatted as %q, which parses to %v", line, text, back)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+)
+
+// MutationKind
+// is a kind of change a Mutator makes to a line.
+type MutationKind int
+
+const (
+	MutatePerturb  MutationKind = iota // Change an integer, register or character operand
+	MutateSwap                         // Swap two operands of the same type
+	MutateRetarget                     // Point a label operand at another label of the program
+)
+
+// mutationKindNames names the mutation kinds.
+var mutationKindNames = []string{"perturb", "swap", "retarget"}
+
+// String
+// returns the name of a mutation kind.
+func (kind MutationKind) String() string {
+	if kind >= 0 && int(kind) < len(mutationKindNames) {
+		return mutationKindNames[kind]
+	}
+	return fmt.Sprintf("MutationKind(%d)", int(kind))
+}
+
+// Mutation
+// records a change a Mutator made: Line is the index of the changed line in the source
+// result, File and LineNumber locate it in the source, Slot is the operand changed and
+// Other the second operand of a swap. Before and After are the text of the line.
+type Mutation struct {
+	Kind       MutationKind `json:"kind"`
+	Line       int          `json:"line"`
+	File       string       `json:"file,omitempty"`
+	LineNumber int          `json:"line_number"`
+	Slot       int          `json:"slot"`
+	Other      int          `json:"other,omitempty"`
+	Before     string       `json:"before"`
+	After      string       `json:"after"`
+}
+
+// Mutator
+// derives random variants of a parsed program for differential testing of tools built on
+// the parser, such as simulators and encoders: run the original and the variants through
+// two implementations and compare. Mutations follow the templates the lines matched:
+// integer operands get values at and around the bounds of their slot, registers take
+// numbers used elsewhere in the program, operands of the same type are swapped and label
+// operands pointed at other labels. Kinds limits the mutations made, all kinds when empty.
+// Unless KeepInvalid is set, a mutated line is parsed again and the mutation dropped if the
+// grammar rejects it, so every variant still parses line by line.
+type Mutator struct {
+	Kinds       []MutationKind
+	KeepInvalid bool
+	parser      *Parser
+	rand        *rand.Rand
+}
+
+// mutateAttempts is how many times Mutate tries per mutation asked for before giving up.
+const mutateAttempts = 16
+
+// NewMutator
+// creates a mutator formatting and checking lines with parser. The same seed and program
+// give the same mutations.
+func NewMutator(parser *Parser, seed int64) *Mutator {
+	return &Mutator{parser: parser, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Mutate
+// applies up to n mutations to a successfully parsed source and returns the mutated
+// program as source text, one line per line of the result, with the mutations made. The
+// program is emitted with its macros expanded and its includes inlined. Fewer mutations
+// are made when the program offers too few places to make them. Sources with failed lines
+// or lines that cannot be formatted are rejected.
+func (m *Mutator) Mutate(sr *SourceResult, n int) (string, []Mutation, error) {
+	lines := make([]ParsedLine, len(sr.Lines))
+	texts := make([]string, len(sr.Lines))
+	for idx, lr := range sr.Lines {
+		if !lr.Ok {
+			return "", nil, fmt.Errorf("%s: line failed: %s", ParseError{File: lr.File, Line: lr.LineNumber}.position(), lr.Error)
+		}
+		text, ok, errmsg := m.parser.FormatLine(lr.ParsedLine)
+		if !ok {
+			return "", nil, fmt.Errorf("%s: %s", ParseError{File: lr.File, Line: lr.LineNumber}.position(), errmsg)
+		}
+		lines[idx], texts[idx] = lr.ParsedLine, text
+		lines[idx].Objects = append([]ObjectType(nil), lr.Objects...)
+	}
+	pool := mutationPool{labels: make([]string, 0), registers: make([]uint64, 0)}
+	if sr.Symbols != nil {
+		for _, sym := range sr.Symbols.Symbols() {
+			pool.labels = append(pool.labels, sym.Name)
+		}
+	}
+	for _, lr := range sr.Lines {
+		for _, obj := range lr.Objects {
+			if num, isInt := obj.ObjectValue.(uint64); isInt && obj.ObjectTypeId == TokenRegister {
+				pool.registers = append(pool.registers, num)
+			}
+		}
+	}
+	mutations := make([]Mutation, 0, n)
+	for attempt := 0; attempt < n*mutateAttempts && len(mutations) < n && len(sr.Lines) > 0; attempt++ {
+		idx := m.rand.Intn(len(sr.Lines))
+		if sr.Lines[idx].entry == nil {
+			continue
+		}
+		tmpl := sr.Lines[idx].entry.Objects
+		mutated := lines[idx]
+		mutated.Objects = append([]ObjectType(nil), lines[idx].Objects...)
+		mutation, changed := m.mutateLine(&mutated, tmpl, pool)
+		if !changed {
+			continue
+		}
+		text, ok, _ := m.parser.FormatLine(mutated)
+		if !ok || text == texts[idx] || (!m.KeepInvalid && !m.parses(sr.Lines[idx], text)) {
+			continue
+		}
+		mutation.Line, mutation.File, mutation.LineNumber = idx, sr.Lines[idx].File, sr.Lines[idx].LineNumber
+		mutation.Before, mutation.After = texts[idx], text
+		mutations = append(mutations, mutation)
+		lines[idx], texts[idx] = mutated, text
+	}
+	return strings.Join(texts, "\n") + "\n", mutations, nil
+}
+
+// mutationPool
+// holds what mutations may draw on from the whole program.
+type mutationPool struct {
+	labels    []string
+	registers []uint64
+}
+
+// mutateLine
+// makes one random mutation of the allowed kinds to the objects of a line, reporting
+// whether the line changed.
+func (m *Mutator) mutateLine(pl *ParsedLine, tmpl []TemplateObject, pool mutationPool) (Mutation, bool) {
+	kinds := m.Kinds
+	if len(kinds) == 0 {
+		kinds = []MutationKind{MutatePerturb, MutateSwap, MutateRetarget}
+	}
+	kind := kinds[m.rand.Intn(len(kinds))]
+	slots := make([]int, 0, len(pl.Objects))
+	for idx := range pl.Objects {
+		if idx > 0 && idx < len(tmpl) && mutable(kind, pl.Objects[idx]) {
+			slots = append(slots, idx)
+		}
+	}
+	if len(slots) == 0 {
+		return Mutation{}, false
+	}
+	slot := slots[m.rand.Intn(len(slots))]
+	obj := &pl.Objects[slot]
+	switch kind {
+	case MutatePerturb:
+		return Mutation{Kind: kind, Slot: slot}, m.perturb(obj, tmpl[slot], pool)
+	case MutateSwap:
+		for _, other := range m.rand.Perm(len(slots)) {
+			peer := &pl.Objects[slots[other]]
+			if slots[other] != slot && peer.ObjectTypeId == obj.ObjectTypeId {
+				*obj, *peer = *peer, *obj
+				return Mutation{Kind: kind, Slot: min(slot, slots[other]), Other: max(slot, slots[other])}, true
+			}
+		}
+	case MutateRetarget:
+		if len(pool.labels) > 0 {
+			obj.ObjectValue = pool.labels[m.rand.Intn(len(pool.labels))]
+			obj.ObjectDescriptor = ""
+			return Mutation{Kind: kind, Slot: slot}, true
+		}
+	}
+	return Mutation{}, false
+}
+
+// mutable
+// reports whether a mutation of the given kind can apply to an object.
+func mutable(kind MutationKind, obj ObjectType) bool {
+	labelRef := obj.ObjectTypeId == TokenLabelRef || obj.ObjectTypeId == TokenLabelRel
+	numeric := isIntegerToken(obj.ObjectTypeId) || isWideTemplate(obj.ObjectTypeId)
+	switch kind {
+	case MutatePerturb:
+		_, isInt := ToBigInt(obj)
+		return isInt && (numeric || obj.ObjectTypeId == TokenRegister || obj.ObjectTypeId == TokenChar)
+	case MutateSwap:
+		return obj.ObjectTypeId == TokenRegister || labelRef || numeric
+	case MutateRetarget:
+		return labelRef
+	}
+	return false
+}
+
+// perturb
+// gives an integer, register or character object a new value: a bound of its slot's range,
+// a neighbour of its value, a value with one bit flipped or, for registers, a register used
+// elsewhere in the program.
+func (m *Mutator) perturb(obj *ObjectType, tmpl TemplateObject, pool mutationPool) bool {
+	val, _ := ToBigInt(*obj)
+	lo, hi := perturbRange(*obj, tmpl)
+	next := new(big.Int)
+	switch choice := m.rand.Intn(5); {
+	case obj.ObjectTypeId == TokenRegister && choice == 0 && len(pool.registers) > 0:
+		next.SetUint64(pool.registers[m.rand.Intn(len(pool.registers))])
+	case choice <= 1:
+		next.Set(lo)
+		if m.rand.Intn(2) == 0 {
+			next.Set(hi)
+		}
+	case choice == 2:
+		next.Add(val, big.NewInt(1))
+	case choice == 3:
+		next.Sub(val, big.NewInt(1))
+	default:
+		next.Xor(val, new(big.Int).Lsh(big.NewInt(1), uint(m.rand.Intn(max(hi.BitLen(), 1)))))
+	}
+	if next.Cmp(lo) < 0 || next.Cmp(hi) > 0 || next.Cmp(val) == 0 {
+		return false
+	}
+	if _, wide := obj.ObjectValue.(*big.Int); wide || !next.IsUint64() {
+		obj.ObjectValue = next
+	} else {
+		obj.ObjectValue = next.Uint64()
+	}
+	obj.ObjectDescriptor = ""
+	return true
+}
+
+// perturbBits are the widths of the values the integer token types hold.
+var perturbBits = map[int]int{
+	TokenUint8:    8,
+	TokenUint16:   16,
+	TokenUint32:   32,
+	TokenUint64:   64,
+	TokenUint128:  128,
+	TokenUint256:  256,
+	TokenChar:     7,
+	TokenRegister: 8,
+}
+
+// perturbRange
+// returns the values perturb may give an object: those its token type holds, within the
+// range of its slot when the slot has one. Characters stay printable ASCII.
+func perturbRange(obj ObjectType, tmpl TemplateObject) (*big.Int, *big.Int) {
+	bits, found := perturbBits[obj.ObjectTypeId]
+	if !found {
+		val, _ := ToBigInt(obj)
+		bits = val.BitLen() + 8
+	}
+	lo := new(big.Int)
+	hi := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+	if obj.ObjectTypeId == TokenChar {
+		lo.SetUint64(' ')
+		hi.SetUint64('~')
+	}
+	if tmpl.HasRange() {
+		if slotLo := new(big.Int).SetUint64(tmpl.MinValue); slotLo.Cmp(lo) > 0 {
+			lo = slotLo
+		}
+		if slotHi := new(big.Int).SetUint64(maxValue(tmpl)); slotHi.Cmp(hi) < 0 {
+			hi = slotHi
+		}
+	}
+	return lo, hi
+}
+
+// parses
+// reports whether the mutated text of a line still parses as a single line.
+func (m *Mutator) parses(lr LineResult, text string) bool {
+	results := m.parser.parseExpanded(lr.LineNumber, text)
+	return len(results) == 1 && results[0].Ok
+}
@@ -0,0 +1,94 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mutatorGrammar has register, bounded immediate and label operands to mutate.
+const mutatorGrammar = `templates:
+  - mnemonic: mov
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Register}
+  - mnemonic: li
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8, min: 1, max: 10}
+  - mnemonic: jmp
+    size: 2
+    operands:
+      - {type: Identifier}
+      - {type: LabelRef}
+`
+
+// mutatorSource is a program using every template of mutatorGrammar.
+const mutatorSource = "start: li r1, 5\nmov r1, r2\nnext: jmp start\nmov r3, r4\njmp next\n"
+
+func TestMutate(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), mutatorGrammar)
+	sr := parseTestSource(t, p, mutatorSource)
+	text, mutations, err := NewMutator(p, 1).Mutate(sr, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mutations) != 4 {
+		t.Fatalf("%d mutations, want 4", len(mutations))
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) != len(sr.Lines) {
+		t.Fatalf("mutated program has %d lines, want %d", len(lines), len(sr.Lines))
+	}
+	for _, mutation := range mutations {
+		if mutation.Before == mutation.After || mutation.LineNumber != mutation.Line+1 {
+			t.Errorf("mutation = %+v", mutation)
+		}
+	}
+	mutated := parseTestSource(t, p, text)
+	for _, lr := range mutated.Lines {
+		if !lr.Ok {
+			t.Errorf("mutated line %q failed: %s", lr.RawText, lr.Error)
+		}
+	}
+	again, repeated, _ := NewMutator(p, 1).Mutate(sr, 4)
+	if again != text || !reflect.DeepEqual(repeated, mutations) {
+		t.Errorf("the same seed gave different mutations")
+	}
+}
+
+func TestMutateKinds(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), mutatorGrammar)
+	sr := parseTestSource(t, p, mutatorSource)
+	m := NewMutator(p, 2)
+	m.Kinds = []MutationKind{MutateSwap}
+	_, mutations, err := m.Mutate(sr, 3)
+	if err != nil || len(mutations) == 0 {
+		t.Fatalf("Mutate = %v, %v, want swaps", mutations, err)
+	}
+	for _, mutation := range mutations {
+		if mutation.Kind != MutateSwap || mutation.Slot != 1 || mutation.Other != 3 {
+			t.Errorf("mutation = %+v, want a swap of the mov registers", mutation)
+		}
+	}
+}
+
+func TestMutateRejectsFailedLines(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), mutatorGrammar)
+	sr := parseTestSource(t, p, "mov r1, r2\nli r1, 20\n")
+	if _, _, err := NewMutator(p, 1).Mutate(sr, 1); err == nil || !strings.HasPrefix(err.Error(), "2: line failed: ") {
+		t.Errorf("Mutate error = %v, want the failed line 2", err)
+	}
+}
+
+func TestMutationKindString(t *testing.T) {
+	for kind, want := range map[MutationKind]string{MutatePerturb: "perturb", MutateSwap: "swap", MutateRetarget: "retarget", 7: "MutationKind(7)"} {
+		if got := kind.String(); got != want {
+			t.Errorf("MutationKind(%d).String() = %q, want %q", int(kind), got, want)
+		}
+	}
+}
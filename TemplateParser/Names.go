@@ -0,0 +1,47 @@
+package TemplateParser
+
+import "fmt"
+
+// NamedObjects
+// collects the objects of the template slots that have a Name, keyed by it, so a matched
+// line can be read as objs["dest"] rather than by position. Returns nil if no slot is
+// named.
+func NamedObjects(templateList []TemplateObject, objList []ObjectType) map[string]ObjectType {
+	var named map[string]ObjectType
+	for idx, tmpl := range templateList {
+		if tmpl.Name == "" || idx >= len(objList) {
+			continue
+		}
+		if named == nil {
+			named = make(map[string]ObjectType)
+		}
+		named[tmpl.Name] = objList[idx]
+	}
+	return named
+}
+
+// ValidateNames
+// checks that no two slots of a template list share a Name.
+func ValidateNames(templateList []TemplateObject) (bool, string) {
+	slots := make(map[string]int)
+	for idx, tmpl := range templateList {
+		if tmpl.Name == "" {
+			continue
+		}
+		if prev, dup := slots[tmpl.Name]; dup {
+			return false, fmt.Sprintf("Name %q is used by slots %d and %d", tmpl.Name, prev, idx)
+		}
+		slots[tmpl.Name] = idx
+	}
+	return true, ""
+}
+
+// ParseLineNamed
+// is ParseLine also returning the objects of the named slots of templateList.
+func ParseLineNamed(txt string, templateList []TemplateObject) (map[string]ObjectType, []ObjectType, bool, string) {
+	objs, ok, errmsg := ParseLine(txt, templateList)
+	if !ok {
+		return nil, objs, false, errmsg
+	}
+	return NamedObjects(templateList, objs), objs, true, ""
+}
@@ -0,0 +1,197 @@
+package TemplateParser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalizer
+// is a set of transformations applied to a line before it is tokenized, such as
+// ParserConfig.Normalize makes TokenizeLine and the parse functions apply. Quoted strings,
+// character literals and comments are kept as they are. Unlike the lowercasing the
+// tokenizer does, the transformations may change the length of the line: the
+// NormalizedLine they produce maps every position back to the line as written, so error
+// columns, warnings and the ObjectText of objects refer to the original text. The zero
+// value changes nothing.
+type Normalizer struct {
+	// FoldCase lowercases every character, including those whose lowercase form is longer
+	// or shorter than they are, such as İ, which the tokenizer's lowercasing keeps.
+	FoldCase bool
+	// CollapseSpace replaces every run of spaces and tabs with a single space.
+	CollapseSpace bool
+	// TabWidth, when positive, replaces tabs with the spaces up to the next multiple of
+	// TabWidth display columns, counted as DisplayColumn counts them. CollapseSpace, if set,
+	// then reduces them to one space.
+	TabWidth int
+}
+
+// NormalizedLine
+// is a line as a Normalizer transformed it, with the mapping of its positions back to the
+// line it came from.
+type NormalizedLine struct {
+	Text   string // The normalized line
+	Source string // The line as written
+	// offsets holds the offset in Source of every byte of Text, followed by the length of
+	// Source; nil when Text is Source
+	offsets []int
+}
+
+// active
+// reports whether the normalizer changes anything.
+func (n Normalizer) active() bool {
+	return n.FoldCase || n.CollapseSpace || n.TabWidth > 0
+}
+
+// Normalize
+// applies the normalizer's transformations to a line.
+func (n Normalizer) Normalize(line string) NormalizedLine {
+	if !n.active() {
+		return NormalizedLine{Text: line, Source: line}
+	}
+	var sb strings.Builder
+	sb.Grow(len(line))
+	offsets := make([]int, 0, len(line)+1)
+	emit := func(text string, offset int) {
+		sb.WriteString(text)
+		for range len(text) {
+			offsets = append(offsets, offset)
+		}
+	}
+	inString, blank, cells := false, false, 0
+	for idx := 0; idx < len(line); {
+		c := line[idx]
+		if !inString && c == ';' {
+			for ; idx < len(line); idx++ {
+				emit(line[idx:idx+1], idx)
+			}
+			break
+		}
+		if !inString && (c == ' ' || c == '\t') {
+			width := 1
+			if c == '\t' {
+				width = tabCells(cells, n.TabWidth)
+			}
+			switch {
+			case n.CollapseSpace && blank:
+			case n.CollapseSpace:
+				emit(" ", idx)
+			case c == '\t' && n.TabWidth > 0:
+				emit(strings.Repeat(" ", width), idx)
+			default:
+				emit(line[idx:idx+1], idx)
+			}
+			blank, cells = true, cells+width
+			idx++
+			continue
+		}
+		blank = false
+		length, fold := 1, n.FoldCase && !inString
+		switch {
+		case c == '"':
+			inString, fold = !inString, false
+		case c == '\\' && inString:
+			length = min(2, len(line)-idx)
+		case c == '\'' && !inString && charLength(line[idx:]) > 0:
+			length, fold = charLength(line[idx:]), false
+		case c >= utf8.RuneSelf:
+			_, length = utf8.DecodeRuneInString(line[idx:])
+		}
+		text := line[idx : idx+length]
+		cells += displayWidth(text, cells, max(n.TabWidth, 1))
+		if fold {
+			text = foldRune(text)
+		}
+		emit(text, idx)
+		idx += length
+	}
+	offsets = append(offsets, len(line))
+	if sb.Len() == len(line) && sb.String() == line {
+		return NormalizedLine{Text: line, Source: line}
+	}
+	return NormalizedLine{Text: sb.String(), Source: line, offsets: offsets}
+}
+
+// tabCells
+// returns the display columns a tab starting at cell cells advances by, tabs counting as
+// one column unless a tab width is set.
+func tabCells(cells int, tabWidth int) int {
+	if tabWidth < 1 {
+		return 1
+	}
+	return tabWidth - cells%tabWidth
+}
+
+// foldRune
+// lowercases the character making up text, keeping invalid UTF-8 as it is.
+func foldRune(text string) string {
+	r, _ := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToLower(r))
+}
+
+// SourceOffset
+// returns the offset in the line as written of the byte at an offset of the normalized
+// line. The end of the normalized line gives the end of the source.
+func (nl NormalizedLine) SourceOffset(offset int) int {
+	if nl.offsets == nil {
+		return offset
+	}
+	return nl.offsets[min(max(offset, 0), len(nl.offsets)-1)]
+}
+
+// SourceColumn
+// converts a 1-based column of the normalized line into the column of the line as written,
+// leaving column 0 as it is.
+func (nl NormalizedLine) SourceColumn(column int) int {
+	if column <= 0 {
+		return column
+	}
+	return nl.SourceOffset(column-1) + 1
+}
+
+// sourceSpan
+// converts a column and a length in bytes of the normalized line into those of the text
+// they came from.
+func (nl NormalizedLine) sourceSpan(column int, length int) (int, int) {
+	if column <= 0 {
+		return column, length
+	}
+	start, end := nl.SourceOffset(column-1), nl.SourceOffset(column-1+length)
+	return start + 1, max(end-start, 0)
+}
+
+// restoreText
+// records on the tokens of the normalized line the text they were written as, so that the
+// texts of the tokens put together give the line as written.
+func (nl NormalizedLine) restoreText(tokens []Token) {
+	if nl.offsets == nil {
+		return
+	}
+	offset := 0
+	for idx := range tokens {
+		end := offset + len(tokens[idx].ValueReceived)
+		written := nl.Source[nl.SourceOffset(offset):nl.SourceOffset(end)]
+		tokens[idx].raw = ""
+		if written != tokens[idx].ValueReceived {
+			tokens[idx].raw = written
+		}
+		offset = end
+	}
+}
+
+// restoreColumns
+// converts the error column and length and the warning columns of a result matched from
+// the normalized line into columns of the line as written.
+func (nl NormalizedLine) restoreColumns(result *LineResult) {
+	if nl.offsets == nil {
+		return
+	}
+	result.ErrorColumn, result.ErrorLength = nl.sourceSpan(result.ErrorColumn, result.ErrorLength)
+	for idx := range result.Warnings {
+		warning := &result.Warnings[idx]
+		warning.Column, warning.Length = nl.sourceSpan(warning.Column, warning.Length)
+	}
+}
@@ -0,0 +1,52 @@
+package TemplateParser
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		n    Normalizer
+		line string
+		want string
+	}{
+		{Normalizer{}, "MOV  R1", "MOV  R1"},
+		{Normalizer{FoldCase: true}, `MOV R1, "AbC", 'Q' ; KEEP`, `mov r1, "AbC", 'Q' ; KEEP`},
+		{Normalizer{FoldCase: true}, "LD İX", "ld ix"},
+		{Normalizer{CollapseSpace: true}, "mov  \t r1,\t\tr2  ;  a  b", "mov r1, r2 ;  a  b"},
+		{Normalizer{CollapseSpace: true}, `str "a   b"`, `str "a   b"`},
+		{Normalizer{TabWidth: 4}, "ab\tc\td", "ab  c   d"},
+		{Normalizer{TabWidth: 4, CollapseSpace: true}, "ab\t\tc", "ab c"},
+	}
+	for _, tt := range tests {
+		if got := tt.n.Normalize(tt.line); got.Text != tt.want || got.Source != tt.line {
+			t.Errorf("%+v.Normalize(%q) = %q, want %q", tt.n, tt.line, got.Text, tt.want)
+		}
+	}
+}
+
+func TestNormalizedLineColumns(t *testing.T) {
+	nl := Normalizer{CollapseSpace: true, FoldCase: true}.Normalize("MOV    R1,  İX")
+	if nl.Text != "mov r1, ix" {
+		t.Fatalf("Text = %q", nl.Text)
+	}
+	for _, tt := range []struct{ column, want int }{{0, 0}, {1, 1}, {5, 8}, {9, 13}, {10, 15}, {11, 16}} {
+		if got := nl.SourceColumn(tt.column); got != tt.want {
+			t.Errorf("SourceColumn(%d) = %d, want %d", tt.column, got, tt.want)
+		}
+	}
+	if same := (Normalizer{}).Normalize("mov r1"); same.SourceColumn(4) != 4 || same.SourceOffset(2) != 2 {
+		t.Errorf("an inactive normalizer moved columns")
+	}
+}
+
+func TestNormalizeParsing(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Normalize = Normalizer{CollapseSpace: true, TabWidth: 8}
+	p := newTestParser(t, config, exprGrammar)
+	result := parseTestSource(t, p, "li\t\tr1,    10\nli   r2,     1ff\n")
+	if first := result.Lines[0]; !first.Ok || first.RawText != "li\t\tr1,    10" || first.Objects[3].ObjectText != "10" {
+		t.Errorf("line 1 = %v, %q, %+v, want a match keeping the text as written", first.Ok, first.RawText, first.Objects)
+	}
+	if second := result.Lines[1]; second.Ok || second.ErrorColumn != 14 || second.ErrorLength != 3 {
+		t.Errorf("line 2 error at column %d length %d, want 1ff at column 14", second.ErrorColumn, second.ErrorLength)
+	}
+}
@@ -0,0 +1,178 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validNumberSuffix
+// checks that a number suffix is made of ASCII letters, not all of them hex digits, so
+// that plain hex literals such as 3b keep their meaning, and scales by a nonzero value.
+func validNumberSuffix(suffix string, scale uint64) error {
+	if suffix == "" {
+		return fmt.Errorf("number suffix is empty")
+	}
+	for idx := 0; idx < len(suffix); idx++ {
+		if !isLetter(suffix[idx]) {
+			return fmt.Errorf("number suffix %q is not made of letters", suffix)
+		}
+	}
+	if hexLength(suffix, 0) == len(suffix) {
+		return fmt.Errorf("number suffix %q is made of hex digits", suffix)
+	}
+	if scale == 0 {
+		return fmt.Errorf("number suffix %q scales by zero", suffix)
+	}
+	return nil
+}
+
+// AddNumberSuffix
+// makes hex literals ending in a suffix such as k, m or w stand for their value times
+// scale, as 10k does for 0x4000 with {"k": 0x400}, and rebuilds the parser's tokenizer.
+// Suffixes ignore case. A suffix added again takes the new scale.
+func (p *Parser) AddNumberSuffix(suffix string, scale uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return fmt.Errorf("cannot add number suffix %s: %s", suffix, errFrozen)
+	}
+	if err := validNumberSuffix(suffix, scale); err != nil {
+		return err
+	}
+	suffixes := p.config.numberSuffixes()
+	if suffixes == nil {
+		suffixes = make(map[string]uint64)
+	}
+	suffixes[strings.ToLower(suffix)] = scale
+	p.config.NumberSuffixes = suffixes
+	p.lexer = newLexer(p.config)
+	return nil
+}
+
+// NumberSuffixes
+// returns the number suffixes of the parser, lowercased, with their scales.
+func (p *Parser) NumberSuffixes() map[string]uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config.numberSuffixes()
+}
+
+// numberSuffixes
+// returns the configuration's valid number suffixes with lowercased names, or nil if it
+// has none.
+func (config ParserConfig) numberSuffixes() map[string]uint64 {
+	if len(config.NumberSuffixes) == 0 {
+		return nil
+	}
+	lowered := make(map[string]uint64, len(config.NumberSuffixes))
+	for suffix, scale := range config.NumberSuffixes {
+		if validNumberSuffix(suffix, scale) == nil {
+			lowered[strings.ToLower(suffix)] = scale
+		}
+	}
+	return lowered
+}
+
+// sortedSuffixes
+// returns the names of number suffixes, longest first.
+func sortedSuffixes(suffixes map[string]uint64) []string {
+	names := make([]string, 0, len(suffixes))
+	for suffix := range suffixes {
+		names = append(names, suffix)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// splitNumberSuffix
+// splits a word into its hex digits and the longest of the given suffixes it ends in,
+// returning the suffix's scale.
+func splitNumberSuffix(word string, suffixes []string, scales map[string]uint64) (string, uint64, bool) {
+	for _, suffix := range suffixes {
+		digits := len(word) - len(suffix)
+		if digits > 0 && hexLength(word[:digits], 0) == digits && strings.EqualFold(word[digits:], suffix) {
+			return word[:digits], scales[strings.ToLower(suffix)], true
+		}
+	}
+	return "", 0, false
+}
+
+// scaleNumber
+// returns the value of hex digits times a scale, or false if it overflows 64 bits.
+func scaleNumber(digits string, scale uint64) (uint64, bool) {
+	val, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	hi, lo := bits.Mul64(val, scale)
+	return lo, hi == 0
+}
+
+// suffixedNumber
+// returns the type and length of the hex literal with a number suffix that makes up the
+// word at the start of s, or a length of 0. The type is that of the scaled value's size;
+// values overflowing 64 bits are TokenUint64 tokens, which fail to parse.
+func (lx *lexer) suffixedNumber(s string, word int) (int, int) {
+	if len(lx.units) == 0 || word == 0 {
+		return TokenUnknown, 0
+	}
+	digits, scale, found := splitNumberSuffix(s[:word], lx.units, lx.scales)
+	if !found {
+		return TokenUnknown, 0
+	}
+	val, ok := scaleNumber(digits, scale)
+	if !ok {
+		return TokenUint64, word
+	}
+	tokenType, _ := lx.numberToken(len(strconv.FormatUint(val, 16)))
+	if tokenType == TokenBigInt {
+		tokenType = TokenUint64
+	}
+	return tokenType, word
+}
+
+// scaledText
+// returns the text of a hex literal token if it has a number suffix, and "" otherwise. It
+// is the descriptor of the objects matched from such literals.
+func (config ParserConfig) scaledText(text string) string {
+	if len(config.NumberSuffixes) == 0 {
+		return ""
+	}
+	suffixes := config.numberSuffixes()
+	if _, _, found := splitNumberSuffix(text, sortedSuffixes(suffixes), suffixes); !found {
+		return ""
+	}
+	return text
+}
+
+// scaledToSlot
+// lets an integer matched from a literal with a number suffix fill a wider sized integer
+// slot its value fits, as 10k does a Uint32 slot, since the width of such a literal comes
+// from its scaled value rather than from digits the writer chose.
+func scaledToSlot(obj *ObjectType, tmpl TemplateObject) {
+	limit, sized := maxDigits[tmpl.TemplateType]
+	own, isNumber := maxDigits[obj.ObjectTypeId]
+	if !sized || !isNumber || obj.ObjectDescriptor == "" || own >= limit {
+		return
+	}
+	if val, isInt := obj.ObjectValue.(uint64); isInt && fitsField(val, limit*4) {
+		obj.ObjectTypeId = tmpl.TemplateType
+	}
+}
+
+// parseNumber
+// parses the text of an integer literal token with the configuration's number options,
+// scaling it by its number suffix if it has one.
+func (config ParserConfig) parseNumber(text string) (uint64, error) {
+	val, _, err := ParseNumber(text, config.numberOptions())
+	return val, err
+}
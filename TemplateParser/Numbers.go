@@ -0,0 +1,351 @@
+package TemplateParser
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OverflowPolicy
+// decides what ParseNumber does with a value too large for its width.
+type OverflowPolicy int
+
+const (
+	OverflowError    OverflowPolicy = iota // Fail with an error wrapping ErrNumberOverflow
+	OverflowWrap                           // Keep the low bits that fit
+	OverflowSaturate                       // Take the largest value of the width
+)
+
+// ErrNumberOverflow is wrapped by the errors of ParseNumber for values too large for their
+// width.
+var ErrNumberOverflow = errors.New("number overflows its width")
+
+// numberPrefixes maps the letter after the 0 of a base prefix to its base.
+var numberPrefixes = map[byte]int{'b': 2, 'o': 8, 'd': 10, 'x': 16}
+
+// NumberOptions
+// controls how ParseNumber reads integer literals. The zero value reads them as ParseLine
+// always has: hex digits without a prefix, whose count gives the width.
+type NumberOptions struct {
+	// Base is the base of literals without a prefix: 2, 8, 10 or 16. Zero means 16.
+	Base int
+	// Bases lists the bases a prefix may select: 0b for 2, 0o for 8, 0d for 10 and 0x for
+	// 16, ignoring case. Since b and d are hex digits, allowing 2 or 10 makes hex literals
+	// such as 0b1 read as prefixed. Nil allows no prefixes.
+	Bases []int
+	// Overflow decides what happens to values too large for their width.
+	Overflow OverflowPolicy
+	// WidthSuffixes maps suffixes, ignoring case, to the width in bits they give a literal,
+	// 8, 16, 32 or 64, as {"u8": 8, "u16": 16} makes ffu16 a 16-bit ff. A suffix starts
+	// with a letter, is made of letters and digits and is not all hex digits; others are
+	// ignored.
+	WidthSuffixes map[string]int
+	// Scales maps number suffixes to the value they multiply by, as
+	// ParserConfig.NumberSuffixes does.
+	Scales map[string]uint64
+}
+
+// base
+// returns the base of literals without a prefix.
+func (opts NumberOptions) base() int {
+	if opts.Base == 0 {
+		return 16
+	}
+	return opts.Base
+}
+
+// extended
+// reports whether the options read more than plain hex literals, so that the tokenizer
+// must ask ParseNumber which words are numbers.
+func (opts NumberOptions) extended() bool {
+	return opts.base() != 16 || len(opts.Bases) > 0 || len(opts.WidthSuffixes) > 0
+}
+
+// allows
+// reports whether a prefix may select a base.
+func (opts NumberOptions) allows(base int) bool {
+	for _, allowed := range opts.Bases {
+		if allowed == base {
+			return true
+		}
+	}
+	return false
+}
+
+// validWidthSuffix
+// checks a width suffix of NumberOptions.
+func validWidthSuffix(suffix string, width int) bool {
+	if suffix == "" || !isLetter(suffix[0]) || hexLength(suffix, 0) == len(suffix) {
+		return false
+	}
+	for idx := 0; idx < len(suffix); idx++ {
+		if !isLetter(suffix[idx]) && !isDecimal(suffix[idx]) {
+			return false
+		}
+	}
+	return width == 8 || width == 16 || width == 32 || width == 64
+}
+
+// ParseNumber
+// reads an integer literal, returning its value and its width in bits: 8, 16, 32 or 64. A
+// base prefix allowed by the options selects the base, and a width suffix or a number
+// suffix may follow the digits. The width is the one a width suffix gives; otherwise, for
+// bases 2, 8 and 16, the one the digits written fill, as ParseLine counts 00ff as 16 bits,
+// and for decimal and scaled literals the smallest one holding the value. Values too
+// large for their width, or for 64 bits, are handled by the options' overflow policy.
+func ParseNumber(text string, opts NumberOptions) (uint64, int, error) {
+	val, width, _, err := parseNumberText(text, opts)
+	return val, width, err
+}
+
+// parseNumberText
+// implements ParseNumber, also reporting whether the width was inferred from the value.
+func parseNumberText(text string, opts NumberOptions) (uint64, int, bool, error) {
+	base := opts.base()
+	if _, known := digitBits[base]; !known {
+		return 0, 0, false, fmt.Errorf("invalid base %d", base)
+	}
+	body := text
+	if len(body) > 2 && body[0] == '0' {
+		if prefixed, found := numberPrefixes[lowerByte(body[1])]; found && opts.allows(prefixed) {
+			base, body = prefixed, body[2:]
+		}
+	}
+	width, scale := 0, uint64(0)
+	if digits, suffixWidth, found := splitWidthSuffix(body, base, opts.WidthSuffixes); found {
+		body, width = digits, suffixWidth
+	} else if len(opts.Scales) > 0 {
+		if digits, factor, found := splitNumberSuffix(body, sortedSuffixes(opts.Scales), opts.Scales); found &&
+			digitLength(digits, base) == len(digits) {
+			body, scale = digits, factor
+		}
+	}
+	if body == "" || digitLength(body, base) != len(body) {
+		return 0, 0, false, fmt.Errorf("%q is not a base %d number", text, base)
+	}
+	val, err := strconv.ParseUint(body, base, 64)
+	overflow := err != nil
+	if overflow {
+		val = wrapDigits(body, base)
+	}
+	if scale > 0 {
+		hi, lo := bits.Mul64(val, scale)
+		val, overflow = lo, overflow || hi != 0
+	}
+	inferred := width == 0 && (scale > 0 || base == 10)
+	if width == 0 {
+		switch {
+		case overflow:
+			width = 64
+		case inferred:
+			width = valueWidth(val)
+		default:
+			width = max(valueWidth(val), digitsWidth(len(body)*digitBits[base]))
+		}
+	}
+	if !overflow && !fitsField(val, width) {
+		overflow = true
+		val &= widthMask(width)
+	}
+	if overflow {
+		switch opts.Overflow {
+		case OverflowWrap:
+			val &= widthMask(width)
+		case OverflowSaturate:
+			val = widthMask(width)
+		default:
+			return 0, width, inferred, fmt.Errorf("%s overflows %d bits: %w", text, width, ErrNumberOverflow)
+		}
+	}
+	return val, width, inferred, nil
+}
+
+// digitBits gives the bits a digit holds in each base ParseNumber reads. Decimal literals
+// take the width of their value instead.
+var digitBits = map[int]int{2: 1, 8: 3, 10: 4, 16: 4}
+
+// lowerByte
+// lowercases an ASCII letter.
+func lowerByte(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// digitLength
+// returns the length of the run of digits of a base at the start of s.
+func digitLength(s string, base int) int {
+	if base == 16 {
+		return hexLength(s, 0)
+	}
+	for idx := 0; idx < len(s); idx++ {
+		if digit := s[idx] - '0'; s[idx] < '0' || int(digit) >= base {
+			return idx
+		}
+	}
+	return len(s)
+}
+
+// splitWidthSuffix
+// splits a literal into its digits in a base and the longest width suffix it ends in,
+// returning the suffix's width.
+func splitWidthSuffix(word string, base int, suffixes map[string]int) (string, int, bool) {
+	if len(suffixes) == 0 {
+		return "", 0, false
+	}
+	names := make([]string, 0, len(suffixes))
+	for suffix, width := range suffixes {
+		if validWidthSuffix(suffix, width) {
+			names = append(names, suffix)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	for _, suffix := range names {
+		digits := len(word) - len(suffix)
+		if digits > 0 && digitLength(word[:digits], base) == digits && strings.EqualFold(word[digits:], suffix) {
+			return word[:digits], suffixes[suffix], true
+		}
+	}
+	return "", 0, false
+}
+
+// wrapDigits
+// returns the low 64 bits of the value of digits too many for a uint64.
+func wrapDigits(digits string, base int) uint64 {
+	var val uint64
+	for idx := 0; idx < len(digits); idx++ {
+		digit := digits[idx] - '0'
+		if digit > 9 {
+			digit = lowerByte(digits[idx]) - 'a' + 10
+		}
+		val = val*uint64(base) + uint64(digit)
+	}
+	return val
+}
+
+// valueWidth
+// returns the smallest of the widths 8, 16, 32 and 64 holding a value.
+func valueWidth(val uint64) int {
+	return digitsWidth(bits.Len64(val))
+}
+
+// digitsWidth
+// rounds a number of bits up to the widths 8, 16, 32 and 64.
+func digitsWidth(n int) int {
+	switch {
+	case n <= 8:
+		return 8
+	case n <= 16:
+		return 16
+	case n <= 32:
+		return 32
+	}
+	return 64
+}
+
+// widthMask
+// returns the largest value of a width.
+func widthMask(width int) uint64 {
+	if width >= 64 {
+		return ^uint64(0)
+	}
+	return uint64(1)<<width - 1
+}
+
+// widthToken
+// returns the sized integer token type of a width.
+func widthToken(width int) int {
+	switch width {
+	case 8:
+		return TokenUint8
+	case 16:
+		return TokenUint16
+	case 32:
+		return TokenUint32
+	}
+	return TokenUint64
+}
+
+// numberOptions
+// returns the options the configuration reads integer literals with: its Numbers, taking
+// its number suffixes as scales unless they set their own.
+func (config ParserConfig) numberOptions() NumberOptions {
+	opts := config.Numbers
+	if opts.Scales == nil {
+		opts.Scales = config.numberSuffixes()
+	}
+	return opts
+}
+
+// optionNumber
+// returns the type and length of the integer literal, as the tokenizer's number options
+// read it, that makes up the word at the start of s, or a length of 0. Literals
+// overflowing their width with OverflowError are TokenUint64 tokens, which fail to parse.
+func (lx *lexer) optionNumber(s string, word int) (int, int) {
+	if word == 0 {
+		return TokenUnknown, 0
+	}
+	_, width, err := ParseNumber(s[:word], *lx.numbers)
+	switch {
+	case errors.Is(err, ErrNumberOverflow):
+		return TokenUint64, word
+	case err != nil:
+		return TokenUnknown, 0
+	}
+	return widthToken(width), word
+}
+
+// numberText
+// writes a value as a literal the configuration reads back as the same value, with enough
+// digits or the width suffix to be read as tokenType where it can: plain hex digits,
+// unless its number options read another base, in which case it takes the 0x prefix if
+// they allow it and the digits of their base otherwise.
+func (config ParserConfig) numberText(val uint64, tokenType int) string {
+	opts := config.Numbers
+	hex := strconv.FormatUint(val, 16)
+	if width := minDigits[tokenType]; len(hex) < width {
+		hex = strings.Repeat("0", width-len(hex)) + hex
+	}
+	switch {
+	case opts.base() == 16:
+		return hex
+	case opts.allows(16):
+		return "0x" + hex
+	}
+	text := strconv.FormatUint(val, opts.base())
+	var suffixes []string
+	for suffix, width := range opts.WidthSuffixes {
+		if validWidthSuffix(suffix, width) && widthToken(width) == tokenType {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	if len(suffixes) > 0 {
+		sort.Strings(suffixes)
+		text += suffixes[0]
+	}
+	return text
+}
+
+// numberDescriptor
+// returns the text of an integer literal token if its width comes from its value rather
+// than from the digits written, as for scaled and decimal literals, and "" otherwise. It
+// is the descriptor of the objects matched from such literals, which lets them fill wider
+// slots (see scaledToSlot).
+func (config ParserConfig) numberDescriptor(text string) string {
+	if !config.Numbers.extended() {
+		return config.scaledText(text)
+	}
+	if _, _, inferred, err := parseNumberText(text, config.numberOptions()); err == nil && inferred {
+		return text
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text  string
+		opts  NumberOptions
+		want  uint64
+		width int
+		err   string
+	}{
+		{"ff", NumberOptions{}, 0xff, 8, ""},
+		{"00ff", NumberOptions{}, 0xff, 16, ""},
+		{"0x1f", NumberOptions{Bases: []int{16}}, 0x1f, 8, ""},
+		{"0b101", NumberOptions{Bases: []int{2}}, 5, 8, ""},
+		{"255", NumberOptions{Base: 10}, 255, 8, ""},
+		{"300", NumberOptions{Base: 10}, 300, 16, ""},
+		{"ffu16", NumberOptions{WidthSuffixes: map[string]int{"u16": 16}}, 0xff, 16, ""},
+		{"4k", NumberOptions{Base: 10, Scales: map[string]uint64{"k": 1024}}, 4096, 16, ""},
+		{"zz", NumberOptions{}, 0, 0, "not a base 16 number"},
+		{"1ffffffffffffffff", NumberOptions{}, 0, 64, "overflows 64 bits"},
+		{"10", NumberOptions{Base: 7}, 0, 0, "invalid base 7"},
+	}
+	for _, tt := range tests {
+		val, width, err := ParseNumber(tt.text, tt.opts)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseNumber(%q) error = %v, want %q", tt.text, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || val != tt.want || width != tt.width {
+			t.Errorf("ParseNumber(%q) = %#x, %d, %v, want %#x, %d", tt.text, val, width, err, tt.want, tt.width)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// constantTable
+// holds named constants. The parser's table holds those defined with DefineConstant; each
+// parsing call has a table of its own, whose parent is the parser's, holding the constants
+// its equ lines define, so they are seen by the later lines of the call and by no other
+// call. Lines of a call may be parsed concurrently, so the table is locked. changes counts
+// the definitions that added or changed a constant, for the line cache.
+type constantTable struct {
+	mu      sync.RWMutex
+	values  map[string]uint64
+	changes uint64
+	parent  *constantTable // Table looked up for constants not in this one, nil for the parser's
+}
+
+// newConstantScope
+// returns the empty table of a parsing call, whose lookups fall back to parent.
+func newConstantScope(parent *constantTable) *constantTable {
+	return &constantTable{parent: parent}
+}
+
+// define
+// sets a constant.
+func (ct *constantTable) define(key string, value uint64) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.values == nil {
+		ct.values = make(map[string]uint64)
+	}
+	if old, found := ct.values[key]; !found || old != value {
+		ct.values[key] = value
+		ct.changes++
+	}
+}
+
+// version
+// returns the number of times a constant was defined or changed value, so results
+// matched with the table can tell whether it changed since.
+func (ct *constantTable) version() uint64 {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.changes
+}
+
+// lookup
+// returns the value of a constant, looking it up in the parent if the table does not hold
+// it.
+func (ct *constantTable) lookup(key string) (uint64, bool) {
+	ct.mu.RLock()
+	val, found := ct.values[key]
+	ct.mu.RUnlock()
+	if !found && ct.parent != nil {
+		return ct.parent.lookup(key)
+	}
+	return val, found
+}
+
+// len
+// returns the number of constants defined in the table, not counting its parent's.
+func (ct *constantTable) len() int {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return len(ct.values)
+}
+
+// empty
+// reports whether neither the table nor its parent holds a constant.
+func (ct *constantTable) empty() bool {
+	return ct.len() == 0 && (ct.parent == nil || ct.parent.empty())
+}
+
+// DefineConstant
+// defines a named constant that operand expressions can use, e.g. COUNT in (COUNT*4)-1.
+// Unlike the constants of equ lines, which belong to the source defining them, it is seen
+// by every line the parser parses.
+func (p *Parser) DefineConstant(name string, value uint64) {
+	p.constants.define(p.identifierKey(name), value)
+}
+
+// Constant
+// returns the value of a named constant defined with DefineConstant.
+func (p *Parser) Constant(name string) (uint64, bool) {
+	return p.constants.lookup(p.identifierKey(name))
+}
+
+// constantScope
+// returns the constant table lines parsed in a context define and look up constants in.
+// Contexts made by the parsing calls all have one; for others a new table is returned, so
+// that what their equ lines define is dropped rather than shared.
+func (p *Parser) constantScope(ctx *ParseContext) *constantTable {
+	if ctx != nil && ctx.constants != nil {
+		return ctx.constants
+	}
+	return newConstantScope(p.constants)
+}
+
+// pendingExpr
+// is an operand expression that refers to names that were not constants when its line
+// was matched, such as labels; ResolveSymbols evaluates it.
+type pendingExpr struct {
+	expr      *Expr
+	constants *constantTable
+}
+
+// collapseExpressions
+// replaces the tokens filling each TokenExpression slot of a template with a single
+// TokenExpression token holding their text. An expression ends before the next top-level
+// token of the type of the following slot when that slot is punctuation, and at the end of
+// the line otherwise.
+func collapseExpressions(tokens []Token, templateList []TemplateObject) []Token {
+	hasExpr := false
+	for _, tmpl := range templateList {
+		hasExpr = hasExpr || tmpl.TemplateType == TokenExpression
+	}
+	if !hasExpr {
+		return tokens
+	}
+	collapsed := make([]Token, 0, len(tokens))
+	slot := 0
+	for idx := 0; idx < len(tokens); idx++ {
+		token := tokens[idx]
+		if isBlankToken(token) || slot >= len(templateList) ||
+			templateList[slot].TemplateType != TokenExpression {
+			collapsed = append(collapsed, token)
+			if token.Type != TokenUnknown {
+				slot++
+			}
+			continue
+		}
+		stop := -1
+		if slot+1 < len(templateList) && isPunctuation(templateList[slot+1].TemplateType) {
+			stop = templateList[slot+1].TemplateType
+		}
+		end, depth := idx, 0
+		for ; end < len(tokens); end++ {
+			tt := tokens[end].Type
+			if depth == 0 && tt == stop {
+				break
+			}
+			if tt == TokenLParen {
+				depth++
+			} else if tt == TokenRParen {
+				depth--
+			}
+		}
+		var sb, written strings.Builder
+		for _, part := range tokens[idx:end] {
+			sb.WriteString(part.ValueReceived)
+			written.WriteString(part.text())
+		}
+		// Keep trailing blanks outside the expression so columns stay right
+		text := strings.TrimRight(sb.String(), " \t")
+		raw := strings.TrimRight(written.String(), " \t")
+		if raw == text {
+			raw = ""
+		}
+		collapsed = append(collapsed, Token{Type: TokenExpression, ValueReceived: text, raw: raw})
+		if trailing := sb.Len() - len(text); trailing > 0 {
+			collapsed = append(collapsed, Token{Type: TokenUnknown, ValueReceived: sb.String()[len(text):]})
+		}
+		slot++
+		idx = end - 1
+	}
+	return collapsed
+}
+
+// evaluateExpressions
+// compiles the TokenExpression objects of a matched line. Expressions whose names are all
+// constants are evaluated at once and range checked; the others are left for
+// ResolveSymbols. The line is marked as failed if an expression is invalid.
+func (p *Parser) evaluateExpressions(result *LineResult, templateList []TemplateObject, constants *constantTable) {
+	if !result.Ok {
+		return
+	}
+	for idx := range result.Objects {
+		obj := &result.Objects[idx]
+		text, isText := obj.ObjectValue.(string)
+		if obj.ObjectTypeId != TokenExpression || !isText {
+			continue
+		}
+		expr, err := p.compileOperandExpr(text)
+		if err != nil {
+			result.Ok, result.Error = false, fmt.Sprintf("Invalid expression %s: %v", text, err)
+			return
+		}
+		val, err := expr.root.eval(constantLookup(constants, nil))
+		if _, unresolved := err.(unknownNameError); unresolved {
+			if result.pending == nil {
+				result.pending = make(map[int]pendingExpr)
+			}
+			result.pending[idx] = pendingExpr{expr, constants}
+			continue
+		}
+		if ok, errmsg := setExpressionValue(obj, val, err, templateList[idx]); !ok {
+			result.Ok, result.Error = false, errmsg
+			return
+		}
+	}
+	result.refresh()
+}
+
+// resolveExpressions
+// evaluates the pending expressions of a line with the label addresses of a symbol table.
+func resolveExpressions(lr *LineResult, symbols *SymbolTable) {
+	for idx, pending := range lr.pending {
+		val, err := pending.expr.root.eval(constantLookup(pending.constants, symbols))
+		if unknown, unresolved := err.(unknownNameError); unresolved {
+			lr.Ok, lr.Error = false, fmt.Sprintf("Unresolved symbol %s", unknown.name)
+			return
+		}
+		tmpl := TemplateObject{}
+		if idx < len(lr.templates) {
+			tmpl = lr.templates[idx]
+		}
+		if ok, errmsg := setExpressionValue(&lr.Objects[idx], val, err, tmpl); !ok {
+			lr.Ok, lr.Error = false, errmsg
+			return
+		}
+	}
+	lr.pending = nil
+}
+
+// resolveLineExpressions
+// evaluates the pending expressions of a line parsed on its own, which no later pass
+// resolves, with the labels of a symbol table, nil for none. The line fails if an
+// expression still names an unknown symbol, rather than keeping the text as its value.
+func resolveLineExpressions(lr *LineResult, symbols *SymbolTable) {
+	if !lr.Ok || lr.pending == nil {
+		return
+	}
+	resolveExpressions(lr, symbols)
+	lr.refresh()
+}
+
+// setExpressionValue
+// stores the result of evaluating an expression in its object and checks its range.
+func setExpressionValue(obj *ObjectType, val interface{}, err error, tmpl TemplateObject) (bool, string) {
+	if err != nil {
+		return false, fmt.Sprintf("Cannot evaluate %s: %v", obj.ObjectDescriptor, err)
+	}
+	num, isInt := val.(uint64)
+	if !isInt {
+		return false, fmt.Sprintf("Expression %s is not an integer", obj.ObjectDescriptor)
+	}
+	obj.ObjectValue = num
+	return tmpl.CheckRange(num)
+}
+
+// unknownNameError
+// reports a name that is neither a constant nor a symbol.
+type unknownNameError struct{ name string }
+
+func (e unknownNameError) Error() string {
+	return "unknown name " + e.name
+}
+
+// constantLookup
+// resolves expression names against constants and, if given, a symbol table.
+func constantLookup(constants *constantTable, symbols *SymbolTable) exprLookup {
+	return func(name string) (interface{}, bool) {
+		if val, found := constants.lookup(name); found {
+			return val, true
+		}
+		if symbols != nil {
+			if sym, found := symbols.Lookup(name); found {
+				return sym.Address, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// compileOperandExpr
+// compiles the text of an operand expression using the source tokenizer, so numbers are
+// hex as everywhere else in a line; a 0x prefix is accepted too.
+func (p *Parser) compileOperandExpr(text string) (*Expr, error) {
+	tokens := contentTokens(p.lexer.scan(text))
+	lex := make([]exprToken, 0, len(tokens))
+	offset := 0
+	for idx := 0; idx < len(tokens); idx++ {
+		token := tokens[idx]
+		offset = strings.Index(text[offset:], token.ValueReceived) + offset
+		switch {
+		case isIntegerToken(token.Type) && token.Type != TokenBigInt:
+			digits := token.ValueReceived
+			if val, err := p.config.parseNumber(digits); err == nil {
+				digits = strconv.FormatUint(val, 16)
+			}
+			lex = append(lex, exprToken{'n', "0x" + digits, offset})
+		case hasPrefixFold(token.ValueReceived, "0x"):
+			// 0x10 is a single word the tokenizer does not take for a number
+			opts := p.config.numberOptions()
+			opts.Bases = append(opts.Bases[:len(opts.Bases):len(opts.Bases)], 16)
+			val, _, err := ParseNumber(token.ValueReceived, opts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", token.ValueReceived)
+			}
+			lex = append(lex, exprToken{'n', "0x" + strconv.FormatUint(val, 16), offset})
+		case token.Type == TokenIdentifier:
+			lex = append(lex, exprToken{'i', p.identifierKey(token.ValueReceived), offset})
+		case token.Type == TokenPlus || token.Type == TokenMinus || token.Type == TokenLParen || token.Type == TokenRParen:
+			lex = append(lex, exprToken{'o', token.ValueReceived, offset})
+		case token.Type == TokenUnknown || token.Type == TokenOperator:
+			op := token.ValueReceived
+			if idx+1 < len(tokens) && tokens[idx+1].Type == TokenUnknown && isExprOperator(op+tokens[idx+1].ValueReceived) {
+				op += tokens[idx+1].ValueReceived
+				idx++
+			}
+			if !isExprOperator(op) {
+				return nil, fmt.Errorf("unexpected %q", op)
+			}
+			lex = append(lex, exprToken{'o', op, offset})
+		default:
+			return nil, fmt.Errorf("unexpected %q", token.ValueReceived)
+		}
+	}
+	return compileExprTokens(text, lex)
+}
+
+// isExprOperator
+// reports whether text is an operator of the expression language.
+func isExprOperator(text string) bool {
+	for _, op := range exprOperators {
+		if op == text {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package TemplateParser
+
+import "testing"
+
+func TestOperandExprPrefixedHex(t *testing.T) {
+	tests := []struct {
+		source string
+		want   uint64
+	}{
+		{"le r1, 0x10", 0x10},
+		{"le r1, 0X1f", 0x1f},
+		{"le r1, 0x10+1", 0x11},
+		{"le r1, (0x10*2)-0x4", 0x1c},
+		{"le r1, 10", 0x10},
+		{"FOO equ 0x40\nle r1, FOO+0x1", 0x41},
+		{"FOO equ 0x40\nli r1, FOO", 0x40},
+	}
+	for _, tt := range tests {
+		p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+		result := parseTestSource(t, p, tt.source)
+		last := len(result.Lines) - 1
+		if got := lineValue(t, result, last); got != tt.want {
+			t.Errorf("%q = %v, want %#x", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestOperandExprInvalidPrefixedHex(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	result := parseTestSource(t, p, "le r1, 0xzz")
+	if result.Lines[0].Ok {
+		t.Fatalf("0xzz parsed as %v", result.Lines[0].Objects)
+	}
+}
+
+func TestOperandExprUnresolvedOutsideSource(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.DefineConstant("BASE", 0x20)
+	tests := []struct {
+		line string
+		ok   bool
+		want uint64
+	}{
+		{"le r1, zz+1", false, 0},
+		{"le r1, BASE+1", true, 0x21},
+		{"le r1, 2*3", true, 6},
+	}
+	for _, tt := range tests {
+		objs, ok, errmsg := p.Parse(tt.line)
+		if ok != tt.ok {
+			t.Errorf("Parse(%q) ok = %v (%s), want %v", tt.line, ok, errmsg, tt.ok)
+			continue
+		}
+		if ok && objs[3].ObjectValue != tt.want {
+			t.Errorf("Parse(%q) = %v, want %#x", tt.line, objs[3].ObjectValue, tt.want)
+		}
+		if _, lineOk, _ := p.ParseLine(tt.line); lineOk != tt.ok {
+			t.Errorf("ParseLine(%q) ok = %v, want %v", tt.line, lineOk, tt.ok)
+		}
+	}
+}
+
+func TestOperandExprContextLabels(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	ctx := &ParseContext{Symbols: NewSymbolTable()}
+	ctx.Symbols.Define("zz", 0x40, 1)
+	pl, ok, errmsg := p.ParseLineContext(ctx, "le r1, zz+1")
+	if !ok {
+		t.Fatalf("ParseLineContext: %s", errmsg)
+	}
+	if got := pl.Objects[3].ObjectValue; got != uint64(0x41) {
+		t.Errorf("zz+1 = %v, want 0x41", got)
+	}
+}
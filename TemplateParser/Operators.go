@@ -0,0 +1,108 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtinPunctuation lists the characters that already have token types of their own and
+// so cannot be operators on their own.
+const builtinPunctuation = ",:[]()+-"
+
+// validOperator
+// checks that an operator is a run of symbol characters: no letters, digits, underscores,
+// blanks, quotes, semicolons or macro signs, and not a single built-in punctuation
+// character. Characters outside ASCII, such as arrows, are allowed.
+func validOperator(op string) error {
+	if op == "" {
+		return fmt.Errorf("operator is empty")
+	}
+	if len(op) == 1 && strings.Contains(builtinPunctuation, op) {
+		return fmt.Errorf("operator %q is already a punctuation token", op)
+	}
+	for idx := 0; idx < len(op); idx++ {
+		if c := op[idx]; isAlnum(c) || strings.IndexByte("_ \t\";@", c) >= 0 {
+			return fmt.Errorf("operator %q contains %q", op, c)
+		}
+	}
+	return nil
+}
+
+// sortedOperators
+// returns an operator table without duplicates, longest first, so the tokenizer picks the
+// longest operator at each position.
+func sortedOperators(operators []string) []string {
+	seen := make(map[string]bool, len(operators))
+	sorted := make([]string, 0, len(operators))
+	for _, op := range operators {
+		if !seen[op] {
+			seen[op] = true
+			sorted = append(sorted, op)
+		}
+	}
+	sort.SliceStable(sorted, func(a, b int) bool { return len(sorted[a]) > len(sorted[b]) })
+	return sorted
+}
+
+// AddOperator
+// adds an operator such as ->, => or :: to the parser's operator table and rebuilds its
+// tokenizer. Adding an operator already in the table does nothing.
+func (p *Parser) AddOperator(op string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return fmt.Errorf("cannot add operator %s: %s", op, errFrozen)
+	}
+	if err := validOperator(op); err != nil {
+		return err
+	}
+	for _, existing := range p.config.Operators {
+		if existing == op {
+			return nil
+		}
+	}
+	p.config.Operators = append(append([]string(nil), p.config.Operators...), op)
+	p.lexer = newLexer(p.config)
+	return nil
+}
+
+// Operators
+// returns the parser's operator table in the order operators were added.
+func (p *Parser) Operators() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.config.Operators...)
+}
+
+// operator
+// returns the length of the longest operator at the start of s, or 0.
+func (lx *lexer) operator(s string) int {
+	for _, op := range lx.operators {
+		if strings.HasPrefix(s, op) {
+			return len(op)
+		}
+	}
+	return 0
+}
+
+// OperatorSlot
+// returns a template slot matching one operator of the table, such as ->.
+func OperatorSlot(op string, errmsg string) TemplateObject {
+	return TemplateObject{TemplateType: TokenOperator, TemplateValue: ObjectType{ObjectValue: op}, TemplateError: errmsg}
+}
+
+// checkOperator
+// checks that an operator slot holding a particular operator got that operator. Operator
+// slots without one accept any operator.
+func (tmpl TemplateObject) checkOperator(obj ObjectType) (bool, string) {
+	want, _ := tmpl.TemplateValue.ObjectValue.(string)
+	if tmpl.TemplateType != TokenOperator || want == "" {
+		return true, ""
+	}
+	if got, _ := obj.ObjectValue.(string); got != want {
+		return false, fmt.Sprintf("Expected %s but got %s: %s", want, got, tmpl.TemplateError)
+	}
+	return true, ""
+}
@@ -0,0 +1,92 @@
+package TemplateParser
+
+import "fmt"
+
+// writtenForm
+// checks the Order of an entry and returns its slots in the order lines write them, with
+// their SameAs and DifferentFrom references renumbered to match, or nil if the entry has
+// no Order. Order must be a permutation of the slots keeping the mnemonic first, and a
+// slot must still be written after the slots it refers back to.
+func writtenForm(entry TemplateEntry) ([]TemplateObject, bool, string) {
+	if entry.Order == nil {
+		return nil, true, ""
+	}
+	if len(entry.Order) != len(entry.Objects) {
+		return nil, false, fmt.Sprintf("Template %s: order lists %d slots but the template has %d", entry.Name, len(entry.Order), len(entry.Objects))
+	}
+	positions := make([]int, len(entry.Objects))
+	for idx := range positions {
+		positions[idx] = -1
+	}
+	for pos, slot := range entry.Order {
+		if slot < 0 || slot >= len(entry.Objects) || positions[slot] >= 0 {
+			return nil, false, fmt.Sprintf("Template %s: order is not a permutation of the slots", entry.Name)
+		}
+		positions[slot] = pos
+	}
+	if entry.Order[0] != 0 {
+		return nil, false, fmt.Sprintf("Template %s: order must keep the mnemonic first", entry.Name)
+	}
+	written := make([]TemplateObject, len(entry.Objects))
+	for pos, slot := range entry.Order {
+		tmpl := entry.Objects[slot]
+		for _, ref := range []*int{&tmpl.SameAs, &tmpl.DifferentFrom} {
+			if *ref == 0 {
+				continue
+			}
+			if positions[*ref] >= pos {
+				return nil, false, fmt.Sprintf("Template %s: order writes slot %d before slot %d it refers to", entry.Name, slot, *ref)
+			}
+			*ref = positions[*ref]
+		}
+		written[pos] = tmpl
+	}
+	return written, true, ""
+}
+
+// form
+// returns the slots of an entry in the order lines write them.
+func (entry *TemplateEntry) form() []TemplateObject {
+	if entry.written != nil {
+		return entry.written
+	}
+	return entry.Objects
+}
+
+// canonicalObjects
+// puts the objects matched against the written form of an entry back in the order of its
+// Objects.
+func (entry *TemplateEntry) canonicalObjects(objs []ObjectType) []ObjectType {
+	if entry.written == nil || len(objs) != len(entry.Order) {
+		return objs
+	}
+	canonical := make([]ObjectType, len(objs))
+	for pos, slot := range entry.Order {
+		canonical[slot] = objs[pos]
+	}
+	return canonical
+}
+
+// writtenSlot
+// returns the position in the written form of an entry of one of its slots.
+func (entry *TemplateEntry) writtenSlot(slot int) int {
+	for pos, canonical := range entry.Order {
+		if canonical == slot {
+			return pos
+		}
+	}
+	return slot
+}
+
+// writtenObjects
+// returns the objects of a line in the order its entry writes them.
+func (pl ParsedLine) writtenObjects() []ObjectType {
+	if pl.entry == nil || pl.entry.written == nil || len(pl.Objects) != len(pl.entry.Order) {
+		return pl.Objects
+	}
+	written := make([]ObjectType, len(pl.Objects))
+	for pos, slot := range pl.entry.Order {
+		written[pos] = pl.Objects[slot]
+	}
+	return written
+}
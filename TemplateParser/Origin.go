@@ -0,0 +1,111 @@
+package TemplateParser
+
+import "fmt"
+
+// OriginDirective is the name of the directive that sets the location counter: .org <hex>
+const OriginDirective = "org"
+
+// isOriginDirective
+// reports whether a tokenized line is a .org directive, matching the name as mnemonics are
+// matched.
+func (p *Parser) isOriginDirective(tokens []Token) bool {
+	var buf [2]Token
+	content := leadingContent(tokens, buf[:])
+	return len(content) >= 2 && content[0].Type == TokenUnknown && content[0].ValueReceived == "." &&
+		content[1].Type == TokenIdentifier && p.mnemonicKey(content[1].ValueReceived) == OriginDirective
+}
+
+// contentTokens
+// returns the tokens of a line that are not whitespace.
+func contentTokens(tokens []Token) []Token {
+	content := make([]Token, 0, len(tokens))
+	for _, token := range tokens {
+		if !isBlankToken(token) {
+			content = append(content, token)
+		}
+	}
+	return content
+}
+
+// leadingContent
+// stores the first non-blank tokens of a line in buf, as many as it holds, and returns
+// them, so checks looking at the start of a line do not allocate.
+func leadingContent(tokens []Token, buf []Token) []Token {
+	n := 0
+	for _, token := range tokens {
+		if n == len(buf) {
+			break
+		}
+		if !isBlankToken(token) {
+			buf[n] = token
+			n++
+		}
+	}
+	return buf[:n]
+}
+
+// countContent
+// returns the number of tokens of a line that are not whitespace.
+func countContent(tokens []Token) int {
+	n := 0
+	for _, token := range tokens {
+		if !isBlankToken(token) {
+			n++
+		}
+	}
+	return n
+}
+
+// parseOrigin
+// parses the operand of a .org directive. The result holds the new origin as its only object.
+func (p *Parser) parseOrigin(lineNo int, tokens []Token) LineResult {
+	result := LineResult{ParsedLine: ParsedLine{LineNumber: lineNo, Directive: OriginDirective}}
+	content := contentTokens(tokens)
+	if len(content) != 3 || !isIntegerToken(content[2].Type) || content[2].Type == TokenBigInt {
+		result.Error = "Expected a single address after .org"
+		return result
+	}
+	val, err := p.config.parseNumber(content[2].ValueReceived)
+	if err != nil {
+		result.Error = "Invalid .org address"
+		return result
+	}
+	result.Objects = []ObjectType{newObject(TokenUint64, val, "")}
+	result.Objects[0].ObjectText = content[2].text()
+	result.Ok = true
+	return result
+}
+
+// originValue
+// returns the address set by a successfully parsed .org line.
+func originValue(lr LineResult) (uint64, bool) {
+	if lr.Directive != OriginDirective || !lr.Ok || len(lr.Objects) != 1 {
+		return 0, false
+	}
+	val, ok := lr.Objects[0].ObjectValue.(uint64)
+	return val, ok
+}
+
+// checkOverlaps
+// marks the first line of every region that overlaps an earlier region as failed.
+func (sr *SourceResult) checkOverlaps() {
+	for _, ov := range sr.AddressMap().Overlaps {
+		later := ov.Second
+		if later.FirstLine < ov.First.FirstLine {
+			later = ov.First
+		}
+		other := ov.First
+		if later == ov.First {
+			other = ov.Second
+		}
+		for idx := range sr.Lines {
+			lr := &sr.Lines[idx]
+			if lr.LineNumber == later.FirstLine && lr.Address == later.Start && lr.Ok {
+				lr.Ok = false
+				lr.Error = fmt.Sprintf("Region %#x-%#x overlaps region %#x-%#x (lines %d-%d)",
+					later.Start, later.End, other.Start, other.End, other.FirstLine, other.LastLine)
+				break
+			}
+		}
+	}
+}
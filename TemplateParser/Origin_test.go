@@ -0,0 +1,28 @@
+package TemplateParser
+
+import "testing"
+
+func TestOriginDirectiveCase(t *testing.T) {
+	preserve := DefaultParserConfig()
+	preserve.PreserveCase = true
+	folding := preserve
+	folding.FoldMnemonics = true
+	tests := []struct {
+		name   string
+		config ParserConfig
+		line   string
+		org    bool
+	}{
+		{"default", DefaultParserConfig(), ".ORG 100", true},
+		{"preserve case", preserve, ".org 100", true},
+		{"preserve case", preserve, ".ORG 100", false},
+		{"fold mnemonics", folding, ".ORG 100", true},
+	}
+	for _, tt := range tests {
+		p := newTestParser(t, tt.config, exprGrammar)
+		lr := parseTestSource(t, p, tt.line+"\n").Lines[0]
+		if org := lr.Ok && lr.Directive == OriginDirective; org != tt.org {
+			t.Errorf("%s: %q is .org = %v, want %v (%s)", tt.name, tt.line, org, tt.org, lr.Error)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ambiguity
+// is a pair of forms registered under the same mnemonic that can match the same line, as
+// reported by Parser.Ambiguities. Forms are in the text form of TemplateSpec.
+type Ambiguity struct {
+	Mnemonic string
+	Forms    [2]string
+}
+
+// String
+// describes the ambiguity.
+func (a Ambiguity) String() string {
+	return fmt.Sprintf("%s: forms %s and %s overlap", a.Mnemonic, a.Forms[0], a.Forms[1])
+}
+
+// Ambiguities
+// analyzes the entries registered under each mnemonic, in registration order, and reports
+// the pairs of forms a single line could match when both are available, in the same
+// profile. Two forms overlap
+// when they have the same number of slots and every pair of slots can hold the same
+// token. Integer slots of different widths are taken to be distinct, since hex literals
+// select their width by their digit count, as are operator slots holding different
+// operators, keyword slots built by CompileTemplate for different words and slots with
+// disjoint ranges. Matcher slots, guards and Validate callbacks cannot be analyzed, so
+// forms relying on them are reported as overlapping; the parser still resolves such lines
+// when only one form matches them.
+func (p *Parser) Ambiguities() []Ambiguity {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var found []Ambiguity
+	for _, name := range p.mnemonics {
+		variants := p.registry[name]
+		for i := 0; i < len(variants); i++ {
+			for j := i + 1; j < len(variants); j++ {
+				if p.overlap(variants[i], variants[j]) {
+					found = append(found, Ambiguity{name, [2]string{
+						p.config.templateSpec(variants[i].form()), p.config.templateSpec(variants[j].form())}})
+				}
+			}
+		}
+	}
+	return found
+}
+
+// compatibleOptions
+// reports whether some set of target options satisfies both requirement maps.
+func compatibleOptions(a map[string]string, b map[string]string) bool {
+	for name, value := range a {
+		if other, found := b[name]; found && other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// sameForm
+// reports whether two template lists describe the same operand form: the same slot types
+// holding the same operators and keywords.
+func sameForm(a []TemplateObject, b []TemplateObject) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := 1; idx < len(a); idx++ {
+		if a[idx].TemplateType != b[idx].TemplateType || slotWord(a[idx]) != slotWord(b[idx]) ||
+			!sameChoices(a[idx], b[idx]) || !sameFlags(a[idx], b[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// formsOverlap
+// reports whether two template lists can match the same line.
+func formsOverlap(a []TemplateObject, b []TemplateObject) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := 1; idx < len(a); idx++ {
+		if !slotsOverlap(a[idx], b[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// slotsOverlap
+// reports whether two template slots can hold the same token.
+func slotsOverlap(a TemplateObject, b TemplateObject) bool {
+	if a.Hooks.matcher() != nil || b.Hooks.matcher() != nil {
+		return true
+	}
+	if !overlappingTypes(a.TemplateType, b.TemplateType) {
+		return false
+	}
+	if a.TemplateType == TokenEnum {
+		return choicesOverlap(a, b)
+	}
+	if b.TemplateType == TokenEnum {
+		return choicesOverlap(b, a)
+	}
+	if a.TemplateType == TokenFlags {
+		return flagsOverlap(a, b)
+	}
+	if b.TemplateType == TokenFlags {
+		return flagsOverlap(b, a)
+	}
+	if wordA, wordB := slotWord(a), slotWord(b); wordA != "" && wordB != "" && !strings.EqualFold(wordA, wordB) {
+		return false
+	}
+	if a.HasRange() && b.HasRange() && (maxValue(a) < b.MinValue || maxValue(b) < a.MinValue) {
+		return false
+	}
+	return true
+}
+
+// overlappingTypes
+// reports whether slots of two token types can take the same token.
+func overlappingTypes(a int, b int) bool {
+	if a == b {
+		return true
+	}
+	names := func(tt int) bool {
+		return tt == TokenIdentifier || tt == TokenLabelRef || tt == TokenLabelRel || tt == TokenEnum || tt == TokenFlags
+	}
+	if names(a) && names(b) {
+		return true
+	}
+	if (a == TokenMemory && b == TokenLBracket) || (a == TokenLBracket && b == TokenMemory) {
+		return true
+	}
+	integers := func(tt int) bool { return isIntegerToken(tt) || isWideTemplate(tt) }
+	return integers(a) && integers(b) && (isWideTemplate(a) || isWideTemplate(b))
+}
+
+// slotWord
+// returns the operator an operator slot must hold or the word of a keyword or mnemonic
+// slot, "" for slots taking any value.
+func slotWord(slot TemplateObject) string {
+	word, _ := slot.TemplateValue.ObjectValue.(string)
+	switch {
+	case slot.TemplateType == TokenOperator:
+		return word
+	case slot.TemplateType == TokenIdentifier && slot.Hooks.validate() != nil:
+		return word
+	}
+	return ""
+}
+
+// maxValue
+// returns the upper bound of a slot's range.
+func maxValue(slot TemplateObject) uint64 {
+	if slot.MaxValue == 0 {
+		return ^uint64(0)
+	}
+	return slot.MaxValue
+}
+
+// selectOverloads
+// returns the entries registered under the mnemonic of a line that are available with the
+// current target options in a profile: every one of them when the configuration allows overloads, and
+// the first otherwise.
+func (p *Parser) selectOverloads(tokens []Token, profile string) ([]*TemplateEntry, bool, string) {
+	if !p.config.Overloads {
+		entry, ok, errmsg := p.selectTemplate(tokens, profile)
+		return []*TemplateEntry{entry}, ok, errmsg
+	}
+	variants, found := p.registry[p.mnemonicKey(FirstIdentifier(tokens))]
+	if !found {
+		entry, ok, errmsg := p.selectTemplate(tokens, profile)
+		return []*TemplateEntry{entry}, ok, errmsg
+	}
+	entries := make([]*TemplateEntry, 0, len(variants))
+	errmsg := ""
+	for _, entry := range variants {
+		if ok, why := p.available(entry, profile); ok {
+			entries = append(entries, entry)
+		} else if errmsg == "" {
+			errmsg = why
+		}
+	}
+	if len(entries) == 0 {
+		return nil, false, errmsg
+	}
+	return entries, true, ""
+}
+
+// resolveOverloads
+// picks the result of a line matched against several forms: the only successful match,
+// or an ambiguity error listing the forms that matched. When no form matches, the error
+// is that of the form that matched furthest into the line, the earliest registered on a
+// tie.
+func (p *Parser) resolveOverloads(results []LineResult, entries []*TemplateEntry) LineResult {
+	var matched []int
+	best := 0
+	for idx, result := range results {
+		if result.Ok {
+			matched = append(matched, idx)
+		} else if furtherError(result, results[best]) {
+			best = idx
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return results[best]
+	case 1:
+		return results[matched[0]]
+	}
+	forms := make([]string, len(matched))
+	for idx, which := range matched {
+		forms[idx] = p.config.templateSpec(entries[which].form())
+	}
+	result := results[matched[0]]
+	result.Ok = false
+	result.Error = fmt.Sprintf("Ambiguous %s: the line matches %s", entries[0].Name, strings.Join(forms, " and "))
+	result.Mnemonic, result.Operands, result.Groups, result.Named = "", nil, nil, nil
+	result.entry = nil
+	return result
+}
+
+// furtherError
+// reports whether a failed result got further into its line than another.
+func furtherError(a LineResult, b LineResult) bool {
+	return a.ErrorColumn > b.ErrorColumn
+}
@@ -0,0 +1,93 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+// overloadGrammar registers mov with a register and with an immediate source, and
+// jmp with a label and with an identifier, which a single line can match.
+const overloadGrammar = `templates:
+  - mnemonic: mov
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Register}
+  - mnemonic: mov
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+  - mnemonic: jmp
+    operands:
+      - {type: Identifier}
+      - {type: LabelRef}
+  - mnemonic: jmp
+    operands:
+      - {type: Identifier}
+      - {type: Identifier}
+`
+
+// overloadParser
+// creates a parser allowing overloads with the templates of overloadGrammar.
+func overloadParser(t *testing.T) *Parser {
+	t.Helper()
+	config := DefaultParserConfig()
+	config.Overloads = true
+	return newTestParser(t, config, overloadGrammar)
+}
+
+func TestAmbiguities(t *testing.T) {
+	found := overloadParser(t).Ambiguities()
+	if len(found) != 1 || found[0].Mnemonic != "jmp" {
+		t.Fatalf("Ambiguities() = %v, want only the jmp forms", found)
+	}
+	if text := found[0].String(); !strings.HasPrefix(text, "jmp: forms ") || !strings.HasSuffix(text, " overlap") {
+		t.Errorf("String() = %q", text)
+	}
+}
+
+func TestAmbiguitiesRanges(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Overloads = true
+	p := newTestParser(t, config, `templates:
+  - mnemonic: rst
+    operands:
+      - {type: Identifier}
+      - {type: Uint8, min: 0, max: 7}
+  - mnemonic: rst
+    operands:
+      - {type: Identifier}
+      - {type: Uint8, min: 8, max: 15}
+`)
+	if found := p.Ambiguities(); len(found) != 0 {
+		t.Errorf("Ambiguities() = %v, want disjoint ranges to be distinct", found)
+	}
+}
+
+func TestOverloadsPickTheMatchingForm(t *testing.T) {
+	result := parseTestSource(t, overloadParser(t), "mov r1, r2\nmov r1, 10\n")
+	if lr := result.Lines[0]; !lr.Ok || lr.Objects[3].ObjectTypeId != TokenRegister {
+		t.Errorf("register form = %v, %q", lr.Objects, lr.Error)
+	}
+	if got := lineValue(t, result, 1); got != uint64(0x10) {
+		t.Errorf("immediate form value = %v", got)
+	}
+}
+
+func TestOverloadsReportAmbiguousLines(t *testing.T) {
+	result := parseTestSource(t, overloadParser(t), "jmp loop\n")
+	lr := result.Lines[0]
+	if lr.Ok || !strings.HasPrefix(lr.Error, "Ambiguous jmp: the line matches ") {
+		t.Errorf("line = %v, %q, want an ambiguity error", lr.Ok, lr.Error)
+	}
+}
+
+func TestLaterFormReplacesWithoutOverloads(t *testing.T) {
+	result := parseTestSource(t, newTestParser(t, DefaultParserConfig(), overloadGrammar), "mov r1, r2\nmov r1, 10\n")
+	if result.Lines[0].Ok || !result.Lines[1].Ok {
+		t.Errorf("lines = %v, %v, want only the later mov form", result.Lines[0].Ok, result.Lines[1].Ok)
+	}
+}
@@ -0,0 +1,73 @@
+package TemplateParser
+
+import (
+	"io"
+	"runtime"
+	"sync"
+)
+
+// parallelBatch is the number of consecutive lines a worker takes at a time.
+const parallelBatch = 64
+
+// ParseSourceParallel
+// parses a source matched against a single template list, like ParseSource on a Parser
+// whose templates were set with SetTemplates, spreading the lines over workers goroutines.
+func ParseSourceParallel(r io.Reader, templateList []TemplateObject, workers int) (*SourceResult, error) {
+	p := NewParser(DefaultParserConfig())
+	p.templates = templateList
+	return p.ParseSourceParallel(r, workers)
+}
+
+// ParseSourceParallel
+// is ParseSource with the tokenizing, macro expansion and matching of lines spread over a
+// pool of workers goroutines; a count below 1 uses one worker per CPU. The location counter,
+// labels and symbol resolution still run in source order afterwards, so the result is the
+// same as ParseSource's, except that equ constants are defined by a sequential pre-pass and
+// so may be used before the line defining them. Macro functions and token filters must be
+// safe for concurrent use.
+func (p *Parser) ParseSourceParallel(r io.Reader, workers int) (*SourceResult, error) {
+	defer p.span("ParseSource", map[string]interface{}{"workers": workers}).End()
+	ctx := p.newContext(nil)
+	lines, err := p.readSource(r, "", nil, "", nil, ctx.budget)
+	pass := p.span("match", map[string]interface{}{"lines": len(lines)})
+	parsed := p.matchParallel(lines, workers, ctx)
+	pass.End()
+	return p.assembleSource(parsed, ctx), ctx.budget.firstError(err)
+}
+
+// matchParallel
+// parses source lines on a pool of workers goroutines, one per CPU if workers is below 1,
+// after parsing the lines defining equ constants sequentially. Every line is parsed in a
+// copy of the call's context.
+func (p *Parser) matchParallel(lines []sourceLine, workers int, ctx *ParseContext) [][]LineResult {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	parsed := make([][]LineResult, len(lines))
+	for idx, line := range lines {
+		if p.definesConstant(line.text) {
+			parsed[idx] = p.tracedLine(line, ctx)
+		}
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range next {
+				for idx := start; idx < min(start+parallelBatch, len(lines)); idx++ {
+					if parsed[idx] == nil {
+						parsed[idx] = p.tracedLine(lines[idx], ctx)
+					}
+				}
+			}
+		}()
+	}
+	for start := 0; start < len(lines); start += parallelBatch {
+		next <- start
+	}
+	close(next)
+	wg.Wait()
+	return parsed
+}
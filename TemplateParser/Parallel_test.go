@@ -0,0 +1,48 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSourceParallelMatchesParseSource(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&sb, "l%d: nop\njmp l%d\n", i, (i*7)%300)
+		if i%50 == 0 {
+			sb.WriteString("jmp nowhere\nbogus r1\n")
+		}
+	}
+	source := sb.String()
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	want := parseTestSource(t, p, source)
+	for _, workers := range []int{1, 4, 0} {
+		got, err := p.ParseSourceParallel(strings.NewReader(source), workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Lines, want.Lines) {
+			for idx := range want.Lines {
+				if !reflect.DeepEqual(got.Lines[idx], want.Lines[idx]) {
+					t.Errorf("%d workers: line %d = %+v, want %+v", workers, idx+1, got.Lines[idx], want.Lines[idx])
+					break
+				}
+			}
+		}
+		if !reflect.DeepEqual(got.Symbols.Symbols(), want.Symbols.Symbols()) {
+			t.Errorf("%d workers: symbols differ from ParseSource", workers)
+		}
+	}
+}
+
+func TestParseSourceParallelTemplateList(t *testing.T) {
+	result, err := ParseSourceParallel(strings.NewReader("mov r1, r2\nmov r1\nadd r3, r4\n"), benchmarkTemplate, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Lines) != 3 || !result.Lines[0].Ok || result.Lines[1].Ok || !result.Lines[2].Ok {
+		t.Errorf("ParseSourceParallel = %+v, want lines 1 and 3 to match", result.Lines)
+	}
+}
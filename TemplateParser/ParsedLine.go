@@ -0,0 +1,159 @@
+package TemplateParser
+
+import "strings"
+
+// Operand
+// is one matched operand of a parsed line: the template slot it filled, the name of its
+// type, the capture group and name of the slot, if any, and the converted object.
+type Operand struct {
+	Slot     int        `json:"slot"`
+	TypeName string     `json:"type"`
+	Group    string     `json:"group,omitempty"`
+	Name     string     `json:"name,omitempty"`
+	Object   ObjectType `json:"object"`
+}
+
+// ParsedLine
+// is the structured form of a parsed source line. Mnemonic is the text of the leading
+// identifier and Operands the remaining objects without punctuation, so consumers do not
+// need to interpret the positional Objects slice by convention. RawText is the line as it
+// appeared in the source, before macro expansion, and Comment the text after its semicolon.
+// The source text of sensitive operands is replaced by RedactedValue in RawText and
+// Expanded; a failed line of a template with sensitive slots has RedactedValue as its text.
+type ParsedLine struct {
+	LineNumber int                     `json:"line"`
+	File       string                  `json:"file,omitempty"`     // File the line was read from, if named (set by ParseSource)
+	Includes   []IncludeSite           `json:"includes,omitempty"` // Include directives leading to File, outermost first
+	Offset     int64                   `json:"offset,omitempty"`   // Byte offset of the line in the original input (set by ParseSource)
+	Segments   []LineSegment           `json:"segments,omitempty"` // Physical lines the line was joined from (see ParserConfig.LineContinuation)
+	RawText    string                  `json:"raw_text,omitempty"`
+	Expanded   string                  `json:"expanded,omitempty"`  // Text after macro expansion, if it differs from RawText
+	Label      string                  `json:"label,omitempty"`     // Label defined at the start of the line, if any
+	Mnemonic   string                  `json:"mnemonic,omitempty"`  // Text of the leading identifier
+	Operands   []Operand               `json:"operands,omitempty"`  // Objects after the mnemonic, without punctuation
+	Comment    string                  `json:"comment,omitempty"`   // Text after the comment semicolon
+	Directive  string                  `json:"directive,omitempty"` // Name of the built-in directive on the line, such as "org"
+	Address    uint64                  `json:"address"`             // Location counter at the start of the line (set by ParseSource)
+	Size       uint64                  `json:"size,omitempty"`      // Size in bytes from the matched template entry
+	Cycles     int                     `json:"cycles,omitempty"`    // Cycle count from the matched template entry
+	Objects    []ObjectType            `json:"objects"`
+	Groups     map[string][]ObjectType `json:"groups,omitempty"`
+	Named      map[string]ObjectType   `json:"named,omitempty"`    // Objects of the named template slots
+	Warnings   []Warning               `json:"warnings,omitempty"` // Problems that did not stop the line from matching
+
+	templates []TemplateObject
+	entry     *TemplateEntry      // Registered entry the line matched, for its Action
+	directive *Directive          // Registered directive the line matched, for its Handler
+	wide      bool                // The line was read from UTF-16 input
+	pending   map[int]pendingExpr // Operand expressions waiting for label addresses
+	errSlot   int                 // Slot plus one the line failed to match at, 0 if none
+	raw       string              // RawText before its sensitive operands were masked
+	expanded  string              // Expanded before its sensitive operands were masked
+}
+
+// LineResult
+// holds the outcome of matching a single line: the parsed line, and the success flag
+// and error message that ParseLine would return. ErrorColumn is the 1-based byte column
+// of the token the error concerns, or 0 if it concerns the whole line; ErrorLength is the
+// length of that token. Expected and TemplateError give the type and error text of the
+// template slot the token failed to match, when the error concerns one.
+type LineResult struct {
+	ParsedLine
+	Ok            bool   `json:"ok"`
+	Error         string `json:"error,omitempty"`
+	ErrorColumn   int    `json:"error_column,omitempty"`
+	ErrorLength   int    `json:"error_length,omitempty"`
+	Expected      string `json:"expected,omitempty"`
+	TemplateError string `json:"template_error,omitempty"`
+	// Origin lists the macro expansions, outermost first, that produced the token the
+	// error concerns, if it came from a macro.
+	Origin []TokenOrigin `json:"origin,omitempty"`
+	// Suggestions helps fix an instruction that failed to match, nil when there is
+	// nothing to suggest.
+	Suggestions *Suggestions `json:"suggestions,omitempty"`
+}
+
+// isPunctuation
+// reports whether a token type is one of the punctuation and operator tokens.
+func isPunctuation(tokenType int) bool {
+	switch tokenType {
+	case TokenComma, TokenColon, TokenLBracket, TokenRBracket,
+		TokenLParen, TokenRParen, TokenPlus, TokenMinus, TokenOperator:
+		return true
+	}
+	return false
+}
+
+// newLineResult
+// builds a LineResult, filling in the mnemonic, operands and capture groups when the
+// match succeeded.
+func newLineResult(config ParserConfig, lineNo int, objs []ObjectType, ok bool, errmsg string,
+	templateList []TemplateObject) LineResult {
+	result := LineResult{ParsedLine: ParsedLine{LineNumber: lineNo, Objects: objs}, Ok: ok, Error: errmsg}
+	if ok {
+		result.templates = templateList
+		result.describe(config)
+	}
+	return result
+}
+
+// describe
+// derives Mnemonic, Operands, Groups and Named from the objects and the matched templates.
+func (pl *ParsedLine) describe(config ParserConfig) {
+	pl.Mnemonic = ""
+	pl.Operands = nil
+	first := 0
+	if pl.Directive == "" && len(pl.Objects) > 0 && pl.Objects[0].ObjectTypeId == TokenIdentifier {
+		pl.Mnemonic, _ = pl.Objects[0].ObjectValue.(string)
+		first = 1
+	}
+	for idx := first; idx < len(pl.Objects); idx++ {
+		obj := pl.Objects[idx]
+		if isPunctuation(obj.ObjectTypeId) {
+			continue
+		}
+		op := Operand{Slot: idx, TypeName: config.tokenName(obj.ObjectTypeId), Object: obj}
+		if idx < len(pl.templates) {
+			op.Group, op.Name = pl.templates[idx].Group, pl.templates[idx].Name
+		}
+		pl.Operands = append(pl.Operands, op)
+	}
+	pl.Groups = Captures(pl.templates, pl.Objects)
+	pl.Named = NamedObjects(pl.templates, pl.Objects)
+}
+
+// refresh
+// copies updated objects, such as resolved label references, into Operands, Groups and Named.
+func (pl *ParsedLine) refresh() {
+	for idx := range pl.Operands {
+		if slot := pl.Operands[idx].Slot; slot < len(pl.Objects) {
+			pl.Operands[idx].Object = pl.Objects[slot]
+		}
+	}
+	pl.Groups = Captures(pl.templates, pl.Objects)
+	pl.Named = NamedObjects(pl.templates, pl.Objects)
+}
+
+// setSource
+// records the original source text of the line and its comment.
+func (pl *ParsedLine) setSource(raw string) {
+	pl.RawText = raw
+	_, comment := SplitComment(raw)
+	pl.Comment = strings.TrimSpace(comment)
+}
+
+// ParseLine
+// parses a single line into its structured form. See Parse.
+func (p *Parser) ParseLine(line string) (ParsedLine, bool, string) {
+	return p.ParseLineContext(nil, line)
+}
+
+// ParsedLines
+// returns the structured form of every line of a parsed source.
+func (sr *SourceResult) ParsedLines() []ParsedLine {
+	lines := make([]ParsedLine, len(sr.Lines))
+	for idx, lr := range sr.Lines {
+		lines[idx] = lr.ParsedLine
+	}
+	return lines
+}
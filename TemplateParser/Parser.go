@@ -0,0 +1,479 @@
+// Package TemplateParser matches lines of text against templates of typed slots and
+// parses whole sources with them.
+//
+// Token and ObjectType have gained fields since their first release, ObjectSensitive,
+// ObjectWidth and ObjectText among them, and Token an unexported field holding its source
+// text, so composite literals of them must name their fields: positional literals such
+// as ObjectType{id, value, desc} no longer compile.
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Parser
+// holds a configuration together with the tokenizer built for it and the templates
+// lines are matched against. Unlike the package-level functions, a Parser builds its
+// tokenizer once, so it should be reused across lines and files.
+//
+// A Parser is safe for concurrent use, so a server can share one across the goroutines
+// handling its requests: any number of goroutines may parse, tokenize, encode and query
+// with it at once. Each call gets its own symbol table and its own table of the constants
+// its equ lines define, so one request's constants are never seen by another, while the
+// constants defined with DefineConstant, the macro table and the interned words of the
+// tokenizer are shared and locked.
+// Methods changing the template set or the configuration, such as RegisterTemplate,
+// AddOperator, SetTargetOption or SetMacros, may also be called while others parse: they
+// wait for the lines being matched, and lines matched afterwards see the change, so a
+// source parsed meanwhile can be matched partly before and partly after it. Freeze the
+// parser once it is set up to have such changes to its template set rejected instead.
+// Callbacks the parser runs while matching a line, such as Validate, Matcher and
+// ValidateContext functions and token filters, must not change the parser; together with
+// macro functions and actions, they must be safe for concurrent use if the parser is.
+type Parser struct {
+	mu        sync.RWMutex // Guards the template set and configuration against lines being matched
+	config    ParserConfig
+	lexer     *lexer
+	templates []TemplateObject
+	registry  map[string][]*TemplateEntry
+	mnemonics []string
+
+	targetOptions  map[string]string
+	directives     map[string]*Directive // Set by RegisterDirective
+	profiles       map[string]Profile
+	profileOrder   []string
+	profile        string
+	grammarParams  map[string]string
+	constants      *constantTable
+	tracer         Tracer
+	logger         *slog.Logger
+	resolver       FileResolver
+	tokenFilters   []TokenFilter
+	frozen         string // GrammarHash recorded by Freeze
+	grammarVersion string
+	deprecations   map[int]TokenDeprecation
+	duplicates     []TemplateIssue // Entries RegisterEntry replaced with the same form, for Validate
+	cache          *lineCache      // Set by SetLineCache
+	stats          *Stats          // Set by SetStats
+	macros         *MacroTable
+	symbols        *SymbolTable // Set by SetSymbols
+}
+
+// NewParser
+// creates a Parser for the given configuration and builds its tokenizer.
+func NewParser(config ParserConfig) *Parser {
+	return &Parser{
+		config:    config,
+		lexer:     newLexer(config),
+		registry:  make(map[string][]*TemplateEntry),
+		constants: &constantTable{},
+	}
+}
+
+// Config
+// returns the configuration the parser was created with.
+func (p *Parser) Config() ParserConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// SetTemplates
+// sets the template list that Parse and ParseAll match lines against. The list is
+// rejected if its capture groups are not contiguous, a back-reference does not refer
+// to an earlier slot, the choices of an Enum slot are not identifiers or the flags of a
+// Flags slot are not single words.
+func (p *Parser) SetTemplates(templateList []TemplateObject) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return false, errFrozen
+	}
+	if ok, errmsg := ValidateGroups(templateList); !ok {
+		return false, errmsg
+	}
+	if ok, errmsg := ValidateNames(templateList); !ok {
+		return false, errmsg
+	}
+	if ok, errmsg := ValidateBackReferences(templateList); !ok {
+		return false, errmsg
+	}
+	if ok, errmsg := ValidateChoices(templateList); !ok {
+		return false, errmsg
+	}
+	if ok, errmsg := p.checkChoiceTokens(templateList); !ok {
+		return false, errmsg
+	}
+	if ok, errmsg := ValidateFlags(templateList); !ok {
+		return false, errmsg
+	}
+	if ok, errmsg := p.checkFlagTokens(templateList); !ok {
+		return false, errmsg
+	}
+	p.templates = templateList
+	return true, ""
+}
+
+// Templates
+// returns the template list set with SetTemplates.
+func (p *Parser) Templates() []TemplateObject {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.templates
+}
+
+// Tokenize
+// scans the input string using the parser's tokenizer. Concatenating the values of the
+// tokens gives back the input.
+func (p *Parser) Tokenize(input string) (tokens []Token) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	defer p.recoverTokens(input, &tokens)
+	return p.lexer.scan(input)
+}
+
+// TokenizeInto
+// tokenizes input like Tokenize, storing the tokens in dst, whose contents are
+// overwritten, to save allocating a token slice per call in hot loops. The returned slice
+// shares dst's storage when it is large enough, so it is only valid until dst is reused.
+func (p *Parser) TokenizeInto(dst []Token, input string) (tokens []Token) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	defer p.recoverTokens(input, &tokens)
+	return p.lexer.scanInto(dst, input)
+}
+
+// TokenizeLine
+// strips the comment from a line and tokenizes it, applying the parser's case handling.
+func (p *Parser) TokenizeLine(txt string) []Token {
+	return p.TokenizeLineInto(nil, txt)
+}
+
+// TokenizeLineInto
+// tokenizes a line like TokenizeLine, storing the tokens in dst as TokenizeInto does.
+func (p *Parser) TokenizeLineInto(dst []Token, txt string) []Token {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tokenizeLine(dst, txt)
+}
+
+// tokenizeLine
+// implements TokenizeLineInto for callers holding the parser's lock.
+func (p *Parser) tokenizeLine(dst []Token, txt string) (tokens []Token) {
+	defer p.recoverTokens(txt, &tokens)
+	return tokenizeLine(dst, txt, p.lexer, p.config)
+}
+
+// Parse
+// parses a single line and matches it against the parser's templates. When templates have
+// been registered, the one registered under the line's first identifier is used; lines whose
+// mnemonic is not registered fall back to the list set with SetTemplates, if any.
+// A line whose macros expand into several lines cannot be parsed this way; use ParseAll.
+// As no labels are defined outside a source, an operand expression naming anything but a
+// constant fails the line.
+func (p *Parser) Parse(line string) ([]ObjectType, bool, string) {
+	results := p.parseExpanded(0, line)
+	if len(results) != 1 {
+		return nil, false, fmt.Sprintf("Line expands to %d lines", len(results))
+	}
+	resolveLineExpressions(&results[0], nil)
+	runAction(&results[0], nil)
+	return results[0].Objects, results[0].Ok, results[0].Error
+}
+
+// parseExpanded
+// expands the macros in a source line and parses every non-empty line the expansion
+// produces. All results carry the line number of the source line.
+func (p *Parser) parseExpanded(lineNo int, line string) []LineResult {
+	return p.parseInContext(line, &ParseContext{Line: lineNo, constants: newConstantScope(p.constants)})
+}
+
+// parseInContext
+// implements parseExpanded for line ctx.Line of ctx.File, expanding macros with the
+// context's macro table and charging the expansions and tokens to its budget. It returns
+// no results if that exceeds a limit.
+func (p *Parser) parseInContext(line string, ctx *ParseContext) (results []LineResult) {
+	lineNo := ctx.Line
+	defer p.recoverLine(lineNo, line, &results)
+	lines, expansions, ok, errmsg := expandLine(p.macroTable(ctx), line)
+	if !ctx.budget.expand(expansions, ctx.File, lineNo) {
+		return nil
+	}
+	if !ok {
+		result := newLineResult(p.config, lineNo, nil, false, errmsg, nil)
+		result.setSource(line)
+		return []LineResult{result}
+	}
+	results = make([]LineResult, 0, len(lines))
+	for _, expanded := range lines {
+		if len(lines) > 1 && strings.TrimSpace(EatComments(expanded.text)) == "" {
+			continue
+		}
+		result, within := p.parseLine(lineNo, expanded.text, expanded.spans, ctx)
+		if !within {
+			return nil
+		}
+		result.setSource(line)
+		if expanded.text != line {
+			result.Expanded = expanded.text
+		}
+		if p.config.VerifyRoundTrip && result.Ok {
+			if _, ok, errmsg := p.verifyResult(result, ctx); !ok {
+				result.Ok, result.Error = false, errmsg
+			}
+		}
+		result.redactSource(nil)
+		results = append(results, result)
+	}
+	return results
+}
+
+// parseLine
+// tokenizes a line, selects its template and matches it, returning the full line result.
+// A label defined at the start of the line is removed before matching and reported in
+// the result's Label field. spans locate the macro expansions the line came from, so the
+// error of a failed line can record the origin of its token. The tokens are charged
+// to the context's budget before matching; false is returned if that exceeds its limit.
+// Lines found in the line cache are charged for the tokens they had when matched. The line
+// cache is not used once the call's equ lines have defined constants.
+func (p *Parser) parseLine(lineNo int, line string, spans []macroSpan, ctx *ParseContext) (LineResult, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var start time.Time
+	if p.stats != nil {
+		start = time.Now()
+	}
+	// Results depending on the constants of equ lines, which belong to one call, are not
+	// cached
+	useCache := p.cache != nil && len(spans) == 0 && len(p.tokenFilters) == 0 && p.constantScope(ctx).len() == 0
+	var key lineCacheKey
+	var constants uint64
+	if useCache {
+		key, constants = lineCacheKey{line, p.lineProfile(ctx)}, p.constants.version()
+		if result, tokens, found := p.cache.get(key, constants); found {
+			if !ctx.budget.matchLine(tokens, ctx.File, lineNo) {
+				return LineResult{}, false
+			}
+			result.LineNumber = lineNo
+			p.logResult(result, true)
+			p.recordLine(result, nil, true, start)
+			return result, true
+		}
+	}
+	var allTokens []Token
+	if len(p.tokenFilters) == 0 {
+		// Without filters, which may keep the tokens they are given, nothing holds on to
+		// the tokens once the line is matched, so their storage is reused
+		buf := getTokenBuffer()
+		allTokens = p.tokenizeLine(*buf, line)
+		defer func() { putTokenBuffer(buf, allTokens) }()
+	} else {
+		allTokens = p.tokenizeLine(nil, line)
+	}
+	allTokens = p.filterTokens(allTokens)
+	content := countContent(allTokens)
+	if !ctx.budget.matchLine(content, ctx.File, lineNo) {
+		return LineResult{}, false
+	}
+	result := p.matchLine(lineNo, allTokens, ctx)
+	p.logTokens(lineNo, line, allTokens, result)
+	result.Warnings = p.deprecationWarnings(allTokens)
+	if !result.Ok && result.ErrorColumn > 0 {
+		offset, length, found := tokenAtColumn(allTokens, result.ErrorColumn)
+		if found {
+			result.Origin, result.ErrorLength = originAt(spans, offset, lineNo), length
+		}
+	}
+	if p.config.Normalize.active() {
+		p.config.Normalize.Normalize(line).restoreColumns(&result)
+	}
+	if useCache && p.cacheable(result, allTokens) {
+		p.cache.put(key, result, content, constants)
+	}
+	p.logResult(result, false)
+	p.recordLine(result, allTokens, false, start)
+	return result, true
+}
+
+// matchLine
+// recognizes the directive or selects the template of a tokenized line and matches it,
+// with suggestions for fixing an instruction that fails to match.
+func (p *Parser) matchLine(lineNo int, allTokens []Token, ctx *ParseContext) LineResult {
+	result := p.matchStatement(lineNo, allTokens, ctx)
+	if !result.Ok && result.Directive == "" {
+		_, tokens := SplitLabel(allTokens)
+		result.Suggestions = p.suggest(tokens, p.lineProfile(ctx), p.constantScope(ctx))
+	}
+	return result
+}
+
+// matchStatement
+// implements matchLine without the suggestions.
+func (p *Parser) matchStatement(lineNo int, allTokens []Token, ctx *ParseContext) LineResult {
+	label, tokens := SplitLabel(allTokens)
+	if label != "" && !hasContent(tokens) {
+		return LineResult{ParsedLine: ParsedLine{LineNumber: lineNo, Label: label}, Ok: true}
+	}
+	if p.isOriginDirective(tokens) {
+		result := p.parseOrigin(lineNo, tokens)
+		result.Label = label
+		return result
+	}
+	if p.isEquDirective(tokens) {
+		result := p.parseEqu(lineNo, tokens, p.constantScope(ctx))
+		result.Label = label
+		return result
+	}
+	if p.isArchDirective(tokens) {
+		result := p.parseArch(lineNo, tokens)
+		result.Label = label
+		return result
+	}
+	if directive, operands := p.registeredDirective(tokens); directive != nil {
+		return p.matchDirective(lineNo, label, allTokens, operands, directive, ctx)
+	}
+	profile := p.lineProfile(ctx)
+	if _, found := p.profiles[profile]; profile != "" && !found {
+		result := newLineResult(p.config, lineNo, nil, false, fmt.Sprintf("Unknown profile %s", profile), nil)
+		result.Label = label
+		return result
+	}
+	entries, ok, errmsg := p.selectOverloads(tokens, profile)
+	p.logSkipped(lineNo, tokens, profile)
+	if !ok {
+		result := newLineResult(p.config, lineNo, nil, false, errmsg, nil)
+		result.Label = label
+		result.ErrorColumn = objectColumn(allTokens, tokens, 0)
+		return result
+	}
+	if len(entries) == 1 {
+		return p.matchEntry(lineNo, label, allTokens, tokens, entries[0], ctx)
+	}
+	results := make([]LineResult, len(entries))
+	for idx, entry := range entries {
+		results[idx] = p.matchEntry(lineNo, label, allTokens, tokens, entry, ctx)
+	}
+	return p.resolveOverloads(results, entries)
+}
+
+// matchEntry
+// matches the tokens of a line, after its label, against a template entry.
+func (p *Parser) matchEntry(lineNo int, label string, allTokens []Token, tokens []Token, entry *TemplateEntry, ctx *ParseContext) LineResult {
+	labelTokens := allTokens[:len(allTokens)-len(tokens)]
+	form := entry.form()
+	constants := p.constantScope(ctx)
+	tokens = p.substituteConstants(collapseExpressions(tokens, form), form, constants)
+	// Collapsing expressions merges tokens, so columns are counted on the merged line
+	allTokens = append(append([]Token(nil), labelTokens...), tokens...)
+	if stray := strayToken(tokens); stray >= 0 && p.config.strictUnknown() {
+		column := tokenOffset(allTokens, len(labelTokens)+stray) + 1
+		result := newLineResult(p.config, lineNo, nil, false, strayError(tokens[stray], column), nil)
+		result.Label = label
+		result.ErrorColumn = column
+		return result
+	}
+	objs, errIdx, starts, ok, errmsg := matchTokens(tokens, form, p.config)
+	if ok {
+		objs = entry.canonicalObjects(objs)
+	}
+	result := newLineResult(p.config, lineNo, objs, ok, errmsg, entry.Objects)
+	result.Label = label
+	result.Size = entry.Size
+	result.Cycles = entry.Cycles
+	if !ok {
+		result.ErrorColumn = matchedColumn(allTokens, tokens, starts, errIdx)
+		if errIdx >= 0 && errIdx < len(form) {
+			result.Expected = p.config.tokenName(form[errIdx].TemplateType)
+			result.TemplateError = form[errIdx].TemplateError
+			result.errSlot = errIdx + 1
+			if entry.written != nil {
+				result.errSlot = entry.Order[errIdx] + 1
+			}
+		}
+	}
+	p.evaluateExpressions(&result, entry.Objects, constants)
+	checkGuards(entry, &result)
+	if errIdx := checkContextValidators(ctx, entry.Objects, &result); errIdx >= 0 {
+		result.ErrorColumn = matchedColumn(allTokens, tokens, starts, entry.writtenSlot(errIdx))
+		result.TemplateError = entry.Objects[errIdx].TemplateError
+		result.errSlot = errIdx + 1
+	}
+	result.entry = entry
+	p.logAttempt(entry, result)
+	return result
+}
+
+// selectTemplate
+// picks the template entry a tokenized line should be matched against in a profile. Lines
+// matched against the list set with SetTemplates get an unnamed entry wrapping it.
+func (p *Parser) selectTemplate(tokens []Token, profile string) (*TemplateEntry, bool, string) {
+	if len(p.registry) == 0 {
+		return &TemplateEntry{Objects: p.templates}, true, ""
+	}
+	mnemonic := FirstIdentifier(tokens)
+	if variants, found := p.registry[p.mnemonicKey(mnemonic)]; found {
+		errmsg := ""
+		for _, entry := range variants {
+			ok, why := p.available(entry, profile)
+			if ok {
+				return entry, true, ""
+			}
+			if errmsg == "" {
+				errmsg = why
+			}
+		}
+		return nil, false, errmsg
+	}
+	if p.templates != nil {
+		return &TemplateEntry{Objects: p.templates}, true, ""
+	}
+	if mnemonic == "" {
+		return nil, false, "No mnemonic found"
+	}
+	return nil, false, fmt.Sprintf("Unknown mnemonic %s", mnemonic)
+}
+
+// ParseAll
+// parses every line read from r, after expanding macros. Lines that are empty once comments are removed are skipped.
+// Input is decoded and split into lines as ParseSource does, and .arch directives select
+// the profile of the lines after them. The returned error reports
+// failures reading r, or is a *LimitExceeded if the input exceeds the parser's Limits;
+// parse failures are recorded per line.
+func (p *Parser) ParseAll(r io.Reader) ([]LineResult, error) {
+	results := make([]LineResult, 0)
+	ctx := p.newContext(nil)
+	b := ctx.budget
+	reader := newLineReader(r)
+	lastLine := 0
+	for {
+		line, offset, last, segments, ok := reader.nextStatement(p.config, lastLine)
+		if !ok {
+			break
+		}
+		lineNo := lastLine + 1
+		lastLine = last
+		if !b.readLine(line, "", lineNo) {
+			break
+		}
+		if strings.TrimSpace(EatComments(line)) == "" {
+			continue
+		}
+		source := sourceLine{number: lineNo, text: line, offset: offset, segments: segments, wide: reader.wide()}
+		lineResults := source.withSource(p.parseInContext(line, ctx.at("", lineNo)))
+		if b.stopped() {
+			break
+		}
+		if arch, isArch := p.archName(line); isArch {
+			ctx.Profile = arch
+		}
+		runActions(lineResults, ctx)
+		results = append(results, lineResults...)
+	}
+	return results, b.firstError(reader.readErr())
+}
@@ -0,0 +1,275 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParserConfig
+// holds the options that control how lines are tokenized and matched.
+type ParserConfig struct {
+	// PreserveCase keeps identifiers, macros and registers in the case they were written.
+	// When false (the default) they are lowercased before matching. Quoted strings always
+	// keep their original text.
+	PreserveCase bool
+	// FoldMnemonics makes mnemonics and directive names case-insensitive even when
+	// PreserveCase is set, so MOV and mov select the same template while labels, macro
+	// names and operand identifiers keep their case. Mnemonics keep the case they were
+	// written in within results.
+	FoldMnemonics bool
+	// MaxNumericDigits is the longest hex literal the tokenizer accepts. Literals of up to
+	// 16 digits become the usual sized integer tokens; when this is larger than 16, longer
+	// literals become TokenBigInt tokens holding a *big.Int. Zero means the default of 16.
+	MaxNumericDigits int
+	// IdentifierLetters lists the Unicode classes whose characters count as letters in
+	// identifiers, labels and macro names besides ASCII letters, such as
+	// []*unicode.RangeTable{unicode.Letter} for every script or {unicode.Greek} for Greek
+	// alone; combining marks may then follow a letter within a name. Nil means ASCII
+	// letters only. Hex literals and registers are always ASCII.
+	IdentifierLetters []*unicode.RangeTable
+	// RegisterSuffixes maps register name suffixes to the width in bits they denote,
+	// e.g. {"d": 32, "w": 16, "b": 8} so that r10d is register 0x10 used as 32 bits.
+	// A configured suffix always wins over a trailing hex digit of the same letter.
+	RegisterSuffixes map[string]int
+	// RegisterPrefixes maps additional register prefixes to the width they denote,
+	// e.g. {"w": 32, "x": 64} for w5/x5 style names. Register numbers are hex, like rN.
+	RegisterPrefixes map[string]int
+	// RegisterAliases maps symbolic register names such as sp, pc or lr to the register
+	// numbers they stand for. Aliases are matched as whole words, ignoring case, and
+	// become TokenRegister tokens like rN.
+	RegisterAliases map[string]uint64
+	// VerifyRoundTrip makes a Parser format every line it matches with FormatLine and
+	// parse the text again, failing the line unless both parses agree. It is meant for
+	// tests that guarantee a grammar survives formatting.
+	VerifyRoundTrip bool
+	// IncludeKeyword is the keyword of the directive ParseSource replaces with the lines of
+	// another file, as in include "macros.s". Empty means IncludeDirective.
+	IncludeKeyword string
+	// MaxIncludeDepth limits how deeply include directives may nest. Zero means the
+	// default of 16.
+	MaxIncludeDepth int
+
+	// Operators is the table of operators the tokenizer recognizes, such as ->, => and ::
+	// in configuration languages. Each becomes a TokenOperator token; where operators
+	// overlap, the longest one at a position wins, so with both -> and ->> in the table
+	// a->>b has the single operator ->>. Operators are made of symbol characters and take
+	// precedence over the built-in punctuation they start with. Parser.AddOperator and the
+	// operators list of a grammar file add to the table.
+	Operators []string
+
+	// TokenPrecedence orders the token classes that can match the same text, such as
+	// TokenRegister and TokenIdentifier for ra, or a custom type and the built-in class
+	// it overlaps. The tokenizer takes the longest token at each position; when several
+	// classes match equally long text, the one listed first wins. Any integer type stands
+	// for hex literals of every width. Classes left out follow in the default order:
+	// custom types in the order they were added, TokenOperator, TokenRegister,
+	// TokenBoolean, TokenIdentifier, hex literals and then the rest.
+	TokenPrecedence []int
+	// FirstMatch restores the tokenizer of earlier versions, which tried the token classes
+	// in a fixed order and took the first match even when a later class matched longer
+	// text, so that ra was an identifier and r1x became r1 followed by x.
+	// TokenPrecedence is ignored.
+	FirstMatch bool
+
+	// Hardened makes a Parser recover from panics while tokenizing or parsing a line,
+	// whether caused by the input or by custom token converters, filters and guards. The
+	// line fails with an internal error instead, and Tokenize returns the input as a
+	// single TokenUnknown token. Services parsing untrusted input should set it.
+	Hardened bool
+	// SourceMaps makes ParseSource and the functions built on it record a source map
+	// of their result in SourceResult.SourceMap.
+	SourceMaps bool
+	// StringIndexes makes ParseSource and the functions built on it replace the text of
+	// the quoted strings of matched lines with their index in SourceResult.Strings, a
+	// uint64, moving the text to the object's descriptor, so that encodings can place the
+	// index in a field.
+	StringIndexes bool
+	// Limits caps the lines, bytes, tokens and macro expansions a single call parsing a
+	// source may process. The zero value sets no limits.
+	Limits Limits
+
+	// Overloads keeps entries registered under one mnemonic with the same RequiredOptions
+	// but different operand forms side by side, as in mov r1, r2 and mov r1, 10, instead of
+	// the later one replacing the earlier. Lines are then matched against every available
+	// form (see Parser.Ambiguities).
+	Overloads bool
+
+	// UnknownTokens decides what matching does with characters the tokenizer does not
+	// recognize, such as the $ in "mov r1 $ r2". Zero means UnknownLenient, so existing
+	// grammars keep matching.
+	UnknownTokens UnknownTokenMode
+
+	// BooleanKeywords maps the words read as TokenBoolean tokens, ignoring case, to their
+	// values, such as {"on": true, "off": false} for configuration languages. Nil means
+	// DefaultBooleanKeywords(), true and false; an empty map disables boolean tokens. A
+	// keyword is never an identifier token, so it cannot be a mnemonic or a label, but
+	// identifier slots accept it as the word it was written as. Parser.AddBooleanKeyword
+	// and the booleans of a grammar file add to the keywords.
+	BooleanKeywords map[string]bool
+
+	// NumberSuffixes maps the suffixes of scaled hex literals, ignoring case, to the value
+	// they multiply by, such as {"k": 0x400, "w": 2} for memory layouts, so that 10k is
+	// 0x4000 and 100w is 0x200. A suffixed literal is a sized integer token of its scaled
+	// value. Suffixes are letters, not all of them hex digits, and scale by a nonzero
+	// value; others are ignored. Parser.AddNumberSuffix and the number_suffixes of a
+	// grammar file add to the table.
+	NumberSuffixes map[string]uint64
+	// Numbers sets how integer literals are read, as ParseNumber reads them: the base of
+	// literals without a prefix, the base prefixes allowed, width suffixes and what happens
+	// to values too large for their width. The zero value reads hex literals whose digit
+	// count gives their width. Literals whose width comes from their value, such as
+	// decimal ones, fill any wider sized slot.
+	Numbers NumberOptions
+
+	// LineContinuation is the marker that, ending a line, joins the next line to it, such as
+	// a backslash, so that long statements can span several lines. ParseSource and the
+	// functions built on it remove the marker and any blanks after it and splice the lines
+	// before tokenizing; a marker in a comment does not count. A joined line takes the
+	// number of its first line and records every physical line in ParsedLine.Segments, and
+	// ParsedLine.SourcePosition maps its columns back to them. Empty, the default, disables
+	// continuation.
+	LineContinuation string
+
+	// Normalize transforms every line before it is tokenized, folding its case, collapsing
+	// its blanks or expanding its tabs, while error columns, warnings and the ObjectText of
+	// objects keep referring to the line as written (see Normalizer). The tokenizer's own
+	// lowercasing, controlled by PreserveCase, still applies afterwards.
+	Normalize Normalizer
+
+	// Separators sets which blanks separate tokens, whether commas may be left out and
+	// whether they may touch the tokens around them. The zero value accepts any blanks and
+	// requires the commas of the templates.
+	Separators Separators
+
+	// customTokens holds the token types added with Parser.AddTokenType.
+	customTokens []CustomTokenType
+}
+
+// UnknownTokenMode
+// selects how unrecognized characters are treated when a line is matched.
+type UnknownTokenMode int
+
+const (
+	UnknownDefault UnknownTokenMode = iota // Same as UnknownLenient
+	UnknownLenient                         // Skip unrecognized characters like whitespace
+	UnknownStrict                          // Fail the line at the first unrecognized character
+)
+
+// strictUnknown
+// reports whether unrecognized characters fail the line.
+func (config ParserConfig) strictUnknown() bool {
+	return config.UnknownTokens == UnknownStrict
+}
+
+// strayToken
+// returns the index of the first unrecognized, non-blank token, or -1 if there is none.
+func strayToken(tokens []Token) int {
+	for idx, token := range tokens {
+		if token.Type == TokenUnknown && !isBlankToken(token) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// strayError
+// formats the error reported for an unrecognized token at a 1-based column.
+func strayError(token Token, column int) string {
+	return fmt.Sprintf("Unexpected %q at column %d", strings.TrimSpace(token.ValueReceived), column)
+}
+
+// DefaultParserConfig
+// returns the configuration used by the package-level functions such as ParseLine.
+func DefaultParserConfig() ParserConfig {
+	return ParserConfig{}
+}
+
+// TokenizeLineWithConfig
+// strips the comment from a line of text and tokenizes what remains, after normalizing it
+// as the configuration's Normalize does. Unless the configuration preserves case, every
+// token except quoted strings and character literals is lowercased.
+func TokenizeLineWithConfig(txt string, config ParserConfig) []Token {
+	return tokenizeLine(nil, txt, newLexer(config), config)
+}
+
+// tokenizeLine
+// implements TokenizeLineWithConfig with an already built tokenizer, storing the tokens
+// in dst as scanInto does.
+func tokenizeLine(dst []Token, txt string, lx *lexer, config ParserConfig) []Token {
+	norm := config.Normalize.Normalize(txt)
+	code := EatComments(norm.Text)
+	tokens := mergeLabelDef(code, lx.scanInto(dst, code))
+	if !config.PreserveCase {
+		for idx := range tokens {
+			if tokens[idx].Type != TokenQuotedString && tokens[idx].Type != TokenChar {
+				if lowered := lx.lower(tokens[idx].ValueReceived); lowered != tokens[idx].ValueReceived {
+					tokens[idx].raw, tokens[idx].ValueReceived = tokens[idx].ValueReceived, lowered
+				}
+			}
+		}
+	}
+	norm.restoreText(tokens)
+	return tokens
+}
+
+// lowerInPlace
+// lowercases text without changing its length in bytes, so error columns computed from
+// token lengths still point into the line: invalid UTF-8 and characters whose lowercase
+// form has a different encoded length are kept as they are.
+func lowerInPlace(text string) string {
+	if isASCII(text) {
+		return strings.ToLower(text)
+	}
+	var sb strings.Builder
+	sb.Grow(len(text))
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		if l := unicode.ToLower(r); r != utf8.RuneError && utf8.RuneLen(l) == size {
+			sb.WriteRune(l)
+		} else {
+			sb.WriteString(text[:size])
+		}
+		text = text[size:]
+	}
+	return sb.String()
+}
+
+// isASCII
+// reports whether text only holds ASCII characters.
+func isASCII(text string) bool {
+	for idx := 0; idx < len(text); idx++ {
+		if text[idx] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLineWithConfig
+// parses a line of text using the given configuration and matches it against a list of template objects.
+func ParseLineWithConfig(txt string, templateList []TemplateObject, config ParserConfig) ([]ObjectType, bool, string) {
+	return MatchTokensWithConfig(TokenizeLineWithConfig(txt, config), templateList, config)
+}
+
+// tokenRanks
+// returns the tie-break rank of each token class, from TokenPrecedence and then the
+// default order.
+func (config ParserConfig) tokenRanks() map[int]int {
+	order := append([]int(nil), config.TokenPrecedence...)
+	for _, custom := range config.customTokens {
+		order = append(order, custom.Id)
+	}
+	order = append(order, TokenOperator, TokenRegister, TokenBoolean, TokenIdentifier, TokenUint8)
+	ranks := make(map[int]int, len(order))
+	for _, tokenType := range order {
+		if isIntegerToken(tokenType) {
+			tokenType = TokenUint8
+		}
+		if _, found := ranks[tokenType]; !found {
+			ranks[tokenType] = len(ranks)
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,21 @@
+package TemplateParser
+
+import "testing"
+
+func TestUnknownTokenModes(t *testing.T) {
+	for _, tt := range []struct {
+		mode UnknownTokenMode
+		ok   bool
+	}{
+		{UnknownDefault, true},
+		{UnknownLenient, true},
+		{UnknownStrict, false},
+	} {
+		config := DefaultParserConfig()
+		config.UnknownTokens = tt.mode
+		p := newTestParser(t, config, exprGrammar)
+		if _, ok, errmsg := p.ParseLine("li r1 $, 10"); ok != tt.ok {
+			t.Errorf("mode %d: ok = %v, %q, want %v", tt.mode, ok, errmsg, tt.ok)
+		}
+	}
+}
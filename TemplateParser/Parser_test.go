@@ -0,0 +1,44 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParserSharedAcrossGoroutines(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	const workers = 8
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for n := 1; n <= workers; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			source := fmt.Sprintf("foo equ %x\nli r1, foo\n", n)
+			for range 50 {
+				result, err := p.ParseSource(strings.NewReader(source))
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, lr := range result.Lines {
+					if !lr.Ok {
+						errs <- fmt.Errorf("worker %d line %d: %s", n, lr.LineNumber, lr.Error)
+						return
+					}
+				}
+				if got := result.Lines[1].Objects[3].ObjectValue; got != uint64(n) {
+					errs <- fmt.Errorf("worker %d: foo = %v", n, got)
+					return
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,94 @@
+package TemplateParser
+
+import "io"
+
+// PassHook
+// is called for every line of an assembly pass, in source order, with the context of the
+// call located at the line. An error marks the line as failed with the error's text.
+type PassHook func(ctx *ParseContext, lr *LineResult) error
+
+// AssemblyOptions
+// configures Assemble. Pass1 is called once every line has been matched and located: its
+// address, size and label are known and ctx.Symbols holds every label of the source, but
+// label operands are not resolved yet. It must not change sizes. Pass2 is called once the
+// label operands of the line are resolved, the actions have run and, with Encode set, the
+// line has been encoded. Context, if set, is the context the source is parsed in; its File
+// names the source.
+type AssemblyOptions struct {
+	Encode  bool
+	Pass1   PassHook
+	Pass2   PassHook
+	Context *ParseContext
+}
+
+// Assembly
+// is the result of Assemble: the parsed source and, with AssemblyOptions.Encode, the
+// encoding of each of its lines. Code lines up with Lines and is nil for lines that have
+// no encoding or failed.
+type Assembly struct {
+	*SourceResult
+	Code [][]byte
+}
+
+// Assemble
+// parses a source in two passes, as ParseSource does, calling hooks after each pass so
+// tools collecting labels, constants and sizes in the first pass and emitting code in the
+// second do not have to run the parser twice. The second pass can also encode the lines
+// of entries with an Encoding, failing lines that do not encode. The returned error is
+// the one ParseSource would return.
+func (p *Parser) Assemble(r io.Reader, opts AssemblyOptions) (*Assembly, error) {
+	defer p.span("Assemble", nil).End()
+	call := p.newContext(opts.Context)
+	parsed, err := p.matchSource(call, r, call.File)
+
+	pass := p.span("pass1", nil)
+	result := p.locateSource(parsed)
+	call.Symbols = result.Symbols
+	runPassHook(opts.Pass1, call, result.Lines)
+	pass.End()
+
+	pass = p.span("pass2", nil)
+	p.resolveSource(result, call)
+	assembly := &Assembly{SourceResult: result, Code: make([][]byte, len(result.Lines))}
+	if opts.Encode {
+		for idx := range result.Lines {
+			assembly.Code[idx] = p.encodeLine(&result.Lines[idx])
+		}
+	}
+	runPassHook(opts.Pass2, call, result.Lines)
+	pass.End()
+
+	if opts.Context != nil {
+		opts.Context.Symbols = call.Symbols
+	}
+	return assembly, call.budget.firstError(err)
+}
+
+// runPassHook
+// calls a hook for every line, marking lines it fails.
+func runPassHook(hook PassHook, ctx *ParseContext, lines []LineResult) {
+	if hook == nil {
+		return
+	}
+	for idx := range lines {
+		lr := &lines[idx]
+		if err := hook(ctx.at(lr.File, lr.LineNumber), lr); err != nil {
+			lr.Ok, lr.Error = false, err.Error()
+		}
+	}
+}
+
+// encodeLine
+// encodes a successfully matched instruction line whose entry has an encoding, marking the
+// line as failed if it does not encode. It returns nil for other lines.
+func (p *Parser) encodeLine(lr *LineResult) []byte {
+	if !lr.Ok || lr.Mnemonic == "" || lr.entry == nil || lr.entry.Encoding == nil {
+		return nil
+	}
+	data, ok, errmsg := p.EncodeBytes(lr.ParsedLine)
+	if !ok {
+		lr.Ok, lr.Error = false, errmsg
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,76 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	var order []string
+	opts := AssemblyOptions{
+		Encode: true,
+		Pass1: func(ctx *ParseContext, lr *LineResult) error {
+			if sym, found := ctx.Symbols.Lookup("next"); !found || sym.Address != 4 {
+				t.Errorf("pass 1 of line %d: next = %+v, %v, want every label defined", lr.LineNumber, sym, found)
+			}
+			order = append(order, "1:"+lr.RawText)
+			return nil
+		},
+		Pass2: func(ctx *ParseContext, lr *LineResult) error {
+			if ctx.Line != lr.LineNumber {
+				t.Errorf("pass 2 context is at line %d, want %d", ctx.Line, lr.LineNumber)
+			}
+			if lr.Mnemonic == "br" {
+				if distance, _ := lr.Objects[1].ObjectValue.(int64); distance != 2 {
+					t.Errorf("pass 2 of %q: distance = %v, want the resolved label", lr.RawText, lr.Objects[1].ObjectValue)
+				}
+			}
+			order = append(order, "2:"+lr.RawText)
+			return nil
+		},
+	}
+	assembly, err := p.Assemble(strings.NewReader("ldi r3, 7f\nbr next\nnext: nop\n"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1:ldi r3, 7f", "1:br next", "1:next: nop", "2:ldi r3, 7f", "2:br next", "2:next: nop"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("hooks ran in order %q, want %q", order, want)
+	}
+	code := [][]byte{{0x13, 0x7f}, {0x02, 0x20}, nil}
+	for idx, data := range code {
+		if !bytes.Equal(assembly.Code[idx], data) {
+			t.Errorf("Code[%d] = % x, want % x", idx, assembly.Code[idx], data)
+		}
+	}
+}
+
+func TestAssembleFailsLines(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	opts := AssemblyOptions{
+		Encode: true,
+		Pass2: func(ctx *ParseContext, lr *LineResult) error {
+			if lr.Mnemonic == "nop" {
+				return errors.New("nop is not allowed")
+			}
+			return nil
+		},
+	}
+	assembly, err := p.Assemble(strings.NewReader("ldi r10, 1\nnop\nldi r1, 2\n"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := []string{"Operand 1 value 0x10 does not fit in 4 bits", "nop is not allowed", ""}
+	for idx, want := range errs {
+		if lr := assembly.Lines[idx]; lr.Ok != (want == "") || lr.Error != want {
+			t.Errorf("line %d = %v, %q, want %q", idx+1, lr.Ok, lr.Error, want)
+		}
+	}
+	if assembly.Code[0] != nil || assembly.Code[2] == nil {
+		t.Errorf("Code = % x, want code only for the last line", assembly.Code)
+	}
+}
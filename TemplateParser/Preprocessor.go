@@ -0,0 +1,39 @@
+package TemplateParser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// EnvFunc
+// returns a preprocessor function for @env("NAME") that expands to the value of an
+// environment variable as a quoted string. Only the listed variables can be read; any
+// other name is an error, so sources cannot probe the environment.
+func EnvFunc(allowed ...string) PreprocessorFunc {
+	permitted := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		permitted[name] = true
+	}
+	return func(args []string) (string, error) {
+		if len(args) != 1 {
+			return "", fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		if !permitted[args[0]] {
+			return "", fmt.Errorf("environment variable %s is not allowed", args[0])
+		}
+		return QuoteString(os.Getenv(args[0])), nil
+	}
+}
+
+// HashFunc
+// is a preprocessor function for @hash("text") that expands to the SHA-256 digest of its
+// argument as a quoted hex string.
+func HashFunc(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	sum := sha256.Sum256([]byte(args[0]))
+	return QuoteString(hex.EncodeToString(sum[:])), nil
+}
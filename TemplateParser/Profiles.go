@@ -0,0 +1,225 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ArchDirective is the name of the directive selecting the profile of the rest of a
+// file: .arch <profile>
+const ArchDirective = "arch"
+
+// Profile
+// is a named set of templates, such as one revision of an instruction set. Lines matched
+// in a profile see the entries registered for it, those of the profile it extends, and so
+// on up the chain, and the base entries registered without a profile. An entry of a
+// profile overrides the entries further up the chain it could replace on registration:
+// those of the same mnemonic and required options and, when the configuration allows
+// overloads, the same operand form.
+type Profile struct {
+	Name    string `json:"name" yaml:"name"`
+	Extends string `json:"extends,omitempty" yaml:"extends,omitempty"`
+}
+
+// DefineProfile
+// defines a profile, extending another one unless extends is "". The extended profile
+// must already be defined, so chains of profiles cannot loop.
+func (p *Parser) DefineProfile(name string, extends string) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return false, errFrozen
+	}
+	if name == "" {
+		return false, "Profile name is empty"
+	}
+	if _, found := p.profiles[name]; found {
+		return false, fmt.Sprintf("Profile %s is already defined", name)
+	}
+	if _, found := p.profiles[extends]; extends != "" && !found {
+		return false, fmt.Sprintf("Profile %s extends unknown profile %s", name, extends)
+	}
+	if p.profiles == nil {
+		p.profiles = make(map[string]Profile)
+	}
+	p.profiles[name] = Profile{name, extends}
+	p.profileOrder = append(p.profileOrder, name)
+	return true, ""
+}
+
+// Profiles
+// returns the defined profiles in definition order.
+func (p *Parser) Profiles() []Profile {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.definedProfiles()
+}
+
+// definedProfiles
+// implements Profiles.
+func (p *Parser) definedProfiles() []Profile {
+	profiles := make([]Profile, len(p.profileOrder))
+	for idx, name := range p.profileOrder {
+		profiles[idx] = p.profiles[name]
+	}
+	return profiles
+}
+
+// SetProfile
+// selects the profile lines are matched in when neither their ParseContext nor an .arch
+// directive names one; "" selects the base entries alone.
+func (p *Parser) SetProfile(name string) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, found := p.profiles[name]; name != "" && !found {
+		return false, fmt.Sprintf("Unknown profile %s", name)
+	}
+	p.profile = name
+	return true, ""
+}
+
+// Profile
+// returns the profile selected with SetProfile.
+func (p *Parser) Profile() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.profile
+}
+
+// lineProfile
+// returns the profile a line parsed in ctx is matched in.
+func (p *Parser) lineProfile(ctx *ParseContext) string {
+	if ctx != nil && ctx.Profile != "" {
+		return ctx.Profile
+	}
+	return p.profile
+}
+
+// profileRank
+// returns the position of the profile an entry belongs to in the chain of a profile: 0 for
+// the profile itself, one more for every step up the chain and the length of the chain for
+// the base entries. It is -1 for entries of profiles outside the chain.
+func (p *Parser) profileRank(entryProfile string, profile string) int {
+	rank := 0
+	for name := profile; name != ""; name = p.profiles[name].Extends {
+		if name == entryProfile {
+			return rank
+		}
+		rank++
+	}
+	if entryProfile == "" {
+		return rank
+	}
+	return -1
+}
+
+// inProfile
+// reports whether an entry is seen by lines matched in a profile: it belongs to the
+// profile's chain and no entry nearer the profile overrides it. The message explains why
+// it is not.
+func (p *Parser) inProfile(entry *TemplateEntry, profile string) (bool, string) {
+	rank := p.profileRank(entry.Profile, profile)
+	if rank < 0 {
+		if profile == "" {
+			return false, fmt.Sprintf("%s is only available in profile %s", entry.Name, entry.Profile)
+		}
+		return false, fmt.Sprintf("%s is not available in profile %s", entry.Name, profile)
+	}
+	if rank == 0 {
+		return true, ""
+	}
+	for _, other := range p.registry[p.mnemonicKey(entry.Name)] {
+		if other == entry || !sameOptions(other.RequiredOptions, entry.RequiredOptions) ||
+			(p.config.Overloads && !sameForm(other.form(), entry.form())) {
+			continue
+		}
+		if otherRank := p.profileRank(other.Profile, profile); otherRank >= 0 && otherRank < rank {
+			return false, fmt.Sprintf("%s is overridden in profile %s", entry.Name, profile)
+		}
+	}
+	return true, ""
+}
+
+// compatibleProfiles
+// reports whether some profile sees entries of both profiles.
+func (p *Parser) compatibleProfiles(a string, b string) bool {
+	return p.profileRank(a, b) >= 0 || p.profileRank(b, a) >= 0
+}
+
+// isArchDirective
+// reports whether a tokenized line is an .arch directive, matching the name as mnemonics
+// are matched.
+func (p *Parser) isArchDirective(tokens []Token) bool {
+	var buf [2]Token
+	content := leadingContent(tokens, buf[:])
+	return len(content) >= 2 && content[0].Type == TokenUnknown && content[0].ValueReceived == "." &&
+		content[1].Type == TokenIdentifier && p.mnemonicKey(content[1].ValueReceived) == ArchDirective
+}
+
+// parseArch
+// parses the operand of an .arch directive, the text after the directive's name, which
+// may tokenize as several tokens, as v2 does. The result holds the profile name as its
+// only object.
+func (p *Parser) parseArch(lineNo int, tokens []Token) LineResult {
+	result := LineResult{ParsedLine: ParsedLine{LineNumber: lineNo, Directive: ArchDirective}}
+	var operand strings.Builder
+	seen := 0
+	for _, token := range tokens {
+		if seen < 2 {
+			if !isBlankToken(token) {
+				seen++
+			}
+			continue
+		}
+		operand.WriteString(token.ValueReceived)
+	}
+	text := strings.TrimSpace(operand.String())
+	if text == "" || strings.ContainsAny(text, " \t") {
+		result.Error = "Expected a profile name after .arch"
+		return result
+	}
+	name, found := p.profileNamed(text)
+	if !found {
+		result.Error = fmt.Sprintf("Unknown profile %s", text)
+		return result
+	}
+	result.Objects = []ObjectType{newObject(TokenIdentifier, name, "")}
+	result.Ok = true
+	return result
+}
+
+// profileNamed
+// returns the defined profile with a name, which the tokenizer may have lowercased, so a
+// name differing only in case from a defined one names it.
+func (p *Parser) profileNamed(name string) (string, bool) {
+	if _, found := p.profiles[name]; found {
+		return name, true
+	}
+	for _, defined := range p.profileOrder {
+		if strings.EqualFold(defined, name) {
+			return defined, true
+		}
+	}
+	return "", false
+}
+
+// archName
+// returns the profile selected by a source line if it is a valid .arch directive.
+func (p *Parser) archName(line string) (string, bool) {
+	code := strings.TrimSpace(EatComments(line))
+	if !strings.HasPrefix(code, ".") {
+		return "", false
+	}
+	tokens := p.TokenizeLine(code)
+	if !p.isArchDirective(tokens) {
+		return "", false
+	}
+	p.mu.RLock()
+	result := p.parseArch(0, tokens)
+	p.mu.RUnlock()
+	if !result.Ok {
+		return "", false
+	}
+	return result.Objects[0].ObjectValue.(string), true
+}
@@ -0,0 +1,27 @@
+package TemplateParser
+
+import "testing"
+
+func TestArchDirectiveCase(t *testing.T) {
+	preserve := DefaultParserConfig()
+	preserve.PreserveCase = true
+	for _, tt := range []struct {
+		name   string
+		config ParserConfig
+		line   string
+		arch   bool
+	}{
+		{"default", DefaultParserConfig(), ".ARCH base", true},
+		{"preserve case", preserve, ".arch base", true},
+		{"preserve case", preserve, ".ARCH base", false},
+	} {
+		p := newTestParser(t, tt.config, exprGrammar)
+		if ok, errmsg := p.DefineProfile("base", ""); !ok {
+			t.Fatal(errmsg)
+		}
+		lr := parseTestSource(t, p, tt.line+"\n").Lines[0]
+		if arch := lr.Ok && lr.Directive == ArchDirective; arch != tt.arch {
+			t.Errorf("%s: %q is .arch = %v, want %v (%s)", tt.name, tt.line, arch, tt.arch, lr.Error)
+		}
+	}
+}
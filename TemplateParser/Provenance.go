@@ -0,0 +1,100 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Provenance
+// records which part of a source line produced a range of encoded bytes: Length bytes at
+// Address came from template slot Slot of the line, or from the opcode bits of the
+// mnemonic when Slot is 0. Name is the Name of the slot, if it has one. A byte holding bits
+// of several fields has a record for each of them.
+type Provenance struct {
+	Address uint64 `json:"address"`
+	Length  int    `json:"length"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Slot    int    `json:"slot"`
+	Name    string `json:"name,omitempty"`
+}
+
+// DebugMap
+// maps the bytes of an encoded program back to the source lines and operands they were
+// produced from, for source-level debugging of assembled output. Entries are ordered by
+// address.
+type DebugMap struct {
+	Entries []Provenance `json:"entries"`
+}
+
+// EncodeWithProvenance
+// encodes a parsed line with EncodeBytes and also returns, for every field of the entry's
+// encoding, the bytes it occupies at the line's address.
+func (p *Parser) EncodeWithProvenance(pl ParsedLine) ([]byte, []Provenance, bool, string) {
+	data, ok, errmsg := p.EncodeBytes(pl)
+	if !ok {
+		return nil, nil, false, errmsg
+	}
+	entry, _, _ := p.encodingEntry(pl)
+	records := make([]Provenance, 0, len(entry.Encoding.Fields))
+	for _, field := range entry.Encoding.Fields {
+		first, last := field.Offset/8, (field.Offset+field.Width-1)/8
+		if entry.Encoding.BigEndian {
+			first, last = len(data)-1-last, len(data)-1-first
+		}
+		record := Provenance{Address: pl.Address + uint64(first), Length: last - first + 1,
+			File: pl.File, Line: pl.LineNumber, Slot: field.Slot}
+		if field.Slot < len(entry.Objects) {
+			record.Name = entry.Objects[field.Slot].Name
+		}
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(a, b int) bool { return records[a].Address < records[b].Address })
+	return data, records, true, ""
+}
+
+// BuildDebugMap
+// encodes every successfully matched instruction line of a source and collects the
+// provenance of their bytes. Lines without a mnemonic and lines matching entries without
+// an encoding are skipped; the first line that
+// fails to encode stops the map, and its error is returned with its file and line number.
+func (p *Parser) BuildDebugMap(lines []LineResult) (*DebugMap, bool, string) {
+	dm := &DebugMap{Entries: make([]Provenance, 0)}
+	for _, lr := range lines {
+		if !lr.Ok || lr.Mnemonic == "" || (lr.entry != nil && lr.entry.Encoding == nil) {
+			continue
+		}
+		_, records, ok, errmsg := p.EncodeWithProvenance(lr.ParsedLine)
+		if !ok {
+			return dm, false, fmt.Sprintf("%s: %s", ParseError{File: lr.File, Line: lr.LineNumber}.position(), errmsg)
+		}
+		dm.Entries = append(dm.Entries, records...)
+	}
+	sort.SliceStable(dm.Entries, func(a, b int) bool { return dm.Entries[a].Address < dm.Entries[b].Address })
+	return dm, true, ""
+}
+
+// Lookup
+// returns the records covering the byte at an address.
+func (dm *DebugMap) Lookup(address uint64) []Provenance {
+	var found []Provenance
+	for _, record := range dm.Entries {
+		if record.Address > address {
+			break
+		}
+		if address < record.Address+uint64(record.Length) {
+			found = append(found, record)
+		}
+	}
+	return found
+}
+
+// WriteJSON
+// writes the debug map as indented JSON.
+func (dm *DebugMap) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dm)
+}
@@ -0,0 +1,53 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBuildDebugMap(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	result := parseTestSource(t, p, "back: ldi r3, 7f\nbr back\nnop\n")
+	dm, ok, errmsg := p.BuildDebugMap(result.Lines)
+	if !ok {
+		t.Fatal(errmsg)
+	}
+	want := []Provenance{
+		{Address: 0, Length: 1, Line: 1, Slot: 0},
+		{Address: 0, Length: 1, Line: 1, Slot: 1},
+		{Address: 1, Length: 1, Line: 1, Slot: 3},
+		{Address: 2, Length: 1, Line: 2, Slot: 1},
+		{Address: 3, Length: 1, Line: 2, Slot: 0},
+	}
+	if !reflect.DeepEqual(dm.Entries, want) {
+		t.Errorf("entries = %+v, want %+v", dm.Entries, want)
+	}
+	if found := dm.Lookup(0); len(found) != 2 || found[0].Slot != 0 || found[1].Slot != 1 {
+		t.Errorf("Lookup(0) = %+v, want the opcode and register of ldi", found)
+	}
+	if found := dm.Lookup(4); len(found) != 0 {
+		t.Errorf("Lookup(4) = %+v, want nothing past the program", found)
+	}
+	var buf bytes.Buffer
+	if err := dm.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded DebugMap
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || !reflect.DeepEqual(decoded, *dm) {
+		t.Errorf("WriteJSON round trip = %+v, %v", decoded, err)
+	}
+}
+
+func TestBuildDebugMapStopsAtEncodingErrors(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), encodingGrammar)
+	result := parseTestSource(t, p, "ldi r3, 7f\nldi r10, 1\nbr 0\n")
+	dm, ok, errmsg := p.BuildDebugMap(result.Lines)
+	if ok || errmsg != "2: Operand 1 value 0x10 does not fit in 4 bits" {
+		t.Errorf("BuildDebugMap = %v, %q, want the error of line 2", ok, errmsg)
+	}
+	if len(dm.Entries) != 3 {
+		t.Errorf("entries = %+v, want those of line 1", dm.Entries)
+	}
+}
@@ -0,0 +1,80 @@
+package TemplateParser
+
+import (
+	"math/big"
+	"path"
+)
+
+// Where
+// returns the lines of a parsed source for which keep returns true, in source order.
+// Each line carries its LineNumber and Address, so the results can be reported directly.
+func (sr *SourceResult) Where(keep func(LineResult) bool) []LineResult {
+	lines := make([]LineResult, 0)
+	for _, line := range sr.Lines {
+		if keep(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// UsingRegister
+// returns the matched lines with register reg as an operand, or as the base or index of a
+// memory operand.
+func (sr *SourceResult) UsingRegister(reg uint64) []LineResult {
+	return sr.Where(func(line LineResult) bool {
+		for _, operand := range line.Operands {
+			if val, isInt := operand.Object.ObjectValue.(uint64); isInt &&
+				operand.Object.ObjectTypeId == TokenRegister && val == reg {
+				return true
+			}
+			if mem, isMemory := operand.Object.ObjectValue.(MemoryOperand); isMemory && mem.uses(reg) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WithMnemonic
+// returns the matched lines whose mnemonic matches a glob pattern such as "ld*" or
+// "b??", using the syntax of path.Match. A malformed pattern matches nothing.
+func (sr *SourceResult) WithMnemonic(pattern string) []LineResult {
+	return sr.Where(func(line LineResult) bool {
+		matched, err := path.Match(pattern, line.Mnemonic)
+		return line.Mnemonic != "" && err == nil && matched
+	})
+}
+
+// WithValueOver
+// returns the matched lines with an integer operand, other than a register number,
+// greater than limit. Big integer operands are included.
+func (sr *SourceResult) WithValueOver(limit uint64) []LineResult {
+	bigLimit := new(big.Int).SetUint64(limit)
+	return sr.Where(func(line LineResult) bool {
+		for _, operand := range line.Operands {
+			if operand.Object.ObjectTypeId == TokenRegister {
+				continue
+			}
+			switch val := operand.Object.ObjectValue.(type) {
+			case uint64:
+				if val > limit {
+					return true
+				}
+			case *big.Int:
+				if val.Cmp(bigLimit) > 0 {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// Failed
+// returns the lines that did not parse.
+func (sr *SourceResult) Failed() []LineResult {
+	return sr.Where(func(line LineResult) bool {
+		return !line.Ok
+	})
+}
@@ -0,0 +1,344 @@
+package TemplateParser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DefaultREPLPrompt is the prompt REPL.Run writes when REPL.Prompt is "".
+const DefaultREPLPrompt = "> "
+
+// REPLCommand
+// runs a meta-command of a REPL, writing its output to w. args is the text after the
+// command's name, with surrounding blanks removed.
+type REPLCommand func(r *REPL, w io.Writer, args string) error
+
+// replCommand
+// is a meta-command registered on a REPL.
+type replCommand struct {
+	help string
+	run  REPLCommand
+}
+
+// REPL
+// is an interactive session with a Parser, for shells over an instruction DSL. It parses
+// one line at a time as the next line of one growing source: labels are defined in the
+// session's symbol table at the location counter, which advances over every matched line
+// and moves with org directives, label operands resolve to the labels defined so far,
+// .arch selects the profile of the lines that follow, and actions run as each line
+// matches. The session starts with a copy of the parser's macros, so macros defined
+// with .macro do not reach the parser. Every line entered is kept in the history.
+//
+// Lines starting with a meta-command are run by it instead of being parsed: .tokens,
+// .templates, .symbols, .macro, .macros, .history, .help and .quit are built in, and
+// AddCommand registers others. Directives the parser knows, such as .org, are parsed as
+// usual. Prompt is written before every line Run reads; "" means DefaultREPLPrompt. A REPL
+// must not be used from several goroutines at once.
+type REPL struct {
+	parser   *Parser
+	ctx      *ParseContext
+	address  uint64
+	history  []string
+	commands map[string]replCommand
+	quit     bool
+	Prompt   string
+}
+
+// NewREPL
+// creates a REPL session using parser, with an empty symbol table and history.
+func NewREPL(parser *Parser) *REPL {
+	macros := NewMacroTable()
+	if mt := parser.Macros(); mt != nil {
+		mt.mu.RLock()
+		for name, macro := range mt.macros {
+			macros.macros[name] = macro
+		}
+		macros.MaxDepth = mt.MaxDepth
+		mt.mu.RUnlock()
+	}
+	r := &REPL{
+		parser:   parser,
+		ctx:      &ParseContext{Symbols: NewSymbolTable(), Macros: macros, constants: newConstantScope(parser.constants)},
+		commands: make(map[string]replCommand),
+	}
+	r.AddCommand("tokens", "show the tokens of a line, or of the last line entered", replTokens)
+	r.AddCommand("templates", "list the registered templates", replTemplates)
+	r.AddCommand("symbols", "list the labels defined so far", replSymbols)
+	r.AddCommand("macro", "define a macro: .macro name body", replMacro)
+	r.AddCommand("macros", "list the session's macros", replMacros)
+	r.AddCommand("history", "list the lines entered", replHistory)
+	r.AddCommand("help", "list the meta-commands", replHelp)
+	r.AddCommand("quit", "end the session", func(r *REPL, w io.Writer, args string) error {
+		r.quit = true
+		return nil
+	})
+	return r
+}
+
+// Parser
+// returns the session's parser.
+func (r *REPL) Parser() *Parser {
+	return r.parser
+}
+
+// Context
+// returns the context lines are parsed in, holding the session's symbols and macros.
+// Changes to it, such as setting Data for context actions, apply to the lines that follow.
+func (r *REPL) Context() *ParseContext {
+	return r.ctx
+}
+
+// Symbols
+// returns the session's symbol table.
+func (r *REPL) Symbols() *SymbolTable {
+	return r.ctx.Symbols
+}
+
+// Macros
+// returns the session's macro table.
+func (r *REPL) Macros() *MacroTable {
+	return r.ctx.Macros
+}
+
+// History
+// returns the lines entered so far, meta-commands included, oldest first.
+func (r *REPL) History() []string {
+	return append([]string(nil), r.history...)
+}
+
+// Address
+// returns the location counter, the address of the next line.
+func (r *REPL) Address() uint64 {
+	return r.address
+}
+
+// AddCommand
+// registers a meta-command run by lines starting with .name, replacing any command of the
+// same name. help is the one-line description .help lists.
+func (r *REPL) AddCommand(name string, help string, run REPLCommand) {
+	r.commands[strings.ToLower(strings.TrimPrefix(name, "."))] = replCommand{help, run}
+}
+
+// Parse
+// parses a line as the next line of the session, without the meta-commands, and returns
+// its results: one per line it expands to. The line is added to the history.
+func (r *REPL) Parse(line string) []LineResult {
+	r.history = append(r.history, line)
+	r.ctx.Line = len(r.history)
+	results := r.parser.parseInContext(line, r.ctx.unlimited(r.ctx.File, r.ctx.Line))
+	for idx := range results {
+		lr := &results[idx]
+		lr.File = r.ctx.File
+		if origin, isOrigin := originValue(*lr); isOrigin {
+			r.address = origin
+		}
+		lr.Address = r.address
+		if lr.Label != "" {
+			if ok, errmsg := r.ctx.Symbols.Define(lr.Label, r.address, lr.LineNumber); !ok && lr.Ok {
+				lr.Ok, lr.Error = false, errmsg
+			}
+		}
+		ResolveSymbols(lr, r.ctx.Symbols)
+		runAction(lr, r.ctx)
+		if lr.Ok && lr.Directive == ArchDirective {
+			r.ctx.Profile = lr.Objects[0].ObjectValue.(string)
+		}
+		r.address += lineSize(*lr)
+	}
+	return results
+}
+
+// Exec
+// runs a line entered in the session, writing what it produces to w: the output of a
+// meta-command, or the results of a parsed line, each matched instruction as its
+// address, mnemonic and operands, and a diagnostic for every error and warning. The
+// error is that of the meta-command or of writing to w.
+func (r *REPL) Exec(w io.Writer, line string) error {
+	if name, args, found := r.command(line); found {
+		r.history = append(r.history, line)
+		return r.commands[name].run(r, w, args)
+	}
+	for _, lr := range r.Parse(line) {
+		if err := writeREPLResult(w, lr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// command
+// splits a line starting with a registered meta-command into its name and arguments.
+func (r *REPL) command(line string) (string, string, bool) {
+	text := strings.TrimSpace(line)
+	if !strings.HasPrefix(text, ".") {
+		return "", "", false
+	}
+	name, args, _ := strings.Cut(text[1:], " ")
+	name = strings.ToLower(name)
+	if _, known := r.commands[name]; !known {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(args), true
+}
+
+// Run
+// reads lines from in until it ends or .quit is entered, running each with Exec and
+// writing the prompt before it and the output after it to out. Errors of meta-commands
+// are written to out and the session goes on; Run stops at the first error reading in
+// or writing out.
+func (r *REPL) Run(in io.Reader, out io.Writer) error {
+	prompt := r.Prompt
+	if prompt == "" {
+		prompt = DefaultREPLPrompt
+	}
+	scanner := bufio.NewScanner(in)
+	r.quit = false
+	for !r.quit {
+		if _, err := io.WriteString(out, prompt); err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		if err := r.Exec(out, scanner.Text()); err != nil {
+			if _, werr := fmt.Fprintf(out, "error: %v\n", err); werr != nil {
+				return werr
+			}
+		}
+	}
+	return nil
+}
+
+// writeREPLResult
+// writes the result of a line parsed by Exec.
+func writeREPLResult(w io.Writer, lr LineResult) error {
+	if !lr.Ok {
+		_, err := io.WriteString(w, NewDiagnostic(lr).String())
+		return err
+	}
+	if lr.Mnemonic != "" && lr.Directive == "" {
+		operands := make([]string, len(lr.Operands))
+		for idx, operand := range lr.Operands {
+			operands[idx] = operand.Object.DisplayValue()
+		}
+		if _, err := fmt.Fprintf(w, "%04x %-6s %s\n", lr.Address, lr.Mnemonic, strings.Join(operands, ", ")); err != nil {
+			return err
+		}
+	}
+	for _, warning := range lr.Warnings {
+		if _, err := io.WriteString(w, newWarningDiagnostic(lr, warning).String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replTokens
+// implements .tokens, writing each token of the line as Type(text).
+func replTokens(r *REPL, w io.Writer, args string) error {
+	line := args
+	for idx := len(r.history) - 2; line == "" && idx >= 0; idx-- {
+		if _, _, isCommand := r.command(r.history[idx]); !isCommand {
+			line = r.history[idx]
+		}
+	}
+	_, err := fmt.Fprintln(w, r.parser.Config().tokenSummary(r.parser.TokenizeLine(line), nil))
+	return err
+}
+
+// replTemplates
+// implements .templates, writing the mnemonic and form of every registered entry, with its
+// profile.
+func replTemplates(r *REPL, w io.Writer, args string) error {
+	config := r.parser.Config()
+	for _, entry := range r.parser.Entries() {
+		line := fmt.Sprintf("%-8s %s", entry.Name, config.templateSpec(entry.form()))
+		if entry.Profile != "" {
+			line += "    [" + entry.Profile + "]"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replSymbols
+// implements .symbols, writing every label with its address.
+func replSymbols(r *REPL, w io.Writer, args string) error {
+	for _, sym := range r.ctx.Symbols.Symbols() {
+		if _, err := fmt.Fprintf(w, "%-16s %04x\n", sym.Name, sym.Address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replMacro
+// implements .macro, defining a macro in the session. \n in the body separates the lines
+// it expands to.
+func replMacro(r *REPL, w io.Writer, args string) error {
+	name, body, _ := strings.Cut(args, " ")
+	if ok, errmsg := r.ctx.Macros.DefineAt(name, strings.ReplaceAll(strings.TrimSpace(body), `\n`, "\n"),
+		r.ctx.File, len(r.history)); !ok {
+		return fmt.Errorf("%s", errmsg)
+	}
+	return nil
+}
+
+// replMacros
+// implements .macros, writing every macro of the session with its body.
+func replMacros(r *REPL, w io.Writer, args string) error {
+	mt := r.ctx.Macros
+	mt.mu.RLock()
+	names := make([]string, 0, len(mt.macros))
+	for name := range mt.macros {
+		names = append(names, name)
+	}
+	macros := make([]Macro, len(names))
+	sort.Strings(names)
+	for idx, name := range names {
+		macros[idx] = mt.macros[name]
+	}
+	mt.mu.RUnlock()
+	for _, macro := range macros {
+		body := strings.ReplaceAll(macro.Body, "\n", `\n`)
+		if macro.Func != nil {
+			body = "(function)"
+		}
+		if _, err := fmt.Fprintf(w, "@%-15s %s\n", macro.Name, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replHistory
+// implements .history, writing the lines entered before it, numbered from 1.
+func replHistory(r *REPL, w io.Writer, args string) error {
+	for idx, line := range r.history[:len(r.history)-1] {
+		if _, err := fmt.Fprintf(w, "%4d  %s\n", idx+1, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replHelp
+// implements .help, listing the meta-commands by name.
+func replHelp(r *REPL, w io.Writer, args string) error {
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, ".%-12s %s\n", name, r.commands[name].help); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestREPLRun(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	p.SetMacros(NewMacroTable())
+	r := NewREPL(p)
+	r.Prompt = "$ "
+	var out bytes.Buffer
+	script := "loop: nop\njmp loop\njmp done\n.symbols\n.macro twice nop\\nnop\n@twice\n.quit\nnop\n"
+	if err := r.Run(strings.NewReader(script), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "$ 0000 nop    \n" +
+		"$ 0001 jmp    0x0\n" +
+		"$ 3: error: Unresolved symbol done\n" +
+		"$ loop             0000\n" +
+		"$ $ 0005 nop    \n0006 nop    \n" +
+		"$ "
+	if out.String() != want {
+		t.Errorf("Run wrote\n%q\nwant\n%q", out.String(), want)
+	}
+	if got := r.History(); len(got) != 7 || got[6] != ".quit" {
+		t.Errorf("History() = %q, want the lines up to .quit", got)
+	}
+	if r.Address() != 7 {
+		t.Errorf("Address() = %d, want 7", r.Address())
+	}
+	if _, found := p.Macros().Lookup("twice"); found {
+		t.Errorf("a macro defined in the session reached the parser")
+	}
+}
+
+func TestREPLCommands(t *testing.T) {
+	r := NewREPL(newTestParser(t, DefaultParserConfig(), labelGrammar))
+	exec := func(line string) string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := r.Exec(&out, line); err != nil {
+			t.Fatalf("Exec(%q): %v", line, err)
+		}
+		return out.String()
+	}
+	exec("jmp 0800")
+	if got := exec(".tokens"); !strings.HasPrefix(got, "Identifier(jmp)") {
+		t.Errorf(".tokens = %q, want the tokens of the last line", got)
+	}
+	if got := exec(".templates"); got != "jmp      <Identifier> <LabelRef>\nnop      <Identifier>\n" {
+		t.Errorf(".templates = %q", got)
+	}
+	if got := exec(".history"); got != "   1  jmp 0800\n   2  .tokens\n   3  .templates\n" {
+		t.Errorf(".history = %q", got)
+	}
+	if got := exec(".help"); !strings.Contains(got, ".quit         end the session\n") {
+		t.Errorf(".help = %q", got)
+	}
+	if got := exec(".nothing"); !strings.Contains(got, "Unknown mnemonic nothing") {
+		t.Errorf("an unknown meta-command was not parsed as a line: %q", got)
+	}
+	var out bytes.Buffer
+	if err := r.Exec(&out, ".macro"); err == nil {
+		t.Errorf(".macro without a name succeeded")
+	}
+}
+
+func TestREPLAddCommand(t *testing.T) {
+	r := NewREPL(newTestParser(t, DefaultParserConfig(), labelGrammar))
+	var calls []string
+	r.AddCommand(".Echo", "echo the arguments", func(r *REPL, w io.Writer, args string) error {
+		calls = append(calls, args)
+		_, err := fmt.Fprintln(w, args)
+		return err
+	})
+	var out bytes.Buffer
+	if err := r.Exec(&out, "  .echo   hello world  "); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello world\n" || !reflect.DeepEqual(calls, []string{"hello world"}) {
+		t.Errorf("custom command wrote %q with calls %q", out.String(), calls)
+	}
+}
@@ -0,0 +1,149 @@
+package TemplateParser
+
+import (
+	"sort"
+	"strings"
+)
+
+// sourceSpan
+// is the byte range [start, end) of a line's text.
+type sourceSpan struct {
+	start, end int
+}
+
+// sensitiveTexts
+// returns the source texts of a line's sensitive objects. hidden reports that none of the
+// line's source may be shown: it failed to match a template with sensitive slots, so which
+// of its tokens are sensitive is not known, or it holds a sensitive object without source
+// text. templateList gives the templates of a line matched without a template entry.
+func (lr LineResult) sensitiveTexts(templateList []TemplateObject) (texts []string, hidden bool) {
+	for _, obj := range lr.Objects {
+		if !obj.ObjectSensitive {
+			continue
+		}
+		if obj.ObjectText == "" {
+			return nil, true
+		}
+		texts = append(texts, obj.ObjectText)
+	}
+	if lr.entry != nil {
+		templateList = lr.entry.Objects
+	}
+	if !lr.Ok && hasSensitiveSlot(templateList) {
+		return nil, true
+	}
+	return texts, false
+}
+
+// hasSensitiveSlot
+// reports whether any of a list of templates is marked Sensitive.
+func hasSensitiveSlot(templateList []TemplateObject) bool {
+	for _, tmpl := range templateList {
+		if tmpl.Sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveSpans
+// returns the spans of a text holding one of texts as whole words, in order and without
+// overlaps.
+func sensitiveSpans(text string, texts []string) []sourceSpan {
+	var spans []sourceSpan
+	for _, secret := range texts {
+		for from := 0; from < len(text); {
+			idx := strings.Index(text[from:], secret)
+			if idx < 0 {
+				break
+			}
+			start, end := from+idx, from+idx+len(secret)
+			if wordBoundary(text, start) && wordBoundary(text, end) {
+				spans = append(spans, sourceSpan{start, end})
+			}
+			from = start + 1
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:0]
+	for _, span := range spans {
+		if last := len(merged) - 1; last >= 0 && span.start <= merged[last].end {
+			merged[last].end = max(merged[last].end, span.end)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// wordBoundary
+// reports whether offset idx of a text does not fall between two word characters.
+func wordBoundary(text string, idx int) bool {
+	return idx <= 0 || idx >= len(text) || !isWordByte(text[idx-1]) || !isWordByte(text[idx])
+}
+
+// maskSpans
+// replaces the spans of a text by RedactedValue.
+func maskSpans(text string, spans []sourceSpan) string {
+	if len(spans) == 0 {
+		return text
+	}
+	var sb strings.Builder
+	last := 0
+	for _, span := range spans {
+		sb.WriteString(text[last:span.start])
+		sb.WriteString(RedactedValue)
+		last = span.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// redactText
+// masks the sensitive operands of a list of lines in a text they were parsed from,
+// returning RedactedValue for a text none of which may be shown.
+func redactText(text string, results []LineResult, templateList []TemplateObject) string {
+	var texts []string
+	for _, lr := range results {
+		found, hidden := lr.sensitiveTexts(templateList)
+		if hidden {
+			return RedactedValue
+		}
+		texts = append(texts, found...)
+	}
+	return maskSpans(text, sensitiveSpans(text, texts))
+}
+
+// redactSource
+// masks the source text of the line's sensitive operands in RawText and Expanded, keeping
+// the unmasked text for locating columns, so that serialized results, listings and
+// diagnostics do not show them. templateList is used as for sensitiveTexts.
+func (lr *LineResult) redactSource(templateList []TemplateObject) {
+	lr.raw, lr.expanded = lr.RawText, lr.Expanded
+	lr.RawText = redactText(lr.RawText, []LineResult{*lr}, templateList)
+	if lr.Expanded != "" {
+		lr.Expanded = redactText(lr.Expanded, []LineResult{*lr}, templateList)
+	}
+}
+
+// rawText
+// returns RawText as it appeared in the source, before sensitive operands were masked.
+func (pl ParsedLine) rawText() string {
+	if pl.raw != "" {
+		return pl.raw
+	}
+	return pl.RawText
+}
+
+// columnText
+// returns the unmasked text the columns of the line count in: the expanded text if macro
+// expansion changed the line, otherwise its source text.
+func (pl ParsedLine) columnText() string {
+	if pl.expanded != "" {
+		return pl.expanded
+	}
+	if pl.Expanded != "" {
+		return pl.Expanded
+	}
+	return pl.rawText()
+}
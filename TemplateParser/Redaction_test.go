@@ -0,0 +1,42 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSensitiveSourceIsMasked(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), sensitiveGrammar)
+	result := parseTestSource(t, p, "key r1, 5eed ; load\nkey r1, 5eed, 1\n")
+	if !result.Lines[0].Ok || result.Lines[1].Ok {
+		t.Fatalf("lines parsed %v, %v, want true, false", result.Lines[0].Ok, result.Lines[1].Ok)
+	}
+	if got, want := result.Lines[0].RawText, "key r1, "+RedactedValue+" ; load"; got != want {
+		t.Errorf("RawText = %q, want %q", got, want)
+	}
+	if got := result.Lines[1].RawText; got != RedactedValue {
+		t.Errorf("RawText of the failed line = %q, want %q", got, RedactedValue)
+	}
+	data, err := json.Marshal(result.Lines)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var listing bytes.Buffer
+	if err := result.Emit(NewListingEmitter(&listing)); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	diagnostics, err := json.Marshal(result.Diagnostics())
+	if err != nil {
+		t.Fatalf("Marshal diagnostics: %v", err)
+	}
+	for name, text := range map[string]string{"JSON": string(data), "listing": listing.String(), "diagnostics": string(diagnostics)} {
+		if strings.Contains(text, "5eed") {
+			t.Errorf("%s shows a sensitive operand:\n%s", name, text)
+		}
+	}
+	if got := result.Diagnostics()[0].RuneColumn; got != 13 {
+		t.Errorf("RuneColumn = %d, want 13", got)
+	}
+}
@@ -0,0 +1,91 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// regexTokenizer is the tokenizer the lexer replaced: an ordered table of regular
+// expressions tried at every position, the first match winning. It is kept as the
+// reference the lexer is compared against and benchmarked with.
+type regexTokenizer []tokenPattern
+
+// regexLeadingPatterns come before the numeric patterns and regexNumericPatterns after
+// the optional BigInt pattern.
+var (
+	regexLeadingPatterns = []tokenPattern{
+		{regexp.MustCompile(`^"((?:[^"\\]|\\.)*)"`), TokenQuotedString},
+		{regexp.MustCompile(`^,`), TokenComma},
+		{regexp.MustCompile(`^:`), TokenColon},
+		{regexp.MustCompile(`^\[`), TokenLBracket},
+		{regexp.MustCompile(`^\]`), TokenRBracket},
+		{regexp.MustCompile(`^\(`), TokenLParen},
+		{regexp.MustCompile(`^\)`), TokenRParen},
+		{regexp.MustCompile(`^\+`), TokenPlus},
+		{regexp.MustCompile(`^-`), TokenMinus},
+		{regexp.MustCompile(`^@[a-zA-Z][a-zA-Z0-9_]*`), TokenMacro},
+		{regexp.MustCompile(`^[a-zA-Z][a-zA-Z][a-zA-Z0-9_]*`), TokenIdentifier},
+	}
+	regexNumericPatterns = []tokenPattern{
+		{regexp.MustCompile(`^[0-9a-fA-F]{9,16}`), TokenUint64},
+		{regexp.MustCompile(`^[0-9a-fA-F]{5,8}`), TokenUint32},
+		{regexp.MustCompile(`^[0-9a-fA-F]{3,4}`), TokenUint16},
+		{regexp.MustCompile(`^[0-9a-fA-F]{1,2}`), TokenUint8},
+	}
+)
+
+// affixAlternation
+// builds a case-insensitive regexp alternation of the keys of a width map.
+func affixAlternation(widths map[string]int) string {
+	keys := sortedKeys(widths)
+	for idx, key := range keys {
+		keys[idx] = regexp.QuoteMeta(key)
+	}
+	return "(?i:" + strings.Join(keys, "|") + ")"
+}
+
+// newRegexTokenizer
+// builds the pattern table for the given configuration, which may set only the register
+// prefixes and suffixes, MaxNumericDigits and custom token types.
+func newRegexTokenizer(config ParserConfig) regexTokenizer {
+	patterns := config.customPatterns()
+	if len(config.RegisterPrefixes) > 0 {
+		patterns = append(patterns, tokenPattern{regexp.MustCompile(
+			`^` + affixAlternation(config.RegisterPrefixes) + `[0-9][0-9a-fA-F]*\b`), TokenRegister})
+	}
+	patterns = append(patterns, regexLeadingPatterns...)
+	if config.MaxNumericDigits > 16 {
+		patterns = append(patterns, tokenPattern{
+			regexp.MustCompile(fmt.Sprintf(`^[0-9a-fA-F]{17,%d}`, config.MaxNumericDigits)), TokenBigInt})
+	}
+	patterns = append(patterns, regexNumericPatterns...)
+	register := `^[rR][0-9a-fA-F]*`
+	if len(config.RegisterSuffixes) > 0 {
+		register += affixAlternation(config.RegisterSuffixes) + `?`
+	}
+	return append(patterns, tokenPattern{regexp.MustCompile(register), TokenRegister})
+}
+
+// scan
+// repeatedly matches the pattern table against the start of the remaining input.
+func (patterns regexTokenizer) scan(input string) []Token {
+	tokens := []Token{}
+	for offset := 0; offset < len(input); {
+		remaining := input[offset:]
+		found := false
+		for _, pattern := range patterns {
+			if loc := pattern.regex.FindStringIndex(remaining); loc != nil && loc[1] > 0 {
+				tokens = append(tokens, Token{Type: pattern.tokenType, ValueReceived: remaining[:loc[1]]})
+				offset += loc[1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			tokens = append(tokens, Token{Type: TokenUnknown, ValueReceived: remaining[:1]})
+			offset++
+		}
+	}
+	return tokens
+}
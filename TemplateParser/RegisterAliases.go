@@ -0,0 +1,61 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AddRegisterAlias
+// makes the parser accept name as a register operand standing for register num, e.g.
+// AddRegisterAlias("sp", 0xd). The name must look like an identifier.
+func (p *Parser) AddRegisterAlias(name string, num uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.frozen != "" {
+		return fmt.Errorf("cannot add register alias %s: %s", name, errFrozen)
+	}
+	if name == "" || !isLetter(name[0]) || wordLength(name, 1) != len(name) {
+		return fmt.Errorf("register alias %q is not a valid name", name)
+	}
+	aliases := make(map[string]uint64, len(p.config.RegisterAliases)+1)
+	for alias, reg := range p.config.RegisterAliases {
+		if !strings.EqualFold(alias, name) {
+			aliases[alias] = reg
+		}
+	}
+	aliases[name] = num
+	p.config.RegisterAliases = aliases
+	p.lexer = newLexer(p.config)
+	return nil
+}
+
+// RegisterAlias
+// returns the register number an alias stands for.
+func (p *Parser) RegisterAlias(name string) (uint64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config.registerAlias(name)
+}
+
+// registerAlias
+// resolves a register alias ignoring case. The aliases are tried in sorted order, so a
+// configuration holding aliases that differ only in case, such as SP and sp, resolves
+// them the same way every time.
+func (config ParserConfig) registerAlias(name string) (uint64, bool) {
+	if len(config.RegisterAliases) == 0 {
+		return 0, false
+	}
+	aliases := make([]string, 0, len(config.RegisterAliases))
+	for alias := range config.RegisterAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if strings.EqualFold(alias, name) {
+			return config.RegisterAliases[alias], true
+		}
+	}
+	return 0, false
+}
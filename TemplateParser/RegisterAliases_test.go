@@ -0,0 +1,24 @@
+package TemplateParser
+
+import "testing"
+
+func TestRegisterAliasesDifferingInCase(t *testing.T) {
+	config := DefaultParserConfig()
+	config.RegisterAliases = map[string]uint64{"SP": 0xd, "sp": 0xe, "lr": 0xf}
+	p := newTestParser(t, config, exprGrammar)
+	for range 20 {
+		if num, found := p.RegisterAlias("Sp"); !found || num != 0xd {
+			t.Fatalf("RegisterAlias(Sp) = %#x, %v, want 0xd from SP", num, found)
+		}
+		line, ok, errmsg := p.ParseLine("li sp, 1")
+		if !ok {
+			t.Fatal(errmsg)
+		}
+		if got := line.Objects[1].ObjectValue; got != uint64(0xd) {
+			t.Fatalf("li sp, 1 register = %v, want 0xd", got)
+		}
+	}
+	if num, found := p.RegisterAlias("LR"); !found || num != 0xf {
+		t.Errorf("RegisterAlias(LR) = %#x, %v, want 0xf", num, found)
+	}
+}
@@ -0,0 +1,59 @@
+package TemplateParser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sortedKeys
+// returns the keys of a width map, longest first so the longest affix wins.
+func sortedKeys(widths map[string]int) []string {
+	if len(widths) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(widths))
+	for key := range widths {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// parseRegister
+// converts register text into its number and the width in bits implied by its prefix
+// or suffix, 0 if the name carries no width. Aliases carry no width.
+func (config ParserConfig) parseRegister(text string) (uint64, int, error) {
+	if num, found := config.registerAlias(text); found {
+		return num, 0, nil
+	}
+	lower := strings.ToLower(text)
+	width := 0
+	digits := ""
+	matched := false
+	for _, prefix := range sortedKeys(config.RegisterPrefixes) {
+		if strings.HasPrefix(lower, strings.ToLower(prefix)) {
+			width = config.RegisterPrefixes[prefix]
+			digits = lower[len(prefix):]
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		digits = lower[1:]
+		for _, suffix := range sortedKeys(config.RegisterSuffixes) {
+			if len(digits) > len(suffix) && strings.HasSuffix(digits, strings.ToLower(suffix)) {
+				width = config.RegisterSuffixes[suffix]
+				digits = digits[:len(digits)-len(suffix)]
+				break
+			}
+		}
+	}
+	val, err := strconv.ParseUint(digits, 16, 64)
+	return val, width, err
+}
@@ -0,0 +1,261 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TemplateEntry
+// is a template registered on a Parser under a mnemonic. Objects describes the whole
+// line, starting with the identifier slot for the mnemonic itself. RequiredOptions gates
+// the entry on the parser's target options: every listed option must have the given value
+// (see Parser.SetTargetOption). UnavailableError, if set, replaces the default message
+// reported when the mnemonic is used while the entry is gated off. Size is the encoded size
+// of the instruction in bytes and Cycles an optional cycle count; both are copied into the
+// results of matching lines and totalled by ParseSource. Guards are extra conditions on
+// the matched operands, checked after the slots themselves match. Action, if set, is called
+// with the objects of every line that matches the entry; an error from it marks the line as
+// failed with the error's text. Parse, ParseLine and ParseAll call it as each line
+// matches, with label operands still holding the label names; ParseSource and the
+// functions built on it call it once labels are resolved, in source order. ContextAction
+// is called the same way after Action, with the ParseContext of the call. Encoding, if
+// set, lays out the instruction word Encode builds from a matched line. Profile names the
+// profile (see Parser.DefineProfile) the entry belongs to; "" registers it as a base entry
+// shared by every profile. Order, if set, lets lines write the slots in another order than
+// Objects lists them, such as source before destination for an AT&T style syntax: Order[i]
+// is the slot written i-th, starting with the mnemonic's slot 0. Results always hold the
+// objects in the order of Objects, so guards, actions and encodings see the same slots
+// whichever order the syntax uses.
+type TemplateEntry struct {
+	Name             string
+	Objects          []TemplateObject
+	Profile          string
+	RequiredOptions  map[string]string
+	UnavailableError string
+	Size             uint64
+	Cycles           int
+	Guards           []Guard
+	Action           func([]ObjectType) error
+	ContextAction    func(*ParseContext, []ObjectType) error
+	Encoding         *Encoding
+	Order            []int
+
+	written []TemplateObject // Objects in the order of Order, nil without one
+}
+
+// RegisterTemplate
+// registers a template under a mnemonic so Parse selects it automatically for lines whose
+// first identifier is that mnemonic. Registering the same mnemonic again replaces the
+// previous template.
+func (p *Parser) RegisterTemplate(name string, tmpl []TemplateObject) (bool, string) {
+	return p.RegisterEntry(TemplateEntry{Name: name, Objects: tmpl})
+}
+
+// RegisterEntry
+// registers a fully described template entry. Entries for the same mnemonic with different
+// RequiredOptions or Profile are kept side by side as variants, e.g. a 32-bit and a 64-bit
+// form; an entry with the same RequiredOptions and Profile as an existing one replaces it,
+// unless the configuration allows overloads and its operand form differs.
+func (p *Parser) RegisterEntry(entry TemplateEntry) (bool, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.frozen != "" {
+		return false, errFrozen
+	}
+	if entry.Name == "" {
+		return false, "Template name is empty"
+	}
+	if len(entry.Objects) == 0 || entry.Objects[0].TemplateType != TokenIdentifier {
+		return false, fmt.Sprintf("Template %s must start with an identifier slot", entry.Name)
+	}
+	if ok, errmsg := ValidateGroups(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if ok, errmsg := ValidateNames(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if ok, errmsg := ValidateBackReferences(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if ok, errmsg := ValidateChoices(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if ok, errmsg := p.checkChoiceTokens(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if ok, errmsg := ValidateFlags(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if ok, errmsg := p.checkFlagTokens(entry.Objects); !ok {
+		return false, fmt.Sprintf("Template %s: %s", entry.Name, errmsg)
+	}
+	if _, found := p.profiles[entry.Profile]; entry.Profile != "" && !found {
+		return false, fmt.Sprintf("Template %s: unknown profile %s", entry.Name, entry.Profile)
+	}
+	if ok, errmsg := validateEncoding(&entry); !ok {
+		return false, errmsg
+	}
+	written, ok, errmsg := writtenForm(entry)
+	if !ok {
+		return false, errmsg
+	}
+	entry.Order, entry.written = append([]int(nil), entry.Order...), written
+	entry.Guards = append([]Guard(nil), entry.Guards...)
+	if ok, errmsg := compileGuards(&entry); !ok {
+		return false, errmsg
+	}
+	p.invalidateCache()
+	key := p.mnemonicKey(entry.Name)
+	variants, found := p.registry[key]
+	if !found {
+		p.mnemonics = append(p.mnemonics, key)
+	}
+	for idx, existing := range variants {
+		if sameOptions(existing.RequiredOptions, entry.RequiredOptions) && existing.Profile == entry.Profile &&
+			(!p.config.Overloads || sameForm(existing.form(), entry.form())) {
+			p.recordDuplicate(existing, &entry)
+			variants[idx] = &entry
+			return true, ""
+		}
+	}
+	p.registry[key] = append(variants, &entry)
+	return true, ""
+}
+
+// Lookup
+// returns the template entry registered under a mnemonic that is available with the
+// parser's current target options and profile.
+func (p *Parser) Lookup(name string) (*TemplateEntry, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, entry := range p.registry[p.mnemonicKey(name)] {
+		if ok, _ := p.available(entry, p.profile); ok {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// Variants
+// returns every entry registered under a mnemonic, whatever its target options and
+// profile.
+func (p *Parser) Variants(name string) []*TemplateEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*TemplateEntry(nil), p.registry[p.mnemonicKey(name)]...)
+}
+
+// SetTargetOption
+// sets a target option such as "mode" = "32" that template entries can be gated on.
+func (p *Parser) SetTargetOption(name string, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	if p.targetOptions == nil {
+		p.targetOptions = make(map[string]string)
+	}
+	p.targetOptions[name] = value
+}
+
+// TargetOption
+// returns the value of a target option, "" if it is not set.
+func (p *Parser) TargetOption(name string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.targetOptions[name]
+}
+
+// available
+// reports whether an entry is seen in a profile and its required options are satisfied,
+// with an error message explaining why not.
+func (p *Parser) available(entry *TemplateEntry, profile string) (bool, string) {
+	if ok, why := p.inProfile(entry, profile); !ok {
+		if entry.UnavailableError != "" {
+			return false, entry.UnavailableError
+		}
+		return false, why
+	}
+	for _, name := range sortedOptionNames(entry.RequiredOptions) {
+		if have := p.targetOptions[name]; have != entry.RequiredOptions[name] {
+			if entry.UnavailableError != "" {
+				return false, entry.UnavailableError
+			}
+			if have == "" {
+				return false, fmt.Sprintf("%s is not available unless %s is %s",
+					entry.Name, name, entry.RequiredOptions[name])
+			}
+			return false, fmt.Sprintf("%s is not available when %s is %s", entry.Name, name, have)
+		}
+	}
+	return true, ""
+}
+
+// sortedOptionNames
+// returns the option names of a requirement map in a stable order.
+func sortedOptionNames(options map[string]string) []string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sameOptions
+// reports whether two requirement maps are identical.
+func sameOptions(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, found := b[name]; !found || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Mnemonics
+// returns the registered mnemonics in registration order.
+func (p *Parser) Mnemonics() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.mnemonics...)
+}
+
+// mnemonicKey
+// normalizes a mnemonic for registry lookups: lowercased unless the configuration
+// preserves case without folding mnemonics.
+func (p *Parser) mnemonicKey(name string) string {
+	if !p.config.foldsMnemonics() {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// identifierKey
+// normalizes an identifier such as a constant name the same way the tokenizer does.
+func (p *Parser) identifierKey(name string) string {
+	if p.config.PreserveCase {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// foldsMnemonics
+// reports whether mnemonics and directive names are matched ignoring case.
+func (config ParserConfig) foldsMnemonics() bool {
+	return !config.PreserveCase || config.FoldMnemonics
+}
+
+// FirstIdentifier
+// returns the text of the first identifier token in a token list, or "" if there is none.
+func FirstIdentifier(tokens []Token) string {
+	for _, token := range tokens {
+		if token.Type == TokenIdentifier {
+			return token.ValueReceived
+		}
+	}
+	return ""
+}
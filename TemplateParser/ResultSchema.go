@@ -0,0 +1,350 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ResultSchemaID is the identifier of the schemas returned by ResultSchema.
+const ResultSchemaID = "https://github.com/jantypas/TemplateParser/result.schema.json"
+
+// schema is a JSON Schema node under construction.
+type schema = map[string]interface{}
+
+// ResultSchema
+// returns a JSON Schema describing the JSON form of the LineResult values the parser
+// produces. Beyond the fields every line has, the schema describes the objects of each
+// registered template variant slot by slot: the type, the value each type carries, the
+// range of the slot and its descriptor as the title. Fields that lines may leave out, such
+// as label or comment, are not required. Services receiving parse results can validate
+// them with it or generate typed clients from it.
+func (p *Parser) ResultSchema() ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	root := schema{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"$id":        ResultSchemaID,
+		"title":      "TemplateParser line result",
+		"type":       "object",
+		"required":   []string{"line", "address", "objects", "ok"},
+		"properties": lineResultProperties(),
+	}
+	defs := schema{
+		"object":       objectSchema(),
+		"operand":      operandSchema(),
+		"include_site": includeSiteSchema(),
+		"line_segment": lineSegmentSchema(),
+		"token_origin": tokenOriginSchema(),
+		"warning":      warningSchema(),
+		"suggestions":  suggestionsSchema(),
+	}
+	var rules []interface{}
+	for _, name := range p.mnemonics {
+		variants := make([]interface{}, 0, len(p.registry[name]))
+		for idx, entry := range p.registry[name] {
+			key := "template:" + entry.Name
+			if idx > 0 {
+				key = fmt.Sprintf("%s#%d", key, idx+1)
+			}
+			defs[key] = p.entrySchema(entry)
+			variants = append(variants, schema{"$ref": "#/$defs/" + key})
+		}
+		rules = append(rules, schema{
+			"if": schema{
+				"required":   []string{"ok", "mnemonic"},
+				"properties": schema{"ok": schema{"const": true}, "mnemonic": p.mnemonicSchema(name)},
+				"not":        schema{"required": []string{"directive"}},
+			},
+			"then": schema{"anyOf": variants},
+		})
+	}
+	if len(rules) > 0 {
+		root["allOf"] = rules
+	}
+	root["$defs"] = defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// lineResultProperties
+// describes the fields of a LineResult.
+func lineResultProperties() schema {
+	str := func(desc string) schema { return schema{"type": "string", "description": desc} }
+	count := func(desc string) schema { return schema{"type": "integer", "minimum": 0, "description": desc} }
+	ref := func(def string) schema { return schema{"$ref": "#/$defs/" + def} }
+	list := func(def string, desc string) schema {
+		return schema{"type": "array", "items": ref(def), "description": desc}
+	}
+	return schema{
+		"line":           count("Line number in the source"),
+		"file":           str("File the line was read from"),
+		"includes":       list("include_site", "Include directives leading to the file, outermost first"),
+		"segments":       list("line_segment", "Physical lines the line was joined from"),
+		"raw_text":       str("Line as it appeared in the source"),
+		"expanded":       str("Line after macro expansion"),
+		"label":          str("Label defined at the start of the line"),
+		"mnemonic":       str("Text of the leading identifier"),
+		"operands":       list("operand", "Objects after the mnemonic, without punctuation"),
+		"comment":        str("Text after the comment semicolon"),
+		"directive":      str("Built-in directive on the line, such as org"),
+		"address":        count("Location counter at the start of the line"),
+		"size":           count("Size in bytes of the matched template"),
+		"cycles":         count("Cycle count of the matched template"),
+		"objects":        schema{"type": []string{"array", "null"}, "items": ref("object"), "description": "One object per template slot"},
+		"groups":         schema{"type": "object", "additionalProperties": schema{"type": "array", "items": ref("object")}, "description": "Objects of each capture group"},
+		"named":          schema{"type": "object", "additionalProperties": ref("object"), "description": "Objects of the named template slots"},
+		"warnings":       list("warning", "Problems that did not stop the line from matching"),
+		"ok":             schema{"type": "boolean", "description": "Whether the line matched"},
+		"error":          str("Why the line failed"),
+		"error_column":   count("1-based column of the token the error concerns"),
+		"error_length":   count("Length of the token the error concerns"),
+		"expected":       str("Type of the slot the token failed to match"),
+		"template_error": str("Error text of the slot the token failed to match"),
+		"origin":         list("token_origin", "Macro expansions that produced the failing token"),
+		"suggestions":    ref("suggestions"),
+	}
+}
+
+// objectSchema
+// describes the JSON form of an ObjectType.
+func objectSchema() schema {
+	return schema{
+		"type":     "object",
+		"required": []string{"type", "type_id", "value"},
+		"properties": schema{
+			"type":       schema{"type": "string", "description": "Token type name"},
+			"type_id":    schema{"type": "integer", "description": "Token type id"},
+			"value":      schema{"description": "Value of the object"},
+			"descriptor": schema{"type": "string", "description": "Label name or expression text"},
+			"sensitive":  schema{"type": "boolean", "description": "Value is redacted"},
+			"width":      schema{"type": "integer", "minimum": 0, "description": "Register width in bits"},
+			"text":       schema{"type": "string", "description": "Source text the object was matched from"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// operandSchema
+// describes the JSON form of an Operand.
+func operandSchema() schema {
+	return schema{
+		"type":     "object",
+		"required": []string{"slot", "type", "object"},
+		"properties": schema{
+			"slot":   schema{"type": "integer", "minimum": 0},
+			"type":   schema{"type": "string"},
+			"group":  schema{"type": "string"},
+			"name":   schema{"type": "string"},
+			"object": schema{"$ref": "#/$defs/object"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// includeSiteSchema
+// describes the JSON form of an IncludeSite.
+func includeSiteSchema() schema {
+	return schema{
+		"type":                 "object",
+		"required":             []string{"file", "line"},
+		"properties":           schema{"file": schema{"type": "string"}, "line": schema{"type": "integer", "minimum": 1}},
+		"additionalProperties": false,
+	}
+}
+
+// lineSegmentSchema
+// describes the JSON form of a LineSegment.
+func lineSegmentSchema() schema {
+	return schema{
+		"type":     "object",
+		"required": []string{"line", "column", "offset"},
+		"properties": schema{
+			"line":   schema{"type": "integer", "minimum": 1},
+			"column": schema{"type": "integer", "minimum": 1},
+			"offset": schema{"type": "integer", "minimum": 0},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// tokenOriginSchema
+// describes the JSON form of a TokenOrigin.
+func tokenOriginSchema() schema {
+	return schema{
+		"type":     "object",
+		"required": []string{"macro", "line", "column"},
+		"properties": schema{
+			"macro":        schema{"type": "string"},
+			"line":         schema{"type": "integer", "minimum": 0},
+			"column":       schema{"type": "integer", "minimum": 0},
+			"defined_file": schema{"type": "string"},
+			"defined_line": schema{"type": "integer", "minimum": 0},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// suggestionsSchema
+// describes the JSON form of Suggestions.
+func suggestionsSchema() schema {
+	nearMatch := schema{
+		"type":     "object",
+		"required": []string{"mnemonic", "form", "matched"},
+		"properties": schema{
+			"mnemonic": schema{"type": "string"},
+			"form":     schema{"type": "string"},
+			"matched":  schema{"type": "integer", "minimum": 0},
+			"expected": schema{"type": "string"},
+		},
+		"additionalProperties": false,
+	}
+	return schema{
+		"type":        "object",
+		"description": "Suggestions for fixing a line that failed to match",
+		"properties": schema{
+			"mnemonics": schema{"type": "array", "items": schema{"type": "string"}},
+			"templates": schema{"type": "array", "items": nearMatch},
+			"expected":  schema{"type": "string"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// warningSchema
+// describes the JSON form of a Warning.
+func warningSchema() schema {
+	return schema{
+		"type":     "object",
+		"required": []string{"column", "message"},
+		"properties": schema{
+			"column":  schema{"type": "integer", "minimum": 0},
+			"length":  schema{"type": "integer", "minimum": 0},
+			"message": schema{"type": "string"},
+		},
+		"additionalProperties": false,
+	}
+}
+
+// mnemonicSchema
+// describes the mnemonic field of lines matching a registered mnemonic. When mnemonics are
+// folded but case is preserved, results keep the case the mnemonic was written in, so any
+// case is accepted.
+func (p *Parser) mnemonicSchema(name string) schema {
+	if !p.config.PreserveCase || !p.config.FoldMnemonics {
+		return schema{"const": name}
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range name {
+		lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+		if lower != upper {
+			sb.WriteString("[" + string(lower) + string(upper) + "]")
+		} else {
+			sb.WriteString(regexpQuote(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return schema{"type": "string", "pattern": sb.String()}
+}
+
+// regexpQuote
+// escapes the regular expression metacharacters of text.
+func regexpQuote(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// entrySchema
+// describes the lines matching a template entry.
+func (p *Parser) entrySchema(entry *TemplateEntry) schema {
+	slots := make([]interface{}, len(entry.Objects))
+	for idx, tmpl := range entry.Objects {
+		slots[idx] = p.slotSchema(tmpl)
+	}
+	props := schema{
+		"objects": schema{
+			"type":        "array",
+			"prefixItems": slots,
+			"items":       false,
+			"minItems":    len(slots),
+		},
+	}
+	if entry.Size > 0 {
+		props["size"] = schema{"const": entry.Size}
+	}
+	if entry.Cycles > 0 {
+		props["cycles"] = schema{"const": entry.Cycles}
+	}
+	return schema{"title": entry.Name, "type": "object", "properties": props}
+}
+
+// slotSchema
+// describes the object matching a template slot.
+func (p *Parser) slotSchema(tmpl TemplateObject) schema {
+	value := slotValueSchema(tmpl)
+	props := schema{
+		"type":    schema{"const": DefaultParserConfig().tokenName(tmpl.TemplateType)},
+		"type_id": schema{"const": tmpl.TemplateType},
+		"value":   value,
+	}
+	if tmpl.Sensitive {
+		props["value"] = schema{"const": RedactedValue}
+		props["sensitive"] = schema{"const": true}
+	}
+	slot := schema{"allOf": []interface{}{schema{"$ref": "#/$defs/object"}}, "properties": props}
+	if desc := tmpl.TemplateValue.ObjectDescriptor; desc != "" {
+		slot["title"] = desc
+	}
+	if tmpl.TemplateError != "" {
+		slot["description"] = tmpl.TemplateError
+	}
+	return slot
+}
+
+// slotValueSchema
+// describes the value of the object matching a template slot.
+func slotValueSchema(tmpl TemplateObject) schema {
+	switch tt := tmpl.TemplateType; {
+	case tt == TokenOperator:
+		if op, _ := tmpl.TemplateValue.ObjectValue.(string); op != "" {
+			return schema{"const": op}
+		}
+		return schema{"type": "string"}
+	case tt == TokenIdentifier || tt == TokenMacro || tt == TokenQuotedString || isPunctuation(tt):
+		return schema{"type": "string"}
+	case tt == TokenChar:
+		return schema{"type": "integer", "minimum": 0, "maximum": utf8.MaxRune}
+	case tt == TokenBoolean:
+		return schema{"type": "boolean"}
+	case tt == TokenEnum:
+		return schema{"type": "integer", "minimum": 0, "maximum": max(len(tmpl.Choices)-1, 0),
+			"description": "Index of the choice among " + strings.Join(tmpl.Choices, ", ")}
+	case tt == TokenFlags:
+		return schema{"type": "integer", "minimum": 0, "description": "Flags ORed together: " + flagValues(tmpl.Flags, ", ")}
+	case tt == TokenMemory:
+		register := schema{"type": "integer", "minimum": 0}
+		return schema{"type": "object", "description": "Base register, index register times scale and displacement",
+			"properties": schema{"hasBase": schema{"type": "boolean"}, "base": register, "baseWidth": register,
+				"hasIndex": schema{"type": "boolean"}, "index": register, "indexWidth": register,
+				"scale": schema{"type": "integer", "minimum": 1}, "displacement": schema{"type": "integer"}}}
+	case tt == TokenUint8 || tt == TokenUint16 || tt == TokenUint32 || tt == TokenUint64 || tt == TokenRegister:
+		value := schema{"type": "integer", "minimum": tmpl.MinValue}
+		if tmpl.MaxValue > 0 {
+			value["maximum"] = tmpl.MaxValue
+		}
+		return value
+	case tt == TokenBigInt || tt == TokenUint128 || tt == TokenUint256:
+		return schema{"type": []string{"integer", "string"}, "pattern": "^0x[0-9a-f]+$"}
+	case tt == TokenLabelRef || tt == TokenLabelRel || tt == TokenExpression:
+		return schema{"type": []string{"integer", "string"}, "description": "Resolved value, or the name or text before resolution"}
+	}
+	return schema{}
+}
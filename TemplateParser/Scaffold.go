@@ -0,0 +1,177 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScaffoldFile
+// is a file generated by Scaffold: its name relative to the project directory and its
+// contents.
+type ScaffoldFile struct {
+	Name string
+	Data []byte
+}
+
+// scaffoldGrammar is the starter grammar written by Scaffold.
+var scaffoldGrammar = GrammarFile{Templates: []GrammarTemplate{
+	{
+		Mnemonic: "mov",
+		Size:     2,
+		Operands: []GrammarOperand{
+			{Type: "Identifier"},
+			{Type: "Register", Descriptor: "destination", Error: "Expected a destination register"},
+			{Type: "Comma"},
+			{Type: "Register", Descriptor: "source", Error: "Expected a source register"},
+		},
+		Guards: []GrammarGuard{{Expr: "destination != source", Error: "Moving a register to itself has no effect"}},
+	},
+	{
+		Mnemonic: "ldi",
+		Size:     2,
+		Operands: []GrammarOperand{
+			{Type: "Identifier"},
+			{Type: "Register", Descriptor: "destination", Error: "Expected a destination register"},
+			{Type: "Comma"},
+			{Type: "Uint8", Descriptor: "value", Error: "Expected an 8-bit value"},
+		},
+	},
+	{
+		Mnemonic: "jmp",
+		Size:     3,
+		Operands: []GrammarOperand{
+			{Type: "Identifier"},
+			{Type: "LabelRef", Descriptor: "target", Error: "Expected a label"},
+		},
+	},
+}}
+
+// scaffoldSource is the sample source written by Scaffold.
+const scaffoldSource = `; Sample source for the starter grammar. Numbers are hex.
+count equ 10
+
+start:  ldi r1, count       ; load a constant
+        mov r2, r1
+        jmp start
+`
+
+// scaffoldMain is the Go program written by Scaffold, formatted with the grammar file name
+// and the Parser method loading it.
+const scaffoldMain = `// Command parse loads the grammar and parses the source files named on the command
+// line, printing each matched line and a diagnostic for each error.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+func main() {
+	files := os.Args[1:]
+	if len(files) == 0 {
+		files = []string{"sample.asm"}
+	}
+	parser := TemplateParser.NewParser(TemplateParser.DefaultParserConfig())
+	grammar, err := os.Open(%q)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	err = parser.%s(grammar)
+	grammar.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	failed := false
+	for _, file := range files {
+		result, err := parser.ParseFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		for _, line := range result.Lines {
+			if line.Ok && line.Mnemonic != "" {
+				operands := make([]string, len(line.Operands))
+				for idx, operand := range line.Operands {
+					operands[idx] = operand.Object.DisplayValue()
+				}
+				fmt.Printf("%%04x %%-4s %%s\n", line.Address, line.Mnemonic, strings.Join(operands, ", "))
+			}
+		}
+		diagnostics := result.Diagnostics()
+		TemplateParser.WriteDiagnostics(os.Stderr, TemplateParser.TextFormatter{}, diagnostics)
+		failed = failed || len(diagnostics) > 0
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+`
+
+// Scaffold
+// generates the files of a starter project built on the package: a grammar in the given
+// format ("yaml", the default when format is empty, or "json"), a sample source file
+// using it and a Go main that loads the grammar and parses source files with a Parser.
+func Scaffold(format string) ([]ScaffoldFile, error) {
+	var grammar bytes.Buffer
+	var grammarName, loader string
+	switch format {
+	case "", "yaml":
+		grammarName, loader = "grammar.yaml", "LoadTemplatesFromYAML"
+		enc := yaml.NewEncoder(&grammar)
+		enc.SetIndent(2)
+		if err := enc.Encode(scaffoldGrammar); err != nil {
+			return nil, err
+		}
+	case "json":
+		grammarName, loader = "grammar.json", "LoadTemplatesFromJSON"
+		enc := json.NewEncoder(&grammar)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(scaffoldGrammar); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown grammar format %q", format)
+	}
+	return []ScaffoldFile{
+		{grammarName, grammar.Bytes()},
+		{"sample.asm", []byte(scaffoldSource)},
+		{"main.go", []byte(fmt.Sprintf(scaffoldMain, grammarName, loader))},
+	}, nil
+}
+
+// WriteScaffold
+// writes the files generated by Scaffold into dir, creating it if needed, and returns
+// their paths. Existing files are only replaced when force is set; otherwise nothing is
+// written if any of them exists.
+func WriteScaffold(dir string, format string, force bool) ([]string, error) {
+	files, err := Scaffold(format)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(files))
+	for idx, file := range files {
+		paths[idx] = filepath.Join(dir, file.Name)
+		if _, err := os.Stat(paths[idx]); err == nil && !force {
+			return nil, fmt.Errorf("%s already exists", paths[idx])
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	for idx, file := range files {
+		if err := os.WriteFile(paths[idx], file.Data, 0o644); err != nil {
+			return paths[:idx], err
+		}
+	}
+	return paths, nil
+}
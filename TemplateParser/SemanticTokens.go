@@ -0,0 +1,169 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// SemanticClass
+// classifies a span of source text for syntax highlighting.
+type SemanticClass int
+
+const (
+	SemanticMnemonic SemanticClass = iota // The mnemonic of an instruction
+	SemanticRegister                      // A register
+	SemanticNumber                        // An integer literal
+	SemanticString                        // A quoted string or character literal
+	SemanticMacro                         // A macro invocation
+	SemanticLabel                         // A label or constant, where defined or referenced
+	SemanticComment                       // A comment, from its semicolon to the end of the line
+	SemanticKeyword                       // A directive such as .org, equ or include, or a boolean keyword
+	SemanticOperator                      // An operator, + or -
+)
+
+// semanticClassNames names the classes for String.
+var semanticClassNames = []string{"mnemonic", "register", "number", "string", "macro", "label", "comment", "keyword", "operator"}
+
+// SemanticTokenLegend lists the LSP semantic token types the classes map to, indexed by
+// class; it is the tokenTypes legend a language server announces for the data of
+// SemanticTokenData.
+var SemanticTokenLegend = []string{"function", "variable", "number", "string", "macro", "label", "comment", "keyword", "operator"}
+
+// SemanticModifierLegend lists the LSP semantic token modifiers SemanticTokenData sets:
+// bit 0 marks the definition of a label or constant.
+var SemanticModifierLegend = []string{"declaration"}
+
+// String
+// returns the name of a class.
+func (class SemanticClass) String() string {
+	if class >= 0 && int(class) < len(semanticClassNames) {
+		return semanticClassNames[class]
+	}
+	return fmt.Sprintf("SemanticClass(%d)", int(class))
+}
+
+// SemanticToken
+// is a classified span of a source line: Length bytes from the 1-based byte Column of line
+// Line. Definition is set on the label or constant a line defines.
+type SemanticToken struct {
+	Line       int           `json:"line"`
+	Column     int           `json:"column"`
+	Length     int           `json:"length"`
+	Class      SemanticClass `json:"class"`
+	Definition bool          `json:"definition,omitempty"`
+}
+
+// SemanticTokens
+// classifies the tokens of every line read from r, for feeding a syntax highlighter or,
+// through SemanticTokenData, an LSP semanticTokens response. Lines are read as ParseSource
+// reads them, but are neither expanded nor matched: the first identifier of a line is its
+// mnemonic and other identifiers are taken for labels and constants. Whitespace,
+// punctuation and unrecognized text are left out.
+func (p *Parser) SemanticTokens(r io.Reader) ([]SemanticToken, error) {
+	lines, _, err := ReadLines(r)
+	tokens := make([]SemanticToken, 0)
+	for idx, line := range lines {
+		tokens = append(tokens, p.SemanticTokensLine(line, idx+1)...)
+	}
+	return tokens, err
+}
+
+// SemanticTokensLine
+// classifies the tokens of one source line, given its line number.
+func (p *Parser) SemanticTokensLine(line string, lineNo int) []SemanticToken {
+	code, _ := SplitComment(line)
+	all := p.TokenizeLine(code)
+	label, body := SplitLabel(all)
+	equ := p.isEquDirective(body)
+	spans := make([]SemanticToken, 0, len(all)+1)
+	add := func(offset int, length int, class SemanticClass, definition bool) {
+		spans = append(spans, SemanticToken{lineNo, offset + 1, length, class, definition})
+	}
+	offset, identifiers := 0, 0
+	for idx, token := range all {
+		start, written := offset, token.text()
+		offset += len(written)
+		switch token.Type {
+		case TokenLabelDef:
+			add(start+strings.Index(token.ValueReceived, label), len(label), SemanticLabel, true)
+		case TokenIdentifier:
+			switch {
+			case idx > 0 && all[idx-1].ValueReceived == ".":
+				add(start-1, len(written)+1, SemanticKeyword, false)
+			case equ && identifiers == 0:
+				add(start, len(written), SemanticLabel, true)
+			case equ && identifiers == 1:
+				add(start, len(written), SemanticKeyword, false)
+			case identifiers == 0 && strings.EqualFold(token.ValueReceived, p.config.includeKeyword()):
+				add(start, len(written), SemanticKeyword, false)
+			case identifiers == 0:
+				add(start, len(written), SemanticMnemonic, false)
+			default:
+				add(start, len(written), SemanticLabel, false)
+			}
+			identifiers++
+		case TokenRegister:
+			add(start, len(written), SemanticRegister, false)
+		case TokenUint8, TokenUint16, TokenUint32, TokenUint64, TokenBigInt:
+			add(start, len(written), SemanticNumber, false)
+		case TokenQuotedString, TokenChar:
+			add(start, len(written), SemanticString, false)
+		case TokenBoolean:
+			add(start, len(written), SemanticKeyword, false)
+		case TokenMacro:
+			add(start, len(written), SemanticMacro, false)
+		case TokenOperator, TokenPlus, TokenMinus:
+			add(start, len(written), SemanticOperator, false)
+		}
+	}
+	if len(code) < len(line) {
+		add(len(code), len(line)-len(code), SemanticComment, false)
+	}
+	return spans
+}
+
+// SemanticTokenData
+// encodes semantic tokens in the relative form of an LSP semanticTokens response: five
+// integers per token, the line delta, the start delta, the length, the index of its type
+// in SemanticTokenLegend and its modifier bits. Positions are converted to the 0-based
+// UTF-16 offsets LSP expects using lines, the text of the source split into lines, such as
+// ReadLines returns; tokens must be in source order.
+func SemanticTokenData(tokens []SemanticToken, lines []string) []uint32 {
+	data := make([]uint32, 0, 5*len(tokens))
+	prevLine, prevChar := 0, 0
+	for _, token := range tokens {
+		line := token.Line - 1
+		text := ""
+		if line >= 0 && line < len(lines) {
+			text = lines[line]
+		}
+		start := utf16Length(text[:min(token.Column-1, len(text))])
+		length := utf16Length(text[min(token.Column-1, len(text)):min(token.Column-1+token.Length, len(text))])
+		if line != prevLine {
+			prevChar = 0
+		}
+		var modifiers uint32
+		if token.Definition {
+			modifiers = 1
+		}
+		data = append(data, uint32(line-prevLine), uint32(start-prevChar), uint32(length), uint32(token.Class), modifiers)
+		prevLine, prevChar = line, start
+	}
+	return data
+}
+
+// utf16Length
+// returns the number of UTF-16 code units of text.
+func utf16Length(text string) int {
+	units := 0
+	for _, r := range text {
+		if r >= 0x10000 && r <= utf8.MaxRune {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return units
+}
@@ -0,0 +1,85 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSemanticTokensLine(t *testing.T) {
+	p := NewParser(DefaultParserConfig())
+	tests := []struct {
+		line string
+		want []SemanticToken
+	}{
+		{"loop: mov r1, 10 ; copy", []SemanticToken{
+			{1, 1, 4, SemanticLabel, true},
+			{1, 7, 3, SemanticMnemonic, false},
+			{1, 11, 2, SemanticRegister, false},
+			{1, 15, 2, SemanticNumber, false},
+			{1, 18, 6, SemanticComment, false},
+		}},
+		{"jmp loop+2", []SemanticToken{
+			{1, 1, 3, SemanticMnemonic, false},
+			{1, 5, 4, SemanticLabel, false},
+			{1, 9, 1, SemanticOperator, false},
+			{1, 10, 1, SemanticNumber, false},
+		}},
+		{"size equ 10", []SemanticToken{
+			{1, 1, 4, SemanticLabel, true},
+			{1, 6, 3, SemanticKeyword, false},
+			{1, 10, 2, SemanticNumber, false},
+		}},
+		{".org 100", []SemanticToken{
+			{1, 1, 4, SemanticKeyword, false},
+			{1, 6, 3, SemanticNumber, false},
+		}},
+		{`str "hi", @msg, true`, []SemanticToken{
+			{1, 1, 3, SemanticMnemonic, false},
+			{1, 5, 4, SemanticString, false},
+			{1, 11, 4, SemanticMacro, false},
+			{1, 17, 4, SemanticKeyword, false},
+		}},
+	}
+	for _, tt := range tests {
+		if got := p.SemanticTokensLine(tt.line, 1); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SemanticTokensLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSemanticTokenData(t *testing.T) {
+	p := NewParser(DefaultParserConfig())
+	source := "mov r1, 20\n\nstr \"é😀\", r2\n"
+	tokens, err := p.SemanticTokens(strings.NewReader(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, _, _ := ReadLines(strings.NewReader(source))
+	want := []uint32{
+		0, 0, 3, uint32(SemanticMnemonic), 0,
+		0, 4, 2, uint32(SemanticRegister), 0,
+		0, 4, 2, uint32(SemanticNumber), 0,
+		2, 0, 3, uint32(SemanticMnemonic), 0,
+		0, 4, 5, uint32(SemanticString), 0,
+		0, 7, 2, uint32(SemanticRegister), 0,
+	}
+	if got := SemanticTokenData(tokens, lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("SemanticTokenData = %v, want %v", got, want)
+	}
+	if data := SemanticTokenData([]SemanticToken{{1, 1, 4, SemanticLabel, true}}, []string{"loop:"}); data[4] != 1 {
+		t.Errorf("definition modifiers = %d, want 1", data[4])
+	}
+}
+
+func TestSemanticClassString(t *testing.T) {
+	if len(semanticClassNames) != len(SemanticTokenLegend) {
+		t.Errorf("%d class names and %d legend entries", len(semanticClassNames), len(SemanticTokenLegend))
+	}
+	if got := SemanticOperator.String(); got != "operator" {
+		t.Errorf("SemanticOperator.String() = %q", got)
+	}
+	if got := SemanticClass(20).String(); got != "SemanticClass(20)" {
+		t.Errorf("SemanticClass(20).String() = %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Separators
+// sets the rules for the blanks and commas between the tokens of a line. The zero value
+// keeps the rules matching has always had: any Unicode space separates tokens, in runs of
+// any length, lines write every comma their template has, and a comma may be written with
+// or without blanks around it.
+type Separators struct {
+	// Blanks lists the characters that separate tokens, such as " " to allow spaces but
+	// not tabs. Lines separating tokens with other spaces fail. Empty means every Unicode
+	// space.
+	Blanks string
+	// SingleBlanks fails lines separating two tokens with more than one blank, as in
+	// "mov  r1, r2". Blanks before the first token and after the last are not counted.
+	SingleBlanks bool
+	// OptionalCommas lets lines leave out the commas of their template, separating the
+	// operands by blanks alone, so that "mov r1 r2" matches mov <reg>, <reg>. Lines
+	// writing the commas still match.
+	OptionalCommas bool
+	// Commas decides whether commas may touch the tokens around them.
+	Commas CommaSpacing
+}
+
+// CommaSpacing
+// selects where blanks go around the commas of a line.
+type CommaSpacing int
+
+const (
+	CommasAny    CommaSpacing = iota // Blanks around commas are optional, as in r1,r2 and r1 , r2
+	CommasSpaced                     // A blank follows every comma and none precedes it, as in r1, r2
+	CommasTight                      // Commas touch the tokens on both sides, as in r1,r2
+)
+
+// isBlank
+// reports whether a blank token is made of the configured blanks.
+func (seps Separators) isBlank(token Token) bool {
+	if seps.Blanks == "" {
+		return true
+	}
+	for _, ch := range token.ValueReceived {
+		if !strings.ContainsRune(seps.Blanks, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSeparators
+// checks the blanks and commas between the tokens of a line against the configuration's
+// Separators, returning the index of the first token breaking them, or -1, and the error.
+func (config ParserConfig) checkSeparators(tokens []Token) (int, string) {
+	seps := config.Separators
+	if seps == (Separators{}) {
+		return -1, ""
+	}
+	column := func(idx int) int { return tokenOffset(tokens, idx) + 1 }
+	first, last := -1, -1
+	for idx, token := range tokens {
+		if !isBlankToken(token) {
+			if first < 0 {
+				first = idx
+			}
+			last = idx
+		}
+	}
+	run := 0 // Blank characters since the last token
+	for idx, token := range tokens {
+		if isBlankToken(token) {
+			if !seps.isBlank(token) {
+				return idx, fmt.Sprintf("Unexpected %q at column %d", token.ValueReceived, column(idx))
+			}
+			run += utf8.RuneCountInString(token.ValueReceived)
+			continue
+		}
+		if seps.SingleBlanks && run > 1 && idx > first {
+			return idx, fmt.Sprintf("Tokens separated by %d blanks at column %d", run, column(idx)-run)
+		}
+		run = 0
+		if token.Type != TokenComma {
+			continue
+		}
+		before := idx > first && isBlankToken(tokens[idx-1])
+		after := idx < last && isBlankToken(tokens[idx+1])
+		switch {
+		case seps.Commas == CommasSpaced && before:
+			return idx, fmt.Sprintf("Blank before the comma at column %d", column(idx))
+		case seps.Commas == CommasSpaced && idx < last && !after:
+			return idx, fmt.Sprintf("Expected a blank after the comma at column %d", column(idx))
+		case seps.Commas == CommasTight && (before || after):
+			return idx, fmt.Sprintf("Blank around the comma at column %d", column(idx))
+		}
+	}
+	return -1, ""
+}
+
+// omittedComma
+// reports whether the comma of a template slot is left out of a line, the next token not
+// being a comma, when the configuration allows it.
+func (config ParserConfig) omittedComma(tmpl TemplateObject, cursor *TokenCursor) bool {
+	if !config.Separators.OptionalCommas || tmpl.TemplateType != TokenComma || tmpl.Hooks.matcher() != nil {
+		return false
+	}
+	token, ok := cursor.Peek()
+	return ok && token.Type != TokenComma
+}
@@ -0,0 +1,44 @@
+package TemplateParser
+
+import "testing"
+
+func TestSeparators(t *testing.T) {
+	tests := []struct {
+		name string
+		seps Separators
+		line string
+		err  string
+	}{
+		{"default", Separators{}, "mov\tr1 ,r2", ""},
+		{"blanks", Separators{Blanks: " "}, "mov r1, r2", ""},
+		{"blanks tab", Separators{Blanks: " "}, "mov\tr1, r2", `Unexpected "\t" at column 4`},
+		{"single", Separators{SingleBlanks: true}, "  mov r1, r2  ", ""},
+		{"single run", Separators{SingleBlanks: true}, "mov   r1, r2", "Tokens separated by 3 blanks at column 4"},
+		{"spaced", Separators{Commas: CommasSpaced}, "mov r1, r2", ""},
+		{"spaced before", Separators{Commas: CommasSpaced}, "mov r1 , r2", "Blank before the comma at column 8"},
+		{"spaced after", Separators{Commas: CommasSpaced}, "mov r1,r2", "Expected a blank after the comma at column 7"},
+		{"tight", Separators{Commas: CommasTight}, "mov r1,r2", ""},
+		{"tight blank", Separators{Commas: CommasTight}, "mov r1, r2", "Blank around the comma at column 7"},
+		{"optional commas", Separators{OptionalCommas: true}, "mov r1 r2", ""},
+		{"optional commas written", Separators{OptionalCommas: true}, "mov r1, r2", ""},
+		{"required commas", Separators{}, "mov r1 r2", "Object list and template list length do not match"},
+	}
+	for _, tt := range tests {
+		config := DefaultParserConfig()
+		config.Separators = tt.seps
+		_, ok, errmsg := ParseLineWithConfig(tt.line, benchmarkTemplate, config)
+		if ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("%s: ParseLineWithConfig(%q) = %v, %q, want %q", tt.name, tt.line, ok, errmsg, tt.err)
+		}
+	}
+}
+
+func TestSeparatorsParseSource(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Separators = Separators{Commas: CommasSpaced}
+	p := newTestParser(t, config, exprGrammar)
+	lr := parseTestSource(t, p, "li r1 , 10\n").Lines[0]
+	if lr.Ok || lr.Error != "Blank before the comma at column 7" {
+		t.Errorf("line = %v, %q, want a failure at the comma", lr.Ok, lr.Error)
+	}
+}
@@ -0,0 +1,213 @@
+package TemplateParser
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// Approximate per-item overheads, in bytes, used by memory accounting.
+const (
+	symbolOverhead = 64
+	macroOverhead  = 64
+	lineOverhead   = 192
+	objectOverhead = 64
+)
+
+// SessionStats
+// reports the approximate memory held by a Session, in bytes, and what it holds.
+type SessionStats struct {
+	Results     int   // Cached source results
+	Lines       int   // Lines in the cached results
+	Symbols     int   // Symbols in the cached results
+	SymbolBytes int64 // Symbol tables of the cached results
+	MacroBytes  int64 // Macro names and bodies of the session's parser
+	ResultBytes int64 // Cached lines, their text and objects
+	TotalBytes  int64
+	Evictions   int // Results evicted to stay under the memory limit
+	Limit       int64
+}
+
+// ErrMemoryLimit is returned when a Session cannot stay under its memory limit.
+var ErrMemoryLimit = errors.New("session memory limit exceeded")
+
+// Session
+// is one tenant's use of a Parser in a long-running service. It caches the results of the
+// sources it parses by name and accounts for the memory they, their symbol tables and
+// the parser's macros use. With a memory limit set, the oldest cached results are evicted
+// to make room for new ones, or, if EvictOnLimit is false, parsing fails once the limit
+// would be exceeded. The session owns its parser; macros defined while parsing belong to
+// the session, and the constants of a source's equ lines to its result. A session is safe
+// for concurrent use; EvictOnLimit must be set before it is shared.
+type Session struct {
+	mu           sync.Mutex // Guards the cached results and their accounting
+	parser       *Parser
+	names        []string // Cached result names, oldest first
+	results      map[string]*SourceResult
+	sizes        map[string]int64
+	limit        int64
+	EvictOnLimit bool
+	evictions    int
+}
+
+// NewSession
+// creates a session using parser. Results are evicted when over the limit by default.
+func NewSession(parser *Parser) *Session {
+	return &Session{
+		parser:       parser,
+		results:      make(map[string]*SourceResult),
+		sizes:        make(map[string]int64),
+		EvictOnLimit: true,
+	}
+}
+
+// Parser
+// returns the session's parser.
+func (s *Session) Parser() *Parser {
+	return s.parser
+}
+
+// SetMemoryLimit
+// caps the approximate memory of the session in bytes; 0 removes the cap.
+func (s *Session) SetMemoryLimit(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+}
+
+// Parse
+// parses a source with ParseSource and caches the result under name, replacing any
+// earlier result of that name. If the limit would be exceeded, older results are evicted
+// or ErrMemoryLimit is returned; the result is returned either way.
+func (s *Session) Parse(name string, r io.Reader) (*SourceResult, error) {
+	result, err := s.parser.ParseSource(r)
+	if err != nil {
+		return result, err
+	}
+	size := resultSize(result)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(name)
+	if s.limit > 0 {
+		for s.usage()+size > s.limit && s.EvictOnLimit && len(s.names) > 0 {
+			s.drop(s.names[0])
+			s.evictions++
+		}
+		if s.usage()+size > s.limit {
+			return result, ErrMemoryLimit
+		}
+	}
+	s.names = append(s.names, name)
+	s.results[name] = result
+	s.sizes[name] = size
+	return result, nil
+}
+
+// Result
+// returns a cached result.
+func (s *Session) Result(name string) (*SourceResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, found := s.results[name]
+	return result, found
+}
+
+// Drop
+// removes a cached result.
+func (s *Session) Drop(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(name)
+}
+
+// drop
+// implements Drop.
+func (s *Session) drop(name string) {
+	if _, found := s.results[name]; !found {
+		return
+	}
+	delete(s.results, name)
+	delete(s.sizes, name)
+	for idx, cached := range s.names {
+		if cached == name {
+			s.names = append(s.names[:idx], s.names[idx+1:]...)
+			break
+		}
+	}
+}
+
+// Stats
+// returns the session's memory accounting.
+func (s *Session) Stats() SessionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := SessionStats{Results: len(s.results), Evictions: s.evictions, Limit: s.limit}
+	for _, result := range s.results {
+		stats.Lines += len(result.Lines)
+		stats.Symbols += result.Symbols.Len()
+		symbols := symbolTableSize(result.Symbols)
+		stats.SymbolBytes += symbols
+		stats.ResultBytes += resultSize(result) - symbols
+	}
+	stats.MacroBytes = macroTableSize(s.parser.Macros())
+	stats.TotalBytes = stats.SymbolBytes + stats.MacroBytes + stats.ResultBytes
+	return stats
+}
+
+// usage
+// returns the memory currently accounted to the session. The caller holds s.mu.
+func (s *Session) usage() int64 {
+	total := macroTableSize(s.parser.Macros())
+	for _, size := range s.sizes {
+		total += size
+	}
+	return total
+}
+
+// symbolTableSize
+// estimates the memory of a symbol table.
+func symbolTableSize(st *SymbolTable) int64 {
+	if st == nil {
+		return 0
+	}
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	var size int64
+	for _, sym := range st.symbols {
+		size += symbolOverhead + 2*int64(len(sym.Name))
+	}
+	return size
+}
+
+// macroTableSize
+// estimates the memory of a macro table.
+func macroTableSize(mt *MacroTable) int64 {
+	if mt == nil {
+		return 0
+	}
+	mt.mu.RLock()
+	defer mt.mu.RUnlock()
+	var size int64
+	for _, macro := range mt.macros {
+		size += macroOverhead + int64(len(macro.Name)+len(macro.Body))
+	}
+	return size
+}
+
+// resultSize
+// estimates the memory of a source result, including its symbol and string tables.
+func resultSize(result *SourceResult) int64 {
+	size := symbolTableSize(result.Symbols) + stringTableSize(result.Strings)
+	for _, line := range result.Lines {
+		size += lineOverhead + int64(len(line.RawText)+len(line.Label)+len(line.Mnemonic)+
+			len(line.Comment)+len(line.Error))
+		size += int64(len(line.Objects)+len(line.Operands)) * objectOverhead
+		for _, obj := range line.Objects {
+			if text, isText := obj.ObjectValue.(string); isText {
+				size += int64(len(text))
+			}
+			size += int64(len(obj.ObjectDescriptor))
+		}
+	}
+	return size
+}
@@ -0,0 +1,57 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSessionEvictsOldest(t *testing.T) {
+	s := NewSession(newTestParser(t, DefaultParserConfig(), exprGrammar))
+	source := "li r1, 10\nli r2, 20\n"
+	if _, err := s.Parse("a", strings.NewReader(source)); err != nil {
+		t.Fatal(err)
+	}
+	s.SetMemoryLimit(s.Stats().TotalBytes)
+	if _, err := s.Parse("b", strings.NewReader(source)); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := s.Result("a"); found {
+		t.Errorf("result a was not evicted for b")
+	}
+	if stats := s.Stats(); stats.Results != 1 || stats.Evictions != 1 {
+		t.Errorf("Stats() = %+v, want 1 result and 1 eviction", stats)
+	}
+	s.EvictOnLimit = false
+	if _, err := s.Parse("c", strings.NewReader(source)); err != ErrMemoryLimit {
+		t.Errorf("Parse over the limit without eviction: %v, want ErrMemoryLimit", err)
+	}
+}
+
+func TestSessionSharedAcrossGoroutines(t *testing.T) {
+	s := NewSession(newTestParser(t, DefaultParserConfig(), exprGrammar))
+	const workers = 8
+	var wg sync.WaitGroup
+	for n := range workers {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for round := range 20 {
+				name := fmt.Sprintf("w%d", n)
+				if _, err := s.Parse(name, strings.NewReader("li r1, 10\n")); err != nil {
+					t.Error(err)
+					return
+				}
+				if round%2 == 0 {
+					s.Drop(name)
+				}
+				s.Stats()
+			}
+		}(n)
+	}
+	wg.Wait()
+	if stats := s.Stats(); stats.Results != workers || stats.Lines != workers {
+		t.Errorf("Stats() = %+v, want %d results of one line", stats, workers)
+	}
+}
@@ -0,0 +1,192 @@
+package TemplateParser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf16"
+)
+
+// Source encodings recognized by the source readers.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+)
+
+// lineReader
+// splits a source into lines the way ParseSource reads them. It detects a UTF-8 byte
+// order mark and UTF-16 input, with or without a byte order mark, decodes UTF-16 to UTF-8,
+// and ends lines at LF, CRLF or a lone CR. Each line carries the byte offset of its start
+// in the original input.
+type lineReader struct {
+	r        *bufio.Reader
+	encoding string
+	offset   int64 // Offset in the original input of the next line
+	err      error
+	buf      []byte // Storage reused for the bytes of each UTF-8 line
+}
+
+// newLineReader
+// creates a line reader, sniffing the encoding from the first bytes of r.
+func newLineReader(r io.Reader) *lineReader {
+	lr := &lineReader{r: bufio.NewReader(r), encoding: EncodingUTF8}
+	head, _ := lr.r.Peek(4)
+	switch {
+	case bytes.HasPrefix(head, []byte{0xef, 0xbb, 0xbf}):
+		lr.encoding, lr.offset = EncodingUTF8BOM, 3
+	case bytes.HasPrefix(head, []byte{0xff, 0xfe}):
+		lr.encoding, lr.offset = EncodingUTF16LE, 2
+	case bytes.HasPrefix(head, []byte{0xfe, 0xff}):
+		lr.encoding, lr.offset = EncodingUTF16BE, 2
+	case len(head) >= 4 && head[0] != 0 && head[1] == 0 && head[2] != 0 && head[3] == 0:
+		lr.encoding = EncodingUTF16LE
+	case len(head) >= 4 && head[0] == 0 && head[1] != 0 && head[2] == 0 && head[3] != 0:
+		lr.encoding = EncodingUTF16BE
+	}
+	lr.r.Discard(int(lr.offset))
+	return lr
+}
+
+// wide
+// reports whether the source is UTF-16.
+func (lr *lineReader) wide() bool {
+	return lr.encoding == EncodingUTF16LE || lr.encoding == EncodingUTF16BE
+}
+
+// next
+// returns the next line without its terminator and the offset of its start, or false at
+// the end of the input or on a read error, which err then reports.
+func (lr *lineReader) next() (string, int64, bool) {
+	if lr.err != nil {
+		return "", 0, false
+	}
+	start := lr.offset
+	var text string
+	var size int64
+	if lr.wide() {
+		text, size = lr.nextWide()
+	} else {
+		text, size = lr.nextNarrow()
+	}
+	if size == 0 {
+		return "", 0, false
+	}
+	lr.offset += size
+	return text, start, true
+}
+
+// nextNarrow
+// reads a UTF-8 line, returning it and the number of bytes it took with its terminator.
+func (lr *lineReader) nextNarrow() (string, int64) {
+	line := lr.buf[:0]
+	defer func() { lr.buf = line }()
+	var size int64
+	for {
+		c, err := lr.r.ReadByte()
+		if err != nil {
+			lr.err = err
+			return string(line), size
+		}
+		size++
+		switch c {
+		case '\n':
+			return string(line), size
+		case '\r':
+			if next, err := lr.r.Peek(1); err == nil && next[0] == '\n' {
+				lr.r.Discard(1)
+				size++
+			}
+			return string(line), size
+		}
+		line = append(line, c)
+	}
+}
+
+// nextWide
+// reads a UTF-16 line, returning it as UTF-8 and the number of bytes it took with its
+// terminator.
+func (lr *lineReader) nextWide() (string, int64) {
+	units := make([]uint16, 0, 64)
+	var size int64
+	readUnit := func() (uint16, bool) {
+		var pair [2]byte
+		if _, err := io.ReadFull(lr.r, pair[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			lr.err = err
+			return 0, false
+		}
+		size += 2
+		if lr.encoding == EncodingUTF16LE {
+			return uint16(pair[0]) | uint16(pair[1])<<8, true
+		}
+		return uint16(pair[0])<<8 | uint16(pair[1]), true
+	}
+	for {
+		unit, ok := readUnit()
+		if !ok {
+			return string(utf16.Decode(units)), size
+		}
+		switch unit {
+		case '\n':
+			return string(utf16.Decode(units)), size
+		case '\r':
+			next, err := lr.r.Peek(2)
+			if err == nil && ((lr.encoding == EncodingUTF16LE && next[0] == '\n' && next[1] == 0) ||
+				(lr.encoding == EncodingUTF16BE && next[0] == 0 && next[1] == '\n')) {
+				lr.r.Discard(2)
+				size += 2
+			}
+			return string(utf16.Decode(units)), size
+		}
+		units = append(units, unit)
+	}
+}
+
+// readErr
+// returns the read error that stopped the reader, nil at the end of the input.
+func (lr *lineReader) readErr() error {
+	if lr.err == io.EOF {
+		return nil
+	}
+	return lr.err
+}
+
+// originalOffset
+// returns the offset in the original input of the byte at a 1-based column of a line, or
+// of the line itself for column 0. Columns of lines changed by macro expansion have no
+// place in the input, so they give the offset of the line. Columns of joined lines are
+// found in the physical line holding them.
+func (pl ParsedLine) originalOffset(column int) int64 {
+	if column <= 0 || pl.Expanded != "" {
+		return pl.Offset
+	}
+	if seg, joined := pl.segment(column); joined {
+		return pl.segmentOffset(seg, column)
+	}
+	raw := pl.rawText()
+	prefix := raw[:min(column-1, len(raw))]
+	if !pl.wide {
+		return pl.Offset + int64(len(prefix))
+	}
+	return pl.Offset + 2*int64(len(utf16.Encode([]rune(prefix))))
+}
+
+// ReadLines
+// returns the lines of a source as ParseSource reads them: decoded from UTF-16 or UTF-8
+// with a byte order mark, and split at LF, CRLF or CR, so their indices are line numbers
+// less one. It also returns the encoding detected, such as EncodingUTF16LE.
+func ReadLines(r io.Reader) ([]string, string, error) {
+	lr := newLineReader(r)
+	lines := make([]string, 0)
+	for {
+		text, _, ok := lr.next()
+		if !ok {
+			return lines, lr.encoding, lr.readErr()
+		}
+		lines = append(lines, text)
+	}
+}
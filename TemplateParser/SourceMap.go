@@ -0,0 +1,151 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sourceMapVersion is the format version of the source maps written by WriteJSON.
+const sourceMapVersion = 1
+
+// SourceMapRecord
+// relates one output record back to its source. Index is the position of the line in the
+// parsed result and Instruction its position among the successfully matched instruction
+// lines, the order encoders emit them in, or -1 for other lines. File is an index into the
+// map's Files; Line, Column and Length locate the statement on the line, after any label
+// and without its comment, with a 1-based byte column. Expanded is set for lines produced
+// by macro expansion, which are located at the macro invocation.
+type SourceMapRecord struct {
+	Index       int    `json:"index"`
+	Instruction int    `json:"instruction"`
+	Address     uint64 `json:"address"`
+	File        int    `json:"file"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Length      int    `json:"length"`
+	Expanded    bool   `json:"expanded,omitempty"`
+}
+
+// SourceMap
+// maps the records generated from a parsed source, its lines and the instructions encoded
+// from them, back to file, line and column, so debuggers and error reporters can point at
+// the source of generated artifacts. Files lists the source files, "" for an unnamed one.
+// ParseSource and the functions built on it fill in SourceResult.SourceMap when
+// ParserConfig.SourceMaps is set.
+type SourceMap struct {
+	Version int               `json:"version"`
+	Files   []string          `json:"files"`
+	Records []SourceMapRecord `json:"records"`
+}
+
+// BuildSourceMap
+// builds the source map of parsed lines, such as the lines of a SourceResult or
+// BuildResult.
+func (p *Parser) BuildSourceMap(lines []LineResult) *SourceMap {
+	sm := &SourceMap{Version: sourceMapVersion, Files: make([]string, 0), Records: make([]SourceMapRecord, 0, len(lines))}
+	files := make(map[string]int)
+	instruction := 0
+	for idx, lr := range lines {
+		file, found := files[lr.File]
+		if !found {
+			file = len(sm.Files)
+			files[lr.File] = file
+			sm.Files = append(sm.Files, lr.File)
+		}
+		record := SourceMapRecord{Index: idx, Instruction: -1, Address: lr.Address, File: file,
+			Line: lr.LineNumber, Expanded: lr.Expanded != ""}
+		record.Column, record.Length = p.statementSpan(lr.rawText())
+		if lr.Ok && lr.Mnemonic != "" && lr.Directive == "" {
+			record.Instruction = instruction
+			instruction++
+		}
+		sm.Records = append(sm.Records, record)
+	}
+	return sm
+}
+
+// statementSpan
+// returns the 1-based column and the length of the statement on a source line: the text
+// after its label, without the comment and surrounding blanks.
+func (p *Parser) statementSpan(raw string) (int, int) {
+	code := strings.TrimRight(EatComments(raw), " \t")
+	tokens := p.TokenizeLine(code)
+	_, rest := SplitLabel(tokens)
+	start := 0
+	for _, token := range tokens[:len(tokens)-len(rest)] {
+		start += len(token.text())
+	}
+	for _, token := range rest {
+		if !isBlankToken(token) {
+			break
+		}
+		start += len(token.text())
+	}
+	if start >= len(code) {
+		start = len(code) - len(strings.TrimLeft(code, " \t"))
+	}
+	return start + 1, max(len(code)-start, 0)
+}
+
+// Record
+// returns the record of the line at an index of the parsed result.
+func (sm *SourceMap) Record(index int) (SourceMapRecord, bool) {
+	if index < 0 || index >= len(sm.Records) || sm.Records[index].Index != index {
+		for _, record := range sm.Records {
+			if record.Index == index {
+				return record, true
+			}
+		}
+		return SourceMapRecord{}, false
+	}
+	return sm.Records[index], true
+}
+
+// Instruction
+// returns the record of the n-th instruction.
+func (sm *SourceMap) Instruction(n int) (SourceMapRecord, bool) {
+	for _, record := range sm.Records {
+		if record.Instruction == n && n >= 0 {
+			return record, true
+		}
+	}
+	return SourceMapRecord{}, false
+}
+
+// FileName
+// returns the name of the file a record is located in.
+func (sm *SourceMap) FileName(record SourceMapRecord) string {
+	if record.File < 0 || record.File >= len(sm.Files) {
+		return ""
+	}
+	return sm.Files[record.File]
+}
+
+// WriteJSON
+// writes the source map as indented JSON.
+func (sm *SourceMap) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sm)
+}
+
+// ReadSourceMap
+// reads a source map in the JSON form WriteJSON writes, checking its version and that its
+// records name files it lists.
+func ReadSourceMap(r io.Reader) (*SourceMap, error) {
+	var sm SourceMap
+	if err := json.NewDecoder(r).Decode(&sm); err != nil {
+		return nil, err
+	}
+	if sm.Version != sourceMapVersion {
+		return nil, fmt.Errorf("unsupported source map version %d", sm.Version)
+	}
+	for _, record := range sm.Records {
+		if record.File < 0 || record.File >= len(sm.Files) {
+			return nil, fmt.Errorf("record %d: undeclared file %d", record.Index, record.File)
+		}
+	}
+	return &sm, nil
+}
@@ -0,0 +1,75 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSourceMap(t *testing.T) {
+	config := DefaultParserConfig()
+	config.SourceMaps = true
+	p := newTestParser(t, config, exprGrammar)
+	mt := NewMacroTable()
+	if ok, errmsg := mt.Define("pair", "li r1, 1\nli r2, 2"); !ok {
+		t.Fatal(errmsg)
+	}
+	p.SetMacros(mt)
+	result := parseTestSource(t, p, "start: li r1, 10 ; load\nhere:\n  le r2, 1+2\n@pair\n")
+	sm := result.SourceMap
+	if sm == nil {
+		t.Fatal("SourceMap is nil with SourceMaps set")
+	}
+	want := []SourceMapRecord{
+		{Index: 0, Instruction: 0, File: 0, Line: 1, Column: 8, Length: 9},
+		{Index: 1, Instruction: -1, Address: 1, File: 0, Line: 2, Column: 1, Length: 5},
+		{Index: 2, Instruction: 1, Address: 1, File: 0, Line: 3, Column: 3, Length: 10},
+		{Index: 3, Instruction: 2, Address: 2, File: 0, Line: 4, Column: 1, Length: 5, Expanded: true},
+		{Index: 4, Instruction: 3, Address: 3, File: 0, Line: 4, Column: 1, Length: 5, Expanded: true},
+	}
+	if !reflect.DeepEqual(sm.Records, want) || !reflect.DeepEqual(sm.Files, []string{""}) {
+		t.Errorf("source map = %+v, want records %+v", sm, want)
+	}
+	if record, found := sm.Instruction(1); !found || record.Line != 3 {
+		t.Errorf("Instruction(1) = %+v, %v, want line 3", record, found)
+	}
+	if _, found := sm.Instruction(-1); found {
+		t.Errorf("Instruction(-1) found a record")
+	}
+	if record, found := sm.Record(2); !found || record.Index != 2 {
+		t.Errorf("Record(2) = %+v, %v", record, found)
+	}
+	if _, found := sm.Record(5); found {
+		t.Errorf("Record(5) found a record past the lines")
+	}
+	var buf bytes.Buffer
+	if err := sm.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSourceMap(&buf)
+	if err != nil || !reflect.DeepEqual(read, sm) {
+		t.Errorf("ReadSourceMap = %+v, %v, want %+v", read, err, sm)
+	}
+}
+
+func TestSourceMapOff(t *testing.T) {
+	result := parseTestSource(t, newTestParser(t, DefaultParserConfig(), exprGrammar), "li r1, 10\n")
+	if result.SourceMap != nil {
+		t.Errorf("SourceMap = %+v without SourceMaps set", result.SourceMap)
+	}
+}
+
+func TestReadSourceMapErrors(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		err  string
+	}{
+		{`{"version": 2, "files": [], "records": []}`, "unsupported source map version 2"},
+		{`{"version": 1, "files": ["a.asm"], "records": [{"index": 3, "file": 1}]}`, "record 3: undeclared file 1"},
+	} {
+		if _, err := ReadSourceMap(strings.NewReader(tt.text)); err == nil || err.Error() != tt.err {
+			t.Errorf("ReadSourceMap(%s) error = %v, want %q", tt.text, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// stateVersion is the version of the format SaveState writes.
+const stateVersion = 1
+
+// savedState
+// is the JSON form of the state SaveState writes: the grammar it was saved with, the
+// constants, the text macros and the labels of the parser's symbol table.
+type savedState struct {
+	Version   int               `json:"version"`
+	Grammar   string            `json:"grammar"`
+	Constants map[string]uint64 `json:"constants,omitempty"`
+	Macros    []savedMacro      `json:"macros,omitempty"`
+	Symbols   []Symbol          `json:"symbols,omitempty"`
+}
+
+// savedMacro
+// is a text macro as SaveState writes it.
+type savedMacro struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// SetSymbols
+// sets the symbol table of labels defined before the sources the parser parses, such as
+// those of files assembled earlier in a build: ParseSource starts the symbol table of its
+// result with them, so lines can refer to them and redefining them is an error. Nil, the
+// default, starts every source with an empty table. The table is copied at the start of
+// each source, so it is not changed by the sources parsed.
+func (p *Parser) SetSymbols(st *SymbolTable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.symbols = st
+}
+
+// Symbols
+// returns the symbol table set with SetSymbols.
+func (p *Parser) Symbols() *SymbolTable {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.symbols
+}
+
+// SaveState
+// writes the state the parser gathers as it parses, so that long interactive sessions and
+// builds spanning several files can checkpoint their progress and resume from it, or fork
+// it to parse speculatively: the constants defined with DefineConstant, the macros of its
+// macro table and the labels of the table set with SetSymbols. The constants of equ lines
+// belong to the result of their source; pass them to DefineConstant to keep them in the
+// state. Preprocessor functions are code and are not saved. The state is JSON and records the GrammarHash of
+// the parser, which RestoreState checks.
+func (p *Parser) SaveState() ([]byte, error) {
+	p.mu.RLock()
+	state := savedState{Version: stateVersion, Grammar: p.grammarHash()}
+	macros, symbols := p.macros, p.symbols
+	p.mu.RUnlock()
+	state.Constants = p.constants.snapshot()
+	if macros != nil {
+		macros.mu.RLock()
+		for _, macro := range macros.macros {
+			if macro.Func == nil {
+				state.Macros = append(state.Macros, savedMacro{macro.Name, macro.Body, macro.File, macro.Line})
+			}
+		}
+		macros.mu.RUnlock()
+		sort.Slice(state.Macros, func(i, j int) bool { return state.Macros[i].Name < state.Macros[j].Name })
+	}
+	if symbols != nil {
+		state.Symbols = symbols.Symbols()
+	}
+	return json.Marshal(state)
+}
+
+// RestoreState
+// replaces the constants, text macros and symbols of the parser with those of a state
+// written by SaveState, keeping the preprocessor functions of its macro table. The macro
+// table and constants are changed in place, so that contexts sharing them see the
+// restored state, and the symbols are restored into a new table. It fails, changing
+// nothing, if the state is malformed or was saved with another grammar.
+func (p *Parser) RestoreState(data []byte) error {
+	var state savedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("invalid parser state: %w", err)
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported parser state version %d", state.Version)
+	}
+	symbols := NewSymbolTable()
+	for _, sym := range state.Symbols {
+		if ok, errmsg := symbols.Define(sym.Name, sym.Address, sym.Line); !ok {
+			return fmt.Errorf("invalid parser state: %s", errmsg)
+		}
+	}
+	for _, macro := range state.Macros {
+		if !isMacroName(macro.Name) {
+			return fmt.Errorf("invalid parser state: invalid macro name %q", macro.Name)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if hash := p.grammarHash(); state.Grammar != hash {
+		return fmt.Errorf("parser state was saved with grammar %s, not %s", state.Grammar, hash)
+	}
+	if p.macros == nil && len(state.Macros) > 0 {
+		p.macros = NewMacroTable()
+	}
+	if p.macros != nil {
+		p.macros.mu.Lock()
+		for name, macro := range p.macros.macros {
+			if macro.Func == nil {
+				delete(p.macros.macros, name)
+			}
+		}
+		for _, macro := range state.Macros {
+			p.macros.macros[macro.Name] = Macro{Name: macro.Name, Body: macro.Body, File: macro.File, Line: macro.Line}
+		}
+		p.macros.mu.Unlock()
+	}
+	p.constants.restore(state.Constants)
+	if len(state.Symbols) > 0 || p.symbols != nil {
+		p.symbols = symbols
+	}
+	return nil
+}
+
+// snapshot
+// returns a copy of the constants.
+func (ct *constantTable) snapshot() map[string]uint64 {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	if len(ct.values) == 0 {
+		return nil
+	}
+	values := make(map[string]uint64, len(ct.values))
+	for key, val := range ct.values {
+		values[key] = val
+	}
+	return values
+}
+
+// restore
+// replaces the constants with a copy of values, counting it as a change so that results
+// matched with the old constants are not reused.
+func (ct *constantTable) restore(values map[string]uint64) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.values = make(map[string]uint64, len(values))
+	for key, val := range values {
+		ct.values[key] = val
+	}
+	ct.changes++
+}
+
+// copy
+// returns a new table holding the symbols of st, in the same order.
+func (st *SymbolTable) copy() *SymbolTable {
+	copied := NewSymbolTable()
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	for _, name := range st.order {
+		copied.symbols[name] = st.symbols[name]
+	}
+	copied.order = append(copied.order, st.order...)
+	return copied
+}
@@ -0,0 +1,81 @@
+package TemplateParser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSaveAndRestoreState(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.DefineConstant("size", 0x20)
+	mt := NewMacroTable()
+	if ok, errmsg := mt.Define("two", "2"); !ok {
+		t.Fatal(errmsg)
+	}
+	p.SetMacros(mt)
+	symbols := NewSymbolTable()
+	symbols.Define("start", 0x100, 1)
+	p.SetSymbols(symbols)
+	state, err := p.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.DefineConstant("size", 1)
+	mt.Define("other", "3")
+	p.SetSymbols(nil)
+	if err := p.RestoreState(state); err != nil {
+		t.Fatal(err)
+	}
+	result := parseTestSource(t, p, "le r1, size\nli r1, @two\nle r1, start\nli r1, @other\n")
+	for idx, want := range []uint64{0x20, 2, 0x100} {
+		if got := lineValue(t, result, idx); got != want {
+			t.Errorf("line %d = %v, want %#x", idx+1, got, want)
+		}
+	}
+	if result.Lines[3].Ok {
+		t.Errorf("a macro defined after the checkpoint survived the restore")
+	}
+	if _, found := mt.Lookup("two"); !found {
+		t.Errorf("the macros were not restored into the parser's table")
+	}
+}
+
+func TestSetSymbols(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	symbols := NewSymbolTable()
+	symbols.Define("loop", 0x40, 1)
+	p.SetSymbols(symbols)
+	result := parseTestSource(t, p, "jmp loop\nloop: nop\n")
+	if got := lineValue(t, result, 0); got != uint64(0x40) {
+		t.Errorf("jmp loop = %v, want the label set before the source", got)
+	}
+	if result.Lines[1].Ok {
+		t.Errorf("redefining a label set with SetSymbols succeeded")
+	}
+	if symbols.Len() != 1 {
+		t.Errorf("parsing changed the table set with SetSymbols")
+	}
+}
+
+func TestRestoreStateErrors(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	state, err := newTestParser(t, DefaultParserConfig(), labelGrammar).SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{"{", "invalid parser state: "},
+		{`{"version": 2}`, "unsupported parser state version 2"},
+		{`{"version": 1, "macros": [{"name": "1x", "body": ""}]}`, `invalid parser state: invalid macro name "1x"`},
+		{string(state), "parser state was saved with grammar "},
+	}
+	for _, tt := range tests {
+		if err := p.RestoreState([]byte(tt.data)); err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("RestoreState(%s) = %v, want %q", tt.data, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package TemplateParser
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsBuckets are the upper bounds of the buckets of the line duration histogram of
+// Stats; a last bucket counts the lines slower than all of them. Collectors copy them when
+// created or reset, so changing them affects only the collectors created or reset later.
+var StatsBuckets = []time.Duration{
+	time.Microsecond, 2500 * time.Nanosecond, 5 * time.Microsecond,
+	10 * time.Microsecond, 25 * time.Microsecond, 50 * time.Microsecond,
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond,
+}
+
+// Stats
+// collects statistics of the lines a parser matches, for services embedding a parser to
+// monitor it: the lines matched, failed and found in the line cache, the tokens of each
+// type, the failures of each template at each slot and a histogram of the time taken
+// per line. Attach it with Parser.SetStats; several parsers may share one. It is safe
+// for concurrent use.
+type Stats struct {
+	mu        sync.Mutex
+	lines     uint64
+	failed    uint64
+	cached    uint64
+	tokens    map[int]uint64
+	names     map[int]string
+	failures  map[failureKey]uint64
+	bounds    []time.Duration
+	buckets   []uint64
+	durations time.Duration
+}
+
+// failureKey
+// identifies the template and slot failed lines are counted under.
+type failureKey struct {
+	mnemonic string
+	form     string
+	slot     int
+}
+
+// StatsSnapshot
+// is the state of a Stats collector at one time. Tokens counts the non-blank tokens of
+// the lines tokenized by the name of their type; lines found in the line cache are not
+// tokenized again. Failures is sorted from the most frequent.
+type StatsSnapshot struct {
+	Lines     uint64            `json:"lines"`
+	Failed    uint64            `json:"failed"`
+	Cached    uint64            `json:"cached"`
+	Tokens    map[string]uint64 `json:"tokens"`
+	Failures  []FailureCount    `json:"failures"`
+	Durations Histogram         `json:"durations"`
+}
+
+// FailureCount
+// counts the lines that failed to match a template at one of its slots. Mnemonic and
+// Form are empty for lines whose mnemonic selected no template, and Slot is -1 for
+// failures not at a slot, such as unknown mnemonics and guards.
+type FailureCount struct {
+	Mnemonic string `json:"mnemonic,omitempty"`
+	Form     string `json:"form,omitempty"`
+	Slot     int    `json:"slot"`
+	Count    uint64 `json:"count"`
+}
+
+// Histogram
+// counts the lines whose matching took up to each of Bounds, with a last count for the
+// lines slower than all of them, and the total time they took.
+type Histogram struct {
+	Bounds []time.Duration `json:"bounds"`
+	Counts []uint64        `json:"counts"`
+	Count  uint64          `json:"count"`
+	Sum    time.Duration   `json:"sum"`
+}
+
+// NewStats
+// creates an empty collector.
+func NewStats() *Stats {
+	s := &Stats{}
+	s.Reset()
+	return s
+}
+
+// Reset
+// clears the statistics collected.
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines, s.failed, s.cached, s.durations = 0, 0, 0, 0
+	s.tokens, s.names = make(map[int]uint64), make(map[int]string)
+	s.failures = make(map[failureKey]uint64)
+	s.bounds = append([]time.Duration(nil), StatsBuckets...)
+	s.buckets = make([]uint64, len(s.bounds)+1)
+}
+
+// SetStats
+// sets the collector the parser records the lines it matches in; nil, the default,
+// disables collecting.
+func (p *Parser) SetStats(stats *Stats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats = stats
+}
+
+// Stats
+// returns the collector set with SetStats.
+func (p *Parser) Stats() *Stats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stats
+}
+
+// recordLine
+// adds a matched line to the parser's collector, with the tokens it was split into, nil
+// for a line found in the line cache, and the time since its matching started. The
+// caller holds the parser's read lock.
+func (p *Parser) recordLine(result LineResult, tokens []Token, cached bool, start time.Time) {
+	if p.stats == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	s := p.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines++
+	if cached {
+		s.cached++
+	}
+	for _, token := range tokens {
+		if isBlankToken(token) {
+			continue
+		}
+		if _, found := s.names[token.Type]; !found {
+			s.names[token.Type] = p.config.tokenName(token.Type)
+		}
+		s.tokens[token.Type]++
+	}
+	if !result.Ok {
+		s.failed++
+		key := failureKey{slot: result.errSlot - 1}
+		if result.entry != nil {
+			key.mnemonic, key.form = result.entry.Name, p.config.templateSpec(result.entry.form())
+		}
+		s.failures[key]++
+	}
+	bucket := sort.Search(len(s.bounds), func(idx int) bool { return elapsed <= s.bounds[idx] })
+	s.buckets[bucket]++
+	s.durations += elapsed
+}
+
+// Snapshot
+// returns the statistics collected so far.
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := StatsSnapshot{Lines: s.lines, Failed: s.failed, Cached: s.cached,
+		Tokens: make(map[string]uint64, len(s.tokens)), Failures: make([]FailureCount, 0, len(s.failures)),
+		Durations: Histogram{Bounds: append([]time.Duration(nil), s.bounds...),
+			Counts: append([]uint64(nil), s.buckets...), Count: s.lines, Sum: s.durations}}
+	for tokenType, count := range s.tokens {
+		snap.Tokens[s.names[tokenType]] += count
+	}
+	for key, count := range s.failures {
+		snap.Failures = append(snap.Failures, FailureCount{key.mnemonic, key.form, key.slot, count})
+	}
+	sort.Slice(snap.Failures, func(i, j int) bool {
+		a, b := snap.Failures[i], snap.Failures[j]
+		switch {
+		case a.Count != b.Count:
+			return a.Count > b.Count
+		case a.Mnemonic != b.Mnemonic:
+			return a.Mnemonic < b.Mnemonic
+		case a.Form != b.Form:
+			return a.Form < b.Form
+		}
+		return a.Slot < b.Slot
+	})
+	return snap
+}
+
+// Var
+// returns an expvar.Var that reads a snapshot of the statistics as JSON, to be published
+// with expvar.Publish.
+func (s *Stats) Var() expvar.Var {
+	return expvar.Func(func() any { return s.Snapshot() })
+}
+
+// WritePrometheus
+// writes a snapshot of the statistics in the Prometheus text exposition format, each
+// metric name starting with namespace and an underscore, for an HTTP handler serving
+// /metrics:
+//
+//	<namespace>_lines_total, _lines_failed_total and _lines_cached_total
+//	<namespace>_tokens_total{type}
+//	<namespace>_failures_total{mnemonic,form,slot}
+//	<namespace>_line_duration_seconds, a histogram
+func (s *Stats) WritePrometheus(w io.Writer, namespace string) error {
+	snap := s.Snapshot()
+	var sb strings.Builder
+	family := func(name string, kind string, help string) string {
+		name = namespace + "_" + name
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		return name
+	}
+	fmt.Fprintf(&sb, "%s %d\n", family("lines_total", "counter", "Lines matched."), snap.Lines)
+	fmt.Fprintf(&sb, "%s %d\n", family("lines_failed_total", "counter", "Lines that failed to match."), snap.Failed)
+	fmt.Fprintf(&sb, "%s %d\n", family("lines_cached_total", "counter", "Lines found in the line cache."), snap.Cached)
+	name := family("tokens_total", "counter", "Non-blank tokens by type.")
+	types := make([]string, 0, len(snap.Tokens))
+	for tokenType := range snap.Tokens {
+		types = append(types, tokenType)
+	}
+	sort.Strings(types)
+	for _, tokenType := range types {
+		fmt.Fprintf(&sb, "%s{type=%s} %d\n", name, promLabel(tokenType), snap.Tokens[tokenType])
+	}
+	name = family("failures_total", "counter", "Failed lines by template and slot.")
+	for _, failure := range snap.Failures {
+		fmt.Fprintf(&sb, "%s{mnemonic=%s,form=%s,slot=\"%d\"} %d\n", name,
+			promLabel(failure.Mnemonic), promLabel(failure.Form), failure.Slot, failure.Count)
+	}
+	name = family("line_duration_seconds", "histogram", "Time taken to match a line.")
+	var cumulative uint64
+	for idx, count := range snap.Durations.Counts {
+		cumulative += count
+		bound := "+Inf"
+		if idx < len(snap.Durations.Bounds) {
+			bound = strconv.FormatFloat(snap.Durations.Bounds[idx].Seconds(), 'g', -1, 64)
+		}
+		fmt.Fprintf(&sb, "%s_bucket{le=\"%s\"} %d\n", name, bound, cumulative)
+	}
+	fmt.Fprintf(&sb, "%s_sum %s\n", name, strconv.FormatFloat(snap.Durations.Sum.Seconds(), 'g', -1, 64))
+	fmt.Fprintf(&sb, "%s_count %d\n", name, snap.Durations.Count)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// promLabel
+// quotes a label value for the Prometheus text format.
+func promLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,27 @@
+package TemplateParser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsKeepsItsBuckets(t *testing.T) {
+	saved := StatsBuckets
+	defer func() { StatsBuckets = saved }()
+	stats := NewStats()
+	StatsBuckets = append(append([]time.Duration(nil), saved...), time.Second, time.Minute)
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	p.SetStats(stats)
+	if _, ok, errmsg := p.ParseLine("li r1, 10"); !ok {
+		t.Fatalf("ParseLine: %s", errmsg)
+	}
+	snap := stats.Snapshot()
+	if len(snap.Durations.Bounds) != len(saved) || len(snap.Durations.Counts) != len(saved)+1 {
+		t.Errorf("histogram has %d bounds and %d counts, want %d and %d", len(snap.Durations.Bounds),
+			len(snap.Durations.Counts), len(saved), len(saved)+1)
+	}
+	stats.Reset()
+	if got := len(stats.Snapshot().Durations.Bounds); got != len(StatsBuckets) {
+		t.Errorf("histogram has %d bounds after Reset, want %d", got, len(StatsBuckets))
+	}
+}
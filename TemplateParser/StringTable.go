@@ -0,0 +1,145 @@
+package TemplateParser
+
+import "sync"
+
+// stringOverhead is the approximate memory of a string table entry besides its text.
+const stringOverhead = 48
+
+// StringTable
+// holds one copy of every quoted string of the lines of a source, in the order they first
+// appear, for assemblers emitting them as data. Each string has an index, counting from 0,
+// and an offset, the position of its first byte in Data, where every string is followed
+// by a NUL byte as .string directives write them. ParseSource fills the table of its
+// result; with ParserConfig.StringIndexes set the objects of quoted strings hold their
+// index instead of their text. It is safe for concurrent use.
+type StringTable struct {
+	mu      sync.RWMutex
+	strings []string
+	offsets []uint64
+	index   map[string]int
+	size    uint64
+}
+
+// NewStringTable
+// creates an empty string table.
+func NewStringTable() *StringTable {
+	return &StringTable{index: make(map[string]int)}
+}
+
+// Add
+// adds a string to the table unless it holds it already, returning its index.
+func (st *StringTable) Add(s string) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if idx, found := st.index[s]; found {
+		return idx
+	}
+	idx := len(st.strings)
+	st.index[s] = idx
+	st.strings = append(st.strings, s)
+	st.offsets = append(st.offsets, st.size)
+	st.size += uint64(len(s)) + 1
+	return idx
+}
+
+// Index
+// returns the index of a string in the table.
+func (st *StringTable) Index(s string) (int, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	idx, found := st.index[s]
+	return idx, found
+}
+
+// String
+// returns the string with an index.
+func (st *StringTable) String(idx int) (string, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	if idx < 0 || idx >= len(st.strings) {
+		return "", false
+	}
+	return st.strings[idx], true
+}
+
+// Offset
+// returns the offset in Data of the string with an index.
+func (st *StringTable) Offset(idx int) (uint64, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	if idx < 0 || idx >= len(st.offsets) {
+		return 0, false
+	}
+	return st.offsets[idx], true
+}
+
+// Strings
+// returns every string in index order.
+func (st *StringTable) Strings() []string {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return append([]string(nil), st.strings...)
+}
+
+// Len
+// returns the number of strings in the table.
+func (st *StringTable) Len() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return len(st.strings)
+}
+
+// Size
+// returns the length of Data in bytes.
+func (st *StringTable) Size() uint64 {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.size
+}
+
+// Data
+// returns the strings in index order, each followed by a NUL byte.
+func (st *StringTable) Data() []byte {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	data := make([]byte, 0, st.size)
+	for _, s := range st.strings {
+		data = append(data, s...)
+		data = append(data, 0)
+	}
+	return data
+}
+
+// addStrings
+// adds the quoted strings of a matched line to the table. When indexes is set the objects
+// are changed to hold the index of their string, a uint64, with the text moved to their
+// descriptor.
+func (st *StringTable) addStrings(lr *LineResult, indexes bool) {
+	changed := false
+	for idx := range lr.Objects {
+		obj := &lr.Objects[idx]
+		text, isText := obj.ObjectValue.(string)
+		if obj.ObjectTypeId != TokenQuotedString || !isText {
+			continue
+		}
+		pos := st.Add(text)
+		if indexes {
+			obj.ObjectValue, obj.ObjectDescriptor = uint64(pos), text
+			changed = true
+		}
+	}
+	if changed {
+		lr.refresh()
+	}
+}
+
+// stringTableSize
+// estimates the memory of a string table.
+func stringTableSize(st *StringTable) int64 {
+	if st == nil {
+		return 0
+	}
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return int64(len(st.strings))*stringOverhead + 2*int64(st.size)
+}
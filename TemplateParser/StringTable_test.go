@@ -0,0 +1,64 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// stringGrammar has a directive-like instruction taking a quoted string.
+const stringGrammar = `templates:
+  - mnemonic: str
+    operands:
+      - {type: Identifier}
+      - {type: QuotedString}
+`
+
+func TestStringTable(t *testing.T) {
+	st := NewStringTable()
+	for _, s := range []string{"hello", "", "hi", "hello"} {
+		st.Add(s)
+	}
+	if st.Len() != 3 || !reflect.DeepEqual(st.Strings(), []string{"hello", "", "hi"}) {
+		t.Errorf("Strings() = %q, want each string once", st.Strings())
+	}
+	if idx, found := st.Index("hi"); !found || idx != 2 {
+		t.Errorf("Index(hi) = %d, %v", idx, found)
+	}
+	if offset, found := st.Offset(2); !found || offset != 7 {
+		t.Errorf("Offset(2) = %d, %v, want 7", offset, found)
+	}
+	if s, found := st.String(0); !found || s != "hello" {
+		t.Errorf("String(0) = %q, %v", s, found)
+	}
+	if _, found := st.String(3); found {
+		t.Errorf("String(3) found a string past the table")
+	}
+	if data := st.Data(); !bytes.Equal(data, []byte("hello\x00\x00hi\x00")) || uint64(len(data)) != st.Size() {
+		t.Errorf("Data() = %q, Size() = %d", data, st.Size())
+	}
+}
+
+func TestParseSourceCollectsStrings(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), stringGrammar)
+	result := parseTestSource(t, p, "str \"one\"\nstr \"two\"\nstr \"one\"\nstr 12\n")
+	if got := result.Strings.Strings(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Errorf("Strings = %q, want those of the matched lines", got)
+	}
+	if got := lineValue(t, result, 2); got != "one" {
+		t.Errorf("string object = %v, want its text without StringIndexes", got)
+	}
+}
+
+func TestStringIndexes(t *testing.T) {
+	config := DefaultParserConfig()
+	config.StringIndexes = true
+	p := newTestParser(t, config, stringGrammar)
+	result := parseTestSource(t, p, "str \"one\"\nstr \"two\"\nstr \"one\"\n")
+	for idx, want := range []uint64{0, 1, 0} {
+		obj := result.Lines[idx].Objects[1]
+		if obj.ObjectValue != want || obj.ObjectDescriptor != result.Strings.Strings()[want] {
+			t.Errorf("line %d string = %+v, want index %d", idx+1, obj, want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package TemplateParser
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestedMnemonics is the most mnemonics Suggestions offers for a line.
+const maxSuggestedMnemonics = 5
+
+// Suggestions
+// helps fix a line that failed to match, for "did you mean" messages and editor
+// completion. Mnemonics lists known mnemonics spelled closest to the one on the line,
+// nearest first. Templates lists the forms of the line's mnemonic, those matching furthest
+// into the line first, and Expected is the token type the best of them expected where
+// matching stopped.
+type Suggestions struct {
+	Mnemonics []string    `json:"mnemonics,omitempty"`
+	Templates []NearMatch `json:"templates,omitempty"`
+	Expected  string      `json:"expected,omitempty"`
+}
+
+// NearMatch
+// is a form a failed line nearly matched: Form is its template in the template spec
+// syntax, Matched the number of its slots the line matched and Expected the type of the
+// slot it failed at, "" if the line has too many tokens.
+type NearMatch struct {
+	Mnemonic string `json:"mnemonic"`
+	Form     string `json:"form"`
+	Matched  int    `json:"matched"`
+	Expected string `json:"expected,omitempty"`
+}
+
+// suggest
+// returns the suggestions for the statement of a line that failed to match in a profile,
+// substituting the constants of its parsing call, nil when there are none or a form of its mnemonic matched and the line failed later, in a guard
+// or validator.
+func (p *Parser) suggest(tokens []Token, profile string, constants *constantTable) *Suggestions {
+	mnemonic := FirstIdentifier(tokens)
+	if mnemonic == "" {
+		return nil
+	}
+	s := &Suggestions{Mnemonics: p.closeMnemonics(mnemonic)}
+	for _, entry := range p.registry[p.mnemonicKey(mnemonic)] {
+		if ok, _ := p.available(entry, profile); !ok {
+			continue
+		}
+		errIdx, ok := p.matchDepth(tokens, entry, constants)
+		if ok {
+			return nil
+		}
+		form := entry.form()
+		near := NearMatch{Mnemonic: entry.Name, Form: TemplateSpec(form), Matched: errIdx}
+		if errIdx < len(form) {
+			near.Expected = p.config.tokenName(form[errIdx].TemplateType)
+		}
+		s.Templates = append(s.Templates, near)
+	}
+	sort.SliceStable(s.Templates, func(i, j int) bool { return s.Templates[i].Matched > s.Templates[j].Matched })
+	if len(s.Templates) > 0 {
+		s.Expected = s.Templates[0].Expected
+	}
+	if len(s.Mnemonics) == 0 && len(s.Templates) == 0 {
+		return nil
+	}
+	return s
+}
+
+// matchDepth
+// matches the tokens of a statement against an entry as matchEntry does, returning the
+// slot matching failed at, which is the number of slots matched, and whether the tokens
+// matched.
+func (p *Parser) matchDepth(tokens []Token, entry *TemplateEntry, constants *constantTable) (int, bool) {
+	form := entry.form()
+	tokens = p.substituteConstants(collapseExpressions(tokens, form), form, constants)
+	_, errIdx, _, ok, _ := matchTokens(tokens, form, p.config)
+	return max(errIdx, 0), ok
+}
+
+// closeMnemonics
+// returns the registered mnemonics within a small edit distance of a mnemonic, other than
+// the mnemonic itself, nearest first and alphabetically on a tie.
+func (p *Parser) closeMnemonics(mnemonic string) []string {
+	word := strings.ToLower(mnemonic)
+	limit := max(1, min(3, len(word)/2))
+	type candidate struct {
+		name     string
+		distance int
+	}
+	candidates := make([]candidate, 0)
+	for _, key := range p.mnemonics {
+		variants := p.registry[key]
+		if len(variants) == 0 || key == p.mnemonicKey(mnemonic) {
+			continue
+		}
+		name := variants[0].Name
+		if d := editDistance(word, strings.ToLower(name)); d <= limit {
+			candidates = append(candidates, candidate{name, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	names := make([]string, 0, min(len(candidates), maxSuggestedMnemonics))
+	for _, c := range candidates[:min(len(candidates), maxSuggestedMnemonics)] {
+		names = append(names, c.name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// editDistance
+// returns the edit distance between two strings, counted in bytes: the fewest insertions,
+// deletions, substitutions and swaps of adjacent bytes turning one into the other, so
+// that transposed letters, a common typo, count as one edit.
+func editDistance(a string, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// DidYouMean
+// returns the "did you mean" hint of the suggestions, such as "did you mean mov64?", or ""
+// when no mnemonic is suggested.
+func (s *Suggestions) DidYouMean() string {
+	if s == nil || len(s.Mnemonics) == 0 {
+		return ""
+	}
+	names := s.Mnemonics[:min(len(s.Mnemonics), 3)]
+	if len(names) == 1 {
+		return "did you mean " + names[0] + "?"
+	}
+	return "did you mean " + strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1] + "?"
+}
@@ -0,0 +1,60 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	result := parseTestSource(t, p, "lii r1, 10\nli r1, r2\nli r1, 10\n")
+	if s := result.Lines[0].Suggestions; s == nil || !reflect.DeepEqual(s.Mnemonics, []string{"li"}) || len(s.Templates) != 0 {
+		t.Errorf("suggestions for lii = %+v, want the close mnemonics", s)
+	}
+	s := result.Lines[1].Suggestions
+	if s == nil {
+		t.Fatal("no suggestions for li r1, r2")
+	}
+	want := []NearMatch{{Mnemonic: "li", Form: TemplateSpec(p.registry["li"][0].form()), Matched: 3, Expected: "Uint8"}}
+	if !reflect.DeepEqual(s.Templates, want) || s.Expected != "Uint8" {
+		t.Errorf("suggestions for li r1, r2 = %+v, want %+v", s, want)
+	}
+	if result.Lines[2].Suggestions != nil {
+		t.Errorf("a matched line has suggestions: %+v", result.Lines[2].Suggestions)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"mov", "mov", 0},
+		{"mov", "mvo", 1},
+		{"mov", "mov64", 2},
+		{"add", "sub", 3},
+		{"", "nop", 3},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	for _, tt := range []struct {
+		mnemonics []string
+		want      string
+	}{
+		{nil, ""},
+		{[]string{"mov64"}, "did you mean mov64?"},
+		{[]string{"mov", "movb", "movw", "movl"}, "did you mean mov, movb or movw?"},
+	} {
+		if got := (&Suggestions{Mnemonics: tt.mnemonics}).DidYouMean(); got != tt.want {
+			t.Errorf("DidYouMean(%q) = %q, want %q", tt.mnemonics, got, tt.want)
+		}
+	}
+	if got := (*Suggestions)(nil).DidYouMean(); got != "" {
+		t.Errorf("nil DidYouMean() = %q", got)
+	}
+}
@@ -0,0 +1,399 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Symbol
+// is a label recorded in a SymbolTable: its name, address and the line defining it.
+type Symbol struct {
+	Name    string `json:"name"`
+	Address uint64 `json:"address"`
+	Line    int    `json:"line"`
+}
+
+// SymbolTable
+// records label addresses during the first pass of ParseSource so label references
+// can be resolved in the second pass. It is safe for concurrent use, so the table of a
+// result can be shared through a ParseContext with lines parsed on other goroutines.
+type SymbolTable struct {
+	mu      sync.RWMutex
+	symbols map[string]Symbol
+	order   []string
+}
+
+// NewSymbolTable
+// creates an empty symbol table.
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{symbols: make(map[string]Symbol)}
+}
+
+// Define
+// records a symbol, failing if it is already defined.
+func (st *SymbolTable) Define(name string, address uint64, line int) (bool, string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if prev, found := st.symbols[name]; found {
+		return false, fmt.Sprintf("Symbol %s already defined on line %d", name, prev.Line)
+	}
+	st.symbols[name] = Symbol{name, address, line}
+	st.order = append(st.order, name)
+	return true, ""
+}
+
+// Lookup
+// returns the symbol with the given name.
+func (st *SymbolTable) Lookup(name string) (Symbol, bool) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	sym, found := st.symbols[name]
+	return sym, found
+}
+
+// Symbols
+// returns every symbol in definition order.
+func (st *SymbolTable) Symbols() []Symbol {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	symbols := make([]Symbol, len(st.order))
+	for idx, name := range st.order {
+		symbols[idx] = st.symbols[name]
+	}
+	return symbols
+}
+
+// Len
+// returns the number of symbols defined.
+func (st *SymbolTable) Len() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return len(st.order)
+}
+
+// mergeLabelDef
+// turns a leading identifier immediately followed by a colon into a TokenLabelDef token,
+// in place, given the code the tokens were scanned from.
+func mergeLabelDef(code string, tokens []Token) []Token {
+	first := 0
+	for first < len(tokens) && isBlankToken(tokens[first]) {
+		first++
+	}
+	if first+1 >= len(tokens) || tokens[first].Type != TokenIdentifier || tokens[first+1].Type != TokenColon {
+		return tokens
+	}
+	offset := tokenOffset(tokens, first)
+	tokens[first] = Token{Type: TokenLabelDef, ValueReceived: code[offset : offset+len(tokens[first].ValueReceived)+1]}
+	return append(tokens[:first+1], tokens[first+2:]...)
+}
+
+// isBlankToken
+// reports whether a token is whitespace that matching skips.
+func isBlankToken(token Token) bool {
+	return token.Type == TokenUnknown && strings.TrimSpace(token.ValueReceived) == ""
+}
+
+// hasContent
+// reports whether a token list contains anything other than whitespace.
+func hasContent(tokens []Token) bool {
+	for _, token := range tokens {
+		if !isBlankToken(token) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitLabel
+// removes a leading TokenLabelDef from a token list, returning the label name and the
+// remaining tokens. The name is "" if the line does not define a label.
+func SplitLabel(tokens []Token) (string, []Token) {
+	for idx, token := range tokens {
+		if isBlankToken(token) {
+			continue
+		}
+		if token.Type == TokenLabelDef {
+			return strings.TrimSuffix(token.ValueReceived, ":"), tokens[idx+1:]
+		}
+		break
+	}
+	return "", tokens
+}
+
+// SourceResult
+// is the outcome of ParseSource: every non-empty line and the symbols they define.
+// TotalSize and TotalCycles sum the Size and Cycles of every line. GrammarHash is the hash
+// of the template set of the frozen parser that produced the result, "" if the parser was
+// not frozen. SourceMap maps the lines back to their source when ParserConfig.SourceMaps
+// is set, and is nil otherwise. Strings holds the quoted strings of the matched lines.
+// Constants holds the constants defined by the equ lines of the source, by name as the
+// parser compares names, nil if there are none; constants defined with DefineConstant are
+// not included.
+type SourceResult struct {
+	Lines       []LineResult
+	Symbols     *SymbolTable
+	Strings     *StringTable
+	Constants   map[string]uint64
+	TotalSize   uint64
+	TotalCycles int
+	GrammarHash string
+	SourceMap   *SourceMap
+}
+
+// Ok
+// reports whether every line parsed and every label reference resolved.
+func (sr *SourceResult) Ok() bool {
+	for _, line := range sr.Lines {
+		if !line.Ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSource
+// parses a whole source in two passes. The first pass expands macros, matches every line,
+// keeps a location counter, records label definitions in a symbol table and the quoted
+// strings of matched lines in a string table. The counter
+// advances by the Size of each matched template entry, or by one for instruction lines
+// whose entry has no Size, and is set by .org directives; .arch directives select the
+// profile (see DefineProfile) the following lines of their file are matched in. The second
+// pass replaces label
+// operands with the address of their label; references to undefined labels mark their
+// line as failed, as do code regions that overlap an earlier region after an origin change.
+// Include directives are replaced by the lines of the file they name, read through the
+// parser's FileResolver; lines from included files carry the file name and the chain of
+// include directives that led to them. The returned error reports failures reading r, in
+// which case the lines read before the failure are still parsed, and otherwise is a
+// *LimitExceeded if the source exceeds the parser's Limits; the result then holds the
+// lines processed before the limit.
+func (p *Parser) ParseSource(r io.Reader) (*SourceResult, error) {
+	return p.parseSource(nil, r, "")
+}
+
+// parseSource
+// implements ParseSource and ParseSourceContext for a source with the given file name, ""
+// if it has none. ctx may be nil.
+func (p *Parser) parseSource(ctx *ParseContext, r io.Reader, file string) (*SourceResult, error) {
+	defer p.span("ParseSource", nil).End()
+	call := p.newContext(ctx)
+	parsed, err := p.matchSource(call, r, file)
+	result := p.assembleSource(parsed, call)
+	if ctx != nil {
+		ctx.Symbols = call.Symbols
+	}
+	return result, call.budget.firstError(err)
+}
+
+// matchSource
+// reads the lines of a source and matches them in order, in the call's context.
+func (p *Parser) matchSource(call *ParseContext, r io.Reader, file string) ([][]LineResult, error) {
+	lines, err := p.readSource(r, file, nil, "", nil, call.budget)
+	pass := p.span("match", map[string]interface{}{"lines": len(lines)})
+	defer pass.End()
+	parsed := make([][]LineResult, len(lines))
+	for idx, line := range lines {
+		parsed[idx] = p.tracedLine(line, call)
+	}
+	return parsed, err
+}
+
+// tracedLine
+// parses a source line inside a line span, in a copy of the call's context located at the
+// line. Once the context's budget is exceeded no more lines are parsed.
+func (p *Parser) tracedLine(line sourceLine, ctx *ParseContext) []LineResult {
+	if ctx.budget.stopped() {
+		return nil
+	}
+	if line.err != "" {
+		return []LineResult{line.includeError(p.config)}
+	}
+	lineCtx := ctx.at(line.file, line.number)
+	if line.profile != "" {
+		lineCtx.Profile = line.profile
+	}
+	if p.tracer == nil {
+		return line.withSource(p.parseInContext(line.text, lineCtx))
+	}
+	defer p.span("line", map[string]interface{}{"line": line.number}).End()
+	return line.withSource(p.parseInContext(line.text, lineCtx))
+}
+
+// sourceLine
+// is a non-blank line of a source with its line number, the file it was read from and the
+// include directives leading to that file. offset is the byte offset of the line in the
+// original input and wide is set for UTF-16 input. profile is the profile selected by the
+// last .arch directive before the line, "" if there is none. err is set on include
+// directives that failed.
+type sourceLine struct {
+	number   int
+	text     string
+	file     string
+	includes []IncludeSite
+	offset   int64
+	segments []LineSegment // Physical lines the line was joined from, nil for a single line
+	wide     bool
+	profile  string
+	err      string
+}
+
+// readSource
+// reads the lines of a source, dropping blank and comment-only lines and replacing include
+// directives with the lines of the files they name, and appends them to lines. file and
+// includes locate the source. profile is the profile in effect where the source starts,
+// changed by the .arch directives in it for the lines after them; included files start
+// with the profile of the include directive, and their directives do not reach back into
+// the including file. The source may be UTF-8, with or without a byte order mark,
+// or UTF-16, and its lines may end in LF, CRLF or CR. Every line read is charged to a
+// budget, and reading stops once it is exceeded. On a read error the lines read so far are
+// returned with the error.
+func (p *Parser) readSource(r io.Reader, file string, includes []IncludeSite, profile string, lines []sourceLine, b *budget) ([]sourceLine, error) {
+	if lines == nil {
+		lines = make([]sourceLine, 0)
+	}
+	reader := newLineReader(r)
+	lastLine := 0
+	for {
+		text, offset, last, segments, ok := reader.nextStatement(p.config, lastLine)
+		if !ok {
+			break
+		}
+		lineNo := lastLine + 1
+		lastLine = last
+		if !b.readLine(text, file, lineNo) {
+			break
+		}
+		if strings.TrimSpace(EatComments(text)) == "" {
+			continue
+		}
+		line := sourceLine{number: lineNo, text: text, file: file, includes: includes, offset: offset,
+			segments: segments, wide: reader.wide(), profile: profile}
+		if arch, isArch := p.archName(text); isArch {
+			profile = arch
+		}
+		name, isInclude, errmsg := p.includeName(text)
+		switch {
+		case !isInclude:
+			lines = append(lines, line)
+		case errmsg != "":
+			line.err = errmsg
+			lines = append(lines, line)
+		default:
+			lines = p.includeFile(line, name, lines, b)
+		}
+	}
+	return lines, reader.readErr()
+}
+
+// assembleSource
+// runs the sequential part of ParseSource over matched lines, in source order: it keeps
+// the location counter, defines labels, collects strings, totals sizes and cycles, records
+// the constants of the call's equ lines, then
+// resolves label operands, checks for overlapping regions and runs the actions of the
+// matched lines with the call's context, whose Symbols it sets to the source's symbol
+// table.
+func (p *Parser) assembleSource(parsed [][]LineResult, ctx *ParseContext) *SourceResult {
+	defer p.span("resolve", nil).End()
+	result := p.locateSource(parsed)
+	result.Constants = ctx.constants.snapshot()
+	p.resolveSource(result, ctx)
+	return result
+}
+
+// locateSource
+// runs the first pass of assembleSource: it keeps the location counter, defines labels in
+// a copy of the parser's symbol table, collects quoted strings and totals sizes and cycles.
+func (p *Parser) locateSource(parsed [][]LineResult) *SourceResult {
+	hash, _ := p.Frozen()
+	result := &SourceResult{Lines: make([]LineResult, 0), Symbols: NewSymbolTable(), Strings: NewStringTable(), GrammarHash: hash}
+	if symbols := p.Symbols(); symbols != nil {
+		result.Symbols = symbols.copy()
+	}
+	var address uint64
+	for _, group := range parsed {
+		for _, lr := range group {
+			if origin, isOrigin := originValue(lr); isOrigin {
+				address = origin
+			}
+			lr.Address = address
+			if lr.Label != "" {
+				if ok, errmsg := result.Symbols.Define(lr.Label, address, lr.LineNumber); !ok && lr.Ok {
+					lr.Ok, lr.Error = false, errmsg
+				}
+			}
+			if lr.Ok {
+				result.Strings.addStrings(&lr, p.config.StringIndexes)
+			}
+			address += lineSize(lr)
+			result.TotalSize += lr.Size
+			result.TotalCycles += lr.Cycles
+			result.Lines = append(result.Lines, lr)
+		}
+	}
+	return result
+}
+
+// resolveSource
+// runs the second pass of assembleSource: it resolves label operands, checks for
+// overlapping regions, runs the actions of the matched lines and builds the source map if
+// the configuration asks for one.
+func (p *Parser) resolveSource(result *SourceResult, ctx *ParseContext) {
+	for idx := range result.Lines {
+		ResolveSymbols(&result.Lines[idx], result.Symbols)
+	}
+	result.checkOverlaps()
+	ctx.Symbols = result.Symbols
+	runActions(result.Lines, ctx)
+	if p.config.SourceMaps {
+		result.SourceMap = p.BuildSourceMap(result.Lines)
+	}
+}
+
+// ParseFile
+// opens the named file and parses it with ParseSource. The file's lines carry its path,
+// and include directives in it are resolved relative to it when no FileResolver is set.
+func (p *Parser) ParseFile(path string) (*SourceResult, error) {
+	defer p.span("ParseFile", map[string]interface{}{"path": path}).End()
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return p.parseSource(nil, f, filepath.Clean(path))
+}
+
+// ResolveSymbols
+// replaces the TokenLabelRef operands of a successfully matched line with the addresses
+// of their labels, and TokenLabelRel operands with the signed (int64) distance from the
+// start of the line to the label. The label name is kept in the object's descriptor.
+// Operand expressions that use labels are evaluated with the label addresses. The line is
+// marked as failed if a label is undefined.
+func ResolveSymbols(lr *LineResult, symbols *SymbolTable) {
+	if !lr.Ok {
+		return
+	}
+	for idx := range lr.Objects {
+		obj := &lr.Objects[idx]
+		name, isName := obj.ObjectValue.(string)
+		if (obj.ObjectTypeId != TokenLabelRef && obj.ObjectTypeId != TokenLabelRel) || !isName {
+			continue
+		}
+		sym, found := symbols.Lookup(name)
+		if !found {
+			lr.Ok, lr.Error = false, fmt.Sprintf("Unresolved symbol %s", name)
+			return
+		}
+		if obj.ObjectTypeId == TokenLabelRel {
+			obj.ObjectValue = int64(sym.Address - lr.Address)
+		} else {
+			obj.ObjectValue = sym.Address
+		}
+		obj.ObjectDescriptor = name
+	}
+	resolveExpressions(lr, symbols)
+	lr.refresh()
+}
@@ -0,0 +1,100 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// labelGrammar has a one-byte nop and a two-byte jump to a label.
+const labelGrammar = `templates:
+  - mnemonic: jmp
+    size: 2
+    operands:
+      - {type: Identifier}
+      - {type: LabelRef}
+  - mnemonic: nop
+    size: 1
+    operands:
+      - {type: Identifier}
+`
+
+func TestParseSourceResolvesLabels(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	result := parseTestSource(t, p, "start: nop\njmp end\nnop\nend: jmp start\njmp gone\n")
+	tests := []struct {
+		address uint64
+		ok      bool
+		target  interface{}
+		err     string
+	}{
+		{0, true, nil, ""},
+		{1, true, uint64(4), ""},
+		{3, true, nil, ""},
+		{4, true, uint64(0), ""},
+		{6, false, nil, "Unresolved symbol gone"},
+	}
+	for idx, tt := range tests {
+		lr := result.Lines[idx]
+		if lr.Address != tt.address || lr.Ok != tt.ok || lr.Error != tt.err {
+			t.Errorf("line %d = address %d, %v, %q, want %d, %v, %q",
+				lr.LineNumber, lr.Address, lr.Ok, lr.Error, tt.address, tt.ok, tt.err)
+			continue
+		}
+		if tt.target != nil && lr.Objects[1].ObjectValue != tt.target {
+			t.Errorf("line %d jumps to %v, want %v", lr.LineNumber, lr.Objects[1].ObjectValue, tt.target)
+		}
+	}
+	for _, want := range []Symbol{{"start", 0, 1}, {"end", 4, 4}} {
+		if sym, found := result.Symbols.Lookup(want.Name); !found || sym != want {
+			t.Errorf("symbol %s = %+v, %v, want %+v", want.Name, sym, found, want)
+		}
+	}
+	if result.Ok() {
+		t.Errorf("result is ok with an unresolved label")
+	}
+}
+
+func TestSymbolTableRedefinition(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	result := parseTestSource(t, p, "here: nop\nhere: nop\n")
+	if result.Lines[1].Ok {
+		t.Errorf("a label defined twice was accepted")
+	}
+}
+
+func TestAddressMapOverlaps(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	result := parseTestSource(t, p, "start: nop\njmp start\n.org 2\nnext: nop\nnop\n.org 10\nnop\n")
+	am := result.AddressMap()
+	wantRegions := []Region{{0, 3, 1, 2}, {2, 4, 4, 5}, {0x10, 0x11, 7, 7}}
+	if !reflect.DeepEqual(am.Regions, wantRegions) {
+		t.Errorf("regions = %+v, want %+v", am.Regions, wantRegions)
+	}
+	if want := []Overlap{{wantRegions[0], wantRegions[1]}}; !reflect.DeepEqual(am.Overlaps, want) {
+		t.Errorf("overlaps = %+v, want %+v", am.Overlaps, want)
+	}
+	if want := []Symbol{{"start", 0, 1}, {"next", 2, 4}}; !reflect.DeepEqual(am.Symbols, want) {
+		t.Errorf("symbols = %+v, want %+v", am.Symbols, want)
+	}
+	if am.Used() != 6 {
+		t.Errorf("Used() = %d, want 6", am.Used())
+	}
+
+	var table, doc bytes.Buffer
+	if err := am.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "OVERLAP") || !strings.Contains(table.String(), "0x00000002-0x00000004") {
+		t.Errorf("table does not show the overlap:\n%s", table.String())
+	}
+	if err := am.WriteJSON(&doc); err != nil {
+		t.Fatal(err)
+	}
+	var decoded AddressMap
+	if err := json.Unmarshal(doc.Bytes(), &decoded); err != nil || !reflect.DeepEqual(decoded, *am) {
+		t.Errorf("JSON address map = %+v, %v, want %+v", decoded, err, *am)
+	}
+}
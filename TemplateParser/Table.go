@@ -0,0 +1,91 @@
+package TemplateParser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// tableColumns are the columns of TableRows before the operand columns.
+var tableColumns = []string{"file", "line", "address", "label", "mnemonic", "directive", "ok", "error"}
+
+// TableRows
+// flattens parsed lines into rows of text for spreadsheets and data frames, starting with
+// a header row. Each line gives one row: its file, line number, address, label, mnemonic,
+// directive, whether it parsed and its error, then the type and value of every operand in
+// the columns operandN_type and operandN, counting from 1. Lines with fewer operands than
+// the widest line leave the remaining columns empty. Integers are written in decimal so
+// spreadsheets read them as numbers, and sensitive values are redacted.
+func TableRows(lines []LineResult) [][]string {
+	width := 0
+	for _, lr := range lines {
+		width = max(width, len(lr.Operands))
+	}
+	header := append(make([]string, 0, len(tableColumns)+2*width), tableColumns...)
+	for n := 1; n <= width; n++ {
+		header = append(header, fmt.Sprintf("operand%d_type", n), fmt.Sprintf("operand%d", n))
+	}
+	rows := append(make([][]string, 0, len(lines)+1), header)
+	for _, lr := range lines {
+		row := make([]string, len(header))
+		row[0], row[1] = lr.File, strconv.Itoa(lr.LineNumber)
+		row[2] = strconv.FormatUint(lr.Address, 10)
+		row[3], row[4], row[5] = lr.Label, lr.Mnemonic, lr.Directive
+		row[6], row[7] = strconv.FormatBool(lr.Ok), lr.Error
+		for idx, operand := range lr.Operands {
+			column := len(tableColumns) + 2*idx
+			row[column], row[column+1] = operand.TypeName, tableValue(operand.Object)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// tableValue
+// returns the text of an object's value in a table cell.
+func tableValue(obj ObjectType) string {
+	if obj.ObjectSensitive {
+		return RedactedValue
+	}
+	switch val := obj.ObjectValue.(type) {
+	case nil:
+		return ""
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case *big.Int:
+		if val == nil {
+			return ""
+		}
+		return val.String()
+	}
+	return fmt.Sprint(obj.ObjectValue)
+}
+
+// WriteTable
+// writes the rows of TableRows for parsed lines as delimited text, with fields separated
+// by comma, such as ',' for CSV or '\t' for TSV. Fields are quoted as encoding/csv quotes
+// them, when they hold the separator, quotes or line breaks.
+func WriteTable(w io.Writer, lines []LineResult, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.WriteAll(TableRows(lines)); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteCSV
+// writes the lines of the result as CSV, one row per line, as WriteTable describes.
+func (sr *SourceResult) WriteCSV(w io.Writer) error {
+	return WriteTable(w, sr.Lines, ',')
+}
+
+// WriteTSV
+// writes the lines of the result as tab-separated values.
+func (sr *SourceResult) WriteTSV(w io.Writer) error {
+	return WriteTable(w, sr.Lines, '\t')
+}
@@ -0,0 +1,45 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	sr := parseTestSource(t, p, "start: li r1, 10\nle r2, 1+2\nbad\n")
+	var buf bytes.Buffer
+	if err := sr.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "file,line,address,label,mnemonic,directive,ok,error,operand1_type,operand1,operand2_type,operand2\n" +
+		",1,0,start,li,,true,,Register,1,Uint8,16\n" +
+		",2,1,,le,,true,,Register,2,Expression,3\n" +
+		",3,2,,,,false,Unknown mnemonic bad,,,,\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV =\n%s\nwant\n%s", buf.String(), want)
+	}
+	buf.Reset()
+	if err := sr.WriteTSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if line, _, _ := bytes.Cut(buf.Bytes(), []byte("\n")); !bytes.Equal(line, []byte("file\tline\taddress\tlabel\tmnemonic\tdirective\tok\terror\toperand1_type\toperand1\toperand2_type\toperand2")) {
+		t.Errorf("WriteTSV header = %q", line)
+	}
+}
+
+func TestTableRowsRedactsAndQuotes(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), sensitiveGrammar)
+	sr := parseTestSource(t, p, "key r1, 1234\n")
+	rows := TableRows(sr.Lines)
+	if got := rows[1][len(rows[1])-1]; got != RedactedValue {
+		t.Errorf("sensitive operand cell = %q, want %q", got, RedactedValue)
+	}
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, []LineResult{{ParsedLine: ParsedLine{LineNumber: 1}, Error: `Expected "a, b"`}}, ','); err != nil {
+		t.Fatal(err)
+	}
+	if want := "file,line,address,label,mnemonic,directive,ok,error\n,1,0,,,,false,\"Expected \"\"a, b\"\"\"\n"; buf.String() != want {
+		t.Errorf("WriteTable = %q, want %q", buf.String(), want)
+	}
+}
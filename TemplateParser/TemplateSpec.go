@@ -0,0 +1,312 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// specTypeAliases are the short type names a template spec may use besides the token type
+// names themselves.
+var specTypeAliases = map[string]int{
+	"id":    TokenIdentifier,
+	"ident": TokenIdentifier,
+	"str":   TokenQuotedString,
+	"u8":    TokenUint8,
+	"u16":   TokenUint16,
+	"u32":   TokenUint32,
+	"u64":   TokenUint64,
+	"u128":  TokenUint128,
+	"u256":  TokenUint256,
+	"big":   TokenBigInt,
+	"reg":   TokenRegister,
+	"label": TokenLabelRef,
+	"rel":   TokenLabelRel,
+	"expr":  TokenExpression,
+	"op":    TokenOperator,
+	"char":  TokenChar,
+	"bool":  TokenBoolean,
+	"mem":   TokenMemory,
+}
+
+// specPunctuation maps the punctuation characters of a template spec to their slots.
+var specPunctuation = map[rune]int{
+	',': TokenComma,
+	':': TokenColon,
+	'[': TokenLBracket,
+	']': TokenRBracket,
+	'(': TokenLParen,
+	')': TokenRParen,
+}
+
+// CompileTemplate
+// builds a template list from its text form, such as
+//
+//	mov <reg:dest>, <reg:src>
+//	ld <reg:dest>, [<reg:base> + <u8:offset>]
+//
+// The first word is the mnemonic and takes an identifier slot. <type> is an operand slot,
+// where type is a token type name such as Register, compared ignoring case, or one of the
+// short forms reg, u8, u16, u32, u64, u128, u256, big, str, id, label, rel, expr, op,
+// char and bool; <type:name> also gives the slot a Name and descriptor. <eq|ne|lt|gt> is
+// an Enum slot taking one of the words between the bars, and <eq|ne:cond> names it;
+// <read=1|write=2|exec=4> is a Flags slot taking those flags, with values in Go syntax.
+// Other words are keywords, identifier slots that only match that word, ignoring case. The
+// characters , : [ ] ( ) take their punctuation slots, + and - the Plus and Minus slots,
+// and any other run of symbols an Operator slot that must hold it. Token type names are
+// resolved against the built-in types; use Parser.CompileTemplate for specs using custom
+// token types.
+func CompileTemplate(spec string) ([]TemplateObject, error) {
+	return DefaultParserConfig().compileTemplate(spec)
+}
+
+// CompileTemplate
+// builds a template list from its text form, resolving type names against the parser's
+// built-in and custom token types.
+func (p *Parser) CompileTemplate(spec string) ([]TemplateObject, error) {
+	return p.config.compileTemplate(spec)
+}
+
+// RegisterSpec
+// compiles a template spec and registers it under its mnemonic, as RegisterTemplate does.
+func (p *Parser) RegisterSpec(spec string) (bool, string) {
+	tmpl, err := p.CompileTemplate(spec)
+	if err != nil {
+		return false, err.Error()
+	}
+	name, _ := tmpl[0].TemplateValue.ObjectValue.(string)
+	return p.RegisterTemplate(name, tmpl)
+}
+
+// compileTemplate
+// implements CompileTemplate with the token types of this configuration.
+func (config ParserConfig) compileTemplate(spec string) ([]TemplateObject, error) {
+	var tmpl []TemplateObject
+	fail := func(pos int, format string, args ...interface{}) ([]TemplateObject, error) {
+		return nil, fmt.Errorf("template spec %q: column %d: %s", spec, pos+1, fmt.Sprintf(format, args...))
+	}
+	for pos := 0; pos < len(spec); {
+		ch := rune(spec[pos])
+		switch {
+		case ch == ' ' || ch == '\t':
+			pos++
+		case ch == '<' && pos+1 < len(spec) && isSpecWord(rune(spec[pos+1])):
+			end := strings.IndexByte(spec[pos:], '>')
+			if end < 0 {
+				return fail(pos, "unterminated slot")
+			}
+			slot, err := config.specSlot(spec[pos+1 : pos+end])
+			if err != nil {
+				return fail(pos, "%v", err)
+			}
+			tmpl = append(tmpl, slot)
+			pos += end + 1
+		case isSpecWord(ch):
+			end := pos
+			for end < len(spec) && isSpecWord(rune(spec[end])) {
+				end++
+			}
+			tmpl = append(tmpl, specKeyword(spec[pos:end], len(tmpl) == 0))
+			pos = end
+		case isSpecPunctuation(ch):
+			tmpl = append(tmpl, TemplateObject{TemplateType: specPunctuation[ch]})
+			pos++
+		case ch > unicode.MaxASCII || unicode.IsControl(ch):
+			bad, _ := utf8.DecodeRuneInString(spec[pos:])
+			return fail(pos, "unexpected character %q", bad)
+		default:
+			end := pos + 1
+			for end < len(spec) && isSpecSymbol(spec, end) {
+				end++
+			}
+			tmpl = append(tmpl, specOperator(spec[pos:end]))
+			pos = end
+		}
+	}
+	if len(tmpl) == 0 || tmpl[0].TemplateType != TokenIdentifier || tmpl[0].Hooks.validate() != nil {
+		return fail(0, "expected a mnemonic")
+	}
+	if ok, errmsg := ValidateNames(tmpl); !ok {
+		return fail(0, "%s", errmsg)
+	}
+	return tmpl, nil
+}
+
+// specSlot
+// builds the operand slot of a <type>, <type:name>, <choice|choice:name> or
+// <flag=value|flag=value:name> item.
+func (config ParserConfig) specSlot(item string) (TemplateObject, error) {
+	typeName, name, _ := strings.Cut(item, ":")
+	var choices []string
+	var flags []Flag
+	tt, found := specTypeAliases[strings.ToLower(typeName)]
+	if !found && strings.Contains(typeName, "=") {
+		tt, found = TokenFlags, true
+		for _, item := range strings.Split(typeName, FlagSeparator) {
+			flagName, text, _ := strings.Cut(item, "=")
+			value, err := strconv.ParseUint(text, 0, 64)
+			if err != nil {
+				return TemplateObject{}, fmt.Errorf("invalid flag %q", item)
+			}
+			flags = append(flags, Flag{flagName, value})
+		}
+	} else if !found && strings.Contains(typeName, "|") {
+		tt, found, choices = TokenEnum, true, strings.Split(typeName, "|")
+		for _, choice := range choices {
+			if choice == "" || strings.IndexFunc(choice, func(ch rune) bool { return !isSpecWord(ch) }) >= 0 {
+				return TemplateObject{}, fmt.Errorf("invalid choice %q", choice)
+			}
+		}
+	}
+	if !found {
+		tt, found = config.specTypeByName(typeName)
+	}
+	if !found {
+		return TemplateObject{}, fmt.Errorf("unknown type %q", typeName)
+	}
+	for _, ch := range name {
+		if !isSpecWord(ch) {
+			return TemplateObject{}, fmt.Errorf("invalid slot name %q", name)
+		}
+	}
+	return TemplateObject{
+		TemplateType:  tt,
+		TemplateValue: ObjectType{ObjectDescriptor: name},
+		TemplateError: name,
+		Name:          name,
+		Choices:       choices,
+		Flags:         flags,
+	}, nil
+}
+
+// specTypeByName
+// resolves a token type name, ignoring case.
+func (config ParserConfig) specTypeByName(name string) (int, bool) {
+	if tt, found := config.tokenTypeByName(name); found {
+		return tt, true
+	}
+	for _, custom := range config.customTokens {
+		if strings.EqualFold(custom.Name, name) {
+			return custom.Id, true
+		}
+	}
+	for _, spec := range builtinRegistry.Specs() {
+		if spec.Id != TokenUnknown && strings.EqualFold(spec.Name, name) {
+			return spec.Id, true
+		}
+	}
+	return 0, false
+}
+
+// specKeyword
+// builds the identifier slot of a word: the mnemonic, or a keyword that must match it.
+func specKeyword(word string, mnemonic bool) TemplateObject {
+	slot := TemplateObject{TemplateType: TokenIdentifier, TemplateValue: StringObject(word, "")}
+	if !mnemonic {
+		slot.Hooks = &SlotHooks{Validate: func(obj ObjectType) error {
+			if got, _ := obj.ObjectValue.(string); !strings.EqualFold(got, word) {
+				return fmt.Errorf("Expected %s but got %s", word, got)
+			}
+			return nil
+		}}
+	}
+	return slot
+}
+
+// specOperator
+// builds the slot of a run of symbols.
+func specOperator(symbol string) TemplateObject {
+	switch symbol {
+	case "+":
+		return TemplateObject{TemplateType: TokenPlus}
+	case "-":
+		return TemplateObject{TemplateType: TokenMinus}
+	}
+	return TemplateObject{TemplateType: TokenOperator, TemplateValue: ObjectType{ObjectValue: symbol}}
+}
+
+// isSpecWord
+// reports whether a character can be part of a word or slot name in a template spec.
+func isSpecWord(ch rune) bool {
+	return ch == '_' || ch == '.' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9')
+}
+
+// isSpecPunctuation
+// reports whether a character takes a punctuation slot of its own.
+func isSpecPunctuation(ch rune) bool {
+	_, found := specPunctuation[ch]
+	return found
+}
+
+// isSpecSymbol
+// reports whether the byte at pos continues a run of symbols: it is printable ASCII that
+// is neither part of a word nor punctuation, and does not start a slot.
+func isSpecSymbol(spec string, pos int) bool {
+	b := spec[pos]
+	if b <= ' ' || b > unicode.MaxASCII || isSpecWord(rune(b)) || isSpecPunctuation(rune(b)) {
+		return false
+	}
+	return b != '<' || pos+1 == len(spec) || !isSpecWord(rune(spec[pos+1]))
+}
+
+// TemplateSpec
+// returns the text form of a template list that CompileTemplate reads, naming token types
+// the way the built-in types are named.
+func TemplateSpec(tmpl []TemplateObject) string {
+	return DefaultParserConfig().templateSpec(tmpl)
+}
+
+// templateSpec
+// implements TemplateSpec with the token type names of this configuration.
+func (config ParserConfig) templateSpec(tmpl []TemplateObject) string {
+	var sb strings.Builder
+	glue := true
+	for idx, slot := range tmpl {
+		var item string
+		word, _ := slot.TemplateValue.ObjectValue.(string)
+		switch {
+		case slot.TemplateType == TokenIdentifier && word != "" && (idx == 0 || slot.Hooks.validate() != nil):
+			item = word
+		case slot.TemplateType == TokenOperator && word != "":
+			item = word
+		case slot.TemplateType == TokenPlus:
+			item = "+"
+		case slot.TemplateType == TokenMinus:
+			item = "-"
+		case isPunctuation(slot.TemplateType) && slot.TemplateType != TokenOperator:
+			item = specPunctuationText(slot.TemplateType)
+		default:
+			item = "<" + config.tokenName(slot.TemplateType)
+			if slot.TemplateType == TokenEnum && len(slot.Choices) > 0 {
+				item = "<" + strings.Join(slot.Choices, "|")
+			}
+			if slot.TemplateType == TokenFlags && len(slot.Flags) > 0 {
+				item = "<" + flagValues(slot.Flags, FlagSeparator)
+			}
+			if slot.Name != "" {
+				item += ":" + slot.Name
+			}
+			item += ">"
+		}
+		closing := item == "," || item == ":" || item == "]" || item == ")"
+		if !glue && !closing {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(item)
+		glue = item == "[" || item == "("
+	}
+	return sb.String()
+}
+
+// specPunctuationText
+// returns the character of a punctuation slot.
+func specPunctuationText(tokenType int) string {
+	for ch, tt := range specPunctuation {
+		if tt == tokenType {
+			return string(ch)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	tmpl, err := CompileTemplate("ld <reg:dest>, [<Register:base> + <u8:offset>]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []int
+	for _, slot := range tmpl {
+		types = append(types, slot.TemplateType)
+	}
+	want := []int{TokenIdentifier, TokenRegister, TokenComma, TokenLBracket, TokenRegister, TokenPlus, TokenUint8, TokenRBracket}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("slot types = %v, want %v", types, want)
+	}
+	if tmpl[1].Name != "dest" || tmpl[4].TemplateValue.ObjectDescriptor != "base" {
+		t.Errorf("slots are not named: %+v", tmpl)
+	}
+	objs, ok, errmsg := ParseLine("LD r1, [r2 + 10]", tmpl)
+	if !ok || objs[6].ObjectValue != uint64(0x10) {
+		t.Errorf("ParseLine with the compiled template = %v, %v, %q", objs, ok, errmsg)
+	}
+}
+
+func TestRegisterSpec(t *testing.T) {
+	p := NewParser(DefaultParserConfig())
+	if ok, errmsg := p.RegisterSpec("ld <reg:dest>, [<reg:base>]"); !ok {
+		t.Fatal(errmsg)
+	}
+	if ok, _ := p.RegisterSpec("ld <nothing>"); ok {
+		t.Errorf("RegisterSpec accepted an unknown type")
+	}
+	result := parseTestSource(t, p, "ld r1, [r2]\nst r1, [r2]\n")
+	if !result.Lines[0].Ok || result.Lines[1].Ok {
+		t.Errorf("lines = %+v, want only the registered mnemonic to match", result.Lines)
+	}
+}
+
+func TestCompileTemplateChoicesAndKeywords(t *testing.T) {
+	tmpl, err := CompileTemplate("jc <eq|ne:cond> to <label>, <read=1|write=2:mode>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot := tmpl[1]; slot.TemplateType != TokenEnum || !reflect.DeepEqual(slot.Choices, []string{"eq", "ne"}) || slot.Name != "cond" {
+		t.Errorf("enum slot = %+v", slot)
+	}
+	if slot := tmpl[5]; slot.TemplateType != TokenFlags || !reflect.DeepEqual(slot.Flags, []Flag{{"read", 1}, {"write", 2}}) {
+		t.Errorf("flags slot = %+v", slot)
+	}
+	if _, ok, errmsg := ParseLine("jc ne TO loop, read", tmpl); !ok {
+		t.Errorf("ParseLine with keyword and choices failed: %s", errmsg)
+	}
+	if _, ok, _ := ParseLine("jc ne at loop, read", tmpl); ok {
+		t.Errorf("the keyword slot accepted another word")
+	}
+}
+
+func TestCompileTemplateErrors(t *testing.T) {
+	for _, tt := range []struct {
+		spec, err string
+	}{
+		{"", "expected a mnemonic"},
+		{"<reg> r1", "expected a mnemonic"},
+		{"mov <reg", "column 5: unterminated slot"},
+		{"mov <what>", `unknown type "what"`},
+		{"mov <reg:a-b>", `invalid slot name "a-b"`},
+		{"mov <a=x>", `invalid flag "a=x"`},
+		{"mov é", "unexpected character 'é'"},
+	} {
+		if _, err := CompileTemplate(tt.spec); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("CompileTemplate(%q) error = %v, want %q", tt.spec, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package TemplateParser
+
+import "strings"
+
+// TokenCursor
+// walks the tokens of a line the way the matcher sees them: TokenUnknown tokens, such as
+// whitespace and characters the tokenizer does not recognize, are stepped over. Mark and
+// Reset let code that tries several ways of matching the same tokens go back to where it
+// started. The zero value is an exhausted cursor.
+type TokenCursor struct {
+	tokens []Token
+	pos    int // Index in tokens of the next token Next returns
+}
+
+// CursorMark
+// is a position of a TokenCursor returned by Mark.
+type CursorMark int
+
+// NewTokenCursor
+// returns a cursor at the first token of a line.
+func NewTokenCursor(tokens []Token) *TokenCursor {
+	tc := &TokenCursor{tokens: tokens}
+	tc.skip()
+	return tc
+}
+
+// skip
+// moves the cursor past TokenUnknown tokens.
+func (tc *TokenCursor) skip() {
+	for tc.pos < len(tc.tokens) && tc.tokens[tc.pos].Type == TokenUnknown {
+		tc.pos++
+	}
+}
+
+// Done
+// reports whether every token has been consumed.
+func (tc *TokenCursor) Done() bool {
+	return tc.pos >= len(tc.tokens)
+}
+
+// Peek
+// returns the next token without consuming it, or false at the end of the line.
+func (tc *TokenCursor) Peek() (Token, bool) {
+	if tc.Done() {
+		return Token{}, false
+	}
+	return tc.tokens[tc.pos], true
+}
+
+// PeekType
+// returns the type of the next token, or TokenUnknown at the end of the line.
+func (tc *TokenCursor) PeekType() int {
+	if token, ok := tc.Peek(); ok {
+		return token.Type
+	}
+	return TokenUnknown
+}
+
+// Next
+// consumes and returns the next token, or returns false at the end of the line.
+func (tc *TokenCursor) Next() (Token, bool) {
+	token, ok := tc.Peek()
+	if ok {
+		tc.pos++
+		tc.skip()
+	}
+	return token, ok
+}
+
+// Mark
+// returns the current position, which Reset returns to.
+func (tc *TokenCursor) Mark() CursorMark {
+	return CursorMark(tc.pos)
+}
+
+// Reset
+// moves the cursor back, or forward, to a position returned by Mark.
+func (tc *TokenCursor) Reset(mark CursorMark) {
+	tc.pos = min(max(int(mark), 0), len(tc.tokens))
+}
+
+// Try
+// runs match on the cursor and reports what it returns, moving the cursor back to where
+// it was if match reports false, so that alternatives can be tried one after the other:
+//
+//	if cursor.Try(memoryOperand) || cursor.Try(registerOperand) { ... }
+func (tc *TokenCursor) Try(match func(cursor *TokenCursor) bool) bool {
+	mark := tc.Mark()
+	if match(tc) {
+		return true
+	}
+	tc.Reset(mark)
+	return false
+}
+
+// Column
+// returns the 1-based byte column of the next token in the line, or the column just past
+// the end of the line at its end.
+func (tc *TokenCursor) Column() int {
+	return tokenOffset(tc.tokens, tc.pos) + 1
+}
+
+// Rest
+// returns the tokens that have not been consumed, including TokenUnknown ones.
+func (tc *TokenCursor) Rest() []Token {
+	return tc.tokens[tc.pos:]
+}
+
+// noteTexts
+// sets the ObjectText of the objects from index done on to the source text of the tokens
+// they were matched from, which run from their start in starts to the last token before
+// the start of the next object or the cursor that is not TokenUnknown, and returns the
+// number of objects whose text is set.
+func (tc *TokenCursor) noteTexts(objList []ObjectType, starts []int, done int) int {
+	for ; done < len(objList) && done < len(starts); done++ {
+		end := tc.pos
+		if done+1 < len(starts) {
+			end = starts[done+1]
+		}
+		// The cursor steps over the blanks and stray tokens after the object's last token
+		for end > starts[done] && tc.tokens[end-1].Type == TokenUnknown {
+			end--
+		}
+		var sb strings.Builder
+		for _, token := range tc.tokens[starts[done]:end] {
+			sb.WriteString(token.text())
+		}
+		objList[done].ObjectText = strings.TrimSpace(sb.String())
+	}
+	return done
+}
+
+// Cursor
+// tokenizes a line as the parser's lines are tokenized, comments removed and token
+// filters applied, and returns a cursor at its first token, for MatchCursor.
+func (p *Parser) Cursor(line string) *TokenCursor {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return NewTokenCursor(p.filterTokens(p.tokenizeLine(nil, line)))
+}
+
+// MatchCursor
+// is MatchCursor using the parser's configuration.
+func (p *Parser) MatchCursor(cursor *TokenCursor, templateList []TemplateObject) ([]ObjectType, bool, string) {
+	return MatchCursorWithConfig(cursor, templateList, p.Config())
+}
@@ -0,0 +1,175 @@
+package TemplateParser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenCursorSkipsUnknownTokens(t *testing.T) {
+	cursor := NewTokenCursor(Tokenize("  mov r1, r2"))
+	var got []string
+	for !cursor.Done() {
+		if cursor.PeekType() == TokenUnknown {
+			t.Fatalf("PeekType at column %d is TokenUnknown", cursor.Column())
+		}
+		token, _ := cursor.Next()
+		got = append(got, token.ValueReceived)
+	}
+	if want := []string{"mov", "r1", ",", "r2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("tokens = %q, want %q", got, want)
+	}
+	if _, ok := cursor.Next(); ok {
+		t.Error("Next at the end of the line reports a token")
+	}
+	if cursor.PeekType() != TokenUnknown || len(cursor.Rest()) != 0 {
+		t.Errorf("exhausted cursor PeekType = %d, Rest = %v", cursor.PeekType(), cursor.Rest())
+	}
+	var zero TokenCursor
+	if !zero.Done() {
+		t.Error("zero cursor is not Done")
+	}
+}
+
+func TestTokenCursorColumn(t *testing.T) {
+	line := "mov  r1"
+	cursor := NewTokenCursor(Tokenize(line))
+	if cursor.Column() != 1 {
+		t.Errorf("Column at start = %d, want 1", cursor.Column())
+	}
+	cursor.Next()
+	if cursor.Column() != 6 {
+		t.Errorf("Column after mov = %d, want 6", cursor.Column())
+	}
+	cursor.Next()
+	if cursor.Column() != len(line)+1 {
+		t.Errorf("Column at end = %d, want %d", cursor.Column(), len(line)+1)
+	}
+}
+
+func TestTokenCursorMarkAndReset(t *testing.T) {
+	cursor := NewTokenCursor(Tokenize("mov r1, r2"))
+	start := cursor.Mark()
+	cursor.Next()
+	cursor.Next()
+	middle := cursor.Mark()
+	cursor.Reset(start)
+	if token, _ := cursor.Peek(); token.ValueReceived != "mov" {
+		t.Errorf("after Reset to start Peek = %q, want mov", token.ValueReceived)
+	}
+	cursor.Reset(middle)
+	if token, _ := cursor.Peek(); token.ValueReceived != "," {
+		t.Errorf("after Reset forward Peek = %q, want ,", token.ValueReceived)
+	}
+	// Marks out of range are clamped to the line
+	cursor.Reset(-5)
+	if cursor.Mark() != 0 {
+		t.Errorf("Reset(-5) leaves mark %d, want 0", cursor.Mark())
+	}
+	cursor.Reset(100)
+	if !cursor.Done() {
+		t.Error("Reset(100) is not Done")
+	}
+}
+
+func TestTokenCursorTry(t *testing.T) {
+	cursor := NewTokenCursor(Tokenize("mov r1, r2"))
+	register := func(c *TokenCursor) bool {
+		token, ok := c.Next()
+		return ok && token.Type == TokenRegister
+	}
+	identifier := func(c *TokenCursor) bool {
+		token, ok := c.Next()
+		return ok && token.Type == TokenIdentifier
+	}
+	if cursor.Try(register) {
+		t.Fatal("Try(register) matched mov")
+	}
+	if token, _ := cursor.Peek(); token.ValueReceived != "mov" {
+		t.Fatalf("failed Try moved the cursor to %q", token.ValueReceived)
+	}
+	if !(cursor.Try(register) || cursor.Try(identifier)) {
+		t.Fatal("no alternative matched mov")
+	}
+	if token, _ := cursor.Peek(); token.ValueReceived != "r1" {
+		t.Errorf("after a matching Try Peek = %q, want r1", token.ValueReceived)
+	}
+}
+
+func TestMatchCursorPrefix(t *testing.T) {
+	head := []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenRegister},
+	}
+	tail := []TemplateObject{
+		{TemplateType: TokenComma},
+		{TemplateType: TokenUint8},
+	}
+	cursor := NewTokenCursor(Tokenize("ldi r1, 10"))
+	objs, ok, errmsg := MatchCursor(cursor, head)
+	if !ok {
+		t.Fatalf("MatchCursor(head) failed: %s", errmsg)
+	}
+	if len(objs) != 2 || objs[1].ObjectText != "r1" {
+		t.Fatalf("MatchCursor(head) = %+v", objs)
+	}
+	if token, _ := cursor.Peek(); token.Type != TokenComma {
+		t.Fatalf("after the head Peek = %+v, want the comma", token)
+	}
+	objs, ok, errmsg = MatchCursor(cursor, tail)
+	if !ok {
+		t.Fatalf("MatchCursor(tail) failed: %s", errmsg)
+	}
+	if objs[1].ObjectValue != uint64(0x10) {
+		t.Errorf("tail operand = %v, want 0x10", objs[1].ObjectValue)
+	}
+	if !cursor.Done() {
+		t.Errorf("cursor not Done after the tail, Rest = %v", cursor.Rest())
+	}
+}
+
+func TestMatchCursorRewindsOnFailure(t *testing.T) {
+	registers := []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenRegister},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenRegister},
+	}
+	immediate := []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenRegister},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenUint8},
+	}
+	cursor := NewTokenCursor(Tokenize("mov r1, 10"))
+	start := cursor.Mark()
+	if _, ok, errmsg := MatchCursor(cursor, registers); ok || errmsg == "" {
+		t.Fatalf("MatchCursor(registers) = %v, %q, want a failure", ok, errmsg)
+	}
+	if cursor.Mark() != start {
+		t.Fatalf("failed MatchCursor left the cursor at %d, want %d", cursor.Mark(), start)
+	}
+	objs, ok, errmsg := MatchCursor(cursor, immediate)
+	if !ok {
+		t.Fatalf("MatchCursor(immediate) failed: %s", errmsg)
+	}
+	if objs[3].ObjectValue != uint64(0x10) || !cursor.Done() {
+		t.Errorf("MatchCursor(immediate) = %+v, Done = %v", objs, cursor.Done())
+	}
+}
+
+func TestParserCursor(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	cursor := p.Cursor("li r1, 5 ; load")
+	objs, ok, errmsg := p.MatchCursor(cursor, []TemplateObject{{TemplateType: TokenIdentifier}})
+	if !ok || objs[0].ObjectText != "li" {
+		t.Fatalf("MatchCursor = %+v, %v, %q", objs, ok, errmsg)
+	}
+	var rest []string
+	for !cursor.Done() {
+		token, _ := cursor.Next()
+		rest = append(rest, token.ValueReceived)
+	}
+	if want := []string{"r1", ",", "5"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("tokens after the mnemonic = %q, want the operands without the comment", rest)
+	}
+}
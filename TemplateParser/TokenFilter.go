@@ -0,0 +1,28 @@
+package TemplateParser
+
+// TokenFilter
+// rewrites the tokens of a line before they are matched against templates, e.g. to
+// substitute aliases or expand syntactic sugar. It may modify and return the slice it is
+// given, or return a new one.
+type TokenFilter func([]Token) []Token
+
+// UseTokenFilter
+// adds a filter that every line parsed by the parser passes through after tokenizing and
+// before directives, labels and templates are recognized. Filters run in the order they
+// were added. Error columns are computed from the filtered tokens, so filters that change
+// the text of tokens shift the columns reported for the line.
+func (p *Parser) UseTokenFilter(filter func([]Token) []Token) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalidateCache()
+	p.tokenFilters = append(p.tokenFilters, filter)
+}
+
+// filterTokens
+// passes the tokens of a line through the parser's filters.
+func (p *Parser) filterTokens(tokens []Token) []Token {
+	for _, filter := range p.tokenFilters {
+		tokens = filter(tokens)
+	}
+	return tokens
+}
@@ -0,0 +1,47 @@
+package TemplateParser
+
+// TokenOrigin
+// is one step of the macro expansion that produced a token: the macro invoked, where it was
+// invoked and where it was defined. Line and Column locate the invocation, in the source
+// line for the first step of a chain and in the body of the previous step's macro after
+// that; Line is then the 1-based line within the body. DefinedFile and DefinedLine are only
+// known for macros registered with MacroTable.DefineAt.
+type TokenOrigin struct {
+	Macro       string `json:"macro"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	DefinedFile string `json:"defined_file,omitempty"`
+	DefinedLine int    `json:"defined_line,omitempty"`
+}
+
+// originAt
+// returns the chain of expansions, outermost first, that produced the byte at offset of an
+// expanded line. lineNo is the number of the source line the expansion came from.
+func originAt(spans []macroSpan, offset int, lineNo int) []TokenOrigin {
+	var chain []TokenOrigin
+	for _, span := range spans {
+		if span.start <= offset && offset < span.end {
+			origin := span.origin
+			if span.depth == 0 {
+				origin.Line = lineNo
+			}
+			chain = append(chain, origin)
+		}
+	}
+	return chain
+}
+
+// tokenAtColumn
+// returns the byte offset in the expanded line and the length of the token at a 1-based
+// column of a line.
+func tokenAtColumn(tokens []Token, column int) (int, int, bool) {
+	offset := 0
+	for _, token := range tokens {
+		end := offset + len(token.ValueReceived)
+		if column-1 >= offset && column-1 < end {
+			return offset, len(token.ValueReceived), true
+		}
+		offset = end
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,29 @@
+package TemplateParser
+
+import "testing"
+
+// Tokens are compared with == by callers, which a slice field would not allow.
+var _ = Token{} == Token{}
+
+func TestErrorOriginFromMacro(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	mt := NewMacroTable()
+	if ok, errmsg := mt.Define("big", "1ff"); !ok {
+		t.Fatal(errmsg)
+	}
+	p.SetMacros(mt)
+	result := parseTestSource(t, p, "li r1, 10\nli r1, @big\n")
+	if !result.Lines[0].Ok || result.Lines[0].Origin != nil {
+		t.Errorf("line 1: ok=%v origin=%v, want a match without an origin", result.Lines[0].Ok, result.Lines[0].Origin)
+	}
+	lr := result.Lines[1]
+	if lr.Ok {
+		t.Fatalf("li r1, @big matched: %v", lr.Objects)
+	}
+	if len(lr.Origin) != 1 || lr.Origin[0].Macro != "big" || lr.Origin[0].Line != 2 || lr.Origin[0].Column != 8 {
+		t.Errorf("Origin = %+v, want @big at line 2 column 8", lr.Origin)
+	}
+	if lr.ErrorLength != 3 {
+		t.Errorf("ErrorLength = %d, want 3", lr.ErrorLength)
+	}
+}
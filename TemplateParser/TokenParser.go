@@ -2,25 +2,88 @@ package TemplateParser
 
 import (
 	"fmt"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-// Our basic object types we can handle
+// Our basic object types we can handle. Their values are kept as they were first
+// numbered, since they may be stored, and overlap the token type ids of matched objects.
+// Which accessor succeeds depends on the value an object holds, not on its type:
+// GetInteger reads any object holding a uint64.
 const (
 	OBJECT_TYPE_STRING = iota
 	OBJECT_TYPE_INTEGER
 	OBJECT_TYPE_BOOLEAN
+	OBJECT_TYPE_BIGINT
 )
 
 // ObjectType
 // represents a generic type that can hold multiple kinds
 // of data including integers, strings, and booleans.
+// ObjectSensitive marks values that must be masked wherever the package
+// reports them (errors, logs, serialized output).
 type ObjectType struct {
 	ObjectTypeId     int
 	ObjectValue      interface{}
 	ObjectDescriptor string
+	ObjectSensitive  bool
+	ObjectWidth      int    // Width in bits inferred from the source text, 0 if unknown
+	ObjectText       string // Source text the object was matched from, such as 0A for 0xa, "" if built in code
+}
+
+// RedactedValue is shown in place of sensitive values.
+const RedactedValue = "<redacted>"
+
+// newObject
+// builds an ObjectType with the given type id, value and descriptor.
+func newObject(id int, value interface{}, desc string) ObjectType {
+	return ObjectType{ObjectTypeId: id, ObjectValue: value, ObjectDescriptor: desc}
+}
+
+// DisplayValue
+// returns the value formatted for display, or RedactedValue if the object is sensitive.
+// Objects matched by Enum and Flags slots display as the keywords they were written as.
+func (obj *ObjectType) DisplayValue() string {
+	if obj == nil {
+		return ""
+	}
+	if obj.ObjectSensitive {
+		return RedactedValue
+	}
+	if (obj.ObjectTypeId == TokenEnum || obj.ObjectTypeId == TokenFlags) && obj.ObjectDescriptor != "" {
+		return obj.ObjectDescriptor
+	}
+	if val, ok := obj.ObjectValue.(uint64); ok {
+		return fmt.Sprintf("%#x", val)
+	}
+	return fmt.Sprint(obj.ObjectValue)
+}
+
+// Redacted
+// returns a copy of the object whose value and text are replaced by RedactedValue if it is
+// sensitive.
+func (obj ObjectType) Redacted() ObjectType {
+	if obj.ObjectSensitive {
+		obj.ObjectValue = RedactedValue
+		if obj.ObjectText != "" {
+			obj.ObjectText = RedactedValue
+		}
+	}
+	return obj
+}
+
+// RedactObjects
+// returns a copy of an object list with every sensitive value masked, suitable for logging
+// or serializing. The original list is left untouched for the direct caller.
+func RedactObjects(objList []ObjectType) []ObjectType {
+	redacted := make([]ObjectType, len(objList))
+	for idx, obj := range objList {
+		redacted[idx] = obj.Redacted()
+	}
+	return redacted
 }
 
 // SetString
@@ -49,209 +112,748 @@ func (obj *ObjectType) SetBoolean(b bool, desc string) {
 
 // GetString
 // retrieves the string value and descriptor if the ObjectType holds a string, otherwise returns an error message.
+// Identifiers, quoted strings, macros and unresolved labels hold strings.
 func (obj *ObjectType) GetString() (bool, string, string) {
-	if obj.ObjectTypeId != OBJECT_TYPE_STRING {
-		return false, "Mismatched object type", ""
-	} else {
-		return true, obj.ObjectValue.(string), obj.ObjectDescriptor
+	if obj == nil {
+		return false, "Nil object", ""
 	}
-
+	val, isString := obj.ObjectValue.(string)
+	if !isString {
+		return false, obj.mismatch("a string"), ""
+	}
+	return true, val, obj.ObjectDescriptor
 }
 
 // GetInteger
-// returns a boolean indicating success, the integer value, and an error message if the object type is not integer.
+// returns a boolean indicating success, the integer value, and an error message if the object does not hold an integer.
+// Objects matched by Enum slots are integers too, holding the index of their choice, as are
+// those matched by Flags slots, holding the flags' values, and registers and characters.
 func (obj *ObjectType) GetInteger() (bool, uint64, string) {
-	if obj.ObjectTypeId != OBJECT_TYPE_INTEGER {
-		return false, 0, "Mismatch object type"
+	if obj == nil {
+		return false, 0, "Nil object"
+	}
+	val, matches := obj.ObjectValue.(uint64)
+	if !matches {
+		return false, 0, obj.mismatch("an integer")
 	}
-	return true, obj.ObjectValue.(uint64), ""
+	return true, val, ""
 }
 
 // GetBoolean
-// retrieves the boolean value and an error message if the ObjectType is not a boolean. Returns a success flag, value, and error.
+// retrieves the boolean value and an error message if the ObjectType does not hold a boolean. Returns a success flag, value, and error.
+// Objects matched from TokenBoolean tokens are booleans too.
 func (obj *ObjectType) GetBoolean() (bool, bool, string) {
-	if obj.ObjectTypeId != OBJECT_TYPE_BOOLEAN {
-		return false, false, "Mismatch object type"
+	if obj == nil {
+		return false, false, "Nil object"
 	}
-	return true, obj.ObjectValue.(bool), ""
+	val, matches := obj.ObjectValue.(bool)
+	if !matches {
+		return false, false, obj.mismatch("a boolean")
+	}
+	return true, val, ""
+}
+
+// mismatch
+// describes an object that does not hold the kind of value an accessor asked for. The
+// value itself is not shown, since it may be sensitive.
+func (obj *ObjectType) mismatch(want string) string {
+	return fmt.Sprintf("Mismatched object type: %s object holds %T, not %s",
+		DefaultParserConfig().tokenName(obj.ObjectTypeId), obj.ObjectValue, want)
+}
+
+// SetBigInt
+// sets the ObjectType instance to hold an arbitrary-precision integer value.
+func (obj *ObjectType) SetBigInt(i *big.Int, desc string) {
+	obj.ObjectTypeId = OBJECT_TYPE_BIGINT
+	obj.ObjectValue = i
+	obj.ObjectDescriptor = desc
+}
+
+// GetBigInt
+// returns a boolean indicating success, the big integer value, and an error message if the object does not hold a big integer.
+func (obj *ObjectType) GetBigInt() (bool, *big.Int, string) {
+	if obj == nil {
+		return false, nil, "Nil object"
+	}
+	val, matches := obj.ObjectValue.(*big.Int)
+	if !matches || val == nil {
+		return false, nil, obj.mismatch("a big integer")
+	}
+	return true, val, ""
 }
 
 // Constants that are tags for the objects we recognize.
 // For everything you want to recognize add a constnat for it.
 const (
-	TokenIdentifier   = 0 // A textual identifier (not a quoted string) Must start with two alpha characters
-	TokenQuotedString = 1 // Quoted string
-	TokenUint64       = 2 // 64-bit unsigned integer
-	TokenUint32       = 3 // 32-bit unsigned integer
-	TokenUint16       = 4 // 16-bit unsigned integer
-	TokenUint8        = 5 // 8 bit unsigned integer
-	TokenRegister     = 6 // A register object "r"number
-	TokenMacro        = 7 // A macro identifier (@identifier)
+	TokenIdentifier   = 0  // A textual identifier (not a quoted string) Must start with two alpha characters, so two-letter mnemonics such as ld qualify
+	TokenQuotedString = 1  // Quoted string
+	TokenUint64       = 2  // 64-bit unsigned integer
+	TokenUint32       = 3  // 32-bit unsigned integer
+	TokenUint16       = 4  // 16-bit unsigned integer
+	TokenUint8        = 5  // 8 bit unsigned integer
+	TokenRegister     = 6  // A register object "r"number
+	TokenMacro        = 7  // A macro identifier (@identifier)
+	TokenComma        = 8  // ,
+	TokenColon        = 9  // :
+	TokenLBracket     = 10 // [
+	TokenRBracket     = 11 // ]
+	TokenLParen       = 12 // (
+	TokenRParen       = 13 // )
+	TokenPlus         = 14 // +
+	TokenMinus        = 15 // -
+	TokenBigInt       = 16 // Unsigned integer wider than 64 bits (see ParserConfig.MaxNumericDigits)
+	TokenUint128      = 17 // Template slot accepting any integer that fits in 128 bits
+	TokenUint256      = 18 // Template slot accepting any integer that fits in 256 bits
+	TokenLabelDef     = 19 // A label definition (identifier: at the start of a line)
+	TokenLabelRef     = 20 // Template slot accepting an identifier that names a label
+	TokenLabelRel     = 21 // Template slot accepting a label, resolved relative to the line's address
+	TokenExpression   = 22 // Template slot accepting an integer expression such as base+10 or (count*4)-1
+	TokenOperator     = 23 // An operator from the configured table, such as -> or :: (see ParserConfig.Operators)
+	TokenChar         = 24 // A character literal such as 'A' or '\n', holding its code point; fills integer slots it fits
+	TokenBoolean      = 25 // A boolean keyword such as true or false (see ParserConfig.BooleanKeywords), holding a bool
+	TokenEnum         = 26 // Template slot accepting one identifier of its Choices, holding the index of the choice
+	TokenFlags        = 27 // Template slot accepting its Flags joined by |, holding their values ORed together
+	TokenMemory       = 28 // Template slot accepting a memory operand such as [r2 + r3*4 + 10], holding a MemoryOperand
 
 	// TokenUnknown represents an unknown or unrecognized token type in the tokenization process.
 	TokenUnknown = 255
 )
 
-// Names for the above constants
-// For each object, this is its name
-var TokenNames = []string{
-	"Identifier",
-	"QuotedString",
-	"Uint64",
-	"Uint32",
-	"Uint16",
-	"Uint8",
-	"Register",
-	"Macro",
+// builtinTokenTypes names the above constants. It is the single table the built-in
+// registry, returned by BuiltinTokenTypes, is built from.
+var builtinTokenTypes = []struct {
+	id   int
+	name string
+}{
+	{TokenIdentifier, "Identifier"},
+	{TokenQuotedString, "QuotedString"},
+	{TokenUint64, "Uint64"},
+	{TokenUint32, "Uint32"},
+	{TokenUint16, "Uint16"},
+	{TokenUint8, "Uint8"},
+	{TokenRegister, "Register"},
+	{TokenMacro, "Macro"},
+	{TokenComma, "Comma"},
+	{TokenColon, "Colon"},
+	{TokenLBracket, "LBracket"},
+	{TokenRBracket, "RBracket"},
+	{TokenLParen, "LParen"},
+	{TokenRParen, "RParen"},
+	{TokenPlus, "Plus"},
+	{TokenMinus, "Minus"},
+	{TokenBigInt, "BigInt"},
+	{TokenUint128, "Uint128"},
+	{TokenUint256, "Uint256"},
+	{TokenLabelDef, "LabelDef"},
+	{TokenLabelRef, "LabelRef"},
+	{TokenLabelRel, "LabelRel"},
+	{TokenExpression, "Expression"},
+	{TokenOperator, "Operator"},
+	{TokenChar, "Char"},
+	{TokenBoolean, "Boolean"},
+	{TokenEnum, "Enum"},
+	{TokenFlags, "Flags"},
+	{TokenMemory, "Memory"},
+	{TokenUnknown, "Unknown"},
+}
+
+// TokenNames
+// holds the names of the built-in token types below TokenUnknown, indexed by id.
+//
+// Deprecated: index-based lookups panic for ids outside the slice, such as TokenUnknown,
+// and changes to it are not seen by the parser. Use BuiltinTokenTypes().Name or
+// Parser.TokenName instead.
+var TokenNames = builtinRegistry.names(lastBuiltinToken() + 1)
+
+// lastBuiltinToken
+// returns the highest id of the built-in token types below TokenUnknown.
+func lastBuiltinToken() int {
+	last := 0
+	for _, tt := range builtinTokenTypes {
+		if tt.id != TokenUnknown {
+			last = max(last, tt.id)
+		}
+	}
+	return last
 }
 
 // Token
-// Represents a lexical token with a type and value.
+// Represents a lexical token with a type and value. Tokens are comparable; the macro
+// expansions that produced the token a line failed at are reported in LineResult.Origin.
 type Token struct {
 	Type          int
 	ValueReceived string
+
+	raw string // Text as written, if lowercasing or substituting a constant changed ValueReceived
+}
+
+// text
+// returns the token's text as it was written in the line.
+func (token Token) text() string {
+	if token.raw != "" {
+		return token.raw
+	}
+	return token.ValueReceived
 }
 
 // TemplateObject
 // is a structure that contains template specifications for parsing input data.
+// MinValue and MaxValue optionally constrain integer and register values; the
+// range is only enforced when at least one of them is non-zero, and a zero
+// MaxValue means there is no upper bound. Hooks, if set, holds the slot's callbacks (see
+// SlotHooks). Name, if set, is the key of the slot's
+// object in ParsedLine.Named and the name guards can refer to it by. Choices lists the
+// keywords an Enum slot accepts, such as the condition codes eq, ne, lt and gt, and Flags
+// the flags a Flags slot accepts, such as read, write and exec in read|exec.
 type TemplateObject struct {
 	TemplateType  int
 	TemplateValue ObjectType
 	TemplateError string
+	MinValue      uint64
+	MaxValue      uint64
+	Sensitive     bool   // Mask the operand's value in errors, logs and serialized results
+	Group         string // Name of the capture group this slot belongs to (see Captures)
+	SameAs        int    // Earlier slot whose value this operand must repeat, 0 for none
+	DifferentFrom int    // Earlier slot whose value this operand must not repeat, 0 for none
+	Hooks         *SlotHooks
+	Name          string
+	Choices       []string
+	Flags         []Flag
+}
+
+// SlotHooks
+// holds the callbacks of a template slot, kept apart from TemplateObject so slots without
+// them stay plain values. Validate, if set, enforces a semantic rule on the operand, such
+// as "the destination must not be r0", once its type, range and back-references have been
+// checked. Matcher, if set, replaces the token the slot would take with custom matching
+// logic (see MatcherFunc). ValidateContext, if set, validates the operand with the
+// ParseContext of the line, once the whole line has matched.
+type SlotHooks struct {
+	Validate        func(ObjectType) error
+	Matcher         MatcherFunc
+	ValidateContext func(*ParseContext, ObjectType) error
+}
+
+// validate
+// returns the Validate callback of the hooks, nil if there are none.
+func (hooks *SlotHooks) validate() func(ObjectType) error {
+	if hooks == nil {
+		return nil
+	}
+	return hooks.Validate
+}
+
+// matcher
+// returns the Matcher of the hooks, nil if there are none.
+func (hooks *SlotHooks) matcher() MatcherFunc {
+	if hooks == nil {
+		return nil
+	}
+	return hooks.Matcher
+}
+
+// validateContext
+// returns the ValidateContext callback of the hooks, nil if there are none.
+func (hooks *SlotHooks) validateContext() func(*ParseContext, ObjectType) error {
+	if hooks == nil {
+		return nil
+	}
+	return hooks.ValidateContext
+}
+
+// CheckValidate
+// runs the Validate hook of the template on a matched object, returning false and an
+// error message built from the template's error text if the callback rejects it.
+// Label operands are validated before their labels are resolved, so they hold the label
+// name, and expression operands hold the expression text.
+func (tmpl *TemplateObject) CheckValidate(obj ObjectType) (bool, string) {
+	validate := tmpl.Hooks.validate()
+	if validate == nil {
+		return true, ""
+	}
+	if err := validate(obj); err != nil {
+		if tmpl.TemplateError == "" {
+			return false, err.Error()
+		}
+		return false, fmt.Sprintf("%v: %s", err, tmpl.TemplateError)
+	}
+	return true, ""
+}
+
+// HasRange
+// reports whether the template object constrains the value of its operand.
+func (tmpl *TemplateObject) HasRange() bool {
+	return tmpl.MinValue != 0 || tmpl.MaxValue != 0
+}
+
+// CheckRange
+// verifies an integer value against the template's MinValue/MaxValue, returning false
+// and an error message built from the template's error text if it falls outside.
+func (tmpl *TemplateObject) CheckRange(val uint64) (bool, string) {
+	if !tmpl.HasRange() {
+		return true, ""
+	}
+	if val < tmpl.MinValue || (tmpl.MaxValue != 0 && val > tmpl.MaxValue) {
+		shown := fmt.Sprintf("%#x", val)
+		if tmpl.Sensitive {
+			shown = RedactedValue
+		}
+		if tmpl.MaxValue == 0 {
+			return false, fmt.Sprintf("Value %s is below the minimum %#x: %s",
+				shown, tmpl.MinValue, tmpl.TemplateError)
+		}
+		return false, fmt.Sprintf("Value %s is outside the range %#x-%#x: %s",
+			shown, tmpl.MinValue, tmpl.MaxValue, tmpl.TemplateError)
+	}
+	return true, ""
+}
+
+// tokenPattern
+// pairs a compiled regular expression with the token type it produces.
+type tokenPattern struct {
+	regex     *regexp.Regexp
+	tokenType int
 }
 
 // Tokenize
 // Scans the input string and generates a slice of tokens based on predefined patterns.
 func Tokenize(input string) []Token {
-	patterns := []struct {
-		regex     *regexp.Regexp
-		tokenType int
-	}{
-		{regexp.MustCompile(`^"([^"]*)"`), TokenQuotedString},
-		{regexp.MustCompile(`^@[a-zA-Z][a-zA-z0-9_]*`), TokenMacro},
-		{regexp.MustCompile(`^[a-zA-Z][a-zA-z][a-zA-Z0-9_]*`), TokenIdentifier},
-		{regexp.MustCompile(`^[0-9a-fA-F]{9,16}`), TokenUint64},
-		{regexp.MustCompile(`^[0-9a-fA-F]{5,8}`), TokenUint32},
-		{regexp.MustCompile(`^[0-9a-fA-F]{3,4}`), TokenUint16},
-		{regexp.MustCompile(`^[0-9a-fA-F]{1,2}`), TokenUint8},
-		{regexp.MustCompile(`^r[0-9a-fA-F]*`), TokenRegister},
-	}
-
-	tokens := []Token{}
-	offset := 0
-	length := len(input)
-
-	for offset < length {
-		remaining := input[offset:]
-		found := false
-
-		for _, pattern := range patterns {
-			matches := pattern.regex.FindStringSubmatch(remaining)
-			if len(matches) > 0 {
-				tokens = append(tokens, Token{pattern.tokenType, matches[0]})
-				offset += len(matches[0])
-				found = true
-				break
+	return defaultLexer.scan(input)
+}
+
+// TokenizeInto
+// tokenizes input like Tokenize, storing the tokens in dst, whose contents are
+// overwritten, to save allocating a token slice per call in hot loops. The returned slice
+// shares dst's storage when it is large enough, so it is only valid until dst is reused.
+func TokenizeInto(dst []Token, input string) []Token {
+	return defaultLexer.scanInto(dst, input)
+}
+
+// TokenizeWithConfig
+// Scans the input string and generates a slice of tokens using the patterns enabled by the configuration.
+func TokenizeWithConfig(input string, config ParserConfig) []Token {
+	return newLexer(config).scan(input)
+}
+
+// UnescapeString
+// strips the surrounding quotes from a quoted string token and resolves the escape
+// sequences \", \\, \n, \t, \r, \0, the byte \xHH and the characters \uHHHH and
+// \UHHHHHHHH, written as UTF-8. Other characters, including multi-byte ones, are kept as
+// they are. Returns the unescaped text, a success flag and an error message.
+func UnescapeString(quoted string) (string, bool, string) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", false, "String is not quoted"
+	}
+	body := quoted[1 : len(quoted)-1]
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", false, "Unterminated escape sequence"
+		}
+		switch body[i] {
+		case '"':
+			sb.WriteByte('"')
+		case '\\':
+			sb.WriteByte('\\')
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '0':
+			sb.WriteByte(0)
+		case 'x':
+			if i+2 >= len(body) {
+				return "", false, "Incomplete hex escape sequence"
 			}
+			val, err := strconv.ParseUint(body[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", false, "Invalid hex escape sequence"
+			}
+			sb.WriteByte(byte(val))
+			i += 2
+		case 'u', 'U':
+			digits := 4
+			if body[i] == 'U' {
+				digits = 8
+			}
+			if i+digits >= len(body) {
+				return "", false, "Incomplete Unicode escape sequence"
+			}
+			val, err := strconv.ParseUint(body[i+1:i+1+digits], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(val)) {
+				return "", false, "Invalid Unicode escape sequence"
+			}
+			sb.WriteRune(rune(val))
+			i += digits
+		default:
+			return "", false, fmt.Sprintf("Unknown escape sequence \\%c", body[i])
 		}
+	}
+	return sb.String(), true, ""
+}
 
-		if !found {
-			tokens = append(tokens, Token{TokenUnknown, string(remaining[0])})
-			offset++
+// QuoteString
+// is the inverse of UnescapeString: it returns text as a quoted string literal, escaping
+// quotes, backslashes, control characters and bytes that are not valid UTF-8. Multi-byte
+// characters are written as they are.
+func QuoteString(text string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			if r, size := utf8.DecodeRuneInString(text[i:]); r != utf8.RuneError || size > 1 {
+				sb.WriteString(text[i : i+size])
+				i += size - 1
+			} else {
+				fmt.Fprintf(&sb, `\x%02x`, text[i])
+			}
+			continue
+		}
+		switch c := text[i]; c {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&sb, `\x%02x`, c)
+			} else {
+				sb.WriteByte(c)
+			}
 		}
 	}
-
-	return tokens
+	sb.WriteByte('"')
+	return sb.String()
 }
 
 // EatComments
-// Removes comments from the input string by truncating text at the first occurrence of a semicolon.
+// Removes comments from the input string by truncating text at the first semicolon
+// that is not inside a quoted string.
 func EatComments(txt string) string {
-	pos := strings.Index(txt, ";")
-	if pos > -1 {
-		return txt[:pos]
+	code, _ := SplitComment(txt)
+	return code
+}
+
+// SplitComment
+// splits a line at the first semicolon that is not inside a quoted string or a character
+// literal, returning the code before it and the comment text after it.
+func SplitComment(txt string) (string, string) {
+	inString := false
+	for i := 0; i < len(txt); i++ {
+		switch txt[i] {
+		case '\\':
+			if inString {
+				i++
+			}
+		case '"':
+			inString = !inString
+		case '\'':
+			if length := charLength(txt[i:]); !inString && length > 0 {
+				i += length - 1
+			}
+		case ';':
+			if !inString {
+				return txt[:i], txt[i+1:]
+			}
+		}
 	}
-	return txt
+	return txt, ""
 }
 
 // ParseLine
 // parses a line of text and attempts to match tokens against a list of template objects.
 func ParseLine(txt string, templateList []TemplateObject) ([]ObjectType, bool, string) {
-	// Create a list of objects
-	objList := make([]ObjectType, 0)
-	input := EatComments(strings.ToLower(txt))
-	tokens := Tokenize(input)
+	return MatchTokens(TokenizeLine(txt), templateList)
+}
+
+// TokenizeLine
+// strips the comment from a line of text and tokenizes what remains, lowercasing every
+// token except quoted strings and character literals, exactly as ParseLine does.
+func TokenizeLine(txt string) []Token {
+	return tokenizeLine(nil, txt, defaultLexer, DefaultParserConfig())
+}
+
+// MatchTokens
+// converts an already tokenized line into objects and matches them against a list of template objects.
+// This is the second half of ParseLine and allows recorded token streams to be replayed.
+func MatchTokens(tokens []Token, templateList []TemplateObject) ([]ObjectType, bool, string) {
+	return MatchTokensWithConfig(tokens, templateList, DefaultParserConfig())
+}
+
+// MatchTokensWithConfig
+// is MatchTokens using the token conversions enabled by the configuration.
+func MatchTokensWithConfig(tokens []Token, templateList []TemplateObject, config ParserConfig) ([]ObjectType, bool, string) {
+	objs, _, _, ok, errmsg := matchTokens(tokens, templateList, config)
+	return objs, ok, errmsg
+}
+
+// MatchCursor
+// matches a template list against the tokens at a cursor's position, for parsers built on
+// this package that try several templates on the same tokens, as overload resolution and
+// nested grammars do. On success the cursor is left after the tokens matched, which need
+// not be the end of the line, so the caller can go on matching; on failure it is reset to
+// where it was, so another template can be tried without tokenizing the line again.
+func MatchCursor(cursor *TokenCursor, templateList []TemplateObject) ([]ObjectType, bool, string) {
+	return MatchCursorWithConfig(cursor, templateList, DefaultParserConfig())
+}
+
+// MatchCursorWithConfig
+// is MatchCursor using the token conversions enabled by the configuration.
+func MatchCursorWithConfig(cursor *TokenCursor, templateList []TemplateObject, config ParserConfig) ([]ObjectType, bool, string) {
+	mark := cursor.Mark()
+	objs, _, _, ok, errmsg := matchCursorInto(nil, cursor, templateList, config, true)
+	if !ok {
+		cursor.Reset(mark)
+	}
+	return objs, ok, errmsg
+}
+
+// matchTokens
+// implements MatchTokensWithConfig, also returning the index of the object that failed to
+// match, or -1 if the failure does not concern a single object, and the index in tokens of
+// the token each object starts at.
+func matchTokens(tokens []Token, templateList []TemplateObject, config ParserConfig) ([]ObjectType, int, []int, bool, string) {
+	return matchTokensInto(nil, tokens, templateList, config)
+}
+
+// matchTokensInto
+// implements matchTokens, storing the objects in dst, which is allocated if it has no
+// capacity.
+func matchTokensInto(dst []ObjectType, tokens []Token, templateList []TemplateObject, config ParserConfig) ([]ObjectType, int, []int, bool, string) {
 	// If we have no tokens, stop here
 	if len(tokens) == 0 {
-		return nil, false, "No tokens found"
+		return nil, -1, nil, false, "No tokens found"
+	}
+	if config.strictUnknown() {
+		if stray := strayToken(tokens); stray >= 0 {
+			return nil, -1, nil, false, strayError(tokens[stray], tokenOffset(tokens, stray)+1)
+		}
+	}
+	if stray, errmsg := config.checkSeparators(tokens); stray >= 0 {
+		return nil, -1, nil, false, errmsg
 	}
+	return matchCursorInto(dst, NewTokenCursor(tokens), templateList, config, false)
+}
+
+// matchCursorInto
+// matches a template list against the tokens from a cursor's position, as matchTokensInto
+// does for a whole line; the starts it returns index the cursor's tokens. With prefix set
+// matching stops once every slot has an object, leaving the tokens after them to the
+// caller, instead of failing lines with tokens left over.
+func matchCursorInto(dst []ObjectType, cursor *TokenCursor, templateList []TemplateObject, config ParserConfig, prefix bool) ([]ObjectType, int, []int, bool, string) {
+	// Create a list of objects
+	objList := dst[:0]
+	if cap(objList) == 0 {
+		objList = make([]ObjectType, 0, len(templateList))
+	}
+	starts := make([]int, 0, len(templateList))
 	// For each token, process it and load an object
-	for _, token := range tokens {
+	texts := 0 // Objects whose ObjectText is set
+	for {
+		texts = cursor.noteTexts(objList, starts, texts)
+		slot := len(objList)
+		if prefix && slot == len(templateList) {
+			break
+		}
+		matcher := slot < len(templateList) && templateList[slot].Hooks.matcher() != nil
+		flags := slot < len(templateList) && templateList[slot].TemplateType == TokenFlags && !matcher
+		memory := slot < len(templateList) && templateList[slot].TemplateType == TokenMemory && !matcher
+		if cursor.Done() && !matcher && !flags && !memory {
+			break
+		}
+		starts = append(starts, int(cursor.Mark()))
+		if flags {
+			obj, ok, errmsg := matchFlags(templateList[slot], cursor)
+			objList = append(objList, obj)
+			if !ok {
+				return objList, slot, starts, false, errmsg
+			}
+			continue
+		}
+		if memory {
+			obj, ok, errmsg := matchMemory(templateList[slot], cursor, config)
+			objList = append(objList, obj)
+			if !ok {
+				return objList, slot, starts, false, errmsg
+			}
+			continue
+		}
+		if matcher {
+			obj, ok, errmsg := matchSlot(templateList[slot], cursor)
+			objList = append(objList, obj)
+			if !ok {
+				return objList, slot, starts, false, errmsg
+			}
+			continue
+		}
+		if slot < len(templateList) && config.omittedComma(templateList[slot], cursor) {
+			objList = append(objList, newObject(TokenComma, ",", ""))
+			continue
+		}
+		token, _ := cursor.Next()
 		switch token.Type {
 		case TokenIdentifier:
 			objList = append(objList,
-				ObjectType{TokenIdentifier, token.ValueReceived, ""})
+				newObject(TokenIdentifier, token.ValueReceived, ""))
 		case TokenMacro:
-			objList = append(objList, ObjectType{TokenMacro, token.ValueReceived, ""})
+			objList = append(objList, newObject(TokenMacro, token.ValueReceived, ""))
 		case TokenQuotedString:
-			objList = append(objList, ObjectType{TokenQuotedString, token.ValueReceived, ""})
+			str, ok, errmsg := UnescapeString(token.ValueReceived)
+			if !ok {
+				objList = append(objList, newObject(TokenQuotedString, "", errmsg))
+				return objList, len(objList) - 1, starts, false, "Invalid string"
+			}
+			objList = append(objList, newObject(TokenQuotedString, str, ""))
 		case TokenUint64:
-			val, err := strconv.ParseUint(token.ValueReceived, 16, 64)
+			val, err := config.parseNumber(token.ValueReceived)
 			if err != nil {
-				objList = append(objList, ObjectType{TokenUint64, 0, "The value of the register is not a valid hex number"})
-				return objList, false, "Invalid number"
+				objList = append(objList, newObject(TokenUint64, 0, "The value of the register is not a valid hex number"))
+				return objList, len(objList) - 1, starts, false, "Invalid number"
 			} else {
-				objList = append(objList, ObjectType{TokenUint64, val, ""})
+				objList = append(objList, newObject(TokenUint64, val, config.numberDescriptor(token.ValueReceived)))
 			}
 		case TokenUint32:
-			val, err := strconv.ParseUint(token.ValueReceived, 16, 64)
+			val, err := config.parseNumber(token.ValueReceived)
 			if err != nil {
-				objList = append(objList, ObjectType{TokenUint32, 0, "The value of the register is not a valid hex number"})
-				return objList, false, "Invalid number"
+				objList = append(objList, newObject(TokenUint32, 0, "The value of the register is not a valid hex number"))
+				return objList, len(objList) - 1, starts, false, "Invalid number"
 			} else {
-				objList = append(objList, ObjectType{TokenUint32, val, ""})
+				objList = append(objList, newObject(TokenUint32, val, config.numberDescriptor(token.ValueReceived)))
 			}
 		case TokenUint16:
-			val, err := strconv.ParseUint(token.ValueReceived, 16, 64)
+			val, err := config.parseNumber(token.ValueReceived)
 			if err != nil {
-				objList = append(objList, ObjectType{TokenUint16, 0, "The value of the register is not a valid hex number"})
-				return objList, false, "Invalid number"
+				objList = append(objList, newObject(TokenUint16, 0, "The value of the register is not a valid hex number"))
+				return objList, len(objList) - 1, starts, false, "Invalid number"
 			} else {
-				objList = append(objList, ObjectType{TokenUint16, val, ""})
+				objList = append(objList, newObject(TokenUint16, val, config.numberDescriptor(token.ValueReceived)))
 			}
 		case TokenUint8:
-			val, err := strconv.ParseUint(token.ValueReceived, 16, 64)
+			val, err := config.parseNumber(token.ValueReceived)
 			if err != nil {
-				objList = append(objList, ObjectType{TokenUint8, 0, "The value of the register is not a valid hex number"})
-				return objList, false, "Invalid number"
+				objList = append(objList, newObject(TokenUint8, 0, "The value of the register is not a valid hex number"))
+				return objList, len(objList) - 1, starts, false, "Invalid number"
 			} else {
-				objList = append(objList, ObjectType{TokenUint8, val, ""})
+				objList = append(objList, newObject(TokenUint8, val, config.numberDescriptor(token.ValueReceived)))
 			}
-		case TokenUnknown:
-			continue
+		case TokenComma, TokenColon, TokenLBracket, TokenRBracket,
+			TokenLParen, TokenRParen, TokenPlus, TokenMinus, TokenOperator:
+			objList = append(objList, newObject(token.Type, token.ValueReceived, ""))
+		case TokenLabelDef:
+			objList = append(objList, newObject(TokenLabelDef, strings.TrimSuffix(token.ValueReceived, ":"), ""))
+		case TokenExpression:
+			objList = append(objList, newObject(TokenExpression, token.ValueReceived, token.ValueReceived))
+		case TokenChar:
+			code, ok, errmsg := UnescapeChar(token.ValueReceived)
+			if !ok {
+				objList = append(objList, newObject(TokenChar, uint64(0), errmsg))
+				return objList, len(objList) - 1, starts, false, "Invalid character: " + errmsg
+			}
+			objList = append(objList, newObject(TokenChar, code, ""))
+		case TokenBoolean:
+			objList = append(objList, newObject(TokenBoolean, config.booleanValue(token.ValueReceived), token.ValueReceived))
+		case TokenBigInt:
+			val, ok := new(big.Int).SetString(token.ValueReceived, 16)
+			if !ok {
+				objList = append(objList, newObject(TokenBigInt, nil, "The value is not a valid hex number"))
+				return objList, len(objList) - 1, starts, false, "Invalid number"
+			}
+			objList = append(objList, newObject(TokenBigInt, val, ""))
+		default:
+			custom, found := config.customTokenType(token.Type)
+			if !found {
+				// Tokens of unregistered types produce no object
+				starts = starts[:len(starts)-1]
+				continue
+			}
+			obj, err := custom.Convert(token.ValueReceived)
+			obj.ObjectTypeId = custom.Id
+			if err != nil {
+				obj.ObjectDescriptor = err.Error()
+				objList = append(objList, obj)
+				return objList, len(objList) - 1, starts, false, fmt.Sprintf("Invalid %s: %v", custom.Name, err)
+			}
+			objList = append(objList, obj)
 		case TokenRegister:
-			val, err := strconv.ParseUint(token.ValueReceived[1:], 16, 64)
+			val, width, err := config.parseRegister(token.ValueReceived)
 			if err != nil {
-				objList = append(objList, ObjectType{TokenRegister, 0, "The value of the register is not a valid hex number"})
-				return objList, false, "Invalid number"
+				objList = append(objList, newObject(TokenRegister, 0, "The value of the register is not a valid hex number"))
+				return objList, len(objList) - 1, starts, false, "Invalid number"
 			} else {
-				objList = append(objList, ObjectType{TokenRegister, val, ""})
+				obj := newObject(TokenRegister, val, "")
+				obj.ObjectWidth = width
+				objList = append(objList, obj)
 			}
 		}
 	}
+	cursor.noteTexts(objList, starts, texts)
 	// If we find our objects and tokens don't match, let us know.
 	// It means this parsing is completely wrong
 	if len(objList) != len(templateList) {
-		return nil, false, "Object list and template list length do not match"
+		return nil, min(len(objList), len(templateList)), starts, false, "Object list and template list length do not match"
+	}
+	for idx := range objList {
+		objList[idx].ObjectSensitive = templateList[idx].Sensitive
 	}
 	for idx, _ := range objList {
-		if objList[idx].ObjectTypeId != templateList[idx].TemplateType {
-			ot := objList[idx].ObjectTypeId
-			tt := templateList[idx].TemplateType
-			return objList, false, fmt.Sprintf("Expected type (%d)%s but got type (%d)%s: %s",
-				tt, TokenNames[tt], ot, TokenNames[ot],
-				templateList[idx].TemplateError)
+		if isWideTemplate(templateList[idx].TemplateType) {
+			// Wide slots take any integer that fits; the other checks still apply
+			if ok, errmsg := widenObject(&objList[idx], templateList[idx], config); !ok {
+				return objList, idx, starts, false, errmsg
+			}
+		} else if ok, errmsg := checkSlot(&objList[idx], templateList[idx], config); !ok {
+			return objList, idx, starts, false, errmsg
+		}
+		if ok, errmsg := templateList[idx].checkOperator(objList[idx]); !ok {
+			return objList, idx, starts, false, errmsg
+		}
+		if ok, errmsg := checkBackReferences(templateList, objList, idx); !ok {
+			return objList, idx, starts, false, errmsg
 		}
+		if ok, errmsg := templateList[idx].CheckValidate(objList[idx]); !ok {
+			return objList, idx, starts, false, errmsg
+		}
+	}
+	return objList, -1, starts, true, ""
+}
+
+// checkSlot
+// converts an object to the type of the slot it fills where the slot allows it, such as a
+// character or a boolean keyword, and checks its type, width and range against the slot.
+func checkSlot(obj *ObjectType, tmpl TemplateObject, config ParserConfig) (bool, string) {
+	if tt := tmpl.TemplateType; (tt == TokenLabelRef || tt == TokenLabelRel) && obj.ObjectTypeId == TokenIdentifier {
+		obj.ObjectTypeId = tt
+	}
+	if ok, errmsg := charToInteger(obj, tmpl); !ok {
+		return false, errmsg
+	}
+	scaledToSlot(obj, tmpl)
+	booleanToIdentifier(obj, tmpl)
+	if ok, errmsg := identifierToChoice(obj, tmpl, config); !ok {
+		return false, errmsg
+	}
+	if errmsg, wide := widthError(*obj, tmpl, config); wide {
+		return false, errmsg
+	}
+	if obj.ObjectTypeId != tmpl.TemplateType {
+		return false, config.mismatchError(tmpl, obj.ObjectTypeId)
+	}
+	if val, isInt := obj.ObjectValue.(uint64); isInt {
+		return tmpl.CheckRange(val)
 	}
-	return objList, true, ""
+	return true, ""
 }
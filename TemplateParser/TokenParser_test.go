@@ -0,0 +1,183 @@
+package TemplateParser
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLineMatching(t *testing.T) {
+	templateList := []TemplateObject{
+		{TemplateType: TokenIdentifier, TemplateValue: StringObject("ldi", ""), TemplateError: "opcode"},
+		{TemplateType: TokenRegister, TemplateError: "dest"},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenUint8, TemplateError: "imm", MaxValue: 0x7f},
+	}
+	tests := []struct {
+		line string
+		ok   bool
+		want interface{}
+		err  string
+	}{
+		{"ldi r1, 10", true, uint64(0x10), ""},
+		{"LDI R1, 7f", true, uint64(0x7f), ""},
+		{"ldi r1, 10 ; load", true, uint64(0x10), ""},
+		{"ldi r1, 80", false, nil, "Value 0x80 is outside the range 0x0-0x7f: imm"},
+		{"ldi r1 10", false, nil, "Object list and template list length do not match"},
+		{"ldi r1, 10, 1", false, nil, "Object list and template list length do not match"},
+		{"ldi r1, ff1", false, nil, "Expected type (5)Uint8 but got type (0)Identifier: imm"},
+		{"ldi 1, 10", false, nil, "Expected type (6)Register but got type (5)Uint8: dest"},
+	}
+	for _, tt := range tests {
+		objs, ok, errmsg := ParseLine(tt.line, templateList)
+		if ok != tt.ok || errmsg != tt.err {
+			t.Errorf("ParseLine(%q) = %v, %q, want %v, %q", tt.line, ok, errmsg, tt.ok, tt.err)
+			continue
+		}
+		if ok && objs[3].ObjectValue != tt.want {
+			t.Errorf("ParseLine(%q) operand = %v, want %v", tt.line, objs[3].ObjectValue, tt.want)
+		}
+	}
+}
+
+func TestTemplateRange(t *testing.T) {
+	templateList := []TemplateObject{
+		{TemplateType: TokenIdentifier, TemplateValue: StringObject("out", ""), TemplateError: "opcode"},
+		{TemplateType: TokenRegister, TemplateError: "register", MaxValue: 7},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenUint8, TemplateError: "port", MinValue: 0x10},
+	}
+	tests := []struct {
+		line string
+		err  string
+	}{
+		{"out r7, 10", ""},
+		{"out r0, 7f", ""},
+		{"out r8, 10", "Value 0x8 is outside the range 0x0-0x7: register"},
+		{"out r1, f", "Value 0xf is below the minimum 0x10: port"},
+	}
+	for _, tt := range tests {
+		if _, ok, errmsg := ParseLine(tt.line, templateList); ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("ParseLine(%q) = %v, %q, want error %q", tt.line, ok, errmsg, tt.err)
+		}
+	}
+	if unbounded := (TemplateObject{TemplateType: TokenUint8}); unbounded.HasRange() {
+		t.Errorf("a slot without MinValue and MaxValue has a range")
+	}
+	if ok, _ := (&TemplateObject{MinValue: 1}).CheckRange(1 << 60); !ok {
+		t.Errorf("a zero MaxValue bounds the value")
+	}
+}
+
+func TestUnescapeString(t *testing.T) {
+	tests := []struct {
+		quoted, want, err string
+	}{
+		{`"plain"`, "plain", ""},
+		{`""`, "", ""},
+		{`"say \"hi\""`, `say "hi"`, ""},
+		{`"a\\b"`, `a\b`, ""},
+		{`"\n\t\r\0"`, "\n\t\r\x00", ""},
+		{`"\x41\x7a"`, "Az", ""},
+		{`"é\U0001F600"`, "é😀", ""},
+		{`plain`, "", "String is not quoted"},
+		{`"\x4"`, "", "Incomplete hex escape sequence"},
+		{`"\xzz"`, "", "Invalid hex escape sequence"},
+		{`"\q"`, "", `Unknown escape sequence \q`},
+	}
+	for _, tt := range tests {
+		got, ok, errmsg := UnescapeString(tt.quoted)
+		if got != tt.want || ok != (tt.err == "") || errmsg != tt.err {
+			t.Errorf("UnescapeString(%s) = %q, %v, %q, want %q, %q", tt.quoted, got, ok, errmsg, tt.want, tt.err)
+		}
+	}
+}
+
+func TestQuotedStringOperand(t *testing.T) {
+	templateList := []TemplateObject{
+		{TemplateType: TokenIdentifier, TemplateValue: StringObject("str", "")},
+		{TemplateType: TokenQuotedString, TemplateError: "text"},
+	}
+	objs, ok, errmsg := ParseLine(`str "a \"b\"; c\n" ; comment`, templateList)
+	if !ok || objs[1].ObjectValue != "a \"b\"; c\n" {
+		t.Errorf("ParseLine stored %v, %v, %q, want the unescaped string", objs, ok, errmsg)
+	}
+	if _, ok, errmsg := ParseLine(`str "bad \q"`, templateList); ok || errmsg != "Invalid string" {
+		t.Errorf("ParseLine of a bad escape = %v, %q, want Invalid string", ok, errmsg)
+	}
+}
+
+func TestSlotHooks(t *testing.T) {
+	templateList := []TemplateObject{
+		{TemplateType: TokenIdentifier, TemplateValue: StringObject("push", "")},
+		{TemplateType: TokenRegister, Hooks: &SlotHooks{
+			Matcher: func(cursor *TokenCursor) (ObjectType, error) {
+				token, ok := cursor.Next()
+				if !ok || token.Type != TokenRegister {
+					return ObjectType{}, errors.New("expected a register")
+				}
+				return StringObject(token.ValueReceived, ""), nil
+			},
+			ValidateContext: func(ctx *ParseContext, obj ObjectType) error {
+				if ctx.File == "kernel.asm" && obj.ObjectValue == "r0" {
+					return errors.New("r0 is reserved")
+				}
+				return nil
+			},
+		}},
+	}
+	p := NewParser(DefaultParserConfig())
+	if ok, errmsg := p.RegisterTemplate("push", templateList); !ok {
+		t.Fatal(errmsg)
+	}
+	tests := []struct {
+		file string
+		line string
+		err  string
+	}{
+		{"user.asm", "push r0", ""},
+		{"kernel.asm", "push r1", ""},
+		{"kernel.asm", "push r0", "r0 is reserved"},
+		{"user.asm", "push 10", "expected a register"},
+	}
+	for _, tt := range tests {
+		_, ok, errmsg := p.ParseLineContext(&ParseContext{File: tt.file}, tt.line)
+		if ok != (tt.err == "") || !strings.Contains(errmsg, tt.err) {
+			t.Errorf("%s: %q = %v, %q, want an error about %q", tt.file, tt.line, ok, errmsg, tt.err)
+		}
+	}
+}
+
+func TestObjectTextStopsAtTheLastToken(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	for _, line := range []string{"li r1 ` , 10", "li r1, 10 `"} {
+		objs, ok, errmsg := p.Parse(line)
+		if !ok {
+			t.Fatalf("Parse(%q): %s", line, errmsg)
+		}
+		var texts []string
+		for _, obj := range objs {
+			texts = append(texts, obj.ObjectText)
+		}
+		if want := []string{"li", "r1", ",", "10"}; !reflect.DeepEqual(texts, want) {
+			t.Errorf("Parse(%q) texts = %q, want %q", line, texts, want)
+		}
+	}
+}
+
+func TestObjectTextOfDirectives(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), exprGrammar)
+	result := parseTestSource(t, p, "foo equ 1 + 2\n.org 0100\n")
+	tests := [][]string{{"foo", "1 + 2"}, {"0100"}}
+	for idx, want := range tests {
+		lr := result.Lines[idx]
+		var texts []string
+		for _, obj := range lr.Objects {
+			texts = append(texts, obj.ObjectText)
+		}
+		if !lr.Ok || !reflect.DeepEqual(texts, want) {
+			t.Errorf("line %d: ok %v, texts %q, want %q", lr.LineNumber, lr.Ok, texts, want)
+		}
+	}
+}
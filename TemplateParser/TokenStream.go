@@ -0,0 +1,110 @@
+package TemplateParser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// tokenRecord
+// is the on-disk form of a single token in a recorded token stream.
+type tokenRecord struct {
+	Type  int    `json:"type"`
+	Value string `json:"value"`
+}
+
+// WriteTokenStream
+// serializes a token stream, one source line per output line, so it can be replayed
+// later with ReadTokenStream and MatchTokens. Each line is a JSON array of tokens.
+func WriteTokenStream(w io.Writer, lines [][]Token) error {
+	bw := bufio.NewWriter(w)
+	for _, tokens := range lines {
+		records := make([]tokenRecord, len(tokens))
+		for idx, token := range tokens {
+			records[idx] = tokenRecord{token.Type, token.ValueReceived}
+		}
+		data, err := json.Marshal(records)
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadTokenStream
+// reads a token stream previously written by WriteTokenStream.
+func ReadTokenStream(r io.Reader) ([][]Token, error) {
+	lines := make([][]Token, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		var records []tokenRecord
+		if err := json.Unmarshal(scanner.Bytes(), &records); err != nil {
+			return nil, fmt.Errorf("token stream line %d: %w", lineNo, err)
+		}
+		tokens := make([]Token, len(records))
+		for idx, rec := range records {
+			tokens[idx] = Token{Type: rec.Type, ValueReceived: rec.Value}
+		}
+		lines = append(lines, tokens)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// SaveTokenStream
+// writes a token stream to the named file, replacing it if it exists.
+func SaveTokenStream(path string, lines [][]Token) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTokenStream(f, lines); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadTokenStream
+// reads a token stream from the named file.
+func LoadTokenStream(path string) ([][]Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTokenStream(f)
+}
+
+// RecordLines
+// tokenizes each line the same way ParseLine does and returns the resulting token stream,
+// ready to be saved with SaveTokenStream.
+func RecordLines(lines []string) [][]Token {
+	stream := make([][]Token, len(lines))
+	for idx, line := range lines {
+		stream[idx] = TokenizeLine(line)
+	}
+	return stream
+}
+
+// ReplayTokenStream
+// runs every line of a recorded token stream through MatchTokens with the given templates.
+// The results are returned in the same order as the recorded lines.
+func ReplayTokenStream(lines [][]Token, templateList []TemplateObject) []LineResult {
+	results := make([]LineResult, len(lines))
+	for idx, tokens := range lines {
+		objs, ok, errmsg := MatchTokens(tokens, templateList)
+		results[idx] = newLineResult(DefaultParserConfig(), idx+1, objs, ok, errmsg, templateList)
+	}
+	return results
+}
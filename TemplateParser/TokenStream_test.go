@@ -0,0 +1,56 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenStreamRoundTrip(t *testing.T) {
+	lines := []string{"mov r1, r2 ; copy", `str "a\"b"`, ""}
+	stream := RecordLines(lines)
+	var buf bytes.Buffer
+	if err := WriteTokenStream(&buf, stream); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != len(lines) {
+		t.Errorf("stream has %d lines, want %d", got, len(lines))
+	}
+	got, err := ReadTokenStream(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, stream) {
+		t.Errorf("ReadTokenStream = %v, want %v", got, stream)
+	}
+
+	path := filepath.Join(t.TempDir(), "tokens.jsonl")
+	if err := SaveTokenStream(path, stream); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTokenStream(path)
+	if err != nil || !reflect.DeepEqual(loaded, stream) {
+		t.Errorf("LoadTokenStream = %v, %v, want %v", loaded, err, stream)
+	}
+}
+
+func TestReplayTokenStream(t *testing.T) {
+	stream := RecordLines([]string{"mov r1, r2", "mov r1"})
+	results := ReplayTokenStream(stream, benchmarkTemplate)
+	if len(results) != 2 || !results[0].Ok || results[1].Ok {
+		t.Fatalf("ReplayTokenStream = %+v, want the first line to match and the second to fail", results)
+	}
+	objs, ok, _ := ParseLine("mov r1, r2", benchmarkTemplate)
+	if !ok || !reflect.DeepEqual(results[0].Objects, objs) || results[1].LineNumber != 2 {
+		t.Errorf("replayed line = %+v, want the objects of ParseLine %+v", results[0], objs)
+	}
+}
+
+func TestReadTokenStreamReportsLine(t *testing.T) {
+	_, err := ReadTokenStream(strings.NewReader("[]\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadTokenStream error = %v, want one naming line 2", err)
+	}
+}
@@ -0,0 +1,141 @@
+package TemplateParser
+
+import "fmt"
+
+// TokenTypeRegistry
+// maps token type ids to names and back. Every lookup is safe for any id: ids the registry
+// does not hold get a generic name instead of failing, so error messages can always name
+// the types involved. BuiltinTokenTypes returns the built-in types and Parser.TokenTypes
+// adds a parser's custom types to them.
+type TokenTypeRegistry struct {
+	specs  []TokenSpec
+	byId   map[int]int
+	byName map[string]int
+}
+
+// builtinRegistry is the registry of the built-in token types, TokenUnknown included.
+var builtinRegistry = newBuiltinTokenTypes()
+
+// BuiltinTokenTypes
+// returns a registry of the built-in token types, TokenUnknown included. Changes to it do
+// not affect the package.
+func BuiltinTokenTypes() *TokenTypeRegistry {
+	return builtinRegistry.clone()
+}
+
+// newBuiltinTokenTypes
+// builds the registry of the built-in token types.
+func newBuiltinTokenTypes() *TokenTypeRegistry {
+	r := NewTokenTypeRegistry()
+	for _, tt := range builtinTokenTypes {
+		if err := r.Register(tt.id, tt.name, false); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// NewTokenTypeRegistry
+// returns an empty registry.
+func NewTokenTypeRegistry() *TokenTypeRegistry {
+	return &TokenTypeRegistry{byId: make(map[int]int), byName: make(map[string]int)}
+}
+
+// Register
+// adds a token type. Both its id and its name must be new to the registry.
+func (r *TokenTypeRegistry) Register(id int, name string, custom bool) error {
+	if name == "" {
+		return fmt.Errorf("token type %d has no name", id)
+	}
+	if idx, taken := r.byName[name]; taken {
+		return fmt.Errorf("token type name %s is already used by type %d", name, r.specs[idx].Id)
+	}
+	if idx, taken := r.byId[id]; taken {
+		return fmt.Errorf("token type %d is already named %s", id, r.specs[idx].Name)
+	}
+	r.byId[id] = len(r.specs)
+	r.byName[name] = len(r.specs)
+	r.specs = append(r.specs, TokenSpec{Id: id, Name: name, Custom: custom, Known: true})
+	return nil
+}
+
+// Lookup
+// returns the spec of a registered token type.
+func (r *TokenTypeRegistry) Lookup(id int) (TokenSpec, bool) {
+	idx, found := r.byId[id]
+	if !found {
+		return TokenSpec{}, false
+	}
+	return r.specs[idx], true
+}
+
+// Spec
+// returns the spec of a token type, with a generic name and Known false for ids the
+// registry does not hold: CustomN for ids from TokenCustomBase on, TokenN below.
+func (r *TokenTypeRegistry) Spec(id int) TokenSpec {
+	if spec, found := r.Lookup(id); found {
+		return spec
+	}
+	if id >= TokenCustomBase {
+		return TokenSpec{Id: id, Name: fmt.Sprintf("Custom%d", id), Custom: true}
+	}
+	return TokenSpec{Id: id, Name: fmt.Sprintf("Token%d", id)}
+}
+
+// Name
+// returns the name of a token type, or its generic name if it is not registered.
+func (r *TokenTypeRegistry) Name(id int) string {
+	return r.Spec(id).Name
+}
+
+// ByName
+// resolves a token type name to its id.
+func (r *TokenTypeRegistry) ByName(name string) (int, bool) {
+	idx, found := r.byName[name]
+	if !found {
+		return 0, false
+	}
+	return r.specs[idx].Id, true
+}
+
+// Specs
+// returns the specs of the registered types in the order they were registered.
+func (r *TokenTypeRegistry) Specs() []TokenSpec {
+	return append([]TokenSpec(nil), r.specs...)
+}
+
+// clone
+// returns a copy of the registry that can be added to without changing it.
+func (r *TokenTypeRegistry) clone() *TokenTypeRegistry {
+	c := &TokenTypeRegistry{specs: r.Specs(), byId: make(map[int]int, len(r.byId)), byName: make(map[string]int, len(r.byName))}
+	for key, idx := range r.byId {
+		c.byId[key] = idx
+	}
+	for key, idx := range r.byName {
+		c.byName[key] = idx
+	}
+	return c
+}
+
+// names
+// returns the names of the types with ids 0 to n-1, indexed by id, "" for ids not held.
+func (r *TokenTypeRegistry) names(n int) []string {
+	names := make([]string, n)
+	for id := range names {
+		if spec, found := r.Lookup(id); found {
+			names[id] = spec.Name
+		}
+	}
+	return names
+}
+
+// TokenTypes
+// returns a registry of the built-in token types and the parser's custom types, in the
+// order they were added. Changes to it do not affect the parser.
+func (p *Parser) TokenTypes() *TokenTypeRegistry {
+	r := builtinRegistry.clone()
+	for _, custom := range p.config.customTokens {
+		r.Register(custom.Id, custom.Name, true)
+	}
+	return r
+}
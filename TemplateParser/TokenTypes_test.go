@@ -0,0 +1,156 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokenSpecFallbacks(t *testing.T) {
+	p := NewParser(DefaultParserConfig())
+	custom, err := p.AddTokenType("Port", `:[0-9]+`, func(text string) (ObjectType, error) {
+		return StringObject(text, ""), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id     int
+		name   string
+		custom bool
+		known  bool
+	}{
+		{TokenIdentifier, "Identifier", false, true},
+		{TokenChar, "Char", false, true},
+		{TokenMemory, "Memory", false, true},
+		{TokenUnknown, "Unknown", false, true},
+		{custom, "Port", true, true},
+		{TokenUnknown - 1, "Token254", false, false},
+		{-1, "Token-1", false, false},
+		{TokenCustomBase + 99, "Custom355", true, false},
+	}
+	for _, tt := range tests {
+		want := TokenSpec{Id: tt.id, Name: tt.name, Custom: tt.custom, Known: tt.known}
+		if got := p.TokenSpec(tt.id); got != want {
+			t.Errorf("TokenSpec(%d) = %+v, want %+v", tt.id, got, want)
+		}
+		if got := p.TokenName(tt.id); got != want.Name {
+			t.Errorf("TokenName(%d) = %q, want %q", tt.id, got, want.Name)
+		}
+	}
+}
+
+func TestTokenTypeRegistryByName(t *testing.T) {
+	r := NewTokenTypeRegistry()
+	if err := r.Register(1, "One", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		id   int
+		name string
+	}{{1, "Other"}, {2, "One"}, {3, ""}} {
+		if err := r.Register(tt.id, tt.name, false); err == nil {
+			t.Errorf("Register(%d, %q) succeeded, want an error", tt.id, tt.name)
+		}
+	}
+	if id, found := r.ByName("One"); !found || id != 1 {
+		t.Errorf("ByName(One) = %d, %v", id, found)
+	}
+	if _, found := r.ByName("Two"); found {
+		t.Errorf("ByName(Two) found a type")
+	}
+}
+
+func TestBuiltinTokenTypesIsACopy(t *testing.T) {
+	if err := BuiltinTokenTypes().Register(TokenCustomBase, "Port", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := BuiltinTokenTypes().ByName("Port"); found {
+		t.Errorf("registering a type changed the built-in registry")
+	}
+}
+
+func TestTokenNames(t *testing.T) {
+	if len(TokenNames) != TokenMemory+1 {
+		t.Fatalf("len(TokenNames) = %d, want %d", len(TokenNames), TokenMemory+1)
+	}
+	for id, name := range TokenNames {
+		if name != "" && name != BuiltinTokenTypes().Name(id) {
+			t.Errorf("TokenNames[%d] = %s, want %s", id, name, BuiltinTokenTypes().Name(id))
+		}
+	}
+	if TokenNames[TokenMemory] != "Memory" {
+		t.Errorf("TokenNames[TokenMemory] = %q, want Memory", TokenNames[TokenMemory])
+	}
+}
+
+func TestCustomTokenNames(t *testing.T) {
+	for _, n := range []int{0, 1, 8} {
+		checkTokenNames(t, n)
+	}
+}
+
+// checkTokenNames
+// registers n custom token classes on a fresh parser and fails the test unless every
+// built-in and custom type resolves to a distinct, non-empty name, ids nobody registered
+// still get a name, colliding names are rejected and type mismatch errors name the custom
+// type involved.
+func checkTokenNames(t *testing.T, n int) {
+	t.Helper()
+	parser := NewParser(DefaultParserConfig())
+	convert := func(text string) (ObjectType, error) {
+		return StringObject(text, ""), nil
+	}
+	ids := make([]int, n)
+	for idx := range ids {
+		id, err := parser.AddTokenType(fmt.Sprintf("Class%d", idx), fmt.Sprintf(`\$c%d\$`, idx), convert)
+		if err != nil {
+			t.Fatalf("AddTokenType(Class%d) failed: %v", idx, err)
+			return
+		}
+		ids[idx] = id
+	}
+	seen := make(map[string]int)
+	for _, spec := range parser.TokenSpecs() {
+		if spec.Name == "" || !spec.Known {
+			t.Errorf("token type %d has spec %+v, want a known name", spec.Id, spec)
+		}
+		if prev, dup := seen[spec.Name]; dup {
+			t.Errorf("token types %d and %d are both named %s", prev, spec.Id, spec.Name)
+		}
+		seen[spec.Name] = spec.Id
+		if got := parser.TokenName(spec.Id); got != spec.Name {
+			t.Errorf("TokenName(%d) = %q, want %q", spec.Id, got, spec.Name)
+		}
+	}
+	for _, id := range []int{-1, TokenUnknown - 1, TokenCustomBase + n} {
+		if spec := parser.TokenSpec(id); spec.Known || spec.Name == "" {
+			t.Errorf("TokenSpec(%d) = %+v, want an unknown type with a fallback name", id, spec)
+		}
+	}
+	collisions := []string{"Uint8", "Unknown"}
+	if n > 0 {
+		collisions = append(collisions, "Class0")
+	}
+	for _, name := range collisions {
+		if _, err := parser.AddTokenType(name, `\$dup\$`, convert); err == nil {
+			t.Errorf("AddTokenType(%s) succeeded, want a name collision", name)
+		}
+	}
+	if n == 0 {
+		return
+	}
+	last := ids[n-1]
+	parser.SetTemplates([]TemplateObject{
+		{TemplateType: TokenIdentifier, TemplateValue: StringObject("op", ""), TemplateError: "opcode"},
+		{TemplateType: last, TemplateError: "operand"},
+	})
+	_, ok, errmsg := parser.Parse("op 5")
+	if ok {
+		t.Errorf("Parse(%q) succeeded, want a type mismatch", "op 5")
+		return
+	}
+	if want := fmt.Sprintf("(%d)%s", last, parser.TokenName(last)); !strings.Contains(errmsg, want) {
+		t.Errorf("Parse(%q) failed with %q, want it to name %s", "op 5", errmsg, want)
+	}
+}
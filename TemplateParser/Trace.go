@@ -0,0 +1,121 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Tracer
+// receives the spans of a parse: one per file or source (ParseFile, ParseSource), one
+// per pass (match, resolve) and one per source line (line). An OpenTelemetry tracer can
+// be adapted by starting a span in StartSpan and ending it in Span.End. ChromeTracer
+// records spans in the Chrome trace event format.
+type Tracer interface {
+	StartSpan(name string, args map[string]interface{}) Span
+}
+
+// Span
+// is a span started by a Tracer.
+type Span interface {
+	End()
+}
+
+// SetTracer
+// sets the tracer that receives the parser's spans; nil disables tracing.
+func (p *Parser) SetTracer(tracer Tracer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracer = tracer
+}
+
+// noSpan is returned when tracing is off.
+type noSpan struct{}
+
+func (noSpan) End() {}
+
+// span
+// starts a span if the parser has a tracer.
+func (p *Parser) span(name string, args map[string]interface{}) Span {
+	if p.tracer == nil {
+		return noSpan{}
+	}
+	return p.tracer.StartSpan(name, args)
+}
+
+// TraceEvent
+// is a complete ("X") event of the Chrome trace event format. Times are in microseconds.
+type TraceEvent struct {
+	Name     string                 `json:"name"`
+	Category string                 `json:"cat"`
+	Phase    string                 `json:"ph"`
+	Start    float64                `json:"ts"`
+	Duration float64                `json:"dur"`
+	Pid      int                    `json:"pid"`
+	Tid      int                    `json:"tid"`
+	Args     map[string]interface{} `json:"args,omitempty"`
+}
+
+// ChromeTracer
+// is a Tracer that records spans as Chrome trace events, which chrome://tracing and
+// Perfetto can display. It is safe for concurrent use; spans of ParseSourceParallel
+// workers are all recorded on one track and may overlap.
+type ChromeTracer struct {
+	mu     sync.Mutex
+	origin time.Time
+	events []TraceEvent
+}
+
+// NewChromeTracer
+// creates a tracer whose timestamps count from now.
+func NewChromeTracer() *ChromeTracer {
+	return &ChromeTracer{origin: time.Now()}
+}
+
+// chromeSpan
+// is a span being recorded by a ChromeTracer.
+type chromeSpan struct {
+	tracer *ChromeTracer
+	event  TraceEvent
+	start  time.Time
+}
+
+// StartSpan
+// starts recording a span.
+func (ct *ChromeTracer) StartSpan(name string, args map[string]interface{}) Span {
+	now := time.Now()
+	return &chromeSpan{ct, TraceEvent{Name: name, Category: "TemplateParser", Phase: "X",
+		Start: microseconds(now.Sub(ct.origin)), Pid: 1, Tid: 1, Args: args}, now}
+}
+
+// End
+// records the span.
+func (cs *chromeSpan) End() {
+	cs.event.Duration = microseconds(time.Since(cs.start))
+	cs.tracer.mu.Lock()
+	cs.tracer.events = append(cs.tracer.events, cs.event)
+	cs.tracer.mu.Unlock()
+}
+
+// Events
+// returns the recorded events in the order they ended.
+func (ct *ChromeTracer) Events() []TraceEvent {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return append([]TraceEvent(nil), ct.events...)
+}
+
+// WriteJSON
+// writes the recorded events as a Chrome trace file.
+func (ct *ChromeTracer) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents []TraceEvent `json:"traceEvents"`
+	}{ct.Events()})
+}
+
+// microseconds
+// converts a duration to fractional microseconds.
+func microseconds(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1000
+}
@@ -0,0 +1,45 @@
+package TemplateParser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestChromeTracerRecordsSpans(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), labelGrammar)
+	tracer := NewChromeTracer()
+	p.SetTracer(tracer)
+	parseTestSource(t, p, "start: nop\njmp start\n")
+	var names []string
+	for _, event := range tracer.Events() {
+		names = append(names, event.Name)
+		if event.Phase != "X" || event.Duration < 0 || event.Start < 0 {
+			t.Errorf("event %+v is not a complete event", event)
+		}
+	}
+	if got, want := strings.Join(names, " "), "line line match resolve ParseSource"; got != want {
+		t.Errorf("spans ended in the order %s, want %s", got, want)
+	}
+	if args := tracer.Events()[1].Args; args["line"] != 2 {
+		t.Errorf("second line span has args %v, want line 2", args)
+	}
+
+	var buf bytes.Buffer
+	if err := tracer.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		TraceEvents []TraceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil || len(doc.TraceEvents) != len(names) {
+		t.Errorf("trace file %s has %d events, want %d (%v)", buf.String(), len(doc.TraceEvents), len(names), err)
+	}
+
+	p.SetTracer(nil)
+	parseTestSource(t, p, "nop\n")
+	if len(tracer.Events()) != len(names) {
+		t.Errorf("spans were recorded after the tracer was removed")
+	}
+}
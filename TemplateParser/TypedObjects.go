@@ -0,0 +1,93 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Get
+// returns the value of an object as a T, for example Get[string](obj) or
+// Get[*big.Int](obj). Integer values may also be read as any other Go integer type, as
+// long as the value fits; anything else that is not held as a T is an error.
+func Get[T any](obj ObjectType) (T, error) {
+	var zero T
+	if val, ok := obj.ObjectValue.(T); ok {
+		return val, nil
+	}
+	target := reflect.ValueOf(&zero).Elem()
+	if fits, isInteger := convertInteger(obj.ObjectValue, target); fits {
+		return zero, nil
+	} else if isInteger {
+		return zero, fmt.Errorf("value %v does not fit in %s", obj.ObjectValue, target.Type())
+	}
+	return zero, fmt.Errorf("object holds %T, not %s", obj.ObjectValue, target.Type())
+}
+
+// GetOr
+// returns the value of an object as a T, or def if it does not hold one.
+func GetOr[T any](obj ObjectType, def T) T {
+	val, err := Get[T](obj)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// convertInteger
+// stores an integer value into an integer target if it fits. It reports whether the value
+// was stored and whether both the value and the target are integers.
+func convertInteger(value interface{}, target reflect.Value) (bool, bool) {
+	var u uint64
+	negative := false
+	switch v := value.(type) {
+	case uint64:
+		u = v
+	case int64:
+		u, negative = uint64(v), v < 0
+	case int:
+		u, negative = uint64(v), v < 0
+	default:
+		return false, false
+	}
+	switch target.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if negative || target.OverflowUint(u) {
+			return false, true
+		}
+		target.SetUint(u)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(u)
+		if (!negative && i < 0) || target.OverflowInt(i) {
+			return false, true
+		}
+		target.SetInt(i)
+	default:
+		return false, false
+	}
+	return true, true
+}
+
+// StringObject
+// returns an object holding a string, as SetString would set it.
+func StringObject(s string, desc string) ObjectType {
+	return newObject(OBJECT_TYPE_STRING, s, desc)
+}
+
+// IntegerObject
+// returns an object holding an integer.
+func IntegerObject(i uint64, desc string) ObjectType {
+	return newObject(OBJECT_TYPE_INTEGER, i, desc)
+}
+
+// BooleanObject
+// returns an object holding a boolean.
+func BooleanObject(b bool, desc string) ObjectType {
+	return newObject(OBJECT_TYPE_BOOLEAN, b, desc)
+}
+
+// BigIntObject
+// returns an object holding an arbitrary-precision integer.
+func BigIntObject(i *big.Int, desc string) ObjectType {
+	return newObject(OBJECT_TYPE_BIGINT, i, desc)
+}
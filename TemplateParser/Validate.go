@@ -0,0 +1,349 @@
+package TemplateParser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateIssueKind
+// classifies a problem Parser.Validate finds in a template set.
+type TemplateIssueKind int
+
+const (
+	IssueDuplicate    TemplateIssueKind = iota // A form registered again under its mnemonic, replacing the earlier entry
+	IssueUnreachable                           // A form no line can select, as another form always wins
+	IssueAmbiguous                             // Two forms a line can both match, as Ambiguities reports
+	IssueBadReference                          // A slot error or guard referring to a slot the form does not have
+)
+
+// templateIssueNames names the kinds for String.
+var templateIssueNames = []string{"duplicate", "unreachable", "ambiguous", "bad-reference"}
+
+// String
+// returns the name of a kind.
+func (kind TemplateIssueKind) String() string {
+	if kind >= 0 && int(kind) < len(templateIssueNames) {
+		return templateIssueNames[kind]
+	}
+	return fmt.Sprintf("TemplateIssueKind(%d)", int(kind))
+}
+
+// MarshalText
+// encodes a kind as its name, so reports read well as JSON.
+func (kind TemplateIssueKind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+// TemplateIssue
+// is a problem found in a template set. Form is the form the issue is about, in the text
+// form of TemplateSpec, and Other the form it conflicts with, if any. Slot is the slot of
+// the form the issue is about, -1 if it is about the whole form.
+type TemplateIssue struct {
+	Kind     TemplateIssueKind `json:"kind"`
+	Mnemonic string            `json:"mnemonic"`
+	Profile  string            `json:"profile,omitempty"`
+	Form     string            `json:"form"`
+	Other    string            `json:"other,omitempty"`
+	Slot     int               `json:"slot"`
+	Message  string            `json:"message"`
+}
+
+// String
+// describes the issue.
+func (issue TemplateIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", issue.Kind, issue.Mnemonic, issue.Message)
+}
+
+// TemplateReport
+// is the result of Parser.Validate, its issues grouped by kind in the order of the kinds,
+// then by mnemonic in registration order.
+type TemplateReport struct {
+	Issues []TemplateIssue `json:"issues"`
+}
+
+// Ok
+// reports whether the template set has no issues.
+func (report *TemplateReport) Ok() bool {
+	return len(report.Issues) == 0
+}
+
+// Count
+// returns the number of issues of a kind.
+func (report *TemplateReport) Count(kind TemplateIssueKind) int {
+	n := 0
+	for _, issue := range report.Issues {
+		if issue.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// String
+// lists the issues, one per line.
+func (report *TemplateReport) String() string {
+	lines := make([]string, len(report.Issues))
+	for idx, issue := range report.Issues {
+		lines[idx] = issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate
+// checks the registered template set, as a sanity check for large hand-written tables.
+// It reports entries that replaced an earlier entry of the same mnemonic, profile,
+// options and form when they were registered, forms that can never be selected, pairs of
+// forms that overlap as Ambiguities finds them, and slot errors and guards that refer to
+// a slot the form does not have.
+//
+// Without overloads the parser selects the first available entry of a mnemonic, so an
+// entry is unreachable when an earlier entry of its profile requires a subset of its
+// options. With overloads every available form is tried and a line matching two is
+// ambiguous, so a form is unreachable when another form available whenever it is accepts
+// every line it does. Forms using Matcher or Validate slots, other than keywords, or
+// guards are never taken to accept every line of another form. Slot errors are checked
+// for references such as "operand 2" or "second operand", counting the slots from the
+// mnemonic at 0 or the operands, punctuation left out, from 1: the error of an operand
+// slot must refer to that operand, those of other slots to some slot of the form. Guards
+// are checked for $N and names the form does not define.
+func (p *Parser) Validate() *TemplateReport {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	report := &TemplateReport{Issues: append([]TemplateIssue(nil), p.duplicates...)}
+	if report.Issues == nil {
+		report.Issues = make([]TemplateIssue, 0)
+	}
+	unreachable := make(map[[2]*TemplateEntry]bool)
+	for _, name := range p.mnemonics {
+		variants := p.registry[name]
+		for j, entry := range variants {
+			for i, other := range variants {
+				if i == j || !p.shadows(other, entry, i < j) {
+					continue
+				}
+				unreachable[[2]*TemplateEntry{other, entry}] = true
+				unreachable[[2]*TemplateEntry{entry, other}] = true
+				why := "the earlier form %s is available whenever it is"
+				if p.config.Overloads {
+					why = "form %s accepts every line it matches and is available whenever it is"
+				}
+				report.Issues = append(report.Issues, p.entryIssue(IssueUnreachable, entry, other,
+					fmt.Sprintf("form %s is never selected: "+why, p.formLabel(entry), p.formLabel(other))))
+				break
+			}
+		}
+	}
+	for _, name := range p.mnemonics {
+		variants := p.registry[name]
+		for i := 0; i < len(variants); i++ {
+			for j := i + 1; j < len(variants); j++ {
+				if !unreachable[[2]*TemplateEntry{variants[i], variants[j]}] && p.overlap(variants[i], variants[j]) {
+					report.Issues = append(report.Issues, p.entryIssue(IssueAmbiguous, variants[j], variants[i],
+						fmt.Sprintf("forms %s and %s overlap", p.formLabel(variants[i]), p.formLabel(variants[j]))))
+				}
+			}
+		}
+	}
+	for _, name := range p.mnemonics {
+		for _, entry := range p.registry[name] {
+			report.Issues = append(report.Issues, p.referenceIssues(entry)...)
+		}
+	}
+	return report
+}
+
+// entryIssue
+// returns an issue about an entry, conflicting with other unless it is nil.
+func (p *Parser) entryIssue(kind TemplateIssueKind, entry *TemplateEntry, other *TemplateEntry, message string) TemplateIssue {
+	issue := TemplateIssue{Kind: kind, Mnemonic: entry.Name, Profile: entry.Profile,
+		Form: p.config.templateSpec(entry.form()), Slot: -1, Message: message}
+	if other != nil {
+		issue.Other = p.config.templateSpec(other.form())
+	}
+	return issue
+}
+
+// formLabel
+// names the form of an entry in messages, with the options it requires.
+func (p *Parser) formLabel(entry *TemplateEntry) string {
+	label := p.config.templateSpec(entry.form())
+	if len(entry.RequiredOptions) == 0 {
+		return label
+	}
+	options := make([]string, 0, len(entry.RequiredOptions))
+	for _, name := range sortedOptionNames(entry.RequiredOptions) {
+		options = append(options, name+"="+entry.RequiredOptions[name])
+	}
+	return label + " (" + strings.Join(options, ", ") + ")"
+}
+
+// recordDuplicate
+// records, for Validate, that an entry being registered replaces an existing entry with
+// the same form.
+func (p *Parser) recordDuplicate(existing *TemplateEntry, entry *TemplateEntry) {
+	if !sameForm(existing.form(), entry.form()) {
+		return
+	}
+	p.duplicates = append(p.duplicates, p.entryIssue(IssueDuplicate, entry, existing,
+		fmt.Sprintf("form %s is registered twice; the later entry replaced the earlier one",
+			p.config.templateSpec(entry.form()))))
+}
+
+// overlap
+// reports whether two entries of a mnemonic are in the ambiguity Ambiguities reports.
+func (p *Parser) overlap(a *TemplateEntry, b *TemplateEntry) bool {
+	return compatibleOptions(a.RequiredOptions, b.RequiredOptions) && p.compatibleProfiles(a.Profile, b.Profile) &&
+		formsOverlap(a.form(), b.form())
+}
+
+// shadows
+// reports whether entry other keeps entry from ever being selected: other is available
+// whenever entry is, and is either tried first, earlier being set when it is registered
+// before entry, or, with overloads, accepts every line entry accepts.
+func (p *Parser) shadows(other *TemplateEntry, entry *TemplateEntry, earlier bool) bool {
+	if other.Profile != entry.Profile || !optionsSubset(other.RequiredOptions, entry.RequiredOptions) {
+		return false
+	}
+	if !p.config.Overloads {
+		return earlier
+	}
+	return len(other.Guards) == 0 && formCovers(other.form(), entry.form())
+}
+
+// optionsSubset
+// reports whether every option required by a is required with the same value by b, so
+// that a is satisfied whenever b is.
+func optionsSubset(a map[string]string, b map[string]string) bool {
+	for name, value := range a {
+		if other, found := b[name]; !found || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// formCovers
+// reports whether template list a accepts every line template list b accepts.
+func formCovers(a []TemplateObject, b []TemplateObject) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := 1; idx < len(a); idx++ {
+		if !slotCovers(a[idx], b[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// slotCovers
+// reports whether template slot a accepts every token slot b accepts. Slots whose
+// callbacks or back references cannot be analyzed never cover another, except keyword
+// slots, whose word is known.
+func slotCovers(a TemplateObject, b TemplateObject) bool {
+	if a.TemplateType != b.TemplateType || a.Hooks.matcher() != nil || a.Hooks.validateContext() != nil ||
+		a.SameAs != 0 || a.DifferentFrom != 0 {
+		return false
+	}
+	if word := slotWord(a); word != "" {
+		return strings.EqualFold(word, slotWord(b))
+	}
+	if a.Hooks.validate() != nil {
+		return false
+	}
+	if a.TemplateType == TokenEnum {
+		return choicesCover(a, b)
+	}
+	if a.TemplateType == TokenFlags {
+		return flagsCover(a, b)
+	}
+	if a.HasRange() {
+		return b.HasRange() && a.MinValue <= b.MinValue && maxValue(b) <= maxValue(a)
+	}
+	return true
+}
+
+var (
+	// numberedSlot finds references such as "operand 2" or "slot #3" in slot errors
+	numberedSlot = regexp.MustCompile(`(?i)\b(operand|slot|argument|arg|parameter|param)\s+#?(\d+)\b`)
+	// ordinalSlot finds references such as "second operand" in slot errors
+	ordinalSlot = regexp.MustCompile(`(?i)\b(first|second|third|fourth|fifth|sixth|seventh|eighth)\s+(operand|slot|argument|arg|parameter|param)\b`)
+)
+
+// ordinals gives the number each ordinal word ordinalSlot finds stands for.
+var ordinals = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6, "seventh": 7, "eighth": 8}
+
+// referenceIssues
+// returns the issues of the slot errors and guards of an entry that refer to slots it
+// does not have.
+func (p *Parser) referenceIssues(entry *TemplateEntry) []TemplateIssue {
+	var issues []TemplateIssue
+	operands := 0
+	for _, slot := range entry.Objects[1:] {
+		if !isPunctuation(slot.TemplateType) {
+			operands++
+		}
+	}
+	operand := 0
+	for idx, slot := range entry.Objects {
+		isOperand := idx > 0 && !isPunctuation(slot.TemplateType)
+		if isOperand {
+			operand++
+		}
+		for _, ref := range slotReferences(slot.TemplateError) {
+			// The errors of operands must name the operand itself; those of the mnemonic and
+			// punctuation, such as "comma expected after operand 1", any slot of the form
+			if ref == idx || (isOperand && ref == operand) ||
+				(!isOperand && (ref < len(entry.Objects) || (ref >= 1 && ref <= operands))) {
+				continue
+			}
+			issue := p.entryIssue(IssueBadReference, entry, nil,
+				fmt.Sprintf("the error of slot %d, %q, refers to operand %d", idx, slot.TemplateError, ref))
+			issue.Slot = idx
+			issues = append(issues, issue)
+			break
+		}
+	}
+	names := make(map[string]bool)
+	for _, slot := range entry.Objects {
+		names[slot.TemplateValue.ObjectDescriptor] = true
+		names[slot.Name] = true
+	}
+	for _, guard := range entry.Guards {
+		if guard.compiled == nil {
+			continue
+		}
+		for _, name := range guard.compiled.Names() {
+			if slot, isSlot := strings.CutPrefix(name, "$"); isSlot {
+				if n, err := strconv.Atoi(slot); err == nil && n >= 0 && n < len(entry.Objects) {
+					continue
+				}
+			} else if names[name] {
+				continue
+			}
+			issues = append(issues, p.entryIssue(IssueBadReference, entry, nil,
+				fmt.Sprintf("guard %q refers to %s, which the form does not have", guard.Expr, name)))
+		}
+	}
+	return issues
+}
+
+// slotReferences
+// returns the slot numbers a slot error refers to.
+func slotReferences(text string) []int {
+	if text == "" {
+		return nil
+	}
+	var refs []int
+	for _, match := range numberedSlot.FindAllStringSubmatch(text, -1) {
+		if n, err := strconv.Atoi(match[2]); err == nil {
+			refs = append(refs, n)
+		}
+	}
+	for _, match := range ordinalSlot.FindAllStringSubmatch(text, -1) {
+		refs = append(refs, ordinals[strings.ToLower(match[1])])
+	}
+	return refs
+}
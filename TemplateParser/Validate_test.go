@@ -0,0 +1,95 @@
+package TemplateParser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateDuplicatesAndUnreachable(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), `templates:
+  - mnemonic: mov
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+  - mnemonic: mov
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+  - mnemonic: fadd
+    operands:
+      - {type: Identifier}
+  - mnemonic: fadd
+    requires: {fpu: "on"}
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+`)
+	report := p.Validate()
+	if report.Ok() || report.Count(IssueDuplicate) != 1 || report.Count(IssueUnreachable) != 1 || len(report.Issues) != 2 {
+		t.Fatalf("Validate() =\n%s\nwant a duplicate and an unreachable form", report)
+	}
+	if issue := report.Issues[0]; issue.Kind != IssueDuplicate || issue.Mnemonic != "mov" || issue.Slot != -1 {
+		t.Errorf("first issue = %+v, want the duplicate mov", issue)
+	}
+	if issue := report.Issues[1]; issue.Kind != IssueUnreachable || issue.Mnemonic != "fadd" || !strings.Contains(issue.Message, "(fpu=on) is never selected") {
+		t.Errorf("second issue = %+v, want the fadd form requiring the fpu", issue)
+	}
+}
+
+func TestValidateOverloads(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Overloads = true
+	p := newTestParser(t, config, overloadGrammar+`  - mnemonic: b
+    operands:
+      - {type: Identifier}
+      - {type: Enum, choices: [eq, ne, lt]}
+  - mnemonic: b
+    operands:
+      - {type: Identifier}
+      - {type: Enum, choices: [eq, ne]}
+`)
+	report := p.Validate()
+	if report.Count(IssueAmbiguous) != 1 || report.Count(IssueUnreachable) != 1 || len(report.Issues) != 2 {
+		t.Fatalf("Validate() =\n%s\nwant the jmp forms ambiguous and a b form unreachable", report)
+	}
+	if issue := report.Issues[0]; issue.Mnemonic != "b" || issue.Form != "<Identifier> <eq|ne>" || issue.Other != "<Identifier> <eq|ne|lt>" {
+		t.Errorf("unreachable issue = %+v", issue)
+	}
+	if issue := report.Issues[1]; issue.Kind != IssueAmbiguous || issue.Mnemonic != "jmp" {
+		t.Errorf("ambiguous issue = %+v", issue)
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	p := newTestParser(t, DefaultParserConfig(), `templates:
+  - mnemonic: add
+    operands:
+      - {type: Identifier}
+      - {type: Register, error: "operand 1 must be a register"}
+      - {type: Comma, error: "comma expected after operand 1"}
+      - {type: Register, error: "the fourth operand must be a register"}
+    guards:
+      - {expr: "$1 != $5"}
+`)
+	report := p.Validate()
+	if report.Count(IssueBadReference) != 2 || len(report.Issues) != 2 {
+		t.Fatalf("Validate() =\n%s\nwant two bad references", report)
+	}
+	if issue := report.Issues[0]; issue.Slot != 3 || !strings.Contains(issue.Message, "refers to operand 4") {
+		t.Errorf("slot error issue = %+v", issue)
+	}
+	if issue := report.Issues[1]; issue.Slot != -1 || !strings.Contains(issue.Message, "refers to $5") {
+		t.Errorf("guard issue = %+v", issue)
+	}
+	data, err := json.Marshal(report.Issues[0])
+	if err != nil || !strings.Contains(string(data), `"kind":"bad-reference"`) {
+		t.Errorf("issue JSON = %s, %v, want the kind by name", data, err)
+	}
+}
+
+func TestValidateCleanSet(t *testing.T) {
+	if report := newTestParser(t, DefaultParserConfig(), exprGrammar).Validate(); !report.Ok() || report.String() != "" {
+		t.Errorf("Validate() = %s, want no issues", report)
+	}
+}
@@ -0,0 +1,131 @@
+package TemplateParser
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are the seed corpus of the fuzz targets: benchmarkLines and lines probing the
+// edges of the tokenizer.
+var fuzzSeeds = append(append([]string(nil), benchmarkLines...),
+	"",
+	"loop: ldi r1, ffffffffffffffff",
+	"ldi r1, 10000000000000000",
+	"str \"unterminated",
+	"str \"\\x4\", \"\\\"\"",
+	"mov r1,, r2 ; ; ;",
+	"\t@macro(r1, [r2 + 4])",
+	"org 8000",
+	"count equ ff",
+	"le r1, (0x10*2)-zz",
+	"\x00\xff\xfe",
+)
+
+// fuzzParser
+// returns a parser with the templates of benchmarkLines registered.
+func fuzzParser() *Parser {
+	parser := NewParser(DefaultParserConfig())
+	for _, name := range []string{"mov", "add"} {
+		parser.RegisterTemplate(name, benchmarkTemplate)
+	}
+	parser.RegisterTemplate("ldi", []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenRegister},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenUint64},
+	})
+	parser.RegisterTemplate("jmp", []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenLabelRef},
+	})
+	parser.RegisterTemplate("le", []TemplateObject{
+		{TemplateType: TokenIdentifier},
+		{TemplateType: TokenRegister},
+		{TemplateType: TokenComma},
+		{TemplateType: TokenExpression},
+	})
+	return parser
+}
+
+// FuzzTokenize checks that the tokenizer does not panic and that the values of the
+// tokens it returns concatenate to the input.
+func FuzzTokenize(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	parser := fuzzParser()
+	f.Fuzz(func(t *testing.T, input string) {
+		var sb strings.Builder
+		for _, token := range parser.Tokenize(input) {
+			sb.WriteString(token.ValueReceived)
+		}
+		if sb.String() != input {
+			t.Errorf("Tokenize(%q) values concatenate to %q", input, sb.String())
+		}
+		TokenizeLine(input)
+	})
+}
+
+// FuzzParseLine checks that ParseLine and a parser do not panic, that failed lines carry
+// an error whose column lies within the line, and that the accessors of every object
+// returned are safe to call and read the value it holds.
+func FuzzParseLine(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	parser := fuzzParser()
+	f.Fuzz(func(t *testing.T, line string) {
+		objs, _, _ := ParseLine(line, benchmarkTemplate)
+		checkObjects(t, line, objs)
+		result, err := parser.ParseSource(strings.NewReader(line))
+		if err != nil {
+			return
+		}
+		for _, lr := range result.Lines {
+			checkObjects(t, line, lr.Objects)
+			if lr.Ok {
+				continue
+			}
+			if lr.Error == "" {
+				t.Errorf("line %q failed without an error", line)
+			}
+			if lr.ErrorColumn < 0 || lr.ErrorColumn > len(lr.RawText)+1 {
+				t.Errorf("line %q failed at column %d, outside the line", line, lr.ErrorColumn)
+			}
+			if diagnostic := NewDiagnostic(lr); diagnostic.String() == "" {
+				t.Errorf("line %q has an empty diagnostic", line)
+			}
+		}
+	})
+}
+
+// checkObjects
+// calls every accessor of the objects, which must not panic whatever they hold, and fails
+// the test if the accessor for the kind of value an object holds does not read it.
+func checkObjects(t *testing.T, line string, objs []ObjectType) {
+	for idx := range objs {
+		obj := &objs[idx]
+		okString, _, _ := obj.GetString()
+		okInteger, _, _ := obj.GetInteger()
+		okBoolean, _, _ := obj.GetBoolean()
+		okBigInt, _, _ := obj.GetBigInt()
+		obj.DisplayValue()
+		var read bool
+		switch obj.ObjectValue.(type) {
+		case string:
+			read = okString
+		case uint64:
+			read = okInteger
+		case bool:
+			read = okBoolean
+		case *big.Int:
+			read = okBigInt
+		default:
+			continue
+		}
+		if !read {
+			t.Errorf("line %q: object %d holding %T cannot be read by its accessor", line, idx, obj.ObjectValue)
+		}
+	}
+}
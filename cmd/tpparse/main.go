@@ -0,0 +1,174 @@
+// Command tpparse works with grammars built on the TemplateParser package.
+//
+// Usage:
+//
+//	tpparse parse [-grammar file] [-overloads] [-profile name] [-json] [-dump-tokens] [-trace] file...
+//	tpparse validate [-grammar file] [-overloads] [-profile name] [-json] [-dump-tokens] [-trace] [file...]
+//	tpparse init [-format yaml|json] [-force] [dir]
+//	tpparse serve [-addr host:port]
+//	tpparse service [-addr host:port]
+//	tpparse repl [-grammar file] [-overloads] [-profile name]
+//
+// parse loads a JSON or YAML grammar, grammar.yaml by default, parses the source files
+// and prints their matched lines, with a diagnostic on standard error for every failed
+// line. validate checks the grammar and the files the same way but only reports
+// diagnostics. With -json both write a JSON report to standard output instead.
+// -dump-tokens shows the tokens of every source line, in the report with -json, and
+// -trace logs how every line is matched to standard error: its tokens, the templates
+// tried and why they were rejected.
+// -overloads keeps templates sharing a mnemonic as overloads, and validate warns about
+// the problems Parser.Validate finds in the grammar, such as duplicate forms and forms
+// that can match the same line. -profile matches the files in a profile of the grammar
+// until an .arch directive selects another. They exit with 0 when every line parsed, 1
+// when some line failed and 2 when the arguments, grammar or files could not be used.
+//
+// init writes a starter grammar, a sample source file and a Go main wiring a Parser into
+// dir, the current directory by default. serve runs the grammar playground, a web page
+// parsing sample input with a grammar as both are edited. service runs the parse service
+// of package service, which keeps the grammars uploaded to it and parses the sources
+// posted to it. repl parses lines typed on standard input one at a time with a grammar;
+// .help lists its meta-commands.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+	"github.com/jantypas/TemplateParser/serve"
+	"github.com/jantypas/TemplateParser/service"
+)
+
+// usage is printed when no or an unknown command is given.
+const usage = `usage: tpparse <command> [arguments]
+
+commands:
+  parse     parse source files with a grammar and print the matched lines
+  validate  check a grammar and source files, reporting only diagnostics
+  init      generate a starter grammar, sample source and Go main
+  serve     run the grammar playground in a web browser
+  service   run the HTTP parse service for uploaded grammars
+  repl      parse lines typed on standard input interactively
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "parse":
+		os.Exit(runParse(os.Args[2:]))
+	case "validate":
+		os.Exit(runValidate(os.Args[2:]))
+	case "init":
+		os.Exit(runInit(os.Args[2:]))
+	case "serve":
+		os.Exit(runServe(os.Args[2:]))
+	case "service":
+		os.Exit(runService(os.Args[2:]))
+	case "repl":
+		os.Exit(runREPL(os.Args[2:]))
+	default:
+		fmt.Fprintf(os.Stderr, "tpparse: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// runInit
+// implements tpparse init and returns the exit code.
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	format := flags.String("format", "yaml", "grammar file format: yaml or json")
+	force := flags.Bool("force", false, "overwrite existing files")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	dir := "."
+	if flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "tpparse init: too many arguments")
+		return 2
+	} else if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	paths, err := TemplateParser.WriteScaffold(dir, *format, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tpparse init: %v\n", err)
+		return 1
+	}
+	for _, path := range paths {
+		fmt.Println("created", path)
+	}
+	return 0
+}
+
+// runServe
+// implements tpparse serve and returns the exit code.
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "tpparse serve: too many arguments")
+		return 2
+	}
+	fmt.Printf("playground at http://%s/\n", *addr)
+	if err := serve.ListenAndServe(*addr); err != nil {
+		fmt.Fprintf(os.Stderr, "tpparse serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runService
+// implements tpparse service and returns the exit code.
+func runService(args []string) int {
+	flags := flag.NewFlagSet("service", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8081", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "tpparse service: too many arguments")
+		return 2
+	}
+	fmt.Printf("parse service at http://%s/grammars\n", *addr)
+	if err := service.New(TemplateParser.DefaultParserConfig()).ListenAndServe(*addr); err != nil {
+		fmt.Fprintf(os.Stderr, "tpparse service: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runREPL
+// implements tpparse repl and returns the exit code.
+func runREPL(args []string) int {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	grammar := flags.String("grammar", "grammar.yaml", "grammar file, JSON if its name ends in .json and YAML otherwise")
+	overloads := flags.Bool("overloads", false, "keep templates sharing a mnemonic as overloads")
+	profile := flags.String("profile", "", "grammar profile to match lines in until an .arch directive selects another")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "tpparse repl: too many arguments")
+		return 2
+	}
+	parser, err := loadGrammar(*grammar, *overloads)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tpparse repl: %v\n", err)
+		return 2
+	}
+	if ok, errmsg := parser.SetProfile(*profile); !ok {
+		fmt.Fprintf(os.Stderr, "tpparse repl: %s\n", errmsg)
+		return 2
+	}
+	if err := TemplateParser.NewREPL(parser).Run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "tpparse repl: %v\n", err)
+		return 1
+	}
+	return 0
+}
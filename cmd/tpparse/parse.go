@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+// Exit codes of parse and validate.
+const (
+	exitOk       = 0 // Every line parsed
+	exitErrors   = 1 // Some line failed; diagnostics were reported
+	exitProblems = 2 // Bad arguments, or a grammar or source that could not be read
+)
+
+// fileReport
+// is the JSON form of one parsed file.
+type fileReport struct {
+	File        string                      `json:"file"`
+	Ok          bool                        `json:"ok"`
+	Lines       []TemplateParser.LineResult `json:"lines,omitempty"`
+	Diagnostics []TemplateParser.Diagnostic `json:"diagnostics"`
+	Tokens      [][]TemplateParser.Token    `json:"tokens,omitempty"`
+}
+
+// report
+// is the JSON document written by -json.
+type report struct {
+	Grammar string       `json:"grammar"`
+	Ok      bool         `json:"ok"`
+	Files   []fileReport `json:"files"`
+}
+
+// parseOptions
+// holds the flags shared by parse and validate.
+type parseOptions struct {
+	grammar    string
+	json       bool
+	dumpTokens bool
+	trace      bool
+	overloads  bool
+	profile    string
+	lines      bool // Print the matched lines; parse sets it, validate does not
+}
+
+// runParse
+// implements tpparse parse and returns the exit code.
+func runParse(args []string) int {
+	return runFiles("parse", args, true)
+}
+
+// runValidate
+// implements tpparse validate and returns the exit code.
+func runValidate(args []string) int {
+	return runFiles("validate", args, false)
+}
+
+// runFiles
+// loads the grammar named by the flags and parses every file named by the arguments,
+// reporting diagnostics and, when lines is set, the matched lines.
+func runFiles(command string, args []string, lines bool) int {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	opts := parseOptions{lines: lines}
+	flags.StringVar(&opts.grammar, "grammar", "grammar.yaml", "grammar file, JSON if its name ends in .json and YAML otherwise")
+	flags.BoolVar(&opts.json, "json", false, "write a JSON report to standard output")
+	flags.BoolVar(&opts.dumpTokens, "dump-tokens", false, "print the tokens of every source line")
+	flags.BoolVar(&opts.trace, "trace", false, "log how every line is matched to standard error")
+	flags.BoolVar(&opts.overloads, "overloads", false, "keep templates sharing a mnemonic as overloads")
+	flags.StringVar(&opts.profile, "profile", "", "grammar profile to match lines in until an .arch directive selects another")
+	if err := flags.Parse(args); err != nil {
+		return exitProblems
+	}
+	parser, err := loadGrammar(opts.grammar, opts.overloads)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tpparse %s: %v\n", command, err)
+		return exitProblems
+	}
+	if ok, errmsg := parser.SetProfile(opts.profile); !ok {
+		fmt.Fprintf(os.Stderr, "tpparse %s: %s\n", command, errmsg)
+		return exitProblems
+	}
+	if opts.trace {
+		parser.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+	if command == "validate" {
+		for _, issue := range parser.Validate().Issues {
+			fmt.Fprintf(os.Stderr, "%s: warning: %s\n", opts.grammar, issue)
+		}
+	}
+	if flags.NArg() == 0 && command == "parse" {
+		fmt.Fprintf(os.Stderr, "tpparse %s: no source files\n", command)
+		return exitProblems
+	}
+	doc := report{Grammar: opts.grammar, Ok: true, Files: make([]fileReport, 0, flags.NArg())}
+	code := exitOk
+	for _, path := range flags.Args() {
+		file, err := parseFile(parser, path, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tpparse %s: %v\n", command, err)
+			return exitProblems
+		}
+		if !file.Ok {
+			doc.Ok, code = false, exitErrors
+		}
+		doc.Files = append(doc.Files, file)
+	}
+	if opts.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			fmt.Fprintf(os.Stderr, "tpparse %s: %v\n", command, err)
+			return exitProblems
+		}
+	}
+	return code
+}
+
+// loadGrammar
+// creates a parser and registers the templates of a grammar file.
+func loadGrammar(path string, overloads bool) (*TemplateParser.Parser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	config := TemplateParser.DefaultParserConfig()
+	config.Overloads = overloads
+	parser := TemplateParser.NewParser(config)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = parser.LoadTemplatesFromJSON(f)
+	} else {
+		err = parser.LoadTemplatesFromYAML(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return parser, nil
+}
+
+// parseFile
+// parses one source file, printing its tokens, lines and diagnostics unless the report is
+// written as JSON.
+func parseFile(parser *TemplateParser.Parser, path string, opts parseOptions) (fileReport, error) {
+	file := fileReport{File: path}
+	if opts.dumpTokens {
+		tokens, err := readTokens(parser, path)
+		if err != nil {
+			return file, err
+		}
+		if opts.json {
+			file.Tokens = tokens
+		} else {
+			printTokens(os.Stdout, parser, path, tokens)
+		}
+	}
+	result, err := parser.ParseFile(path)
+	if err != nil {
+		return file, err
+	}
+	file.Ok = result.Ok()
+	file.Diagnostics = append(result.Diagnostics(), result.Warnings()...)
+	if opts.json {
+		if opts.lines {
+			file.Lines = result.Lines
+		}
+		return file, nil
+	}
+	if opts.lines {
+		printLines(os.Stdout, result.Lines)
+	}
+	TemplateParser.WriteDiagnostics(os.Stderr, TemplateParser.TextFormatter{}, file.Diagnostics)
+	return file, nil
+}
+
+// readTokens
+// tokenizes every line of a file, blank lines included, so the index of a line's tokens is
+// its line number less one.
+func readTokens(parser *TemplateParser.Parser, path string) ([][]TemplateParser.Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	lines, _, err := TemplateParser.ReadLines(f)
+	tokens := make([][]TemplateParser.Token, len(lines))
+	for idx, line := range lines {
+		tokens[idx] = parser.TokenizeLine(line)
+	}
+	return tokens, err
+}
+
+// printTokens
+// writes the tokens of each non-blank line as file:line: Type(text) ...
+func printTokens(w io.Writer, parser *TemplateParser.Parser, path string, tokens [][]TemplateParser.Token) {
+	for idx, line := range tokens {
+		parts := make([]string, 0, len(line))
+		for _, token := range line {
+			if token.Type == TemplateParser.TokenUnknown && strings.TrimSpace(token.ValueReceived) == "" {
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s(%s)", parser.TokenName(token.Type), token.ValueReceived))
+		}
+		if len(parts) > 0 {
+			fmt.Fprintf(w, "%s:%d: %s\n", path, idx+1, strings.Join(parts, " "))
+		}
+	}
+}
+
+// printLines
+// writes each matched instruction line with its address, mnemonic and operands.
+func printLines(w io.Writer, lines []TemplateParser.LineResult) {
+	for _, line := range lines {
+		if !line.Ok || line.Mnemonic == "" {
+			continue
+		}
+		operands := make([]string, len(line.Operands))
+		for idx, operand := range line.Operands {
+			operands[idx] = operand.Object.DisplayValue()
+		}
+		fmt.Fprintf(w, "%04x %-6s %s\n", line.Address, line.Mnemonic, strings.Join(operands, ", "))
+	}
+}
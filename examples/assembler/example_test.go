@@ -0,0 +1,20 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+	"github.com/jantypas/TemplateParser/templatetest"
+)
+
+// TestExpectedOutput runs the example on its embedded input and diffs the output against
+// expected.txt.
+func TestExpectedOutput(t *testing.T) {
+	var out bytes.Buffer
+	sources := []TemplateParser.BuildSource{embeddedSource("main.asm"), embeddedSource("lib.asm")}
+	if err := assemble(&out, sources, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	templatetest.AssertOutput(t, out.Bytes(), "expected.txt")
+}
@@ -0,0 +1,3 @@
+; Library routine shared by the program: keeps the result in r4 and stops.
+finish: mov r4, r2
+        hlt
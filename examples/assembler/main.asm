@@ -0,0 +1,13 @@
+; Counts down from count, adding step to r2 on every pass, then jumps to the
+; library routine in lib.asm.
+count   equ 5
+step    equ 3
+
+        .org 0100
+start:  ldi r1, count
+        ldi r3, step
+        ldi r2, 0
+loop:   add r2, r3
+        dec r1
+        bnz loop
+        jmp finish
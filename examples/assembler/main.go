@@ -0,0 +1,144 @@
+// Command assembler is a toy two-file assembler: it loads an embedded grammar whose
+// templates carry instruction encodings, builds main.asm and lib.asm together so labels
+// resolve across files, encodes every line and prints a listing, the relocations and the
+// symbol table. Other source files can be given on the command line. The grammar also
+// works with tpparse, which parses every file on its own, so labels shared between files
+// only resolve here:
+//
+//	tpparse parse -grammar grammar.yaml lib.asm
+//
+// With -debugmap file the provenance of every encoded byte is also written to file as JSON,
+// and with -lines file the line table mapping addresses to source lines.
+// The output on the embedded sources is kept in expected.txt, which the example's test
+// compares it with, so the example doubles as an integration test of the registry,
+// assembler and encoder.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+//go:embed grammar.yaml main.asm lib.asm
+var files embed.FS
+
+func main() {
+	debugMap := flag.String("debugmap", "", "write the debug map of the encoded bytes to this file")
+	lineTable := flag.String("lines", "", "write the line table of the encoded bytes to this file")
+	flag.Parse()
+	sources := []TemplateParser.BuildSource{embeddedSource("main.asm"), embeddedSource("lib.asm")}
+	if flag.NArg() > 0 {
+		sources = nil
+		for _, path := range flag.Args() {
+			sources = append(sources, TemplateParser.BuildSource{Name: path, Open: fileOpener(path)})
+		}
+	}
+	var out bytes.Buffer
+	if err := assemble(&out, sources, *debugMap, *lineTable); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(out.Bytes())
+}
+
+// embeddedSource
+// returns a build source reading an embedded file.
+func embeddedSource(name string) TemplateParser.BuildSource {
+	return TemplateParser.BuildSource{Name: name, Open: func() (io.ReadCloser, error) { return files.Open(name) }}
+}
+
+// fileOpener
+// returns a function opening a file on disk.
+func fileOpener(path string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) { return os.Open(path) }
+}
+
+// assemble
+// builds the sources and writes the listing, relocations and symbols to w, and the debug
+// map and line table to the files named by debugMap and lineTable if they are set. Lines
+// that fail to parse or encode are reported as diagnostics and make assemble fail.
+func assemble(w io.Writer, sources []TemplateParser.BuildSource, debugMap string, lineTable string) error {
+	parser := TemplateParser.NewParser(TemplateParser.DefaultParserConfig())
+	if err := parser.LoadTemplatesFS(files, "grammar.yaml"); err != nil {
+		return err
+	}
+	result, err := parser.Build(sources, 0)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, line := range result.Lines {
+		if !line.Ok {
+			TemplateParser.TextFormatter{}.FormatDiagnostic(os.Stderr, TemplateParser.NewDiagnostic(line))
+			failed++
+			continue
+		}
+		if line.Mnemonic == "" {
+			continue
+		}
+		code, ok, errmsg := parser.EncodeBytes(line.ParsedLine)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", line.File, line.LineNumber, errmsg)
+			failed++
+			continue
+		}
+		text, label := TemplateParser.EatComments(line.RawText), ""
+		if line.Label != "" {
+			text, label = text[strings.Index(text, ":")+1:], line.Label+":"
+		}
+		fmt.Fprintf(w, "%04x  %-8x  %-8s%s\n", line.Address, code, label, strings.TrimSpace(text))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d lines failed", failed)
+	}
+	fmt.Fprintln(w, "\nrelocations:")
+	for _, reloc := range result.Relocations {
+		if reloc.Relative {
+			fmt.Fprintf(w, "  %s:%d %-6s %+d\n", reloc.File, reloc.Line, reloc.Symbol, int64(reloc.Value))
+		} else {
+			fmt.Fprintf(w, "  %s:%d %-6s %04x\n", reloc.File, reloc.Line, reloc.Symbol, reloc.Value)
+		}
+	}
+	fmt.Fprintln(w, "\nsymbols:")
+	for _, symbol := range result.Symbols.Symbols() {
+		fmt.Fprintf(w, "  %-6s %04x\n", symbol.Name, symbol.Address)
+	}
+	fmt.Fprintf(w, "\n%d bytes\n", result.TotalSize)
+	if debugMap == "" && lineTable == "" {
+		return nil
+	}
+	dm, ok, errmsg := parser.BuildDebugMap(result.Lines)
+	if !ok {
+		return fmt.Errorf("%s", errmsg)
+	}
+	if debugMap != "" {
+		if err := writeFile(debugMap, dm.WriteJSON); err != nil {
+			return err
+		}
+	}
+	if lineTable != "" {
+		return writeFile(lineTable, TemplateParser.BuildLineTable(result.Lines).WriteText)
+	}
+	return nil
+}
+
+// writeFile
+// creates a file and writes it with write.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
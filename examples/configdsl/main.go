@@ -0,0 +1,154 @@
+// Command configdsl reads a service configuration written in a small language built with
+// operators and boolean keywords: settings are assigned with =, switches take on or off,
+// upstreams point at an address with -> and routes send a path to an upstream with =>.
+// Actions bound to the templates fill a Go struct as lines match, then the configuration
+// is checked as a whole, so routes to upstreams that were never declared are reported at
+// the line of the route. The result is printed as JSON, followed by one compact
+// diagnostic per problem. Another configuration file can be given on the command line.
+//
+// The output on the embedded configuration is kept in expected.txt, which the example's
+// test compares it with, so the example doubles as an integration test of operators,
+// boolean keywords and actions. It exits with 1 when the configuration has problems.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+//go:embed grammar.yaml service.conf
+var files embed.FS
+
+// Config
+// is the configuration of a service.
+type Config struct {
+	Name      string            `json:"name"`
+	Port      uint64            `json:"port"`
+	Workers   uint64            `json:"workers"`
+	TLS       bool              `json:"tls"`
+	Upstreams map[string]string `json:"upstreams"`
+	Routes    []Route           `json:"routes"`
+}
+
+// Route
+// sends requests for a path to an upstream.
+type Route struct {
+	Path     string `json:"path"`
+	Upstream string `json:"upstream"`
+	line     int
+}
+
+func main() {
+	flag.Parse()
+	name := "service.conf"
+	var source io.Reader
+	if flag.NArg() > 0 {
+		data, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		name, source = flag.Arg(0), bytes.NewReader(data)
+	} else {
+		data, _ := files.ReadFile(name)
+		source = bytes.NewReader(data)
+	}
+	var out bytes.Buffer
+	problems, err := load(&out, name, source)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	os.Stdout.Write(out.Bytes())
+	if problems > 0 {
+		os.Exit(1)
+	}
+}
+
+// newParser
+// loads the embedded grammar and binds actions storing every setting in config.
+func newParser(config *Config) (*TemplateParser.Parser, error) {
+	parser := TemplateParser.NewParser(TemplateParser.DefaultParserConfig())
+	if err := parser.LoadTemplatesFS(files, "grammar.yaml"); err != nil {
+		return nil, err
+	}
+	parser.SetAction("name", func(objs []TemplateParser.ObjectType) error {
+		config.Name = objs[2].ObjectValue.(string)
+		return nil
+	})
+	parser.SetAction("port", func(objs []TemplateParser.ObjectType) error {
+		config.Port = objs[2].ObjectValue.(uint64)
+		return nil
+	})
+	parser.SetAction("workers", func(objs []TemplateParser.ObjectType) error {
+		config.Workers = objs[2].ObjectValue.(uint64)
+		return nil
+	})
+	parser.SetAction("tls", func(objs []TemplateParser.ObjectType) error {
+		config.TLS = objs[2].ObjectValue.(bool)
+		return nil
+	})
+	parser.SetAction("upstream", func(objs []TemplateParser.ObjectType) error {
+		name := objs[1].ObjectValue.(string)
+		if _, dup := config.Upstreams[name]; dup {
+			return fmt.Errorf("upstream %s is declared twice", name)
+		}
+		config.Upstreams[name] = objs[3].ObjectValue.(string)
+		return nil
+	})
+	parser.SetAction("route", func(objs []TemplateParser.ObjectType) error {
+		config.Routes = append(config.Routes, Route{Path: objs[1].ObjectValue.(string), Upstream: objs[3].ObjectValue.(string)})
+		return nil
+	})
+	return parser, nil
+}
+
+// load
+// parses a configuration, writing it as JSON to w followed by a diagnostic for every
+// problem, and returns the number of problems.
+func load(w io.Writer, name string, r io.Reader) (int, error) {
+	config := Config{Upstreams: make(map[string]string), Routes: make([]Route, 0)}
+	parser, err := newParser(&config)
+	if err != nil {
+		return 0, err
+	}
+	lines, err := parser.ParseAll(r)
+	if err != nil {
+		return 0, err
+	}
+	diagnostics := make([]TemplateParser.Diagnostic, 0)
+	for _, line := range lines {
+		line.File = name
+		if !line.Ok {
+			diagnostics = append(diagnostics, TemplateParser.NewDiagnostic(line))
+		} else if line.Mnemonic == "route" {
+			config.Routes[len(config.Routes)-1].line = line.LineNumber
+		}
+	}
+	routes := config.Routes[:0]
+	for _, route := range config.Routes {
+		if _, found := config.Upstreams[route.Upstream]; !found {
+			diagnostics = append(diagnostics, TemplateParser.Diagnostic{ParseError: TemplateParser.ParseError{
+				File: name, Line: route.line, Message: fmt.Sprintf("route %s uses undeclared upstream %s", route.Path, route.Upstream)}})
+			continue
+		}
+		routes = append(routes, route)
+	}
+	config.Routes = routes
+	sort.SliceStable(diagnostics, func(a, b int) bool { return diagnostics[a].Line < diagnostics[b].Line })
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(w, "%s\n", data)
+	TemplateParser.WriteDiagnostics(w, TemplateParser.CompactFormatter{}, diagnostics)
+	return len(diagnostics), nil
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/templatetest"
+)
+
+// TestExpectedOutput runs the example and diffs the output against expected.txt.
+func TestExpectedOutput(t *testing.T) {
+	var out bytes.Buffer
+	if err := run(&out); err != nil {
+		t.Fatal(err)
+	}
+	templatetest.AssertOutput(t, out.Bytes(), "expected.txt")
+}
@@ -0,0 +1,56 @@
+// Command embedded shows how to ship a grammar inside a binary with go:embed and load it
+// with LoadTemplatesFS, so no grammar file is needed on disk at runtime. Its output is
+// kept in expected.txt, which the example's test compares it with.
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+//go:embed grammar.yaml
+var files embed.FS
+
+const source = `start: ldi r1, 10
+	mov r2, r1
+	mov r2, r2
+	jmp start
+`
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run
+// parses the source with the embedded grammar and writes every line to w, as its address,
+// mnemonic and operands or as its error.
+func run(w io.Writer) error {
+	p := TemplateParser.NewParser(TemplateParser.DefaultParserConfig())
+	if err := p.LoadTemplatesFS(files, "grammar.yaml"); err != nil {
+		return err
+	}
+	result, err := p.ParseSource(strings.NewReader(source))
+	if err != nil {
+		return err
+	}
+	for _, line := range result.Lines {
+		if !line.Ok {
+			fmt.Fprintf(w, "line %d: %s\n", line.LineNumber, line.Error)
+			continue
+		}
+		operands := make([]string, len(line.Operands))
+		for idx, operand := range line.Operands {
+			operands[idx] = fmt.Sprintf("%s=%s", operand.TypeName, operand.Object.DisplayValue())
+		}
+		fmt.Fprintf(w, "%04x %-4s %s\n", line.Address, line.Mnemonic, strings.Join(operands, " "))
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/templatetest"
+)
+
+// TestExpectedOutput runs the example on its embedded input and diffs the output against
+// expected.txt.
+func TestExpectedOutput(t *testing.T) {
+	var out bytes.Buffer
+	data, _ := files.ReadFile("commands.txt")
+	if _, err := validate(&out, "commands.txt", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	templatetest.AssertOutput(t, out.Bytes(), "expected.txt")
+}
@@ -0,0 +1,129 @@
+// Command netcmd validates the configuration commands of a small network device: host
+// name, VLANs, firewall rules and static routes. The grammar checks the shape of every
+// command, ranges and keywords; actions bound to the rule and route templates check the
+// addresses with the net package. Accepted commands are printed with their values and
+// rejected ones as diagnostics, with port and VLAN numbers in decimal. Other command files can be given on the command line,
+// and tpparse checks everything but the addresses with the grammar:
+//
+//	tpparse validate -grammar grammar.yaml commands.txt
+//
+// The output on the embedded commands is kept in expected.txt, which the example's test
+// compares it with, so the example doubles as an integration test of grammars, guards,
+// actions and diagnostics. It exits with 1 when a command was rejected.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+//go:embed grammar.yaml commands.txt
+var files embed.FS
+
+func main() {
+	flag.Parse()
+	name := "commands.txt"
+	var commands io.Reader
+	if flag.NArg() > 0 {
+		data, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		name, commands = flag.Arg(0), bytes.NewReader(data)
+	} else {
+		data, _ := files.ReadFile(name)
+		commands = bytes.NewReader(data)
+	}
+	var out bytes.Buffer
+	rejected, err := validate(&out, name, commands)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	os.Stdout.Write(out.Bytes())
+	if rejected > 0 {
+		os.Exit(1)
+	}
+}
+
+// newParser
+// loads the embedded grammar and binds the address checks to its templates.
+func newParser() (*TemplateParser.Parser, error) {
+	parser := TemplateParser.NewParser(TemplateParser.DefaultParserConfig())
+	if err := parser.LoadTemplatesFS(files, "grammar.yaml"); err != nil {
+		return nil, err
+	}
+	for _, rule := range []string{"permit", "deny"} {
+		parser.SetAction(rule, func(objs []TemplateParser.ObjectType) error {
+			return checkNetwork(objs[5].ObjectValue.(string))
+		})
+	}
+	parser.SetAction("route", func(objs []TemplateParser.ObjectType) error {
+		if err := checkNetwork(objs[1].ObjectValue.(string)); err != nil {
+			return err
+		}
+		if gateway := objs[3].ObjectValue.(string); net.ParseIP(gateway) == nil {
+			return fmt.Errorf("%q is not an IP address", gateway)
+		}
+		return nil
+	})
+	return parser, nil
+}
+
+// checkNetwork
+// checks that a network is written in CIDR notation.
+func checkNetwork(network string) error {
+	if _, _, err := net.ParseCIDR(network); err != nil {
+		return fmt.Errorf("%q is not a network in CIDR notation", network)
+	}
+	return nil
+}
+
+// keywords are the words of the commands that carry no value and are left out of the
+// output.
+var keywords = map[string]bool{"name": true, "port": true, "from": true, "via": true}
+
+// validate
+// parses the commands read from r, writing every accepted command and the diagnostic of
+// every rejected one to w, and returns the number of rejected commands.
+func validate(w io.Writer, name string, r io.Reader) (int, error) {
+	parser, err := newParser()
+	if err != nil {
+		return 0, err
+	}
+	lines, err := parser.ParseAll(r)
+	if err != nil {
+		return 0, err
+	}
+	rejected := 0
+	for _, line := range lines {
+		line.File = name
+		if !line.Ok {
+			TemplateParser.TextFormatter{TabWidth: 8}.FormatDiagnostic(w, TemplateParser.NewDiagnostic(line))
+			rejected++
+			continue
+		}
+		fmt.Fprintf(w, "%s:%d: %s", name, line.LineNumber, line.Mnemonic)
+		for _, operand := range line.Operands {
+			value := operand.Object.DisplayValue()
+			if keywords[value] && operand.TypeName == "Identifier" {
+				continue
+			}
+			if num, ok := operand.Object.ObjectValue.(uint64); ok {
+				value = fmt.Sprint(num)
+			}
+			fmt.Fprintf(w, " %s", value)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d commands, %d rejected\n", len(lines), rejected)
+	return rejected, nil
+}
@@ -0,0 +1,48 @@
+//go:build js && wasm
+
+// Command wasm binds the jsapi wrapper to JavaScript so a page can parse with grammars
+// typed into it. It defines a global templateParser object whose functions take and
+// return strings:
+//
+//	templateParser.parse(requestJSON)          // jsapi.Parse
+//	templateParser.parseSource(grammar, text)  // jsapi.ParseSource
+//
+// Build it and copy the support script shipped with Go next to index.html:
+//
+//	GOOS=js GOARCH=wasm go build -o examples/wasm/parser.wasm ./examples/wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" examples/wasm/
+//
+// then serve the directory with any static file server and open index.html.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/jantypas/TemplateParser/jsapi"
+)
+
+// stringArgs
+// returns the first n arguments of a call as strings, "" for those missing.
+func stringArgs(args []js.Value, n int) []string {
+	strs := make([]string, n)
+	for idx := 0; idx < n && idx < len(args); idx++ {
+		if args[idx].Type() == js.TypeString {
+			strs[idx] = args[idx].String()
+		}
+	}
+	return strs
+}
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("parse", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return jsapi.Parse(stringArgs(args, 1)[0])
+	}))
+	api.Set("parseSource", js.FuncOf(func(this js.Value, args []js.Value) any {
+		strs := stringArgs(args, 2)
+		return jsapi.ParseSource(strs[0], strs[1])
+	}))
+	js.Global().Set("templateParser", api)
+	// Keep the functions alive for as long as the page is open
+	select {}
+}
@@ -1,3 +1,7 @@
 module github.com/jantypas/TemplateParser
 
 go 1.23
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require google.golang.org/protobuf v1.34.2
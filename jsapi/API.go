@@ -0,0 +1,124 @@
+// Package jsapi wraps the parser in a few functions taking and returning strings, JSON
+// for anything structured, so it can be bound to JavaScript when compiled to WebAssembly
+// (GOOS=js GOARCH=wasm) and power browser playgrounds for custom assembly DSLs without
+// exposing Go types to the host. Every call loads its grammar into a fresh Parser in
+// hardened mode and parses within fixed limits, so no state is kept between calls and
+// hostile input cannot hang the page. See examples/wasm for a syscall/js binding.
+package jsapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+// parseLimits bounds the work of parsing a request's source, whose macros could otherwise
+// expand without end.
+var parseLimits = TemplateParser.Limits{Tokens: 1 << 20, Expansions: 1 << 16}
+
+// Request
+// is a parse request. Format is "yaml" or "json"; when empty it is guessed from the
+// grammar text.
+type Request struct {
+	Grammar string `json:"grammar"`
+	Format  string `json:"format,omitempty"`
+	Source  string `json:"source"`
+}
+
+// Response
+// is the result of a parse request. Error reports a request or grammar that failed to
+// load, in which case the other fields are empty. Tokens holds the tokens of every source
+// line, blank lines included, so they line up with line numbers.
+type Response struct {
+	Error       string                      `json:"error,omitempty"`
+	Tokens      [][]TemplateParser.Token    `json:"tokens"`
+	Lines       []TemplateParser.LineResult `json:"lines"`
+	Diagnostics []string                    `json:"diagnostics"`
+	Symbols     []TemplateParser.Symbol     `json:"symbols"`
+}
+
+// Parse
+// decodes a Request from JSON, runs it and returns its Response as JSON.
+func Parse(request string) string {
+	var req Request
+	if err := json.Unmarshal([]byte(request), &req); err != nil {
+		return encode(newResponse("invalid request: " + err.Error()))
+	}
+	return encode(Run(req))
+}
+
+// ParseSource
+// parses source with a grammar in YAML or JSON and returns the Response as JSON.
+func ParseSource(grammar string, source string) string {
+	return encode(Run(Request{Grammar: grammar, Source: source}))
+}
+
+// Run
+// loads the grammar of a request into a fresh Parser in hardened mode and parses its
+// source within fixed limits. A source over the limits reports a diagnostic after the
+// lines parsed before it.
+func Run(req Request) Response {
+	resp := newResponse("")
+	config := TemplateParser.DefaultParserConfig()
+	config.Hardened = true
+	config.Limits = parseLimits
+	parser := TemplateParser.NewParser(config)
+	format := req.Format
+	if format == "" {
+		format = "yaml"
+		if strings.HasPrefix(strings.TrimSpace(req.Grammar), "{") {
+			format = "json"
+		}
+	}
+	var err error
+	switch format {
+	case "json":
+		err = parser.LoadTemplatesFromJSON(strings.NewReader(req.Grammar))
+	case "yaml":
+		err = parser.LoadTemplatesFromYAML(strings.NewReader(req.Grammar))
+	default:
+		resp.Error = "unknown grammar format " + format
+		return resp
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	lines, _, _ := TemplateParser.ReadLines(strings.NewReader(req.Source))
+	for _, line := range lines {
+		resp.Tokens = append(resp.Tokens, parser.TokenizeLine(line))
+	}
+	result, err := parser.ParseSource(strings.NewReader(req.Source))
+	resp.Lines = result.Lines
+	resp.Symbols = result.Symbols.Symbols()
+	for _, d := range append(result.Diagnostics(), result.Warnings()...) {
+		var sb bytes.Buffer
+		TemplateParser.TextFormatter{}.FormatDiagnostic(&sb, d)
+		resp.Diagnostics = append(resp.Diagnostics, sb.String())
+	}
+	if err != nil {
+		resp.Diagnostics = append(resp.Diagnostics, err.Error())
+	}
+	return resp
+}
+
+// newResponse
+// returns a response with empty lists, so they encode as [] rather than null, and an
+// error message.
+func newResponse(errmsg string) Response {
+	return Response{Error: errmsg, Tokens: [][]TemplateParser.Token{}, Lines: []TemplateParser.LineResult{},
+		Diagnostics: []string{}, Symbols: []TemplateParser.Symbol{}}
+}
+
+// encode
+// returns a response as JSON. A response that cannot be encoded is replaced by one
+// reporting why.
+func encode(resp Response) string {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(newResponse("encoding response: " + err.Error()))
+	}
+	return string(data)
+}
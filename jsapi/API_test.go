@@ -0,0 +1,82 @@
+package jsapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testGrammar = `templates:
+  - mnemonic: li
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+`
+
+const testJSONGrammar = `{"templates": [{"mnemonic": "nop", "operands": [{"type": "Identifier"}]}]}`
+
+// decode
+// decodes a response returned as JSON, failing the test if it does not decode.
+func decode(t *testing.T, data string) Response {
+	t.Helper()
+	var resp Response
+	if err := json.Unmarshal([]byte(data), &resp); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return resp
+}
+
+func TestParse(t *testing.T) {
+	request, _ := json.Marshal(Request{Grammar: testGrammar, Source: "start: li r1, 5\n\nli r1, r2\n"})
+	resp := decode(t, Parse(string(request)))
+	if resp.Error != "" || len(resp.Tokens) != 3 || len(resp.Tokens[1]) != 0 {
+		t.Errorf("response = %+v, want the tokens of three lines, the second blank", resp)
+	}
+	if len(resp.Lines) != 2 || !resp.Lines[0].Ok || resp.Lines[1].Ok || len(resp.Diagnostics) != 1 {
+		t.Errorf("lines = %+v, diagnostics %v, want the second line to fail", resp.Lines, resp.Diagnostics)
+	}
+	if len(resp.Symbols) != 1 || resp.Symbols[0].Name != "start" {
+		t.Errorf("symbols = %+v, want start", resp.Symbols)
+	}
+}
+
+func TestParseSourceGuessesFormat(t *testing.T) {
+	resp := decode(t, ParseSource(testJSONGrammar, "nop\n"))
+	if resp.Error != "" || len(resp.Lines) != 1 || !resp.Lines[0].Ok {
+		t.Errorf("response = %+v, want the JSON grammar to load", resp)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		request string
+		err     string
+	}{
+		{"{", "invalid request: "},
+		{`{"grammar": "templates: [", "source": "li r1, 5"}`, "yaml"},
+		{`{"grammar": "templates: []", "format": "toml", "source": ""}`, "unknown grammar format toml"},
+	}
+	for _, tt := range tests {
+		data := Parse(tt.request)
+		resp := decode(t, data)
+		if !strings.Contains(resp.Error, tt.err) {
+			t.Errorf("Parse(%s) error = %q, want %q", tt.request, resp.Error, tt.err)
+		}
+		if !strings.Contains(data, `"lines":[]`) || !strings.Contains(data, `"symbols":[]`) {
+			t.Errorf("Parse(%s) = %s, want empty lists", tt.request, data)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	source := strings.Repeat("li r1, 5\n", 1<<18+1)
+	resp := Run(Request{Grammar: testGrammar, Source: source})
+	if resp.Error != "" || len(resp.Diagnostics) == 0 {
+		t.Fatalf("response error %q with %d diagnostics, want a limit diagnostic", resp.Error, len(resp.Diagnostics))
+	}
+	if last := resp.Diagnostics[len(resp.Diagnostics)-1]; !strings.Contains(last, "tokens limit of 1048576 exceeded") {
+		t.Errorf("last diagnostic = %q, want the tokens limit", last)
+	}
+}
@@ -0,0 +1,473 @@
+// Package pb encodes tokens, template slots, objects and line results in the protocol
+// buffer messages of templateparser.proto, so parse results can be exchanged with pipeline
+// stages not written in Go. The messages are those of package templateparserpb, generated
+// from the schema by protoc-gen-go; this package converts the TemplateParser types to and
+// from them.
+//
+// Everything but the parts without a serialized form survives a round trip: callbacks of
+// template slots, the unexported state of tokens and lines and, as in the JSON form, the
+// values and texts of sensitive objects. Those are written as TemplateParser.RedactedValue,
+// so the exchange of sensitive objects is lossy: decoding gives back the marker, not the
+// value.
+package pb
+
+//go:generate protoc -I.. --go_out=.. --go_opt=module=github.com/jantypas/TemplateParser pb/templateparser.proto
+
+import (
+	"fmt"
+	"math/big"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+	"github.com/jantypas/TemplateParser/pb/templateparserpb"
+)
+
+// marshalOptions writes map fields in key order, so that messages are written the same
+// way every time.
+var marshalOptions = proto.MarshalOptions{Deterministic: true}
+
+// MarshalToken
+// encodes a token as a Token message.
+func MarshalToken(token TemplateParser.Token) []byte {
+	data, _ := marshalOptions.Marshal(tokenMessage(token))
+	return data
+}
+
+// UnmarshalToken
+// decodes a Token message.
+func UnmarshalToken(data []byte) (TemplateParser.Token, error) {
+	var msg templateparserpb.Token
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return TemplateParser.Token{}, err
+	}
+	return tokenFromMessage(&msg), nil
+}
+
+// MarshalObject
+// encodes an object as an ObjectType message. It fails for values of Go types the schema
+// has no form for, such as those a custom token type's converter may return.
+func MarshalObject(obj TemplateParser.ObjectType) ([]byte, error) {
+	if err := checkValue(obj); err != nil {
+		return nil, err
+	}
+	return marshalOptions.Marshal(objectMessage(obj))
+}
+
+// UnmarshalObject
+// decodes an ObjectType message.
+func UnmarshalObject(data []byte) (TemplateParser.ObjectType, error) {
+	var msg templateparserpb.ObjectType
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return TemplateParser.ObjectType{}, err
+	}
+	return objectFromMessage(&msg)
+}
+
+// MarshalTemplate
+// encodes a template slot as a TemplateObject message, without its callbacks.
+func MarshalTemplate(tmpl TemplateParser.TemplateObject) ([]byte, error) {
+	if err := checkValue(tmpl.TemplateValue); err != nil {
+		return nil, err
+	}
+	return marshalOptions.Marshal(templateMessage(tmpl))
+}
+
+// UnmarshalTemplate
+// decodes a TemplateObject message.
+func UnmarshalTemplate(data []byte) (TemplateParser.TemplateObject, error) {
+	var msg templateparserpb.TemplateObject
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return TemplateParser.TemplateObject{}, err
+	}
+	return templateFromMessage(&msg)
+}
+
+// MarshalLineResult
+// encodes the result of a line as a LineResult message.
+func MarshalLineResult(lr TemplateParser.LineResult) ([]byte, error) {
+	if err := checkLine(lr); err != nil {
+		return nil, err
+	}
+	return marshalOptions.Marshal(lineResultMessage(lr))
+}
+
+// UnmarshalLineResult
+// decodes a LineResult message.
+func UnmarshalLineResult(data []byte) (TemplateParser.LineResult, error) {
+	var msg templateparserpb.LineResult
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return TemplateParser.LineResult{}, err
+	}
+	return lineResultFromMessage(&msg)
+}
+
+// MarshalLineResults
+// encodes the results of the lines of a source as a LineResults message.
+func MarshalLineResults(lines []TemplateParser.LineResult) ([]byte, error) {
+	msg := &templateparserpb.LineResults{Lines: make([]*templateparserpb.LineResult, len(lines))}
+	for idx, lr := range lines {
+		if err := checkLine(lr); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lr.LineNumber, err)
+		}
+		msg.Lines[idx] = lineResultMessage(lr)
+	}
+	return marshalOptions.Marshal(msg)
+}
+
+// UnmarshalLineResults
+// decodes a LineResults message.
+func UnmarshalLineResults(data []byte) ([]TemplateParser.LineResult, error) {
+	var msg templateparserpb.LineResults
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	lines := make([]TemplateParser.LineResult, len(msg.Lines))
+	for idx, line := range msg.Lines {
+		lr, err := lineResultFromMessage(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", idx+1, err)
+		}
+		lines[idx] = lr
+	}
+	return lines, nil
+}
+
+// builtinTypes is the registry of the built-in token types, which names types in messages.
+var builtinTypes = TemplateParser.BuiltinTokenTypes()
+
+// typeName
+// returns the name of a built-in token type, "" for other ids.
+func typeName(id int) string {
+	name := builtinTypes.Name(id)
+	if _, builtin := builtinTypes.ByName(name); !builtin {
+		return ""
+	}
+	return name
+}
+
+// typeId
+// resolves a decoded type from its name, falling back to its id as the JSON form does.
+func typeId(name string, id int64) int {
+	if tt, found := builtinTypes.ByName(name); found && name != "" {
+		return tt
+	}
+	return int(id)
+}
+
+// checkValue
+// reports an object value the schema cannot hold.
+func checkValue(obj TemplateParser.ObjectType) error {
+	if obj.ObjectSensitive {
+		return nil
+	}
+	switch obj.ObjectValue.(type) {
+	case nil, string, uint64, int64, bool, *big.Int, TemplateParser.MemoryOperand:
+		return nil
+	}
+	return fmt.Errorf("object value of type %T has no protobuf form", obj.ObjectValue)
+}
+
+// checkLine
+// reports an object of a line whose value the schema cannot hold.
+func checkLine(lr TemplateParser.LineResult) error {
+	for _, obj := range lr.Objects {
+		if err := checkValue(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenMessage
+// converts a token to a Token message.
+func tokenMessage(token TemplateParser.Token) *templateparserpb.Token {
+	return &templateparserpb.Token{Type: int64(token.Type), TypeName: typeName(token.Type), Value: token.ValueReceived}
+}
+
+// tokenFromMessage
+// converts a Token message to a token.
+func tokenFromMessage(msg *templateparserpb.Token) TemplateParser.Token {
+	return TemplateParser.Token{Type: typeId(msg.TypeName, msg.Type), ValueReceived: msg.Value}
+}
+
+// valueMessage
+// converts an object value to a Value message, nil for objects without one.
+func valueMessage(value interface{}) *templateparserpb.Value {
+	switch val := value.(type) {
+	case string:
+		return &templateparserpb.Value{Kind: &templateparserpb.Value_StringValue{StringValue: val}}
+	case uint64:
+		return &templateparserpb.Value{Kind: &templateparserpb.Value_UintValue{UintValue: val}}
+	case int64:
+		return &templateparserpb.Value{Kind: &templateparserpb.Value_IntValue{IntValue: val}}
+	case bool:
+		return &templateparserpb.Value{Kind: &templateparserpb.Value_BoolValue{BoolValue: val}}
+	case *big.Int:
+		if val == nil {
+			return nil
+		}
+		return &templateparserpb.Value{Kind: &templateparserpb.Value_BigInt{BigInt: val.Text(16)}}
+	case TemplateParser.MemoryOperand:
+		return &templateparserpb.Value{Kind: &templateparserpb.Value_Memory{Memory: &templateparserpb.MemoryOperand{
+			HasBase: val.HasBase, Base: val.Base, BaseWidth: int64(val.BaseWidth),
+			HasIndex: val.HasIndex, Index: val.Index, IndexWidth: int64(val.IndexWidth),
+			Scale: val.Scale, Displacement: val.Displacement}}}
+	}
+	return nil
+}
+
+// valueFromMessage
+// converts a Value message to an object value.
+func valueFromMessage(msg *templateparserpb.Value) (interface{}, error) {
+	switch kind := msg.GetKind().(type) {
+	case *templateparserpb.Value_StringValue:
+		return kind.StringValue, nil
+	case *templateparserpb.Value_UintValue:
+		return kind.UintValue, nil
+	case *templateparserpb.Value_IntValue:
+		return kind.IntValue, nil
+	case *templateparserpb.Value_BoolValue:
+		return kind.BoolValue, nil
+	case *templateparserpb.Value_BigInt:
+		n, ok := new(big.Int).SetString(kind.BigInt, 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid big_int %q", kind.BigInt)
+		}
+		return n, nil
+	case *templateparserpb.Value_Memory:
+		mem := kind.Memory
+		return TemplateParser.MemoryOperand{HasBase: mem.HasBase, Base: mem.Base, BaseWidth: int(mem.BaseWidth),
+			HasIndex: mem.HasIndex, Index: mem.Index, IndexWidth: int(mem.IndexWidth),
+			Scale: mem.Scale, Displacement: mem.Displacement}, nil
+	}
+	return nil, nil
+}
+
+// objectMessage
+// converts an object to an ObjectType message, redacting it if it is sensitive.
+func objectMessage(obj TemplateParser.ObjectType) *templateparserpb.ObjectType {
+	obj = obj.Redacted()
+	return &templateparserpb.ObjectType{Type: int64(obj.ObjectTypeId), TypeName: typeName(obj.ObjectTypeId),
+		Value: valueMessage(obj.ObjectValue), Descriptor_: obj.ObjectDescriptor, Sensitive: obj.ObjectSensitive,
+		Width: int64(obj.ObjectWidth), Text: obj.ObjectText}
+}
+
+// objectFromMessage
+// converts an ObjectType message to an object.
+func objectFromMessage(msg *templateparserpb.ObjectType) (TemplateParser.ObjectType, error) {
+	value, err := valueFromMessage(msg.Value)
+	return TemplateParser.ObjectType{ObjectTypeId: typeId(msg.TypeName, msg.Type), ObjectValue: value,
+		ObjectDescriptor: msg.Descriptor_, ObjectSensitive: msg.Sensitive, ObjectWidth: int(msg.Width),
+		ObjectText: msg.Text}, err
+}
+
+// objectMessages
+// converts a list of objects to ObjectType messages.
+func objectMessages(objs []TemplateParser.ObjectType) []*templateparserpb.ObjectType {
+	if objs == nil {
+		return nil
+	}
+	msgs := make([]*templateparserpb.ObjectType, len(objs))
+	for idx, obj := range objs {
+		msgs[idx] = objectMessage(obj)
+	}
+	return msgs
+}
+
+// objectsFromMessages
+// converts a list of ObjectType messages to objects.
+func objectsFromMessages(msgs []*templateparserpb.ObjectType) ([]TemplateParser.ObjectType, error) {
+	if msgs == nil {
+		return nil, nil
+	}
+	objs := make([]TemplateParser.ObjectType, len(msgs))
+	for idx, msg := range msgs {
+		obj, err := objectFromMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		objs[idx] = obj
+	}
+	return objs, nil
+}
+
+// isZeroObject
+// reports whether an object is the zero ObjectType, so that template slots without a
+// value are written without one.
+func isZeroObject(obj TemplateParser.ObjectType) bool {
+	return valueMessage(obj.ObjectValue) == nil && obj.ObjectTypeId == 0 && obj.ObjectDescriptor == "" &&
+		!obj.ObjectSensitive && obj.ObjectWidth == 0 && obj.ObjectText == ""
+}
+
+// templateMessage
+// converts a template slot to a TemplateObject message, without its callbacks.
+func templateMessage(tmpl TemplateParser.TemplateObject) *templateparserpb.TemplateObject {
+	msg := &templateparserpb.TemplateObject{Type: int64(tmpl.TemplateType), TypeName: typeName(tmpl.TemplateType),
+		Error: tmpl.TemplateError, MinValue: tmpl.MinValue, MaxValue: tmpl.MaxValue, Sensitive: tmpl.Sensitive,
+		Group: tmpl.Group, SameAs: int64(tmpl.SameAs), DifferentFrom: int64(tmpl.DifferentFrom), Name: tmpl.Name,
+		Choices: tmpl.Choices}
+	if !isZeroObject(tmpl.TemplateValue) {
+		msg.Value = objectMessage(tmpl.TemplateValue)
+	}
+	for _, flag := range tmpl.Flags {
+		msg.Flags = append(msg.Flags, &templateparserpb.Flag{Name: flag.Name, Value: flag.Value})
+	}
+	return msg
+}
+
+// templateFromMessage
+// converts a TemplateObject message to a template slot.
+func templateFromMessage(msg *templateparserpb.TemplateObject) (TemplateParser.TemplateObject, error) {
+	tmpl := TemplateParser.TemplateObject{TemplateType: typeId(msg.TypeName, msg.Type), TemplateError: msg.Error,
+		MinValue: msg.MinValue, MaxValue: msg.MaxValue, Sensitive: msg.Sensitive, Group: msg.Group,
+		SameAs: int(msg.SameAs), DifferentFrom: int(msg.DifferentFrom), Name: msg.Name, Choices: msg.Choices}
+	if msg.Value != nil {
+		value, err := objectFromMessage(msg.Value)
+		if err != nil {
+			return tmpl, err
+		}
+		tmpl.TemplateValue = value
+	}
+	for _, flag := range msg.Flags {
+		tmpl.Flags = append(tmpl.Flags, TemplateParser.Flag{Name: flag.Name, Value: flag.Value})
+	}
+	return tmpl, nil
+}
+
+// parsedLineMessage
+// converts a parsed line to a ParsedLine message.
+func parsedLineMessage(pl TemplateParser.ParsedLine) *templateparserpb.ParsedLine {
+	msg := &templateparserpb.ParsedLine{Line: int64(pl.LineNumber), File: pl.File, Offset: pl.Offset,
+		RawText: pl.RawText, Expanded: pl.Expanded, Label: pl.Label, Mnemonic: pl.Mnemonic,
+		Comment: pl.Comment, Directive: pl.Directive, Address: pl.Address, Size: pl.Size,
+		Cycles: int64(pl.Cycles), Objects: objectMessages(pl.Objects)}
+	for _, site := range pl.Includes {
+		msg.Includes = append(msg.Includes, &templateparserpb.IncludeSite{File: site.File, Line: int64(site.Line)})
+	}
+	for _, seg := range pl.Segments {
+		msg.Segments = append(msg.Segments, &templateparserpb.LineSegment{Line: int64(seg.Line),
+			Column: int64(seg.Column), Offset: seg.Offset})
+	}
+	for _, operand := range pl.Operands {
+		msg.Operands = append(msg.Operands, &templateparserpb.Operand{Slot: int64(operand.Slot),
+			TypeName: operand.TypeName, Group: operand.Group, Name: operand.Name,
+			Object: objectMessage(operand.Object)})
+	}
+	if pl.Groups != nil {
+		msg.Groups = make(map[string]*templateparserpb.ObjectList, len(pl.Groups))
+		for group, objs := range pl.Groups {
+			msg.Groups[group] = &templateparserpb.ObjectList{Objects: objectMessages(objs)}
+		}
+	}
+	if pl.Named != nil {
+		msg.Named = make(map[string]*templateparserpb.ObjectType, len(pl.Named))
+		for name, obj := range pl.Named {
+			msg.Named[name] = objectMessage(obj)
+		}
+	}
+	for _, warning := range pl.Warnings {
+		msg.Warnings = append(msg.Warnings, &templateparserpb.Warning{Column: int64(warning.Column),
+			Length: int64(warning.Length), Message: warning.Message})
+	}
+	return msg
+}
+
+// parsedLineFromMessage
+// converts a ParsedLine message to a parsed line.
+func parsedLineFromMessage(msg *templateparserpb.ParsedLine) (TemplateParser.ParsedLine, error) {
+	pl := TemplateParser.ParsedLine{LineNumber: int(msg.Line), File: msg.File, Offset: msg.Offset,
+		RawText: msg.RawText, Expanded: msg.Expanded, Label: msg.Label, Mnemonic: msg.Mnemonic,
+		Comment: msg.Comment, Directive: msg.Directive, Address: msg.Address, Size: msg.Size,
+		Cycles: int(msg.Cycles)}
+	var err error
+	if pl.Objects, err = objectsFromMessages(msg.Objects); err != nil {
+		return pl, err
+	}
+	for _, site := range msg.Includes {
+		pl.Includes = append(pl.Includes, TemplateParser.IncludeSite{File: site.File, Line: int(site.Line)})
+	}
+	for _, seg := range msg.Segments {
+		pl.Segments = append(pl.Segments, TemplateParser.LineSegment{Line: int(seg.Line), Column: int(seg.Column),
+			Offset: seg.Offset})
+	}
+	for _, operand := range msg.Operands {
+		obj, err := objectFromMessage(operand.Object)
+		if err != nil {
+			return pl, err
+		}
+		pl.Operands = append(pl.Operands, TemplateParser.Operand{Slot: int(operand.Slot), TypeName: operand.TypeName,
+			Group: operand.Group, Name: operand.Name, Object: obj})
+	}
+	if msg.Groups != nil {
+		pl.Groups = make(map[string][]TemplateParser.ObjectType, len(msg.Groups))
+		for group, list := range msg.Groups {
+			objs, err := objectsFromMessages(list.GetObjects())
+			if err != nil {
+				return pl, err
+			}
+			if objs == nil {
+				objs = make([]TemplateParser.ObjectType, 0)
+			}
+			pl.Groups[group] = objs
+		}
+	}
+	if msg.Named != nil {
+		pl.Named = make(map[string]TemplateParser.ObjectType, len(msg.Named))
+		for name, named := range msg.Named {
+			obj, err := objectFromMessage(named)
+			if err != nil {
+				return pl, err
+			}
+			pl.Named[name] = obj
+		}
+	}
+	for _, warning := range msg.Warnings {
+		pl.Warnings = append(pl.Warnings, TemplateParser.Warning{Column: int(warning.Column),
+			Length: int(warning.Length), Message: warning.Message})
+	}
+	return pl, nil
+}
+
+// lineResultMessage
+// converts the result of a line to a LineResult message.
+func lineResultMessage(lr TemplateParser.LineResult) *templateparserpb.LineResult {
+	msg := &templateparserpb.LineResult{Line: parsedLineMessage(lr.ParsedLine), Ok: lr.Ok, Error: lr.Error,
+		ErrorColumn: int64(lr.ErrorColumn), ErrorLength: int64(lr.ErrorLength), Expected: lr.Expected,
+		TemplateError: lr.TemplateError}
+	for _, origin := range lr.Origin {
+		msg.Origin = append(msg.Origin, &templateparserpb.TokenOrigin{Macro: origin.Macro, Line: int64(origin.Line),
+			Column: int64(origin.Column), DefinedFile: origin.DefinedFile, DefinedLine: int64(origin.DefinedLine)})
+	}
+	if s := lr.Suggestions; s != nil {
+		msg.Suggestions = &templateparserpb.Suggestions{Mnemonics: s.Mnemonics, Expected: s.Expected}
+		for _, near := range s.Templates {
+			msg.Suggestions.Templates = append(msg.Suggestions.Templates, &templateparserpb.NearMatch{
+				Mnemonic: near.Mnemonic, Form: near.Form, Matched: int64(near.Matched), Expected: near.Expected})
+		}
+	}
+	return msg
+}
+
+// lineResultFromMessage
+// converts a LineResult message to the result of a line.
+func lineResultFromMessage(msg *templateparserpb.LineResult) (TemplateParser.LineResult, error) {
+	lr := TemplateParser.LineResult{Ok: msg.Ok, Error: msg.Error, ErrorColumn: int(msg.ErrorColumn),
+		ErrorLength: int(msg.ErrorLength), Expected: msg.Expected, TemplateError: msg.TemplateError}
+	for _, origin := range msg.Origin {
+		lr.Origin = append(lr.Origin, TemplateParser.TokenOrigin{Macro: origin.Macro, Line: int(origin.Line),
+			Column: int(origin.Column), DefinedFile: origin.DefinedFile, DefinedLine: int(origin.DefinedLine)})
+	}
+	if s := msg.Suggestions; s != nil {
+		lr.Suggestions = &TemplateParser.Suggestions{Mnemonics: s.Mnemonics, Expected: s.Expected}
+		for _, near := range s.Templates {
+			lr.Suggestions.Templates = append(lr.Suggestions.Templates, TemplateParser.NearMatch{
+				Mnemonic: near.Mnemonic, Form: near.Form, Matched: int(near.Matched), Expected: near.Expected})
+		}
+	}
+	var err error
+	if msg.Line != nil {
+		lr.ParsedLine, err = parsedLineFromMessage(msg.Line)
+	}
+	return lr, err
+}
@@ -0,0 +1,160 @@
+package pb
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+func TestObjectRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		obj  TemplateParser.ObjectType
+		want interface{}
+	}{
+		{TemplateParser.ObjectType{ObjectTypeId: TemplateParser.TokenUint8, ObjectValue: uint64(0x10), ObjectText: "10"}, uint64(0x10)},
+		{TemplateParser.ObjectType{ObjectTypeId: TemplateParser.TokenUint8, ObjectValue: uint64(0x10), ObjectText: "10", ObjectSensitive: true}, TemplateParser.RedactedValue},
+	} {
+		data, err := MarshalObject(tt.obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := UnmarshalObject(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ObjectValue != tt.want || got.ObjectSensitive != tt.obj.ObjectSensitive {
+			t.Errorf("round trip of %+v = %+v, want value %v", tt.obj, got, tt.want)
+		}
+	}
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	token := TemplateParser.Token{Type: TemplateParser.TokenRegister, ValueReceived: "r1"}
+	got, err := UnmarshalToken(MarshalToken(token))
+	if err != nil || got != token {
+		t.Errorf("round trip of %+v = %+v, %v", token, got, err)
+	}
+}
+
+// fullLine returns a line result with every exported field set, so a field the messages
+// lose shows up in the round trip.
+func fullLine() TemplateParser.LineResult {
+	obj := func(value interface{}, text string) TemplateParser.ObjectType {
+		return TemplateParser.ObjectType{ObjectTypeId: TemplateParser.TokenUint16, ObjectValue: value,
+			ObjectDescriptor: "imm", ObjectWidth: 16, ObjectText: text}
+	}
+	mem := TemplateParser.MemoryOperand{HasBase: true, Base: 1, BaseWidth: 32, HasIndex: true, Index: 2,
+		IndexWidth: 64, Scale: 4, Displacement: -8}
+	objects := []TemplateParser.ObjectType{
+		obj("text", "\"text\""), obj(uint64(0x1234), "1234"), obj(int64(-5), "-5"), obj(true, "true"),
+		obj(new(big.Int).Lsh(big.NewInt(1), 80), "1<<80"), obj(mem, "[r1+r2*4-8]"),
+	}
+	return TemplateParser.LineResult{
+		ParsedLine: TemplateParser.ParsedLine{
+			LineNumber: 3, File: "main.s", Includes: []TemplateParser.IncludeSite{{File: "top.s", Line: 7}},
+			Offset: 120, Segments: []TemplateParser.LineSegment{{Line: 3, Column: 1, Offset: 120}},
+			RawText: "loop: mov 1234 ; copy", Expanded: "loop: mov 0x1234 ; copy", Label: "loop",
+			Mnemonic: "mov", Comment: "copy", Directive: "org", Address: 0x100, Size: 3, Cycles: 2,
+			Operands: []TemplateParser.Operand{{Slot: 1, TypeName: "Uint16", Group: "src", Name: "value",
+				Object: objects[1]}},
+			Objects:  objects,
+			Groups:   map[string][]TemplateParser.ObjectType{"src": objects[1:2], "empty": {}},
+			Named:    map[string]TemplateParser.ObjectType{"value": objects[1]},
+			Warnings: []TemplateParser.Warning{{Column: 6, Length: 3, Message: "deprecated"}},
+		},
+		Ok: true, Error: "error", ErrorColumn: 5, ErrorLength: 4, Expected: "register",
+		TemplateError: "not a register",
+		Origin: []TemplateParser.TokenOrigin{{Macro: "copy", Line: 2, Column: 4, DefinedFile: "macros.s",
+			DefinedLine: 9}},
+		Suggestions: &TemplateParser.Suggestions{Mnemonics: []string{"movb"}, Expected: "register",
+			Templates: []TemplateParser.NearMatch{{Mnemonic: "mov", Form: "mov r, r", Matched: 1,
+				Expected: "register"}}},
+	}
+}
+
+// checkFieldsSet fails for the exported fields of a struct left at their zero value.
+func checkFieldsSet(t *testing.T, value reflect.Value) {
+	t.Helper()
+	for idx := 0; idx < value.NumField(); idx++ {
+		field := value.Type().Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+		if value.Field(idx).IsZero() {
+			t.Errorf("%s.%s is not set", value.Type().Name(), field.Name)
+		}
+		if field.Anonymous {
+			checkFieldsSet(t, value.Field(idx))
+		}
+	}
+}
+
+func TestLineResultRoundTrip(t *testing.T) {
+	lr := fullLine()
+	checkFieldsSet(t, reflect.ValueOf(lr))
+	// ObjectSensitive is left to TestSensitiveLineRoundTrip, as it changes what is written.
+	sensitive := lr.Objects[0]
+	sensitive.ObjectSensitive = true
+	checkFieldsSet(t, reflect.ValueOf(sensitive))
+	checkFieldsSet(t, reflect.ValueOf(lr.Objects[5].ObjectValue))
+	data, err := MarshalLineResult(lr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalLineResult(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, lr) {
+		t.Errorf("round trip of\n%+v\n= %+v", lr, got)
+	}
+	lines, err := UnmarshalLineResults(must(MarshalLineResults([]TemplateParser.LineResult{lr, {}})))
+	if err != nil || len(lines) != 2 || !reflect.DeepEqual(lines[0], lr) {
+		t.Errorf("round trip of the lines = %+v, %v", lines, err)
+	}
+}
+
+func TestSensitiveLineRoundTrip(t *testing.T) {
+	lr := fullLine()
+	lr.Objects[1].ObjectSensitive = true
+	got, err := UnmarshalLineResult(must(MarshalLineResult(lr)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := lr.Objects[1].Redacted()
+	if !reflect.DeepEqual(got.Objects[1], want) || got.Objects[1].ObjectValue != TemplateParser.RedactedValue {
+		t.Errorf("sensitive object = %+v, want %+v", got.Objects[1], want)
+	}
+}
+
+func TestTemplateRoundTrip(t *testing.T) {
+	tmpl := TemplateParser.TemplateObject{TemplateType: TemplateParser.TokenUint8,
+		TemplateValue: TemplateParser.ObjectType{ObjectTypeId: TemplateParser.TokenUint8, ObjectValue: uint64(3),
+			ObjectDescriptor: "count", ObjectWidth: 8, ObjectText: "3"},
+		TemplateError: "count", MinValue: 1, MaxValue: 8, Sensitive: true, Group: "args", SameAs: 1,
+		DifferentFrom: 2, Hooks: &TemplateParser.SlotHooks{}, Name: "count", Choices: []string{"1", "2"},
+		Flags: []TemplateParser.Flag{{Name: "wide", Value: 1}}}
+	checkFieldsSet(t, reflect.ValueOf(tmpl))
+	got, err := UnmarshalTemplate(must(MarshalTemplate(tmpl)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tmpl
+	want.Hooks = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip of %+v = %+v", want, got)
+	}
+	empty, err := UnmarshalTemplate(must(MarshalTemplate(TemplateParser.TemplateObject{})))
+	if err != nil || !reflect.DeepEqual(empty, TemplateParser.TemplateObject{}) {
+		t.Errorf("round trip of an empty template = %+v, %v", empty, err)
+	}
+}
+
+func must(data []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
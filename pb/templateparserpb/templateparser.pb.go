@@ -0,0 +1,2042 @@
+// Protocol buffer schema of the tokens, template slots, objects and line results of the
+// TemplateParser package, for exchanging parse results with stages not written in Go.
+// The Go bindings in package templateparserpb are generated from this file with
+// protoc-gen-go, as the go:generate directive of package pb runs it; package pb converts
+// between them and the TemplateParser types.
+//
+// Type ids are the package's token type ids, such as 6 for Register; type_name carries
+// the name of built-in types so readers need not hard-code the ids.
+//
+// The exchange is lossy for sensitive objects: as in the JSON form, the value and text of
+// an object with sensitive set are written as the redaction marker "<redacted>", so
+// readers cannot recover them and decoding gives the marker back in their place. Stages
+// that need those values must get them some other way than these messages.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: pb/templateparser.proto
+
+package templateparserpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TokenOrigin is one step of the macro expansion that produced a token.
+type TokenOrigin struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Macro       string `protobuf:"bytes,1,opt,name=macro,proto3" json:"macro,omitempty"`
+	Line        int64  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	Column      int64  `protobuf:"varint,3,opt,name=column,proto3" json:"column,omitempty"`
+	DefinedFile string `protobuf:"bytes,4,opt,name=defined_file,json=definedFile,proto3" json:"defined_file,omitempty"`
+	DefinedLine int64  `protobuf:"varint,5,opt,name=defined_line,json=definedLine,proto3" json:"defined_line,omitempty"`
+}
+
+func (x *TokenOrigin) Reset() {
+	*x = TokenOrigin{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenOrigin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenOrigin) ProtoMessage() {}
+
+func (x *TokenOrigin) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenOrigin.ProtoReflect.Descriptor instead.
+func (*TokenOrigin) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TokenOrigin) GetMacro() string {
+	if x != nil {
+		return x.Macro
+	}
+	return ""
+}
+
+func (x *TokenOrigin) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *TokenOrigin) GetColumn() int64 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *TokenOrigin) GetDefinedFile() string {
+	if x != nil {
+		return x.DefinedFile
+	}
+	return ""
+}
+
+func (x *TokenOrigin) GetDefinedLine() int64 {
+	if x != nil {
+		return x.DefinedLine
+	}
+	return 0
+}
+
+// Token is a lexical token.
+type Token struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type     int64  `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	TypeName string `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	Value    string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Token) Reset() {
+	*x = Token{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Token) ProtoMessage() {}
+
+func (x *Token) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Token.ProtoReflect.Descriptor instead.
+func (*Token) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Token) GetType() int64 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *Token) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *Token) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// Value is the value of an object; it is absent for objects without one.
+type Value struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Kind:
+	//	*Value_StringValue
+	//	*Value_UintValue
+	//	*Value_IntValue
+	//	*Value_BoolValue
+	//	*Value_BigInt
+	//	*Value_Memory
+	Kind isValue_Kind `protobuf_oneof:"kind"`
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{2}
+}
+
+func (m *Value) GetKind() isValue_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetStringValue() string {
+	if x, ok := x.GetKind().(*Value_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *Value) GetUintValue() uint64 {
+	if x, ok := x.GetKind().(*Value_UintValue); ok {
+		return x.UintValue
+	}
+	return 0
+}
+
+func (x *Value) GetIntValue() int64 {
+	if x, ok := x.GetKind().(*Value_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (x *Value) GetBoolValue() bool {
+	if x, ok := x.GetKind().(*Value_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (x *Value) GetBigInt() string {
+	if x, ok := x.GetKind().(*Value_BigInt); ok {
+		return x.BigInt
+	}
+	return ""
+}
+
+func (x *Value) GetMemory() *MemoryOperand {
+	if x, ok := x.GetKind().(*Value_Memory); ok {
+		return x.Memory
+	}
+	return nil
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Value_UintValue struct {
+	UintValue uint64 `protobuf:"varint,2,opt,name=uint_value,json=uintValue,proto3,oneof"`
+}
+
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"zigzag64,3,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,4,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Value_BigInt struct {
+	// Base 16 without a prefix, with a leading - if negative
+	BigInt string `protobuf:"bytes,5,opt,name=big_int,json=bigInt,proto3,oneof"`
+}
+
+type Value_Memory struct {
+	Memory *MemoryOperand `protobuf:"bytes,6,opt,name=memory,proto3,oneof"`
+}
+
+func (*Value_StringValue) isValue_Kind() {}
+
+func (*Value_UintValue) isValue_Kind() {}
+
+func (*Value_IntValue) isValue_Kind() {}
+
+func (*Value_BoolValue) isValue_Kind() {}
+
+func (*Value_BigInt) isValue_Kind() {}
+
+func (*Value_Memory) isValue_Kind() {}
+
+// MemoryOperand is the value of a Memory object.
+type MemoryOperand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HasBase      bool   `protobuf:"varint,1,opt,name=has_base,json=hasBase,proto3" json:"has_base,omitempty"`
+	Base         uint64 `protobuf:"varint,2,opt,name=base,proto3" json:"base,omitempty"`
+	BaseWidth    int64  `protobuf:"varint,3,opt,name=base_width,json=baseWidth,proto3" json:"base_width,omitempty"`
+	HasIndex     bool   `protobuf:"varint,4,opt,name=has_index,json=hasIndex,proto3" json:"has_index,omitempty"`
+	Index        uint64 `protobuf:"varint,5,opt,name=index,proto3" json:"index,omitempty"`
+	IndexWidth   int64  `protobuf:"varint,6,opt,name=index_width,json=indexWidth,proto3" json:"index_width,omitempty"`
+	Scale        uint64 `protobuf:"varint,7,opt,name=scale,proto3" json:"scale,omitempty"`
+	Displacement int64  `protobuf:"zigzag64,8,opt,name=displacement,proto3" json:"displacement,omitempty"`
+}
+
+func (x *MemoryOperand) Reset() {
+	*x = MemoryOperand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MemoryOperand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryOperand) ProtoMessage() {}
+
+func (x *MemoryOperand) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryOperand.ProtoReflect.Descriptor instead.
+func (*MemoryOperand) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MemoryOperand) GetHasBase() bool {
+	if x != nil {
+		return x.HasBase
+	}
+	return false
+}
+
+func (x *MemoryOperand) GetBase() uint64 {
+	if x != nil {
+		return x.Base
+	}
+	return 0
+}
+
+func (x *MemoryOperand) GetBaseWidth() int64 {
+	if x != nil {
+		return x.BaseWidth
+	}
+	return 0
+}
+
+func (x *MemoryOperand) GetHasIndex() bool {
+	if x != nil {
+		return x.HasIndex
+	}
+	return false
+}
+
+func (x *MemoryOperand) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *MemoryOperand) GetIndexWidth() int64 {
+	if x != nil {
+		return x.IndexWidth
+	}
+	return 0
+}
+
+func (x *MemoryOperand) GetScale() uint64 {
+	if x != nil {
+		return x.Scale
+	}
+	return 0
+}
+
+func (x *MemoryOperand) GetDisplacement() int64 {
+	if x != nil {
+		return x.Displacement
+	}
+	return 0
+}
+
+// ObjectType is a matched object.
+type ObjectType struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        int64  `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	TypeName    string `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	Value       *Value `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Descriptor_ string `protobuf:"bytes,4,opt,name=descriptor,proto3" json:"descriptor,omitempty"`
+	Sensitive   bool   `protobuf:"varint,5,opt,name=sensitive,proto3" json:"sensitive,omitempty"`
+	Width       int64  `protobuf:"varint,6,opt,name=width,proto3" json:"width,omitempty"`
+	Text        string `protobuf:"bytes,7,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *ObjectType) Reset() {
+	*x = ObjectType{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObjectType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectType) ProtoMessage() {}
+
+func (x *ObjectType) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectType.ProtoReflect.Descriptor instead.
+func (*ObjectType) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ObjectType) GetType() int64 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *ObjectType) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *ObjectType) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ObjectType) GetDescriptor_() string {
+	if x != nil {
+		return x.Descriptor_
+	}
+	return ""
+}
+
+func (x *ObjectType) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
+func (x *ObjectType) GetWidth() int64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ObjectType) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Flag is a flag a Flags slot accepts.
+type Flag struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value uint64 `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Flag) Reset() {
+	*x = Flag{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Flag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flag) ProtoMessage() {}
+
+func (x *Flag) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flag.ProtoReflect.Descriptor instead.
+func (*Flag) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Flag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Flag) GetValue() uint64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// TemplateObject is a template slot. Validate, Matcher and ValidateContext callbacks have
+// no serialized form.
+type TemplateObject struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type          int64       `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	TypeName      string      `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	Value         *ObjectType `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Error         string      `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	MinValue      uint64      `protobuf:"varint,5,opt,name=min_value,json=minValue,proto3" json:"min_value,omitempty"`
+	MaxValue      uint64      `protobuf:"varint,6,opt,name=max_value,json=maxValue,proto3" json:"max_value,omitempty"`
+	Sensitive     bool        `protobuf:"varint,7,opt,name=sensitive,proto3" json:"sensitive,omitempty"`
+	Group         string      `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`
+	SameAs        int64       `protobuf:"varint,9,opt,name=same_as,json=sameAs,proto3" json:"same_as,omitempty"`
+	DifferentFrom int64       `protobuf:"varint,10,opt,name=different_from,json=differentFrom,proto3" json:"different_from,omitempty"`
+	Name          string      `protobuf:"bytes,11,opt,name=name,proto3" json:"name,omitempty"`
+	Choices       []string    `protobuf:"bytes,12,rep,name=choices,proto3" json:"choices,omitempty"`
+	Flags         []*Flag     `protobuf:"bytes,13,rep,name=flags,proto3" json:"flags,omitempty"`
+}
+
+func (x *TemplateObject) Reset() {
+	*x = TemplateObject{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TemplateObject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemplateObject) ProtoMessage() {}
+
+func (x *TemplateObject) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemplateObject.ProtoReflect.Descriptor instead.
+func (*TemplateObject) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TemplateObject) GetType() int64 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *TemplateObject) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *TemplateObject) GetValue() *ObjectType {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TemplateObject) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TemplateObject) GetMinValue() uint64 {
+	if x != nil {
+		return x.MinValue
+	}
+	return 0
+}
+
+func (x *TemplateObject) GetMaxValue() uint64 {
+	if x != nil {
+		return x.MaxValue
+	}
+	return 0
+}
+
+func (x *TemplateObject) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
+func (x *TemplateObject) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *TemplateObject) GetSameAs() int64 {
+	if x != nil {
+		return x.SameAs
+	}
+	return 0
+}
+
+func (x *TemplateObject) GetDifferentFrom() int64 {
+	if x != nil {
+		return x.DifferentFrom
+	}
+	return 0
+}
+
+func (x *TemplateObject) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TemplateObject) GetChoices() []string {
+	if x != nil {
+		return x.Choices
+	}
+	return nil
+}
+
+func (x *TemplateObject) GetFlags() []*Flag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+// IncludeSite is an include directive that led to a line.
+type IncludeSite struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	File string `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Line int64  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+}
+
+func (x *IncludeSite) Reset() {
+	*x = IncludeSite{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IncludeSite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncludeSite) ProtoMessage() {}
+
+func (x *IncludeSite) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncludeSite.ProtoReflect.Descriptor instead.
+func (*IncludeSite) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *IncludeSite) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *IncludeSite) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+// LineSegment is a physical line a continued statement was joined from.
+type LineSegment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Line   int64 `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Column int64 `protobuf:"varint,2,opt,name=column,proto3" json:"column,omitempty"`
+	Offset int64 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *LineSegment) Reset() {
+	*x = LineSegment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LineSegment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineSegment) ProtoMessage() {}
+
+func (x *LineSegment) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineSegment.ProtoReflect.Descriptor instead.
+func (*LineSegment) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LineSegment) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *LineSegment) GetColumn() int64 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *LineSegment) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// Operand is a matched operand of a line.
+type Operand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Slot     int64       `protobuf:"varint,1,opt,name=slot,proto3" json:"slot,omitempty"`
+	TypeName string      `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	Group    string      `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	Name     string      `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Object   *ObjectType `protobuf:"bytes,5,opt,name=object,proto3" json:"object,omitempty"`
+}
+
+func (x *Operand) Reset() {
+	*x = Operand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Operand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operand) ProtoMessage() {}
+
+func (x *Operand) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operand.ProtoReflect.Descriptor instead.
+func (*Operand) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Operand) GetSlot() int64 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Operand) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *Operand) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Operand) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Operand) GetObject() *ObjectType {
+	if x != nil {
+		return x.Object
+	}
+	return nil
+}
+
+// ObjectList holds the objects of a capture group.
+type ObjectList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Objects []*ObjectType `protobuf:"bytes,1,rep,name=objects,proto3" json:"objects,omitempty"`
+}
+
+func (x *ObjectList) Reset() {
+	*x = ObjectList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObjectList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectList) ProtoMessage() {}
+
+func (x *ObjectList) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectList.ProtoReflect.Descriptor instead.
+func (*ObjectList) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ObjectList) GetObjects() []*ObjectType {
+	if x != nil {
+		return x.Objects
+	}
+	return nil
+}
+
+// Warning is a problem that did not stop a line from matching.
+type Warning struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column  int64  `protobuf:"varint,1,opt,name=column,proto3" json:"column,omitempty"`
+	Length  int64  `protobuf:"varint,2,opt,name=length,proto3" json:"length,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Warning) GetColumn() int64 {
+	if x != nil {
+		return x.Column
+	}
+	return 0
+}
+
+func (x *Warning) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Warning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// ParsedLine is the structured form of a parsed source line.
+type ParsedLine struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Line      int64                  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	File      string                 `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Includes  []*IncludeSite         `protobuf:"bytes,3,rep,name=includes,proto3" json:"includes,omitempty"`
+	Offset    int64                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	Segments  []*LineSegment         `protobuf:"bytes,5,rep,name=segments,proto3" json:"segments,omitempty"`
+	RawText   string                 `protobuf:"bytes,6,opt,name=raw_text,json=rawText,proto3" json:"raw_text,omitempty"`
+	Expanded  string                 `protobuf:"bytes,7,opt,name=expanded,proto3" json:"expanded,omitempty"`
+	Label     string                 `protobuf:"bytes,8,opt,name=label,proto3" json:"label,omitempty"`
+	Mnemonic  string                 `protobuf:"bytes,9,opt,name=mnemonic,proto3" json:"mnemonic,omitempty"`
+	Operands  []*Operand             `protobuf:"bytes,10,rep,name=operands,proto3" json:"operands,omitempty"`
+	Comment   string                 `protobuf:"bytes,11,opt,name=comment,proto3" json:"comment,omitempty"`
+	Directive string                 `protobuf:"bytes,12,opt,name=directive,proto3" json:"directive,omitempty"`
+	Address   uint64                 `protobuf:"varint,13,opt,name=address,proto3" json:"address,omitempty"`
+	Size      uint64                 `protobuf:"varint,14,opt,name=size,proto3" json:"size,omitempty"`
+	Cycles    int64                  `protobuf:"varint,15,opt,name=cycles,proto3" json:"cycles,omitempty"`
+	Objects   []*ObjectType          `protobuf:"bytes,16,rep,name=objects,proto3" json:"objects,omitempty"`
+	Groups    map[string]*ObjectList `protobuf:"bytes,17,rep,name=groups,proto3" json:"groups,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Named     map[string]*ObjectType `protobuf:"bytes,18,rep,name=named,proto3" json:"named,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Warnings  []*Warning             `protobuf:"bytes,19,rep,name=warnings,proto3" json:"warnings,omitempty"`
+}
+
+func (x *ParsedLine) Reset() {
+	*x = ParsedLine{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ParsedLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParsedLine) ProtoMessage() {}
+
+func (x *ParsedLine) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParsedLine.ProtoReflect.Descriptor instead.
+func (*ParsedLine) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ParsedLine) GetLine() int64 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ParsedLine) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetIncludes() []*IncludeSite {
+	if x != nil {
+		return x.Includes
+	}
+	return nil
+}
+
+func (x *ParsedLine) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ParsedLine) GetSegments() []*LineSegment {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+func (x *ParsedLine) GetRawText() string {
+	if x != nil {
+		return x.RawText
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetExpanded() string {
+	if x != nil {
+		return x.Expanded
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetMnemonic() string {
+	if x != nil {
+		return x.Mnemonic
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetOperands() []*Operand {
+	if x != nil {
+		return x.Operands
+	}
+	return nil
+}
+
+func (x *ParsedLine) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetDirective() string {
+	if x != nil {
+		return x.Directive
+	}
+	return ""
+}
+
+func (x *ParsedLine) GetAddress() uint64 {
+	if x != nil {
+		return x.Address
+	}
+	return 0
+}
+
+func (x *ParsedLine) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ParsedLine) GetCycles() int64 {
+	if x != nil {
+		return x.Cycles
+	}
+	return 0
+}
+
+func (x *ParsedLine) GetObjects() []*ObjectType {
+	if x != nil {
+		return x.Objects
+	}
+	return nil
+}
+
+func (x *ParsedLine) GetGroups() map[string]*ObjectList {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *ParsedLine) GetNamed() map[string]*ObjectType {
+	if x != nil {
+		return x.Named
+	}
+	return nil
+}
+
+func (x *ParsedLine) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// NearMatch is a template a failed line came close to matching.
+type NearMatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Mnemonic string `protobuf:"bytes,1,opt,name=mnemonic,proto3" json:"mnemonic,omitempty"`
+	Form     string `protobuf:"bytes,2,opt,name=form,proto3" json:"form,omitempty"`
+	Matched  int64  `protobuf:"varint,3,opt,name=matched,proto3" json:"matched,omitempty"`
+	Expected string `protobuf:"bytes,4,opt,name=expected,proto3" json:"expected,omitempty"`
+}
+
+func (x *NearMatch) Reset() {
+	*x = NearMatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NearMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearMatch) ProtoMessage() {}
+
+func (x *NearMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearMatch.ProtoReflect.Descriptor instead.
+func (*NearMatch) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *NearMatch) GetMnemonic() string {
+	if x != nil {
+		return x.Mnemonic
+	}
+	return ""
+}
+
+func (x *NearMatch) GetForm() string {
+	if x != nil {
+		return x.Form
+	}
+	return ""
+}
+
+func (x *NearMatch) GetMatched() int64 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+func (x *NearMatch) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+// Suggestions helps fix a line that failed to match.
+type Suggestions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Mnemonics []string     `protobuf:"bytes,1,rep,name=mnemonics,proto3" json:"mnemonics,omitempty"`
+	Templates []*NearMatch `protobuf:"bytes,2,rep,name=templates,proto3" json:"templates,omitempty"`
+	Expected  string       `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"`
+}
+
+func (x *Suggestions) Reset() {
+	*x = Suggestions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Suggestions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestions) ProtoMessage() {}
+
+func (x *Suggestions) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestions.ProtoReflect.Descriptor instead.
+func (*Suggestions) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Suggestions) GetMnemonics() []string {
+	if x != nil {
+		return x.Mnemonics
+	}
+	return nil
+}
+
+func (x *Suggestions) GetTemplates() []*NearMatch {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+func (x *Suggestions) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+// LineResult is the outcome of matching a line.
+type LineResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Line          *ParsedLine    `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	Ok            bool           `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string         `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorColumn   int64          `protobuf:"varint,4,opt,name=error_column,json=errorColumn,proto3" json:"error_column,omitempty"`
+	ErrorLength   int64          `protobuf:"varint,5,opt,name=error_length,json=errorLength,proto3" json:"error_length,omitempty"`
+	Expected      string         `protobuf:"bytes,6,opt,name=expected,proto3" json:"expected,omitempty"`
+	TemplateError string         `protobuf:"bytes,7,opt,name=template_error,json=templateError,proto3" json:"template_error,omitempty"`
+	Origin        []*TokenOrigin `protobuf:"bytes,8,rep,name=origin,proto3" json:"origin,omitempty"`
+	Suggestions   *Suggestions   `protobuf:"bytes,9,opt,name=suggestions,proto3" json:"suggestions,omitempty"`
+}
+
+func (x *LineResult) Reset() {
+	*x = LineResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LineResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineResult) ProtoMessage() {}
+
+func (x *LineResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineResult.ProtoReflect.Descriptor instead.
+func (*LineResult) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LineResult) GetLine() *ParsedLine {
+	if x != nil {
+		return x.Line
+	}
+	return nil
+}
+
+func (x *LineResult) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *LineResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *LineResult) GetErrorColumn() int64 {
+	if x != nil {
+		return x.ErrorColumn
+	}
+	return 0
+}
+
+func (x *LineResult) GetErrorLength() int64 {
+	if x != nil {
+		return x.ErrorLength
+	}
+	return 0
+}
+
+func (x *LineResult) GetExpected() string {
+	if x != nil {
+		return x.Expected
+	}
+	return ""
+}
+
+func (x *LineResult) GetTemplateError() string {
+	if x != nil {
+		return x.TemplateError
+	}
+	return ""
+}
+
+func (x *LineResult) GetOrigin() []*TokenOrigin {
+	if x != nil {
+		return x.Origin
+	}
+	return nil
+}
+
+func (x *LineResult) GetSuggestions() *Suggestions {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+// LineResults holds the results of the lines of a source, in order.
+type LineResults struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lines []*LineResult `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"`
+}
+
+func (x *LineResults) Reset() {
+	*x = LineResults{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_templateparser_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LineResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineResults) ProtoMessage() {}
+
+func (x *LineResults) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_templateparser_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineResults.ProtoReflect.Descriptor instead.
+func (*LineResults) Descriptor() ([]byte, []int) {
+	return file_pb_templateparser_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LineResults) GetLines() []*LineResult {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+var File_pb_templateparser_proto protoreflect.FileDescriptor
+
+var file_pb_templateparser_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x70, 0x62, 0x2f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72,
+	0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x22, 0x95, 0x01, 0x0a, 0x0b, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x61, 0x63,
+	0x72, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x61, 0x63, 0x72, 0x6f, 0x12,
+	0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c,
+	0x69, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x64, 0x4c, 0x69, 0x6e,
+	0x65, 0x22, 0x54, 0x0a, 0x05, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1b,
+	0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x4a, 0x04, 0x08, 0x04, 0x10, 0x05, 0x22, 0xe9, 0x01, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x23, 0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1f, 0x0a, 0x0a, 0x75, 0x69, 0x6e, 0x74, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x09, 0x75, 0x69,
+	0x6e, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x12, 0x48, 0x00, 0x52, 0x08, 0x69, 0x6e,
+	0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1f, 0x0a, 0x0a, 0x62, 0x6f, 0x6f, 0x6c, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x62, 0x6f,
+	0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a, 0x07, 0x62, 0x69, 0x67, 0x5f, 0x69,
+	0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x62, 0x69, 0x67, 0x49,
+	0x6e, 0x74, 0x12, 0x37, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72,
+	0x73, 0x65, 0x72, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x6e,
+	0x64, 0x48, 0x00, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x06, 0x0a, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x22, 0xeb, 0x01, 0x0a, 0x0d, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x6e, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x5f, 0x62, 0x61, 0x73,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x61, 0x73, 0x42, 0x61, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x62, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04,
+	0x62, 0x61, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x62, 0x61, 0x73, 0x65, 0x57, 0x69,
+	0x64, 0x74, 0x68, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x73, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x68, 0x61, 0x73, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x5f,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x57, 0x69, 0x64, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x61, 0x6c, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x12, 0x52, 0x0c, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x22, 0xd2, 0x01, 0x0a, 0x0a, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x2b, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65,
+	0x72, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1e,
+	0x0a, 0x0a, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x30, 0x0a, 0x04, 0x46, 0x6c, 0x61, 0x67, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x91, 0x03, 0x0a, 0x0e, 0x54, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x4f, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6d, 0x69, 0x6e, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x73, 0x65, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x65, 0x5f, 0x61, 0x73, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x73, 0x61, 0x6d, 0x65, 0x41, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x64,
+	0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0d, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x74, 0x46, 0x72,
+	0x6f, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65,
+	0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x6f, 0x69, 0x63, 0x65, 0x73,
+	0x12, 0x2a, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x14, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72,
+	0x2e, 0x46, 0x6c, 0x61, 0x67, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x22, 0x35, 0x0a, 0x0b,
+	0x49, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x53, 0x69, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66,
+	0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c,
+	0x69, 0x6e, 0x65, 0x22, 0x51, 0x0a, 0x0b, 0x4c, 0x69, 0x6e, 0x65, 0x53, 0x65, 0x67, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x16,
+	0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x98, 0x01, 0x0a, 0x07, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x73, 0x6c, 0x6f, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x79, 0x70, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a,
+	0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x4f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x06, 0x6f, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x22, 0x42, 0x0a, 0x0a, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x34, 0x0a, 0x07, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65,
+	0x72, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x6f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x73, 0x22, 0x53, 0x0a, 0x07, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67,
+	0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xef, 0x06, 0x0a, 0x0a, 0x50,
+	0x61, 0x72, 0x73, 0x65, 0x64, 0x4c, 0x69, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c,
+	0x65, 0x12, 0x37, 0x0a, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61,
+	0x72, 0x73, 0x65, 0x72, 0x2e, 0x49, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x53, 0x69, 0x74, 0x65,
+	0x52, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x12, 0x37, 0x0a, 0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70,
+	0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x6e, 0x65, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x72,
+	0x61, 0x77, 0x5f, 0x74, 0x65, 0x78, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72,
+	0x61, 0x77, 0x54, 0x65, 0x78, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64,
+	0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64,
+	0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x6e, 0x65, 0x6d,
+	0x6f, 0x6e, 0x69, 0x63, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x6e, 0x65, 0x6d,
+	0x6f, 0x6e, 0x69, 0x63, 0x12, 0x33, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x73,
+	0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x52,
+	0x08, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x76,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x07, 0x6f, 0x62, 0x6a, 0x65, 0x63,
+	0x74, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x12, 0x3e, 0x0a,
+	0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x11, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e,
+	0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x50,
+	0x61, 0x72, 0x73, 0x65, 0x64, 0x4c, 0x69, 0x6e, 0x65, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x3b, 0x0a,
+	0x05, 0x6e, 0x61, 0x6d, 0x65, 0x64, 0x18, 0x12, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x74,
+	0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x50, 0x61,
+	0x72, 0x73, 0x65, 0x64, 0x4c, 0x69, 0x6e, 0x65, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x64, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x08, 0x77, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x13, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x74,
+	0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x57, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x1a,
+	0x55, 0x0a, 0x0b, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x30, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72,
+	0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x54, 0x0a, 0x0a, 0x4e, 0x61, 0x6d, 0x65, 0x64, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x30, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
+	0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x71, 0x0a, 0x09,
+	0x4e, 0x65, 0x61, 0x72, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x6e, 0x65,
+	0x6d, 0x6f, 0x6e, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x6e, 0x65,
+	0x6d, 0x6f, 0x6e, 0x69, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63,
+	0x68, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x22,
+	0x80, 0x01, 0x0a, 0x0b, 0x53, 0x75, 0x67, 0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x1c, 0x0a, 0x09, 0x6d, 0x6e, 0x65, 0x6d, 0x6f, 0x6e, 0x69, 0x63, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x09, 0x6d, 0x6e, 0x65, 0x6d, 0x6f, 0x6e, 0x69, 0x63, 0x73, 0x12, 0x37, 0x0a,
+	0x09, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65,
+	0x72, 0x2e, 0x4e, 0x65, 0x61, 0x72, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x09, 0x74, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x22, 0xdf, 0x02, 0x0a, 0x0a, 0x4c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x2e, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72,
+	0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x64, 0x4c, 0x69, 0x6e, 0x65, 0x52, 0x04, 0x6c, 0x69, 0x6e,
+	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f,
+	0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x1a, 0x0a,
+	0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x33, 0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65,
+	0x72, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x52, 0x06, 0x6f,
+	0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x3d, 0x0a, 0x0b, 0x73, 0x75, 0x67, 0x67, 0x65, 0x73, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x74, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2e, 0x53, 0x75, 0x67, 0x67,
+	0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0b, 0x73, 0x75, 0x67, 0x67, 0x65, 0x73, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x22, 0x3f, 0x0a, 0x0b, 0x4c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x12, 0x30, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72,
+	0x73, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x05,
+	0x6c, 0x69, 0x6e, 0x65, 0x73, 0x42, 0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x61, 0x6e, 0x74, 0x79, 0x70, 0x61, 0x73, 0x2f, 0x54, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x50, 0x61, 0x72, 0x73, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x2f, 0x74,
+	0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x70, 0x61, 0x72, 0x73, 0x65, 0x72, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pb_templateparser_proto_rawDescOnce sync.Once
+	file_pb_templateparser_proto_rawDescData = file_pb_templateparser_proto_rawDesc
+)
+
+func file_pb_templateparser_proto_rawDescGZIP() []byte {
+	file_pb_templateparser_proto_rawDescOnce.Do(func() {
+		file_pb_templateparser_proto_rawDescData = protoimpl.X.CompressGZIP(file_pb_templateparser_proto_rawDescData)
+	})
+	return file_pb_templateparser_proto_rawDescData
+}
+
+var file_pb_templateparser_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_pb_templateparser_proto_goTypes = []any{
+	(*TokenOrigin)(nil),    // 0: templateparser.TokenOrigin
+	(*Token)(nil),          // 1: templateparser.Token
+	(*Value)(nil),          // 2: templateparser.Value
+	(*MemoryOperand)(nil),  // 3: templateparser.MemoryOperand
+	(*ObjectType)(nil),     // 4: templateparser.ObjectType
+	(*Flag)(nil),           // 5: templateparser.Flag
+	(*TemplateObject)(nil), // 6: templateparser.TemplateObject
+	(*IncludeSite)(nil),    // 7: templateparser.IncludeSite
+	(*LineSegment)(nil),    // 8: templateparser.LineSegment
+	(*Operand)(nil),        // 9: templateparser.Operand
+	(*ObjectList)(nil),     // 10: templateparser.ObjectList
+	(*Warning)(nil),        // 11: templateparser.Warning
+	(*ParsedLine)(nil),     // 12: templateparser.ParsedLine
+	(*NearMatch)(nil),      // 13: templateparser.NearMatch
+	(*Suggestions)(nil),    // 14: templateparser.Suggestions
+	(*LineResult)(nil),     // 15: templateparser.LineResult
+	(*LineResults)(nil),    // 16: templateparser.LineResults
+	nil,                    // 17: templateparser.ParsedLine.GroupsEntry
+	nil,                    // 18: templateparser.ParsedLine.NamedEntry
+}
+var file_pb_templateparser_proto_depIdxs = []int32{
+	3,  // 0: templateparser.Value.memory:type_name -> templateparser.MemoryOperand
+	2,  // 1: templateparser.ObjectType.value:type_name -> templateparser.Value
+	4,  // 2: templateparser.TemplateObject.value:type_name -> templateparser.ObjectType
+	5,  // 3: templateparser.TemplateObject.flags:type_name -> templateparser.Flag
+	4,  // 4: templateparser.Operand.object:type_name -> templateparser.ObjectType
+	4,  // 5: templateparser.ObjectList.objects:type_name -> templateparser.ObjectType
+	7,  // 6: templateparser.ParsedLine.includes:type_name -> templateparser.IncludeSite
+	8,  // 7: templateparser.ParsedLine.segments:type_name -> templateparser.LineSegment
+	9,  // 8: templateparser.ParsedLine.operands:type_name -> templateparser.Operand
+	4,  // 9: templateparser.ParsedLine.objects:type_name -> templateparser.ObjectType
+	17, // 10: templateparser.ParsedLine.groups:type_name -> templateparser.ParsedLine.GroupsEntry
+	18, // 11: templateparser.ParsedLine.named:type_name -> templateparser.ParsedLine.NamedEntry
+	11, // 12: templateparser.ParsedLine.warnings:type_name -> templateparser.Warning
+	13, // 13: templateparser.Suggestions.templates:type_name -> templateparser.NearMatch
+	12, // 14: templateparser.LineResult.line:type_name -> templateparser.ParsedLine
+	0,  // 15: templateparser.LineResult.origin:type_name -> templateparser.TokenOrigin
+	14, // 16: templateparser.LineResult.suggestions:type_name -> templateparser.Suggestions
+	15, // 17: templateparser.LineResults.lines:type_name -> templateparser.LineResult
+	10, // 18: templateparser.ParsedLine.GroupsEntry.value:type_name -> templateparser.ObjectList
+	4,  // 19: templateparser.ParsedLine.NamedEntry.value:type_name -> templateparser.ObjectType
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_pb_templateparser_proto_init() }
+func file_pb_templateparser_proto_init() {
+	if File_pb_templateparser_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pb_templateparser_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*TokenOrigin); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Token); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Value); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*MemoryOperand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ObjectType); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Flag); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*TemplateObject); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*IncludeSite); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*LineSegment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*Operand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*ObjectList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*Warning); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ParsedLine); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*NearMatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*Suggestions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*LineResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_templateparser_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*LineResults); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pb_templateparser_proto_msgTypes[2].OneofWrappers = []any{
+		(*Value_StringValue)(nil),
+		(*Value_UintValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_BigInt)(nil),
+		(*Value_Memory)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pb_templateparser_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pb_templateparser_proto_goTypes,
+		DependencyIndexes: file_pb_templateparser_proto_depIdxs,
+		MessageInfos:      file_pb_templateparser_proto_msgTypes,
+	}.Build()
+	File_pb_templateparser_proto = out.File
+	file_pb_templateparser_proto_rawDesc = nil
+	file_pb_templateparser_proto_goTypes = nil
+	file_pb_templateparser_proto_depIdxs = nil
+}
@@ -0,0 +1,71 @@
+// Package serve provides a grammar playground: a small web UI with a grammar pane and a
+// sample input pane that parses the input live and shows its tokens, matched lines and
+// diagnostics. It is meant for iterating on new template sets.
+package serve
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	"github.com/jantypas/TemplateParser/jsapi"
+)
+
+//go:embed static
+var static embed.FS
+
+// maxRequestBytes limits the size of a parse request.
+const maxRequestBytes = 1 << 20
+
+// ParseRequest
+// is the body of a POST to /api/parse. Format is "yaml" or "json"; when empty it is
+// guessed from the grammar text.
+type ParseRequest = jsapi.Request
+
+// ParseResponse
+// is the reply to a parse request. Error reports a grammar that failed to load, in which
+// case the other fields are empty. Tokens holds the tokens of every source line, blank
+// lines included, so they line up with line numbers.
+type ParseResponse = jsapi.Response
+
+// Handler
+// returns the playground: the UI at / and the parse API at /api/parse.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	ui, _ := fs.Sub(static, "static")
+	mux.Handle("/", http.FileServer(http.FS(ui)))
+	mux.HandleFunc("/api/parse", handleParse)
+	return mux
+}
+
+// ListenAndServe
+// serves the playground on addr, such as "localhost:8080".
+func ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, Handler())
+}
+
+// handleParse
+// parses a sample source with a grammar.
+func handleParse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST a ParseRequest", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ParseRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Parse(req))
+}
+
+// Parse
+// loads the grammar of a request into a fresh Parser in hardened mode and parses its
+// source within fixed limits, as jsapi.Run does. A source over the limits reports a
+// diagnostic after the lines parsed before it.
+func Parse(req ParseRequest) ParseResponse {
+	return jsapi.Run(req)
+}
@@ -0,0 +1,68 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testGrammar = `templates:
+  - mnemonic: li
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+`
+
+// request
+// sends a request to the playground and returns its recorded reply.
+func request(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestParseAPI(t *testing.T) {
+	body, _ := json.Marshal(ParseRequest{Grammar: testGrammar, Source: "li r1, 5\n\nli r1, r2\n"})
+	rec := request(t, http.MethodPost, "/api/parse", string(body))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("POST /api/parse = %d %s", rec.Code, rec.Body.String())
+	}
+	var resp ParseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != "" || len(resp.Tokens) != 3 || len(resp.Tokens[1]) != 0 {
+		t.Errorf("response = %+v, want the tokens of three lines, the second blank", resp)
+	}
+	if len(resp.Lines) != 2 || !resp.Lines[0].Ok || resp.Lines[1].Ok || len(resp.Diagnostics) != 1 {
+		t.Errorf("lines = %+v, diagnostics %v, want the second line to fail", resp.Lines, resp.Diagnostics)
+	}
+}
+
+func TestParseAPIRejects(t *testing.T) {
+	if rec := request(t, http.MethodGet, "/api/parse", ""); rec.Code != http.StatusMethodNotAllowed ||
+		rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET /api/parse = %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if rec := request(t, http.MethodPost, "/api/parse", "{"); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST of bad JSON = %d, want 400", rec.Code)
+	}
+	body, _ := json.Marshal(ParseRequest{Grammar: "templates: [", Source: "li r1, 5"})
+	var resp ParseResponse
+	rec := request(t, http.MethodPost, "/api/parse", string(body))
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+		t.Errorf("bad grammar = %s, want an error", rec.Body.String())
+	}
+}
+
+func TestServesUI(t *testing.T) {
+	rec := request(t, http.MethodGet, "/", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "api/parse") {
+		t.Errorf("GET / = %d, want the playground page calling api/parse", rec.Code)
+	}
+}
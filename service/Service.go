@@ -0,0 +1,310 @@
+// Package service exposes parsers over HTTP as a shared, central validator: a team
+// uploads its template definitions once under a name and then posts source text to be
+// parsed against them, receiving the parsed lines and diagnostics as JSON. Unlike the
+// playground of package serve, which loads a grammar for every request, grammars stay
+// loaded, frozen, until they are replaced or deleted.
+//
+// The API is:
+//
+//	GET    /grammars              names and hashes of the loaded grammars
+//	PUT    /grammars/{name}       load a grammar from the body, YAML or JSON
+//	GET    /grammars/{name}       the grammar as JSON
+//	DELETE /grammars/{name}       unload a grammar
+//	POST   /grammars/{name}/parse parse the body as source text
+//	GET    /metrics               parse statistics in the Prometheus text format
+//
+// JSON grammars are recognized by a Content-Type of application/json or a body starting
+// with {; anything else is read as YAML. Parse requests accepting application/x-protobuf
+// get the lines as a LineResults message of package pb instead of a JSON ParseResult. The
+// service needs nothing beyond the standard library; a gRPC front end can wrap Load and
+// Parse.
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+	"github.com/jantypas/TemplateParser/pb"
+)
+
+// ProtobufType is the media type of protobuf replies.
+const ProtobufType = "application/x-protobuf"
+
+// DefaultMaxBodyBytes limits the size of uploaded grammars and sources when
+// Service.MaxBodyBytes is zero.
+const DefaultMaxBodyBytes = 4 << 20
+
+// DefaultLimits bounds the work of parsing one request when the service's configuration
+// sets no limits, so that macros cannot expand without end.
+var DefaultLimits = TemplateParser.Limits{Tokens: 1 << 22, Expansions: 1 << 18}
+
+// Service
+// holds the grammars loaded by name and serves the API. It is safe for concurrent use.
+// MaxBodyBytes limits the size of request bodies, zero meaning DefaultMaxBodyBytes; set it
+// before serving.
+type Service struct {
+	MaxBodyBytes int64
+
+	config   TemplateParser.ParserConfig
+	stats    *TemplateParser.Stats
+	mu       sync.RWMutex
+	grammars map[string]*TemplateParser.Parser
+}
+
+// GrammarInfo
+// describes a loaded grammar: its name, its GrammarHash and its mnemonics.
+type GrammarInfo struct {
+	Name      string   `json:"name"`
+	Hash      string   `json:"hash"`
+	Mnemonics []string `json:"mnemonics"`
+}
+
+// ParseResult
+// is the reply to a parse request: whether every line parsed, the line results, the
+// labels defined and a diagnostic for every failed line and warning. Error reports a
+// source that could not be read in full, such as one over the limits, after the lines
+// parsed before it.
+type ParseResult struct {
+	Ok          bool                        `json:"ok"`
+	Lines       []TemplateParser.LineResult `json:"lines"`
+	Symbols     []TemplateParser.Symbol     `json:"symbols"`
+	Diagnostics []TemplateParser.Diagnostic `json:"diagnostics"`
+	Error       string                      `json:"error,omitempty"`
+}
+
+// errorReply
+// is the body of a failed request.
+type errorReply struct {
+	Error string `json:"error"`
+}
+
+// New
+// returns a service without grammars whose parsers use config, made hardened. A
+// configuration without limits gets DefaultLimits.
+func New(config TemplateParser.ParserConfig) *Service {
+	config.Hardened = true
+	if config.Limits == (TemplateParser.Limits{}) {
+		config.Limits = DefaultLimits
+	}
+	return &Service{config: config, stats: TemplateParser.NewStats(), grammars: make(map[string]*TemplateParser.Parser)}
+}
+
+// Stats
+// returns the collector the parsers of every grammar loaded record their lines in.
+func (s *Service) Stats() *TemplateParser.Stats {
+	return s.stats
+}
+
+// Load
+// loads a grammar in YAML or JSON under a name, replacing any grammar of that name, and
+// freezes its parser.
+func (s *Service) Load(name string, grammar []byte, format string) (GrammarInfo, error) {
+	if name == "" || strings.ContainsAny(name, "/ \t\n") {
+		return GrammarInfo{}, errors.New("invalid grammar name " + name)
+	}
+	parser := TemplateParser.NewParser(s.config)
+	var err error
+	switch format {
+	case "json":
+		err = parser.LoadTemplatesFromJSON(bytes.NewReader(grammar))
+	case "yaml", "":
+		err = parser.LoadTemplatesFromYAML(bytes.NewReader(grammar))
+	default:
+		err = errors.New("unknown grammar format " + format)
+	}
+	if err != nil {
+		return GrammarInfo{}, err
+	}
+	parser.SetStats(s.stats)
+	parser.Freeze()
+	s.mu.Lock()
+	s.grammars[name] = parser
+	s.mu.Unlock()
+	return grammarInfo(name, parser), nil
+}
+
+// Unload
+// removes the grammar of a name, reporting whether there was one.
+func (s *Service) Unload(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, found := s.grammars[name]
+	delete(s.grammars, name)
+	return found
+}
+
+// Grammar
+// returns the parser of a loaded grammar.
+func (s *Service) Grammar(name string) (*TemplateParser.Parser, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	parser, found := s.grammars[name]
+	return parser, found
+}
+
+// Grammars
+// describes the loaded grammars, sorted by name.
+func (s *Service) Grammars() []GrammarInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	infos := make([]GrammarInfo, 0, len(s.grammars))
+	for name, parser := range s.grammars {
+		infos = append(infos, grammarInfo(name, parser))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// grammarInfo
+// describes a loaded grammar.
+func grammarInfo(name string, parser *TemplateParser.Parser) GrammarInfo {
+	return GrammarInfo{Name: name, Hash: parser.GrammarHash(), Mnemonics: parser.Mnemonics()}
+}
+
+// Parse
+// parses source text with a loaded grammar. Include directives in the source fail, as
+// the service has no files to read them from. Every request is parsed in a context of its
+// own, so the labels and equ constants it defines are not seen by the requests after it,
+// whichever client sends them.
+func (s *Service) Parse(name string, source io.Reader) (ParseResult, bool) {
+	parser, found := s.Grammar(name)
+	if !found {
+		return ParseResult{}, false
+	}
+	result, err := parser.ParseSourceContext(&TemplateParser.ParseContext{}, source)
+	reply := ParseResult{Ok: err == nil && result.Ok(), Lines: result.Lines,
+		Symbols: result.Symbols.Symbols(), Diagnostics: append(result.Diagnostics(), result.Warnings()...)}
+	if reply.Lines == nil {
+		reply.Lines = []TemplateParser.LineResult{}
+	}
+	if reply.Diagnostics == nil {
+		reply.Diagnostics = []TemplateParser.Diagnostic{}
+	}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	return reply, true
+}
+
+// Handler
+// returns the HTTP handler serving the API.
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /grammars", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusOK, s.Grammars())
+	})
+	mux.HandleFunc("PUT /grammars/{name}", s.handleLoad)
+	mux.HandleFunc("GET /grammars/{name}", s.handleExport)
+	mux.HandleFunc("DELETE /grammars/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.Unload(r.PathValue("name")) {
+			reply(w, http.StatusNotFound, errorReply{"no grammar " + r.PathValue("name")})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /grammars/{name}/parse", s.handleParse)
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		s.stats.WritePrometheus(w, "templateparser")
+	})
+	return mux
+}
+
+// ListenAndServe
+// serves the API on addr, such as "localhost:8081".
+func (s *Service) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// handleLoad
+// loads the grammar in the body of a request.
+func (s *Service) handleLoad(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+	format := "yaml"
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") ||
+		bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		format = "json"
+	}
+	info, err := s.Load(r.PathValue("name"), body, format)
+	if err != nil {
+		reply(w, http.StatusBadRequest, errorReply{err.Error()})
+		return
+	}
+	reply(w, http.StatusOK, info)
+}
+
+// handleExport
+// writes a loaded grammar as JSON.
+func (s *Service) handleExport(w http.ResponseWriter, r *http.Request) {
+	parser, found := s.Grammar(r.PathValue("name"))
+	if !found {
+		reply(w, http.StatusNotFound, errorReply{"no grammar " + r.PathValue("name")})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	parser.ExportTemplatesJSON(w)
+}
+
+// handleParse
+// parses the source text in the body of a request.
+func (s *Service) handleParse(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+	result, found := s.Parse(r.PathValue("name"), bytes.NewReader(body))
+	if !found {
+		reply(w, http.StatusNotFound, errorReply{"no grammar " + r.PathValue("name")})
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), ProtobufType) {
+		data, err := pb.MarshalLineResults(result.Lines)
+		if err != nil {
+			reply(w, http.StatusInternalServerError, errorReply{err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", ProtobufType)
+		w.Write(data)
+		return
+	}
+	reply(w, http.StatusOK, result)
+}
+
+// readBody
+// reads the body of a request within the size limit, replying with an error if it
+// cannot.
+func (s *Service) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	limit := s.MaxBodyBytes
+	if limit == 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		reply(w, status, errorReply{err.Error()})
+		return nil, false
+	}
+	return body, true
+}
+
+// reply
+// writes a JSON reply.
+func reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
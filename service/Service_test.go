@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+const testGrammar = `templates:
+  - mnemonic: li
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+`
+
+// post
+// sends a request to the handler and returns the status and body of its reply.
+func post(t *testing.T, h http.Handler, method, path, body string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec.Code, rec.Body.String()
+}
+
+func TestParseRequestsDoNotShareConstants(t *testing.T) {
+	h := New(TemplateParser.DefaultParserConfig()).Handler()
+	if code, body := post(t, h, http.MethodPut, "/grammars/isa", testGrammar); code != http.StatusOK {
+		t.Fatalf("loading the grammar: %d %s", code, body)
+	}
+	for _, tt := range []struct {
+		client string
+		source string
+		ok     bool
+	}{
+		{"first", "foo equ 10\nli r1, foo\n", true},
+		{"second", "foo equ 20\nli r1, foo\n", true},
+		{"third", "li r1, foo\n", false},
+	} {
+		code, body := post(t, h, http.MethodPost, "/grammars/isa/parse", tt.source)
+		if code != http.StatusOK {
+			t.Fatalf("%s client: %d %s", tt.client, code, body)
+		}
+		var result ParseResult
+		if err := json.Unmarshal([]byte(body), &result); err != nil {
+			t.Fatalf("%s client: %v", tt.client, err)
+		}
+		if result.Ok != tt.ok {
+			t.Errorf("%s client: ok = %v, want %v: %s", tt.client, result.Ok, tt.ok, body)
+		}
+	}
+}
+
+func TestParseUnknownGrammar(t *testing.T) {
+	h := New(TemplateParser.DefaultParserConfig()).Handler()
+	if code, _ := post(t, h, http.MethodPost, "/grammars/none/parse", "li r1, 1\n"); code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,148 @@
+// Package templatetest provides assertion helpers and a golden-file harness for test
+// suites of grammars built on the TemplateParser package.
+package templatetest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+// AssertParses
+// parses line against templateList and fails the test unless the parse succeeds, the first
+// object (the opcode) equals wantOpcode and the remaining objects carry wantOperands in order.
+// Integer operands may be given as any Go integer type. The parsed objects are returned so the
+// caller can make further checks.
+func AssertParses(t testing.TB, templateList []TemplateParser.TemplateObject, line string,
+	wantOpcode string, wantOperands ...interface{}) []TemplateParser.ObjectType {
+	t.Helper()
+	objs, ok, errmsg := TemplateParser.ParseLine(line, templateList)
+	if !ok {
+		t.Fatalf("ParseLine(%q) failed: %s", line, errmsg)
+		return objs
+	}
+	if len(objs) == 0 {
+		t.Fatalf("ParseLine(%q) returned no objects", line)
+		return objs
+	}
+	if got := fmt.Sprint(objs[0].ObjectValue); got != wantOpcode {
+		t.Errorf("ParseLine(%q) opcode = %q, want %q", line, got, wantOpcode)
+	}
+	operands := objs[1:]
+	if len(operands) != len(wantOperands) {
+		t.Errorf("ParseLine(%q) returned %d operands, want %d", line, len(operands), len(wantOperands))
+		return objs
+	}
+	for idx, want := range wantOperands {
+		if !ValueEqual(operands[idx].ObjectValue, want) {
+			t.Errorf("ParseLine(%q) operand %d = %v, want %v", line, idx, operands[idx].ObjectValue, want)
+		}
+	}
+	return objs
+}
+
+// AssertFails
+// parses line against templateList and fails the test unless the parse fails with an error
+// message containing wantCode. An empty wantCode accepts any failure.
+func AssertFails(t testing.TB, templateList []TemplateParser.TemplateObject, line string, wantCode string) {
+	t.Helper()
+	objs, ok, errmsg := TemplateParser.ParseLine(line, templateList)
+	if ok {
+		t.Fatalf("ParseLine(%q) succeeded with %v, want failure %q", line, objs, wantCode)
+		return
+	}
+	if !strings.Contains(errmsg, wantCode) {
+		t.Errorf("ParseLine(%q) failed with %q, want %q", line, errmsg, wantCode)
+	}
+}
+
+// AssertRoundTrip
+// fails the test unless every line parses with parser, formats with FormatLine and parses
+// back to the same result. It returns the formatted lines.
+func AssertRoundTrip(t testing.TB, parser *TemplateParser.Parser, lines ...string) []string {
+	t.Helper()
+	formatted := make([]string, len(lines))
+	for idx, line := range lines {
+		text, ok, errmsg := parser.VerifyLine(line)
+		if !ok {
+			t.Errorf("VerifyLine(%q) failed: %s", line, errmsg)
+		}
+		formatted[idx] = text
+	}
+	return formatted
+}
+
+// AssertGenerated
+// generates n random lines for every template entry registered with parser, using
+// GenerateLine seeded with seed, and fails the test unless every line parses, formats with
+// FormatLine and parses back to the same result. Entries that are unavailable, have guards
+// or cannot be generated are skipped. It returns the lines generated.
+func AssertGenerated(t testing.TB, parser *TemplateParser.Parser, seed int64, n int) []string {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+	lines := make([]string, 0)
+	for _, entry := range parser.Entries() {
+		if _, available := parser.Lookup(entry.Name); !available || len(entry.Guards) > 0 || len(entry.Objects) == 0 {
+			continue
+		}
+		templateList := append([]TemplateParser.TemplateObject(nil), entry.Objects...)
+		if word, _ := templateList[0].TemplateValue.ObjectValue.(string); word == "" {
+			templateList[0].TemplateValue = TemplateParser.StringObject(entry.Name, "")
+		}
+		for idx := 0; idx < n; idx++ {
+			line := parser.GenerateLine(templateList, rng)
+			if line == "" {
+				break
+			}
+			lines = append(lines, line)
+			if _, ok, errmsg := parser.VerifyLine(line); !ok {
+				t.Errorf("Generated line %q of %s: %s", line, entry.Name, errmsg)
+			}
+		}
+	}
+	return lines
+}
+
+// ValueEqual
+// compares a parsed object value with an expected value, treating any Go integer type
+// as equal to the uint64 values produced by the parser.
+func ValueEqual(got interface{}, want interface{}) bool {
+	if g, ok := got.(uint64); ok {
+		if w, ok := toUint64(want); ok {
+			return g == w
+		}
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+// toUint64
+// converts any non-negative Go integer to uint64.
+func toUint64(v interface{}) (uint64, bool) {
+	switch n := v.(type) {
+	case int:
+		return uint64(n), n >= 0
+	case int8:
+		return uint64(n), n >= 0
+	case int16:
+		return uint64(n), n >= 0
+	case int32:
+		return uint64(n), n >= 0
+	case int64:
+		return uint64(n), n >= 0
+	case uint:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case uint64:
+		return n, true
+	}
+	return 0, false
+}
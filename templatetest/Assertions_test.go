@@ -0,0 +1,86 @@
+package templatetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+const testGrammar = `templates:
+  - mnemonic: ldi
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Uint8}
+  - mnemonic: mov
+    operands:
+      - {type: Identifier}
+      - {type: Register}
+      - {type: Comma}
+      - {type: Register}
+`
+
+var testTemplate = []TemplateParser.TemplateObject{
+	{TemplateType: TemplateParser.TokenIdentifier},
+	{TemplateType: TemplateParser.TokenRegister},
+	{TemplateType: TemplateParser.TokenComma},
+	{TemplateType: TemplateParser.TokenUint8},
+}
+
+func testParser(t *testing.T) *TemplateParser.Parser {
+	t.Helper()
+	parser := TemplateParser.NewParser(TemplateParser.DefaultParserConfig())
+	if err := parser.LoadTemplatesFromYAML(strings.NewReader(testGrammar)); err != nil {
+		t.Fatal(err)
+	}
+	return parser
+}
+
+func TestAssertParses(t *testing.T) {
+	objs := AssertParses(t, testTemplate, "ldi r1, 7f", "ldi", uint8(1), ",", 0x7f)
+	if len(objs) != 4 {
+		t.Errorf("AssertParses returned %d objects", len(objs))
+	}
+}
+
+func TestAssertFails(t *testing.T) {
+	AssertFails(t, testTemplate, "ldi r1, 100", "does not fit in Uint8")
+	AssertFails(t, testTemplate, "ldi r1", "")
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	got := AssertRoundTrip(t, testParser(t), "ldi r1, 10", "MOV  R1,R2")
+	want := []string{"ldi r1, 10", "mov r1, r2"}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Errorf("line %d formatted as %q, want %q", idx, got[idx], want[idx])
+		}
+	}
+}
+
+func TestAssertGenerated(t *testing.T) {
+	if lines := AssertGenerated(t, testParser(t), 1, 5); len(lines) != 10 {
+		t.Errorf("AssertGenerated generated %d lines, want 10", len(lines))
+	}
+}
+
+func TestValueEqual(t *testing.T) {
+	tests := []struct {
+		got, want interface{}
+		equal     bool
+	}{
+		{uint64(5), 5, true},
+		{uint64(5), uint8(5), true},
+		{uint64(5), -5, false},
+		{uint64(5), 6, false},
+		{"r1", "r1", true},
+		{"r1", 1, false},
+	}
+	for _, tt := range tests {
+		if got := ValueEqual(tt.got, tt.want); got != tt.equal {
+			t.Errorf("ValueEqual(%v, %v) = %v, want %v", tt.got, tt.want, got, tt.equal)
+		}
+	}
+}
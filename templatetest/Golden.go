@@ -0,0 +1,161 @@
+package templatetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jantypas/TemplateParser/TemplateParser"
+)
+
+// GoldenSuffix is appended to the name of a source file to name the file holding its
+// expected results.
+const GoldenSuffix = ".golden.json"
+
+// update makes Golden write the golden files instead of comparing with them:
+//
+//	go test ./... -args -templatetest.update
+var update = flag.Bool("templatetest.update", false, "rewrite the golden files of templatetest.Golden")
+
+// GoldenResult
+// is the content of a golden file: whether the source parsed, its line results and the
+// labels it defined.
+type GoldenResult struct {
+	Ok      bool                        `json:"ok"`
+	Lines   []TemplateParser.LineResult `json:"lines"`
+	Symbols []TemplateParser.Symbol     `json:"symbols,omitempty"`
+}
+
+// Golden
+// parses every source file in dir with parser's ParseFile, one subtest per file, and fails
+// the subtest unless the results, as a GoldenResult in indented JSON, equal the content of
+// the file's golden file: its name followed by GoldenSuffix. Files whose name starts with
+// . or _, such as files only included by others, are not parsed. Run the tests with
+// -args -templatetest.update to write the golden files from the current results, then
+// review them like any other change. Failed lines are part of the results, so a suite can
+// pin down its error messages too.
+func Golden(t *testing.T, parser *TemplateParser.Parser, dir string) {
+	t.Helper()
+	sources, err := goldenSources(dir)
+	if err != nil {
+		t.Fatalf("Golden(%q): %v", dir, err)
+	}
+	if len(sources) == 0 {
+		t.Fatalf("Golden(%q): no source files", dir)
+	}
+	for _, name := range sources {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			got, err := goldenJSON(parser, path)
+			if err != nil {
+				t.Fatalf("ParseFile(%q): %v", path, err)
+			}
+			goldenPath := path + GoldenSuffix
+			if *update {
+				if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+					t.Fatalf("writing %s: %v", goldenPath, err)
+				}
+				return
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("reading %s: %v (run with -args -templatetest.update to create it)", goldenPath, err)
+			}
+			if diff := goldenDiff(want, got); diff != "" {
+				t.Errorf("results of %s differ from %s (run with -args -templatetest.update to accept them):\n%s",
+					path, goldenPath, diff)
+			}
+		})
+	}
+}
+
+// AssertOutput
+// fails the test unless got equals the content of the file at path, showing the
+// differing lines as Golden does. It checks the output of a program against the output
+// expected of it, such as the expected.txt of the examples.
+func AssertOutput(t testing.TB, got []byte, path string) {
+	t.Helper()
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	if diff := goldenDiff(want, got); diff != "" {
+		t.Errorf("output differs from %s:\n%s", path, diff)
+	}
+}
+
+// goldenSources
+// returns the names of the source files in dir, sorted.
+func goldenSources(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, GoldenSuffix) || strings.HasPrefix(name, ".") ||
+			strings.HasPrefix(name, "_") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// goldenJSON
+// parses a source file and returns its GoldenResult as indented JSON.
+func goldenJSON(parser *TemplateParser.Parser, path string) ([]byte, error) {
+	result, err := parser.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	golden := GoldenResult{Ok: result.Ok(), Lines: result.Lines}
+	if result.Symbols != nil {
+		golden.Symbols = result.Symbols.Symbols()
+	}
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// goldenDiff
+// describes how got differs from want line by line, showing up to a few differing lines
+// with their line numbers, or returns "" if they are equal.
+func goldenDiff(want []byte, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+	const maxShown = 10
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	var sb strings.Builder
+	shown := 0
+	for idx := 0; idx < max(len(wantLines), len(gotLines)) && shown < maxShown; idx++ {
+		var wantLine, gotLine string
+		if idx < len(wantLines) {
+			wantLine = wantLines[idx]
+		}
+		if idx < len(gotLines) {
+			gotLine = gotLines[idx]
+		}
+		if wantLine == gotLine {
+			continue
+		}
+		fmt.Fprintf(&sb, "line %d:\n  want %s\n  got  %s\n", idx+1, wantLine, gotLine)
+		shown++
+	}
+	if shown == maxShown {
+		sb.WriteString("  ...\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,40 @@
+package templatetest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGolden(t *testing.T) {
+	Golden(t, testParser(t), filepath.Join("testdata", "golden"))
+}
+
+func TestGoldenSourcesSkipsIncludes(t *testing.T) {
+	names, err := goldenSources(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"errors.asm", "ok.asm"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("goldenSources = %v, want %v", names, want)
+	}
+}
+
+func TestGoldenDiff(t *testing.T) {
+	if diff := goldenDiff([]byte("a\nb\n"), []byte("a\nb\n")); diff != "" {
+		t.Errorf("equal files differ: %s", diff)
+	}
+	want := "line 2:\n  want b\n  got  c\n"
+	if diff := goldenDiff([]byte("a\nb\n"), []byte("a\nc\n")); diff != want {
+		t.Errorf("goldenDiff = %q, want %q", diff, want)
+	}
+}
+
+func TestAssertOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expected.txt")
+	if err := os.WriteFile(path, []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	AssertOutput(t, []byte("a\nb\n"), path)
+}
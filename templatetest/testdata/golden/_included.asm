@@ -0,0 +1 @@
+mov r3, r2
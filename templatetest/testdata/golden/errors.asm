@@ -0,0 +1,2 @@
+ldi r1, 100
+mov r1
@@ -0,0 +1,3 @@
+start: ldi r1, 10
+	mov r2, r1
+include "_included.asm"